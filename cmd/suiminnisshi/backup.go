@@ -0,0 +1,144 @@
+// cmd/suiminnisshi/backup.go
+// backupは、ユーザーのデータのバックアップ（JSON）を出力・復元するサブコマンドを提供します。
+// サーバーの移行では、移行元で backup を実行し、移行先で作成したアカウントに restore で復元します。
+//
+//	suiminnisshi backup --user ID [FILE]
+//	suiminnisshi restore --user ID FILE
+//
+// FILE を省略した場合、または - を指定した場合は標準出力（restore では標準入力）を使います。
+
+// Package main provides the entry point for SuiminNisshi.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/config"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+)
+
+const (
+	// backupサブコマンドの使い方
+	backupUsage = "usage: suiminnisshi backup --user ID [FILE]"
+	// restoreサブコマンドの使い方
+	restoreUsage = "usage: suiminnisshi restore --user ID FILE"
+)
+
+// backupサブコマンドを実行
+func runBackup(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	flags.SetOutput(out)
+	userID := flags.Int64("user", 0, "バックアップするユーザーのID")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(backupUsage)
+	}
+	if *userID == 0 || flags.NArg() > 1 {
+		return errors.New(backupUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+
+	db, repo, err := openRepository(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// バックアップの出力先が標準出力の場合があるため、ログは出力しない
+	svc := service.NewService(repo, service.ErrorLevel, log.New(io.Discard, "", 0))
+	backup, err := svc.Backup().Export(context.Background(), *userID)
+	if err != nil {
+		return err
+	}
+
+	output := out
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(backup)
+}
+
+// restoreサブコマンドを実行
+func runRestore(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(out)
+	userID := flags.Int64("user", 0, "復元先のユーザーのID（睡眠日誌のないアカウント）")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(restoreUsage)
+	}
+	if *userID == 0 || flags.NArg() != 1 {
+		return errors.New(restoreUsage)
+	}
+
+	input := in
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+
+	db, repo, err := openRepository(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// 復元した日のキャッシュを無効化できるよう、サーバーと同じキャッシュを使う
+	logger := log.New(out, "[SuiminNisshi] ", log.LstdFlags)
+	svc := service.NewService(repo, service.InfoLevel, logger)
+	summaryCache, err := newCache(cfg.Cache)
+	if err != nil {
+		return err
+	}
+	if closer, ok := summaryCache.(io.Closer); ok {
+		defer closer.Close()
+	}
+	svc.Summary().SetCache(summaryCache)
+
+	ctx := context.Background()
+	user, err := repo.User().GetByID(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user not found: %d", *userID)
+	}
+
+	backup, err := svc.Backup().Decode(input)
+	if err != nil {
+		return err
+	}
+	result, err := svc.Backup().Restore(ctx, user.ID, backup)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "restored user %d: %d diaries, %d records\n", user.ID, result.Diaries, result.Records)
+	return nil
+}
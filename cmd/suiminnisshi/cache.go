@@ -0,0 +1,29 @@
+// cmd/suiminnisshi/cache.go
+// cacheは、設定に応じた集計データのキャッシュを作成します。
+
+// Package main provides the entry point for SuiminNisshi.
+package main
+
+import (
+	"fmt"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/cache"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/config"
+)
+
+// 設定に応じた集計データのキャッシュを作成
+func newCache(cfg config.CacheConfig) (cache.Cache, error) {
+	switch cfg.Driver {
+	case "memory", "":
+		return cache.NewLRU(int64(cfg.MaxMB) << 20), nil
+	case "redis":
+		return cache.NewRedis(cache.RedisOptions{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+			Prefix:   cfg.RedisPrefix,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,107 @@
+// cmd/suiminnisshi/import_csv.go
+// import_csvは、CSVファイルの睡眠記録を取り込むサブコマンドを提供します。
+// 形式は設定画面の「データの取り込み」と同じで、取り込めない行がある場合は行ごとの誤りを表示して1件も取り込みません。
+//
+//	suiminnisshi import-csv --user ID [--dry-run] FILE
+//
+// FILE に - を指定した場合は標準入力から読み込みます。--dry-run を指定した場合は検証のみを行います。
+
+// Package main provides the entry point for SuiminNisshi.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/config"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+)
+
+// import-csvサブコマンドの使い方
+const importCSVUsage = "usage: suiminnisshi import-csv --user ID [--dry-run] FILE"
+
+// import-csvサブコマンドを実行
+func runImportCSV(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("import-csv", flag.ContinueOnError)
+	flags.SetOutput(out)
+	userID := flags.Int64("user", 0, "記録を取り込むユーザーのID")
+	dryRun := flags.Bool("dry-run", false, "検証のみを行い、記録を作成しない")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(importCSVUsage)
+	}
+	if *userID == 0 || flags.NArg() != 1 {
+		return errors.New(importCSVUsage)
+	}
+
+	input := in
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+
+	db, repo, err := openRepository(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// 取り込んだ日のキャッシュを無効化できるよう、サーバーと同じキャッシュを使う
+	logger := log.New(out, "[SuiminNisshi] ", log.LstdFlags)
+	svc := service.NewService(repo, service.InfoLevel, logger)
+	summaryCache, err := newCache(cfg.Cache)
+	if err != nil {
+		return err
+	}
+	if closer, ok := summaryCache.(io.Closer); ok {
+		defer closer.Close()
+	}
+	svc.Summary().SetCache(summaryCache)
+
+	ctx := context.Background()
+	user, err := repo.User().GetByID(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user not found: %d", *userID)
+	}
+
+	var result *service.CSVImport
+	if *dryRun {
+		result, err = svc.Import().PreviewCSV(ctx, user.ID, input)
+	} else {
+		result, err = svc.Import().ImportCSV(ctx, user.ID, input)
+	}
+	if result != nil {
+		for _, lineErr := range result.Errors {
+			fmt.Fprintf(out, "line %d: %s\n", lineErr.Line, lineErr.Message)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d invalid lines", len(result.Errors))
+	}
+
+	if *dryRun {
+		fmt.Fprintf(out, "checked  user %d: %d records to import (%d duplicates skipped)\n", user.ID, len(result.Rows), result.Duplicates)
+		return nil
+	}
+	fmt.Fprintf(out, "imported user %d: %d records (%d duplicates skipped)\n", user.ID, result.Imported, result.Duplicates)
+	return nil
+}
@@ -0,0 +1,41 @@
+// cmd/suiminnisshi/mailer.go
+// mailerは、設定に応じたメールの送信方法を作成します。
+
+// Package main provides the entry point for SuiminNisshi.
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/config"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/mail"
+)
+
+// 設定に応じたメールの送信方法を作成（"none" の場合はnil）
+func newMailer(cfg config.MailConfig) (mail.Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password), nil
+	case "file":
+		return mail.NewFileMailer(cfg.OutboxDir), nil
+	case "none", "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
+
+// 設定に応じたメールの送信キューを作成して送信を開始（"none" の場合はnil）
+func startMailQueue(cfg config.MailConfig, logger *log.Logger) (*mail.Queue, error) {
+	mailer, err := newMailer(cfg)
+	if err != nil || mailer == nil {
+		return nil, err
+	}
+
+	options := mail.DefaultQueueOptions()
+	options.MaxAttempts = cfg.MaxAttempts
+	queue := mail.NewQueue(mailer, logger, options)
+	queue.Start()
+	return queue, nil
+}
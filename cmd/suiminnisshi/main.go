@@ -7,18 +7,24 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	// ユーザーのタイムゾーンを実行環境のタイムゾーンデータベースに依存せず扱うため埋め込む
+	_ "time/tzdata"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/config"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/handler"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/middleware"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/mysql"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/sqlite"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -28,6 +34,43 @@ import (
 
 // サーバーの初期化、設定の読み込み、データベース接続、ルーターの設定、ハンドラーの登録、サーバーの起動、グレースフルシャットダウンを行います。
 func main() {
+	// サブコマンドの実行
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-summaries" {
+		if err := runRebuildSummaries(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-csv" {
+		if err := runImportCSV(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		if err := runBackup(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		if err := runRestore(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// ロガーの初期化
 	logger := log.New(os.Stdout, "[SuiminNisshi] ", log.LstdFlags|log.Lshortfile)
 	logger.Printf("[Initialize] SuiminNisshi Startup...")
@@ -39,28 +82,56 @@ func main() {
 		logger.Fatalf("[NG] Failed to load config: %v", err)
 	}
 
-	// データベース接続の初期化
-	logger.Printf("[Initialize] Connecting to database...")
-	dbConfig := mysql.DBConfig{
-		Host:     cfg.Database.Host,
-		Port:     cfg.Database.Port,
-		User:     cfg.Database.User,
-		Password: cfg.Database.Password,
-		DBName:   cfg.Database.DBName,
-	}
-	db, err := mysql.NewDB(dbConfig)
+	// データベース接続とリポジトリの初期化
+	logger.Printf("[Initialize] Connecting to database (%s)...", cfg.Database.Driver)
+	db, repo, err := openRepository(cfg.Database)
 	if err != nil {
 		logger.Fatalf("[NG] Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
-	// リポジトリの初期化
-	logger.Printf("[Initialize] Initializing repository...")
-	repo := mysql.NewMySQLRepository(db)
+	// マイグレーションの適用
+	if cfg.Database.AutoMigrate {
+		logger.Printf("[Initialize] Applying migrations...")
+		applied, err := autoMigrate(db, cfg.Database.Driver)
+		if err != nil {
+			logger.Fatalf("[NG] Failed to apply migrations: %v", err)
+		}
+		for _, m := range applied {
+			logger.Printf("[Migrate] Applied %04d_%s", m.Version, m.Name)
+		}
+	}
 
 	// サービスの初期化
 	logger.Printf("[Initialize] Initializing service...")
 	svc := service.NewService(repo, service.DebugLevel, logger)
+	svc.Session().SetLifetime(cfg.Session.Lifetime)
+	svc.SetBaseURL(cfg.Server.BaseURL)
+	svc.Email().SetFrom(cfg.Mail.From, cfg.Mail.FromName)
+	svc.LoginThrottle().SetPolicy(service.LoginThrottlePolicy{
+		MaxFailures:     cfg.Login.MaxFailures,
+		LockoutDuration: cfg.Login.LockoutDuration,
+	})
+	svc.Reminder().SetLeadTime(cfg.Reminder.LeadTime)
+	svc.WeeklyReport().SetAttachPDF(cfg.WeeklyReport.AttachPDF)
+
+	// 集計データのキャッシュの初期化
+	logger.Printf("[Initialize] Initializing summary cache (driver=%s)...", cfg.Cache.Driver)
+	summaryCache, err := newCache(cfg.Cache)
+	if err != nil {
+		logger.Fatalf("[NG] Failed to initialize summary cache: %v", err)
+	}
+	svc.Summary().SetCache(summaryCache)
+
+	// メール送信キューの初期化
+	logger.Printf("[Initialize] Starting mail queue (driver=%s)...", cfg.Mail.Driver)
+	mailQueue, err := startMailQueue(cfg.Mail, logger)
+	if err != nil {
+		logger.Fatalf("[NG] Failed to start mail queue: %v", err)
+	}
+	if mailQueue != nil {
+		svc.Email().SetMailer(mailQueue)
+	}
 
 	// テンプレートマネージャーの初期化
 	logger.Printf("[Initialize] Loading templates...")
@@ -98,70 +169,14 @@ func main() {
 	fileServer := http.FileServer(http.Dir("web/static"))
 	r.Handle("/static/*", http.StripPrefix("/static/", fileServer))
 
-	// ルートの登録
-	logger.Printf("[Initialize] Registering routes...")
-	router := handler.NewRouter(r)
-
-	// サービスをハンドラーに渡す
-	logger.Printf("[Initialize] Passing service to handlers...")
-	authHandler := handler.NewAuthHandler(tm, svc)
-	authHandler.RegisterRoutes(router)
-
-	// アカウント削除ハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering account deletion routes...")
-	accountDeletionHandler := handler.NewAccountDeletionHandler(tm, svc)
-	accountDeletionHandler.RegisterRoutes(r)
-
-	// ダッシュボードハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering dashboard routes...")
-	dashboardHandler := handler.NewDashboardHandler(tm, svc)
-	dashboardHandler.RegisterRoutes(router)
-
 	// エラーハンドラーの初期化
 	logger.Printf("[Initialize] Initializing error handler...")
 	errorHandler := handler.NewErrorHandler(tm, svc, logger)
 	r.NotFound(errorHandler.Handle404)
 	r.MethodNotAllowed(errorHandler.Handle404)
 
-	// パスワードリセットハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering password reset routes...")	
-	passwordResetHandler := handler.NewPasswordResetHandler(tm, svc)
-	passwordResetHandler.RegisterRoutes(r)
-
-	// プライバシーポリシーハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering privacy policy routes...")
-	privacyPolicyHandler := handler.NewPrivacyHandler(tm, svc)
-	privacyPolicyHandler.RegisterRoutes(r)
-
-	// プロフィールハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering profile routes...")
-	profileHandler := handler.NewProfileHandler(tm, svc)
-	profileHandler.RegisterRoutes(router)
-
-	// ユーザー登録ハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering registration routes...")
-	registrationHandler := handler.NewRegisterHandler(tm, svc)
-	registrationHandler.RegisterRoutes(r)
-
-	// 設定ハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering settings routes...")
-	settingsHandler := handler.NewSettingsHandler(tm, svc)
-	settingsHandler.RegisterRoutes(r)
-
-	// 睡眠記録ハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering sleep record routes...")
-	sleepRecordHandler := handler.NewSleepRecordHandler(tm, svc)
-	sleepRecordHandler.RegisterRoutes(r)
-
-	// 統計情報ハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering statistics routes...")
-	statisticsHandler := handler.NewStatisticsHandler(tm, svc)
-	statisticsHandler.RegisterRoutes(r)
-
-	// 利用規約ハンドラーの初期化と登録
-	logger.Printf("[Initialize] Registering terms routes...")
-	termsHandler := handler.NewTermsHandler(tm, svc)
-	termsHandler.RegisterRoutes(r)
+	// ハンドラーの初期化とルートの登録
+	handler.RegisterAppRoutes(r, tm, svc, errorHandler, logger)
 
 	// サーバーの設定
 	logger.Printf("[Initialize] Setting up server...")
@@ -181,12 +196,92 @@ func main() {
 		}
 	}()
 
+	// キャッシュの使用状況のサーバーの設定と起動
+	// 利用者に公開しないよう、アプリケーションとは別の内部向けのアドレスで提供する
+	var metricsServer *http.Server
+	if cfg.Cache.MetricsEnabled {
+		logger.Printf("[Initialize] Registering cache metrics route...")
+		metricsRouter := chi.NewRouter()
+		metricsRouter.Use(chimiddleware.Recoverer)
+		cacheMetricsHandler := handler.NewCacheMetricsHandler(svc)
+		cacheMetricsHandler.RegisterRoutes(metricsRouter)
+		metricsServer = &http.Server{
+			Addr:         cfg.Cache.MetricsAddr,
+			Handler:      metricsRouter,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+
+		go func() {
+			logger.Printf("[START] Cache metrics server is starting on %s", cfg.Cache.MetricsAddr)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Fatalf("[NG] Failed to start cache metrics server: %v", err)
+			}
+		}()
+	}
+
+	// 期限切れセッション・ログイン失敗の記録・パスワード再設定用トークン・通知の送信記録の定期削除
+	// 削除に失敗しても、残りの削除は続ける
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			count, err := svc.Session().PurgeExpired(context.Background())
+			if err != nil {
+				logger.Printf("[NG] Failed to purge expired sessions: %v", err)
+			} else if count > 0 {
+				logger.Printf("[Session] Purged %d expired sessions", count)
+			}
+
+			// 保存期間を過ぎたログイン失敗の記録も削除
+			count, err = svc.LoginThrottle().PurgeAudit(context.Background())
+			if err != nil {
+				logger.Printf("[NG] Failed to purge login failures: %v", err)
+			} else if count > 0 {
+				logger.Printf("[Login] Purged %d login failure records", count)
+			}
+
+			// 有効期限切れのパスワード再設定用トークンも削除
+			count, err = svc.User().PurgeExpiredResetTokens(context.Background())
+			if err != nil {
+				logger.Printf("[NG] Failed to purge password reset tokens: %v", err)
+			} else if count > 0 {
+				logger.Printf("[PasswordReset] Purged %d expired reset tokens", count)
+			}
+
+			// 保存期間を過ぎた通知の送信記録も削除
+			count, err = svc.Reminder().PurgeDeliveries(context.Background())
+			if err != nil {
+				logger.Printf("[NG] Failed to purge notification deliveries: %v", err)
+			} else if count > 0 {
+				logger.Printf("[Reminder] Purged %d notification delivery records", count)
+			}
+		}
+	}()
+
+	// 就寝時刻のリマインダー・週間レポートの送信
+	notifyCtx, stopNotify := context.WithCancel(context.Background())
+	defer stopNotify()
+	if cfg.Reminder.Enabled {
+		logger.Printf("[Initialize] Starting bedtime reminder (lead time=%s)...", cfg.Reminder.LeadTime)
+		go svc.Reminder().Run(notifyCtx, service.DefaultReminderInterval)
+	}
+	if cfg.WeeklyReport.Enabled {
+		logger.Printf("[Initialize] Starting weekly report (pdf=%t)...", cfg.WeeklyReport.AttachPDF)
+		go svc.WeeklyReport().Run(notifyCtx, service.DefaultWeeklyReportInterval)
+	}
+
+	// 集計データのキャッシュの再作成（日付が変わったとき）と使用状況のチェック
+	go svc.Summary().Run(notifyCtx, service.DefaultSummaryInterval)
+
 	// グレースフルシャットダウンの設定
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Println("[STOP] Server is shutting down...")
+	stopNotify()
 
 	// シャットダウンのコンテキスト
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -196,6 +291,54 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Fatalf("[STOP] Server forced to shutdown: %v", err)
 	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logger.Printf("[STOP] Cache metrics server forced to shutdown: %v", err)
+		}
+	}
+
+	// 送信待ちのメールを送信してからメール送信キューを停止
+	if mailQueue != nil {
+		if err := mailQueue.Close(ctx); err != nil {
+			logger.Printf("[STOP] Mail queue stopped before sending all mails: %v", err)
+		}
+	}
+
+	// 集計データのキャッシュへの接続を閉じる（Redisの場合）
+	if closer, ok := summaryCache.(io.Closer); ok {
+		closer.Close()
+	}
 
 	logger.Println("[STOP] Server stopped gracefully")
 }
+
+// 設定されたドライバーに応じてデータベースに接続し、リポジトリを作成
+func openRepository(cfg config.DatabaseConfig) (*sql.DB, repository.Repository, error) {
+	switch cfg.Driver {
+	case "sqlite":
+		db, err := sqlite.NewDB(sqlite.DBConfig{Path: cfg.Path})
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, sqlite.NewSQLiteRepository(db), nil
+	case "mysql", "":
+		db, err := mysql.NewDB(mysqlConfig(cfg))
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, mysql.NewMySQLRepository(db), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
+}
+
+// MySQLの接続設定を作成
+func mysqlConfig(cfg config.DatabaseConfig) mysql.DBConfig {
+	return mysql.DBConfig{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		User:     cfg.User,
+		Password: cfg.Password,
+		DBName:   cfg.DBName,
+	}
+}
@@ -0,0 +1,127 @@
+// cmd/suiminnisshi/migrate.go
+// migrateは、スキーママイグレーションのサブコマンドを提供します。
+//
+//	suiminnisshi migrate up          未適用のマイグレーションをすべて適用
+//	suiminnisshi migrate down [N]    適用済みのマイグレーションを新しいものからN件（既定は1件）取り消し
+//	suiminnisshi migrate status      マイグレーションの適用状況を表示
+
+// Package main provides the entry point for SuiminNisshi.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/config"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/migration"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/mysql"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/sqlite"
+)
+
+// migrateサブコマンドの使い方
+const migrateUsage = "usage: suiminnisshi migrate up|down [N]|status"
+
+// migrateサブコマンドを実行
+func runMigrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+
+	db, err := openDB(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	dialect, err := migration.DialectFor(cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+	migrator, err := migration.NewMigrator(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Fprintf(out, "applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Fprintf(out, "reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(out, "no applied migrations")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d_%-24s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
+
+// 起動時に未適用のマイグレーションを適用
+func autoMigrate(db *sql.DB, driver string) ([]migration.Migration, error) {
+	dialect, err := migration.DialectFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := migration.NewMigrator(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return migrator.Up(context.Background())
+}
+
+// マイグレーション用にデータベースへ接続
+// SQLiteの場合も自動でマイグレーションを適用しないよう、接続のみを行う
+func openDB(cfg config.DatabaseConfig) (*sql.DB, error) {
+	switch cfg.Driver {
+	case "sqlite":
+		return sqlite.Open(sqlite.DBConfig{Path: cfg.Path})
+	case "mysql", "":
+		return mysql.NewDB(mysqlConfig(cfg))
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,107 @@
+// cmd/suiminnisshi/rebuild_summaries.go
+// rebuild_summariesは、日別集計データを睡眠記録から集計し直すサブコマンドを提供します。
+// マイグレーションの適用前から記録がある場合や、集計方法を変更した場合の過去分の再集計（バックフィル）に使います。
+//
+//	suiminnisshi rebuild-summaries --from YYYY-MM-DD [--to YYYY-MM-DD] [--user ID]
+//
+// --to を省略した場合は今日（UTC）まで、--user を省略した場合は全ユーザーを集計し直します。
+
+// Package main provides the entry point for SuiminNisshi.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/config"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+)
+
+// rebuild-summariesサブコマンドの使い方
+const rebuildSummariesUsage = "usage: suiminnisshi rebuild-summaries --from YYYY-MM-DD [--to YYYY-MM-DD] [--user ID]"
+
+// rebuild-summariesサブコマンドを実行
+func runRebuildSummaries(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("rebuild-summaries", flag.ContinueOnError)
+	flags.SetOutput(out)
+	userID := flags.Int64("user", 0, "集計し直すユーザーのID（省略した場合は全ユーザー）")
+	from := flags.String("from", "", "集計し直す最初の睡眠日（YYYY-MM-DD）")
+	to := flags.String("to", "", "集計し直す最後の睡眠日（YYYY-MM-DD、省略した場合は今日）")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(rebuildSummariesUsage)
+	}
+
+	startDate, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		return fmt.Errorf("invalid --from date: %q\n%s", *from, rebuildSummariesUsage)
+	}
+	endDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if *to != "" {
+		if endDate, err = time.Parse("2006-01-02", *to); err != nil {
+			return fmt.Errorf("invalid --to date: %q\n%s", *to, rebuildSummariesUsage)
+		}
+	}
+	if startDate.After(endDate) {
+		return fmt.Errorf("--from must not be after --to\n%s", rebuildSummariesUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+
+	db, repo, err := openRepository(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// 集計し直した日のキャッシュを無効化できるよう、サーバーと同じキャッシュを使う
+	logger := log.New(out, "[SuiminNisshi] ", log.LstdFlags)
+	svc := service.NewService(repo, service.InfoLevel, logger)
+	summaryCache, err := newCache(cfg.Cache)
+	if err != nil {
+		return err
+	}
+	if closer, ok := summaryCache.(io.Closer); ok {
+		defer closer.Close()
+	}
+	svc.Summary().SetCache(summaryCache)
+
+	ctx := context.Background()
+	var users []*models.User
+	if *userID != 0 {
+		user, err := repo.User().GetByID(ctx, *userID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return fmt.Errorf("user not found: %d", *userID)
+		}
+		users = append(users, user)
+	} else if users, err = repo.User().GetAll(ctx); err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		summaries, err := svc.Summary().RecomputeDays(ctx, user.ID, startDate, endDate)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild summaries for user %d: %v", user.ID, err)
+		}
+		recorded := 0
+		for _, summary := range summaries {
+			if summary.HasRecords() {
+				recorded++
+			}
+		}
+		fmt.Fprintf(out, "rebuilt  user %d: %d days (%d with records)\n", user.ID, len(summaries), recorded)
+	}
+
+	return nil
+}
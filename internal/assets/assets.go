@@ -0,0 +1,16 @@
+// internal/assets/assets.go
+// assetsは、実行ファイルに埋め込むファイル（PDF出力用のフォントなど）を提供します。
+
+// Package assets provides files embedded in the executable.
+package assets
+
+import (
+	// ファイルを埋め込むために必要
+	_ "embed"
+)
+
+// PDF出力に使う日本語のゴシック体フォント（M+ 1p Regular、ライセンスは fonts/LICENSE.md）
+// 起動時の作業ディレクトリに関係なく読み込めるよう、実行ファイルに埋め込む
+//
+//go:embed fonts/mplus-1p-regular.ttf
+var GothicFont []byte
@@ -0,0 +1,45 @@
+// internal/cache/cache.go
+// cacheは、日別集計・期間統計などの計算結果を保持するキャッシュのインターフェイスとキーを提供します。
+// キャッシュの設計は doc/cache.md を参照してください。
+
+// Package cache provides caches for computed data.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// 日別集計データの保持期間
+	DailySummaryTTL = 90 * 24 * time.Hour
+	// 期間統計データの保持期間
+	PeriodStatsTTL = 24 * time.Hour
+)
+
+// 期間統計データの期間
+var StatsPeriods = []string{"30days", "60days", "90days"}
+
+// キャッシュのインターフェイス
+// 値はバイト列で保持し、見つからない場合と有効期限切れの場合はいずれも ok が false になる
+type Cache interface {
+	// 値を取得
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// 複数の値を一括で取得（見つかったキーのみを返す）
+	GetMulti(ctx context.Context, keys []string) (map[string][]byte, error)
+	// 値を保存（ttl が0以下の場合は有効期限なし）
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// 値を削除（存在しないキーは無視する）
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// 日別集計データのキャッシュキー（daily_summary:{user_id}:{date}）
+func DailySummaryKey(userID int64, date time.Time) string {
+	return fmt.Sprintf("daily_summary:%d:%s", userID, date.Format("2006-01-02"))
+}
+
+// 期間統計データのキャッシュキー（period_stats:{user_id}:{period}）
+func PeriodStatsKey(userID int64, period string) string {
+	return fmt.Sprintf("period_stats:%d:%s", userID, period)
+}
@@ -0,0 +1,179 @@
+// internal/cache/lru.go
+// lruは、プロセス内のメモリーに値を保持する、有効期限付きのLRUキャッシュを提供します。
+
+// Package cache provides caches for computed data.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// 既定の、LRUキャッシュの最大サイズ（64MB）
+const DefaultLRUMaxBytes = 64 << 20
+
+// 有効期限付きのLRUキャッシュ
+// 保持する値の合計サイズ（キーと値のバイト数）が上限を超えると、最も長く使われていない値から削除する
+type LRU struct {
+	mutex     sync.Mutex
+	maxBytes  int64
+	bytes     int64
+	items     map[string]*list.Element
+	order     *list.List // 先頭ほど最近使われた値
+	evictions uint64
+	now       func() time.Time
+}
+
+// LRUキャッシュの値
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time // ゼロ値の場合は有効期限なし
+}
+
+// 新しいLRUを作成（maxBytes が0以下の場合は既定の最大サイズ）
+func NewLRU(maxBytes int64) *LRU {
+	if maxBytes <= 0 {
+		maxBytes = DefaultLRUMaxBytes
+	}
+	return &LRU{
+		maxBytes: maxBytes,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// 現在時刻の取得方法を差し替え（テスト用）
+func (c *LRU) SetClock(now func() time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+// 値を取得
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	value, ok := c.get(key)
+	return value, ok, nil
+}
+
+// 複数の値を一括で取得
+func (c *LRU) GetMulti(_ context.Context, keys []string) (map[string][]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if value, ok := c.get(key); ok {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// 値を保存
+// 1つの値が最大サイズを超える場合は保存しない
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.remove(key)
+
+	entry := &lruEntry{key: key, value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expires = c.now().Add(ttl)
+	}
+	size := entry.size()
+	if size > c.maxBytes {
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(entry)
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeElement(oldest)
+		c.evictions++
+	}
+
+	return nil
+}
+
+// 値を削除
+func (c *LRU) Delete(_ context.Context, keys ...string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range keys {
+		c.remove(key)
+	}
+	return nil
+}
+
+// 保持している値の数
+func (c *LRU) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+// 保持している値の合計サイズ（バイト）
+func (c *LRU) Size() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.bytes
+}
+
+// 最大サイズ（バイト）
+func (c *LRU) MaxSize() int64 {
+	return c.maxBytes
+}
+
+// 最大サイズを超えたために削除した値の数
+func (c *LRU) Evictions() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.evictions
+}
+
+// 値を取得して最近使われた値にする（有効期限切れの値は削除する）
+func (c *LRU) get(key string) ([]byte, bool) {
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.removeElement(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return append([]byte(nil), entry.value...), true
+}
+
+// キーの値を削除
+func (c *LRU) remove(key string) {
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// 値を削除
+func (c *LRU) removeElement(element *list.Element) {
+	entry := c.order.Remove(element).(*lruEntry)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size()
+}
+
+// 値のサイズ（キーと値のバイト数）
+func (e *lruEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
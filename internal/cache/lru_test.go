@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// テスト用の時計
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// 保持している値のキーを、最近使われた順に取得
+func lruKeys(c *LRU) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var keys []string
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		keys = append(keys, element.Value.(*lruEntry).key)
+	}
+	return keys
+}
+
+// 合計サイズが上限を超えると、最も長く使われていない値から削除することを確認
+func TestLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	// キー1バイト・値9バイトの値を3つまで保持できる
+	c := NewLRU(30)
+	value := []byte("123456789")
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(ctx, key, value, 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+	if c.Len() != 3 || c.Size() != 30 || c.Evictions() != 0 {
+		t.Fatalf("len = %d, size = %d, evictions = %d, want 3, 30, 0", c.Len(), c.Size(), c.Evictions())
+	}
+
+	// a を使うと、最も長く使われていないのは b になる
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("Get a: not found")
+	}
+	if err := c.Set(ctx, "d", value, 0); err != nil {
+		t.Fatalf("Set d: %v", err)
+	}
+	if got, want := lruKeys(c), []string{"d", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys = %v, want %v", got, want)
+	}
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Fatal("Get b: evicted value was found")
+	}
+
+	// 大きな値は、収まるまで複数の値を削除する
+	if err := c.Set(ctx, "e", []byte("1234567890123456789"), 0); err != nil {
+		t.Fatalf("Set e: %v", err)
+	}
+	if got, want := lruKeys(c), []string{"e", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys = %v, want %v", got, want)
+	}
+	if c.Size() != 30 || c.Evictions() != 3 {
+		t.Fatalf("size = %d, evictions = %d, want 30, 3", c.Size(), c.Evictions())
+	}
+
+	// 同じキーの上書きはサイズを差し替える
+	if err := c.Set(ctx, "d", []byte("1"), 0); err != nil {
+		t.Fatalf("Set d: %v", err)
+	}
+	if c.Size() != 22 || c.Len() != 2 {
+		t.Fatalf("size = %d, len = %d, want 22, 2", c.Size(), c.Len())
+	}
+
+	// 最大サイズを超える値は保存せず、他の値も削除しない
+	if err := c.Set(ctx, "f", make([]byte, 30), 0); err != nil {
+		t.Fatalf("Set f: %v", err)
+	}
+	if _, ok, _ := c.Get(ctx, "f"); ok {
+		t.Fatal("Get f: oversized value was stored")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("len = %d, want 2", c.Len())
+	}
+
+	// 削除したサイズは差し引く
+	if err := c.Delete(ctx, "d", "missing"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if c.Size() != 20 || c.Len() != 1 {
+		t.Fatalf("size = %d, len = %d, want 20, 1", c.Size(), c.Len())
+	}
+}
+
+// 有効期限を過ぎた値は取得できず、削除されることを確認
+func TestLRUExpiry(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewLRU(0)
+	c.SetClock(clock.Now)
+
+	c.Set(ctx, "short", []byte("1"), time.Minute)
+	c.Set(ctx, "long", []byte("2"), time.Hour)
+	c.Set(ctx, "forever", []byte("3"), 0)
+
+	clock.now = clock.now.Add(time.Minute - time.Nanosecond)
+	values, _ := c.GetMulti(ctx, []string{"short", "long", "forever", "missing"})
+	if len(values) != 3 {
+		t.Fatalf("before expiry: values = %v, want 3 values", values)
+	}
+
+	clock.now = clock.now.Add(time.Nanosecond)
+	if _, ok, _ := c.Get(ctx, "short"); ok {
+		t.Fatal("short: expired value was found")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("len = %d, want 2 (expired value is removed)", c.Len())
+	}
+
+	clock.now = clock.now.Add(24 * time.Hour)
+	values, _ = c.GetMulti(ctx, []string{"short", "long", "forever"})
+	if got, ok := values["forever"]; len(values) != 1 || !ok || string(got) != "3" {
+		t.Fatalf("after a day: values = %v, want only forever", values)
+	}
+}
+
+// 取得した値を変更しても、キャッシュの値は変わらないことを確認
+func TestLRUCopiesValues(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(0)
+	value := []byte("abc")
+	c.Set(ctx, "key", value, 0)
+	value[0] = 'x'
+
+	got, _, _ := c.Get(ctx, "key")
+	got[1] = 'y'
+	if again, _, _ := c.Get(ctx, "key"); string(again) != "abc" {
+		t.Fatalf("value = %q, want abc", again)
+	}
+}
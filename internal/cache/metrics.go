@@ -0,0 +1,182 @@
+// internal/cache/metrics.go
+// metricsは、キャッシュのヒット率・更新の成功率・メモリー使用率を集計する機能を提供します。
+// 監視項目とアラート条件は doc/cache.md の「5. 監視と運用」を参照してください。
+
+// Package cache provides caches for computed data.
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// ヒット率のアラートのしきい値（%、これ以下でアラート）
+	HitRateAlertThreshold = 85.0
+	// メモリー使用率のアラートのしきい値（%、これ以上でアラート）
+	MemoryUsageAlertThreshold = 80.0
+)
+
+// キャッシュの使用状況を集計する Cache
+// 取得・保存・削除の回数を数え、元のキャッシュが対応していれば値の数やサイズもあわせて返す
+type Metered struct {
+	cache        Cache
+	hits         atomic.Uint64
+	misses       atomic.Uint64
+	getErrors    atomic.Uint64
+	sets         atomic.Uint64
+	setErrors    atomic.Uint64
+	deletes      atomic.Uint64
+	deleteErrors atomic.Uint64
+}
+
+// 値の数・サイズを返せるキャッシュ（LRU）
+type sizer interface {
+	Len() int
+	Size() int64
+	MaxSize() int64
+	Evictions() uint64
+}
+
+// キャッシュの使用状況
+type Stats struct {
+	Hits         uint64 `json:"hits"`
+	Misses       uint64 `json:"misses"`
+	GetErrors    uint64 `json:"get_errors"`
+	Sets         uint64 `json:"sets"`
+	SetErrors    uint64 `json:"set_errors"`
+	Deletes      uint64 `json:"deletes"`
+	DeleteErrors uint64 `json:"delete_errors"`
+	Evictions    uint64 `json:"evictions"`
+	Entries      int    `json:"entries"`
+	Bytes        int64  `json:"bytes"`
+	MaxBytes     int64  `json:"max_bytes"` // 0の場合は不明（Redisなど）
+}
+
+// 新しいMeteredを作成
+func NewMetered(cache Cache) *Metered {
+	return &Metered{cache: cache}
+}
+
+// 値を取得
+func (m *Metered) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := m.cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		m.getErrors.Add(1)
+	case ok:
+		m.hits.Add(1)
+	default:
+		m.misses.Add(1)
+	}
+	return value, ok, err
+}
+
+// 複数の値を一括で取得（キーごとにヒット・ミスを数える）
+func (m *Metered) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values, err := m.cache.GetMulti(ctx, keys)
+	if err != nil {
+		m.getErrors.Add(1)
+		return nil, err
+	}
+	m.hits.Add(uint64(len(values)))
+	m.misses.Add(uint64(len(keys) - len(values)))
+	return values, nil
+}
+
+// 値を保存
+func (m *Metered) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := m.cache.Set(ctx, key, value, ttl); err != nil {
+		m.setErrors.Add(1)
+		return err
+	}
+	m.sets.Add(1)
+	return nil
+}
+
+// 値を削除
+func (m *Metered) Delete(ctx context.Context, keys ...string) error {
+	if err := m.cache.Delete(ctx, keys...); err != nil {
+		m.deleteErrors.Add(1)
+		return err
+	}
+	m.deletes.Add(uint64(len(keys)))
+	return nil
+}
+
+// 使用状況を取得
+func (m *Metered) Stats() Stats {
+	stats := Stats{
+		Hits:         m.hits.Load(),
+		Misses:       m.misses.Load(),
+		GetErrors:    m.getErrors.Load(),
+		Sets:         m.sets.Load(),
+		SetErrors:    m.setErrors.Load(),
+		Deletes:      m.deletes.Load(),
+		DeleteErrors: m.deleteErrors.Load(),
+	}
+	if s, ok := m.cache.(sizer); ok {
+		stats.Entries = s.Len()
+		stats.Bytes = s.Size()
+		stats.MaxBytes = s.MaxSize()
+		stats.Evictions = s.Evictions()
+	}
+	return stats
+}
+
+// ヒット率（%、取得の記録がない場合は0）
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses + s.GetErrors
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total) * 100
+}
+
+// 更新（保存・削除）の成功率（%、更新の記録がない場合は100）
+func (s Stats) UpdateSuccessRate() float64 {
+	succeeded := s.Sets + s.Deletes
+	total := succeeded + s.SetErrors + s.DeleteErrors
+	if total == 0 {
+		return 100
+	}
+	return float64(succeeded) / float64(total) * 100
+}
+
+// メモリー使用率（%、最大サイズが不明な場合は0）
+func (s Stats) MemoryUsage() float64 {
+	if s.MaxBytes <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / float64(s.MaxBytes) * 100
+}
+
+// 前回の使用状況からの差分（回数のみ差分とし、値の数・サイズは現在の値のまま）
+func (s Stats) Since(previous Stats) Stats {
+	s.Hits -= previous.Hits
+	s.Misses -= previous.Misses
+	s.GetErrors -= previous.GetErrors
+	s.Sets -= previous.Sets
+	s.SetErrors -= previous.SetErrors
+	s.Deletes -= previous.Deletes
+	s.DeleteErrors -= previous.DeleteErrors
+	s.Evictions -= previous.Evictions
+	return s
+}
+
+// アラート条件に該当する項目の一覧
+// ヒット率は、判断できるだけの取得（minLookups 回以上）がある場合のみ判定する
+func (s Stats) Alerts(minLookups uint64) []string {
+	var alerts []string
+	if s.Hits+s.Misses+s.GetErrors >= minLookups && s.HitRate() <= HitRateAlertThreshold {
+		alerts = append(alerts, "キャッシュのヒット率が85%以下です")
+	}
+	if s.SetErrors+s.DeleteErrors > 0 {
+		alerts = append(alerts, "キャッシュの更新エラーが発生しています")
+	}
+	if s.MemoryUsage() >= MemoryUsageAlertThreshold {
+		alerts = append(alerts, "キャッシュのメモリー使用率が80%以上です")
+	}
+	return alerts
+}
@@ -0,0 +1,168 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var errTestCache = errors.New("cache unavailable")
+
+// 取得・保存・削除を失敗させられるキャッシュ
+type failingCache struct {
+	Cache
+	err error
+}
+
+func (c *failingCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if c.err != nil {
+		return nil, false, c.err
+	}
+	return c.Cache.Get(ctx, key)
+}
+
+func (c *failingCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.Cache.GetMulti(ctx, keys)
+}
+
+func (c *failingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.Cache.Set(ctx, key, value, ttl)
+}
+
+func (c *failingCache) Delete(ctx context.Context, keys ...string) error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.Cache.Delete(ctx, keys...)
+}
+
+// 取得・保存・削除の回数と、LRUの値の数・サイズを集計することを確認
+func TestMeteredStats(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(100)
+	m := NewMetered(lru)
+
+	m.Set(ctx, "a", []byte("123"), 0)
+	m.Set(ctx, "b", []byte("456"), 0)
+	m.Get(ctx, "a")
+	m.Get(ctx, "missing")
+	// GetMulti はキーごとに数える
+	m.GetMulti(ctx, []string{"a", "b", "c", "d"})
+	m.Delete(ctx, "b", "c")
+
+	want := Stats{Hits: 3, Misses: 3, Sets: 2, Deletes: 2, Entries: 1, Bytes: 4, MaxBytes: 100}
+	if got := m.Stats(); got != want {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+	if got := m.Stats().HitRate(); got != 50 {
+		t.Errorf("hit rate = %v, want 50", got)
+	}
+	if got := m.Stats().MemoryUsage(); got != 4 {
+		t.Errorf("memory usage = %v, want 4", got)
+	}
+
+	// 失敗は成功と別に数える
+	failing := &failingCache{Cache: NewLRU(0), err: errTestCache}
+	m = NewMetered(failing)
+	m.Get(ctx, "a")
+	m.GetMulti(ctx, []string{"a", "b"})
+	m.Set(ctx, "a", []byte("1"), 0)
+	m.Delete(ctx, "a")
+	failing.err = nil
+	m.Set(ctx, "a", []byte("1"), 0)
+	m.Get(ctx, "a")
+
+	want = Stats{Hits: 1, GetErrors: 2, Sets: 1, SetErrors: 1, DeleteErrors: 1}
+	if got := m.Stats(); got != want {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+	if got := m.Stats().UpdateSuccessRate(); math.Abs(got-100.0/3) > 1e-9 {
+		t.Errorf("update success rate = %v, want 33.3", got)
+	}
+}
+
+// ヒット率・更新の成功率・メモリー使用率の計算を確認
+func TestStatsRates(t *testing.T) {
+	tests := []struct {
+		name        string
+		stats       Stats
+		hitRate     float64
+		successRate float64
+		memoryUsage float64
+	}{
+		{name: "記録なし", stats: Stats{}, hitRate: 0, successRate: 100, memoryUsage: 0},
+		{name: "取得エラーはミスとして扱う", stats: Stats{Hits: 3, GetErrors: 1}, hitRate: 75, successRate: 100},
+		{name: "更新", stats: Stats{Sets: 6, Deletes: 3, SetErrors: 1}, successRate: 90},
+		{name: "最大サイズが不明", stats: Stats{Bytes: 100}, successRate: 100, memoryUsage: 0},
+		{name: "メモリー使用率", stats: Stats{Bytes: 80, MaxBytes: 100}, successRate: 100, memoryUsage: 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.HitRate(); math.Abs(got-tt.hitRate) > 1e-9 {
+				t.Errorf("HitRate = %v, want %v", got, tt.hitRate)
+			}
+			if got := tt.stats.UpdateSuccessRate(); math.Abs(got-tt.successRate) > 1e-9 {
+				t.Errorf("UpdateSuccessRate = %v, want %v", got, tt.successRate)
+			}
+			if got := tt.stats.MemoryUsage(); math.Abs(got-tt.memoryUsage) > 1e-9 {
+				t.Errorf("MemoryUsage = %v, want %v", got, tt.memoryUsage)
+			}
+		})
+	}
+}
+
+// アラート条件のしきい値を確認
+func TestStatsAlerts(t *testing.T) {
+	const (
+		hitRateAlert = "キャッシュのヒット率が85%以下です"
+		updateAlert  = "キャッシュの更新エラーが発生しています"
+		memoryAlert  = "キャッシュのメモリー使用率が80%以上です"
+	)
+
+	tests := []struct {
+		name  string
+		stats Stats
+		want  []string
+	}{
+		{name: "正常", stats: Stats{Hits: 90, Misses: 10, Bytes: 79, MaxBytes: 100}},
+		{name: "ヒット率が85%", stats: Stats{Hits: 85, Misses: 15}, want: []string{hitRateAlert}},
+		{name: "ヒット率が85%を超える", stats: Stats{Hits: 86, Misses: 14}},
+		{name: "取得エラーもヒット率に含める", stats: Stats{Hits: 85, Misses: 10, GetErrors: 5}, want: []string{hitRateAlert}},
+		{name: "取得回数が少ない場合はヒット率を判定しない", stats: Stats{Hits: 1, Misses: 98}},
+		{name: "保存エラー", stats: Stats{Sets: 100, SetErrors: 1}, want: []string{updateAlert}},
+		{name: "削除エラー", stats: Stats{DeleteErrors: 1}, want: []string{updateAlert}},
+		{name: "メモリー使用率が80%", stats: Stats{Bytes: 80, MaxBytes: 100}, want: []string{memoryAlert}},
+		{
+			name:  "すべて",
+			stats: Stats{Hits: 10, Misses: 90, SetErrors: 1, Bytes: 95, MaxBytes: 100},
+			want:  []string{hitRateAlert, updateAlert, memoryAlert},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Alerts(100); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Alerts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 前回からの差分は回数のみ差し引くことを確認
+func TestStatsSince(t *testing.T) {
+	previous := Stats{Hits: 10, Misses: 5, Sets: 3, SetErrors: 1, Evictions: 2, Entries: 4, Bytes: 40, MaxBytes: 100}
+	current := Stats{Hits: 15, Misses: 6, Sets: 5, SetErrors: 1, Evictions: 3, Entries: 6, Bytes: 60, MaxBytes: 100}
+
+	want := Stats{Hits: 5, Misses: 1, Sets: 2, Evictions: 1, Entries: 6, Bytes: 60, MaxBytes: 100}
+	if got := current.Since(previous); got != want {
+		t.Fatalf("Since = %+v, want %+v", got, want)
+	}
+}
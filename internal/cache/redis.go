@@ -0,0 +1,291 @@
+// internal/cache/redis.go
+// redisは、Redis互換のサーバー（Redis・Valkey・KeyDBなど）に値を保持するキャッシュを提供します。
+// 外部ライブラリに依存しないよう、必要なコマンド（GET・MGET・SET・DEL）のみをRESPで送受信します。
+
+// Package cache provides caches for computed data.
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// ErrRedisProtocol Redisの応答が正しくありません
+var ErrRedisProtocol = errors.New("invalid redis response / Redisの応答が正しくありません")
+
+// Redis互換のサーバーへの接続の設定
+type RedisOptions struct {
+	Addr        string        // ホスト:ポート
+	Password    string        // AUTH のパスワード（空の場合は認証しない）
+	DB          int           // SELECT するデータベース番号
+	Prefix      string        // キーの前に付ける文字列（他のアプリケーションとサーバーを共有する場合）
+	PoolSize    int           // 保持する接続の数
+	DialTimeout time.Duration // 接続のタイムアウト
+	Timeout     time.Duration // 1コマンドあたりの読み書きのタイムアウト
+}
+
+// Redis互換のサーバーに値を保持するキャッシュ
+type Redis struct {
+	options RedisOptions
+	pool    chan *redisConn
+}
+
+// Redisへの接続
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Redisのエラー応答
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// 新しいRedisを作成（0以下の設定項目は既定値を使う）
+// 接続は最初のコマンドの実行時に行う
+func NewRedis(options RedisOptions) *Redis {
+	if options.PoolSize <= 0 {
+		options.PoolSize = 4
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = 3 * time.Second
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = time.Second
+	}
+	return &Redis{
+		options: options,
+		pool:    make(chan *redisConn, options.PoolSize),
+	}
+}
+
+// 値を取得
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", r.options.Prefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, ErrRedisProtocol
+	}
+	return value, true, nil
+}
+
+// 複数の値を一括で取得（MGET）
+func (r *Redis) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, "MGET")
+	for _, key := range keys {
+		args = append(args, r.options.Prefix+key)
+	}
+	reply, err := r.do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != len(keys) {
+		return nil, ErrRedisProtocol
+	}
+	for i, item := range items {
+		if value, ok := item.([]byte); ok {
+			values[keys[i]] = value
+		}
+	}
+	return values, nil
+}
+
+// 値を保存（有効期限はミリ秒単位で設定する）
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", r.options.Prefix + key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// 値を削除
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, r.options.Prefix+key)
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// 保持している接続を閉じる
+func (r *Redis) Close() error {
+	for {
+		select {
+		case conn := <-r.pool:
+			conn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// コマンドを実行して応答を取得
+// 通信に失敗した接続は破棄し、エラー応答を受け取った接続は再利用する（read はエラー応答の場合も応答の全体を読み込む）
+func (r *Redis) do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := r.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(r.options.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.conn.SetDeadline(deadline)
+
+	reply, err := conn.command(args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		conn.conn.Close()
+		return nil, err
+	}
+	r.put(conn)
+	return reply, err
+}
+
+// 接続を取得（保持している接続がなければ新たに接続する）
+func (r *Redis) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-r.pool:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: r.options.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", r.options.Addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+	netConn.SetDeadline(time.Now().Add(r.options.Timeout))
+
+	if r.options.Password != "" {
+		if _, err := conn.command("AUTH", r.options.Password); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if r.options.DB != 0 {
+		if _, err := conn.command("SELECT", strconv.Itoa(r.options.DB)); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// 接続を返却（保持できる数を超える場合は閉じる）
+func (r *Redis) put(conn *redisConn) {
+	select {
+	case r.pool <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+// コマンドを送信して応答を読み込む
+func (c *redisConn) command(args ...string) (interface{}, error) {
+	buf := make([]byte, 0, 64)
+	buf = fmt.Appendf(buf, "*%d\r\n", len(args))
+	for _, arg := range args {
+		buf = fmt.Appendf(buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// 応答を1つ読み込む
+// 単純文字列は string、整数は int64、バルク文字列は []byte（NULLの場合は nil）、配列は []interface{} で返す
+// エラー応答（配列の要素を含む）は redisError を返す
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, ErrRedisProtocol
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, ErrRedisProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		value := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, value); err != nil {
+			return nil, err
+		}
+		return value[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, ErrRedisProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		// 要素のエラー応答は、残りの要素をすべて読み込んでから返す（接続に読み残しがあると、次のコマンドが残りを応答として読み込むため）
+		items := make([]interface{}, n)
+		var replyErr error
+		for i := range items {
+			item, err := c.read()
+			var itemErr redisError
+			if errors.As(err, &itemErr) {
+				if replyErr == nil {
+					replyErr = err
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		if replyErr != nil {
+			return nil, replyErr
+		}
+		return items, nil
+	}
+	return nil, ErrRedisProtocol
+}
@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 決まった応答を返す接続
+type fakeConn struct {
+	reader  *strings.Reader
+	written bytes.Buffer
+	closed  bool
+}
+
+func (c *fakeConn) Read(b []byte) (int, error)       { return c.reader.Read(b) }
+func (c *fakeConn) Write(b []byte) (int, error)      { return c.written.Write(b) }
+func (c *fakeConn) Close() error                     { c.closed = true; return nil }
+func (c *fakeConn) LocalAddr() net.Addr              { return nil }
+func (c *fakeConn) RemoteAddr() net.Addr             { return nil }
+func (c *fakeConn) SetDeadline(time.Time) error      { return nil }
+func (c *fakeConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *fakeConn) SetWriteDeadline(time.Time) error { return nil }
+
+// 応答 replies を返す接続を作成
+func newFakeRedisConn(replies string) (*redisConn, *fakeConn) {
+	conn := &fakeConn{reader: strings.NewReader(replies)}
+	return &redisConn{conn: conn, reader: bufio.NewReader(conn)}, conn
+}
+
+// 応答 replies を返す接続を保持したRedisを作成
+func newFakeRedis(replies string, prefix string) (*Redis, *fakeConn) {
+	r := NewRedis(RedisOptions{Addr: "127.0.0.1:0", Prefix: prefix, PoolSize: 1})
+	conn, fake := newFakeRedisConn(replies)
+	r.pool <- conn
+	return r, fake
+}
+
+// RESPの応答の読み込みを確認
+func TestRedisRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "単純文字列", reply: "+OK\r\n", want: "OK"},
+		{name: "エラー", reply: "-ERR unknown command\r\n", wantErr: "redis: ERR unknown command"},
+		{name: "整数", reply: ":42\r\n", want: int64(42)},
+		{name: "バルク文字列", reply: "$5\r\nhello\r\n", want: []byte("hello")},
+		{name: "改行を含むバルク文字列", reply: "$4\r\na\r\nb\r\n", want: []byte("a\r\nb")},
+		{name: "空のバルク文字列", reply: "$0\r\n\r\n", want: []byte{}},
+		{name: "NULLのバルク文字列", reply: "$-1\r\n", want: nil},
+		{name: "配列", reply: "*3\r\n$1\r\na\r\n$-1\r\n:1\r\n", want: []interface{}{[]byte("a"), nil, int64(1)}},
+		{name: "入れ子の配列", reply: "*2\r\n*1\r\n+x\r\n$1\r\nb\r\n", want: []interface{}{[]interface{}{"x"}, []byte("b")}},
+		{name: "NULLの配列", reply: "*-1\r\n", want: nil},
+		{name: "要素のエラー", reply: "*3\r\n$1\r\na\r\n-ERR first\r\n-ERR second\r\n", wantErr: "redis: ERR first"},
+		{name: "入れ子の要素のエラー", reply: "*2\r\n*2\r\n-ERR nested\r\n$1\r\nx\r\n$1\r\nb\r\n", wantErr: "redis: ERR nested"},
+		{name: "不明な種類", reply: "?1\r\n", wantErr: ErrRedisProtocol.Error()},
+		{name: "CRLFでない", reply: "+OK\n", wantErr: ErrRedisProtocol.Error()},
+		{name: "長さが数値でない", reply: "$x\r\n", wantErr: ErrRedisProtocol.Error()},
+		{name: "途中で切れたバルク文字列", reply: "$20\r\nhel", wantErr: io.ErrUnexpectedEOF.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 応答の後ろに次の応答を置き、読み残しがないことを確認する
+			conn, _ := newFakeRedisConn(tt.reply + "+NEXT\r\n")
+			got, err := conn.read()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %s", err, tt.wantErr)
+				}
+				var replyErr redisError
+				if !errors.As(err, &replyErr) {
+					return
+				}
+			} else if err != nil {
+				t.Fatalf("read: %v", err)
+			} else if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("read = %#v, want %#v", got, tt.want)
+			}
+
+			// エラー応答の場合も、応答の全体を読み込んでいる
+			if next, err := conn.read(); err != nil || next != "NEXT" {
+				t.Fatalf("next reply = %#v, %v, want NEXT", next, err)
+			}
+		})
+	}
+}
+
+// コマンドをRESPの配列で送信することを確認
+func TestRedisCommand(t *testing.T) {
+	r, fake := newFakeRedis("+OK\r\n:2\r\n", "app:")
+	ctx := context.Background()
+
+	if err := r.Set(ctx, "key", []byte("a\r\nb"), 1500*time.Millisecond); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := r.Delete(ctx, "a", "b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	want := "*5\r\n$3\r\nSET\r\n$7\r\napp:key\r\n$4\r\na\r\nb\r\n$2\r\nPX\r\n$4\r\n1500\r\n" +
+		"*3\r\n$3\r\nDEL\r\n$5\r\napp:a\r\n$5\r\napp:b\r\n"
+	if got := fake.written.String(); got != want {
+		t.Fatalf("written = %q, want %q", got, want)
+	}
+}
+
+// GET・MGETの応答の変換を確認
+func TestRedisGet(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newFakeRedis("$5\r\nvalue\r\n$-1\r\n*3\r\n$1\r\na\r\n$-1\r\n$1\r\nc\r\n", "")
+
+	value, ok, err := r.Get(ctx, "found")
+	if err != nil || !ok || string(value) != "value" {
+		t.Fatalf("Get found = %q, %v, %v", value, ok, err)
+	}
+	if value, ok, err := r.Get(ctx, "missing"); err != nil || ok || value != nil {
+		t.Fatalf("Get missing = %q, %v, %v", value, ok, err)
+	}
+	values, err := r.GetMulti(ctx, []string{"a", "b", "c"})
+	if want := map[string][]byte{"a": []byte("a"), "c": []byte("c")}; err != nil || !reflect.DeepEqual(values, want) {
+		t.Fatalf("GetMulti = %q, %v, want %q", values, err, want)
+	}
+}
+
+// エラー応答を受け取った接続は、応答を読み終えた状態で再利用することを確認
+func TestRedisReuseAfterErrorReply(t *testing.T) {
+	ctx := context.Background()
+	// MGET の要素のエラー応答の後に、次の GET の応答が続く
+	r, fake := newFakeRedis("*3\r\n$1\r\na\r\n-WRONGTYPE wrong kind\r\n$1\r\nc\r\n$4\r\nnext\r\n", "")
+
+	if _, err := r.GetMulti(ctx, []string{"a", "b", "c"}); err == nil || err.Error() != "redis: WRONGTYPE wrong kind" {
+		t.Fatalf("GetMulti: error = %v, want WRONGTYPE", err)
+	}
+	if fake.closed || len(r.pool) != 1 {
+		t.Fatalf("connection after error reply: closed = %v, pooled = %d, want reused", fake.closed, len(r.pool))
+	}
+
+	value, ok, err := r.Get(ctx, "next")
+	if err != nil || !ok || string(value) != "next" {
+		t.Fatalf("Get after error reply = %q, %v, %v, want next", value, ok, err)
+	}
+}
+
+// 応答が正しくない接続は破棄することを確認
+func TestRedisDiscardBrokenConn(t *testing.T) {
+	ctx := context.Background()
+	r, fake := newFakeRedis("*2\r\n$1\r\na\r\n?broken\r\n", "")
+
+	if _, err := r.GetMulti(ctx, []string{"a", "b"}); !errors.Is(err, ErrRedisProtocol) {
+		t.Fatalf("GetMulti: error = %v, want %v", err, ErrRedisProtocol)
+	}
+	if !fake.closed || len(r.pool) != 0 {
+		t.Fatalf("broken connection: closed = %v, pooled = %d, want discarded", fake.closed, len(r.pool))
+	}
+}
@@ -7,14 +7,21 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 /*
 	アプリケーション全体の設定を保持する構造体
 */
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Session      SessionConfig
+	Login        LoginConfig
+	Mail         MailConfig
+	Reminder     ReminderConfig
+	WeeklyReport WeeklyReportConfig
+	Cache        CacheConfig
 }
 
 /*
@@ -30,11 +37,74 @@ type ServerConfig struct {
 	データベース関連の設定
 */
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
+	Driver      string // "mysql" または "sqlite"
+	Path        string // SQLiteのデータベースファイルのパス
+	Host        string
+	Port        int
+	User        string
+	Password    string
+	DBName      string
+	AutoMigrate bool // 起動時に未適用のマイグレーションを適用するか
+}
+
+/*
+	セッション関連の設定
+*/
+type SessionConfig struct {
+	Lifetime time.Duration
+}
+
+/*
+	ログイン試行の制限関連の設定
+*/
+type LoginConfig struct {
+	MaxFailures     int           // アカウントをロックするまでのログインの失敗回数
+	LockoutDuration time.Duration // アカウントをロックする期間
+}
+
+/*
+	メール送信関連の設定
+*/
+type MailConfig struct {
+	Driver      string // "smtp"、"file"（開発用にファイルへ出力）または "none"（送信しない）
+	From        string // 送信元のメールアドレス
+	FromName    string // 送信元の表示名
+	Host        string // SMTPサーバーのホスト
+	Port        int    // SMTPサーバーのポート
+	Username    string // SMTP認証のユーザー名（空の場合は認証しない）
+	Password    string // SMTP認証のパスワード
+	OutboxDir   string // "file" の場合の出力先ディレクトリ
+	MaxAttempts int    // 送信に失敗した場合を含む試行回数
+}
+
+/*
+	就寝時刻のリマインダー関連の設定
+*/
+type ReminderConfig struct {
+	Enabled  bool          // リマインダーを送信するか
+	LeadTime time.Duration // 就寝時刻のどれだけ前に送信するか
+}
+
+/*
+	週間レポート関連の設定
+*/
+type WeeklyReportConfig struct {
+	Enabled   bool // 週間レポートを送信するか
+	AttachPDF bool // 週間レポートのメールにPDFを添付するか
+}
+
+/*
+	集計データのキャッシュ関連の設定
+*/
+type CacheConfig struct {
+	Driver         string // "memory"（プロセス内のLRU）または "redis"
+	MaxMB          int    // "memory" の場合の最大サイズ（MB）
+	RedisAddr      string // "redis" の場合のサーバーのホスト:ポート
+	RedisPassword  string // "redis" の場合のパスワード（空の場合は認証しない）
+	RedisDB        int    // "redis" の場合のデータベース番号
+	RedisPrefix    string // "redis" の場合のキーの前に付ける文字列
+	MetricsEnabled bool   // キャッシュの使用状況を /metrics/cache で提供するか
+	MetricsAddr    string // キャッシュの使用状況を提供する内部向けのホスト:ポート（アプリケーションのポートとは別）
 }
 
 /*
@@ -48,11 +118,50 @@ func Load() (*Config, error) {
 			BaseURL: getEnvStr("APP_BASE_URL", "http://localhost:8080"),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnvStr("DB_HOST", "db"),
-			Port:     getEnvInt("DB_PORT", 3306),
-			User:     getEnvStr("DB_USER", "suiminnisshi"),
-			Password: getEnvStr("DB_PASSWORD", "suiminnisshi_password"),
-			DBName:   getEnvStr("DB_NAME", "suiminnisshi"),
+			Driver:      getEnvStr("DB_DRIVER", "mysql"),
+			Path:        getEnvStr("DB_PATH", "data/suiminnisshi.db"),
+			Host:        getEnvStr("DB_HOST", "db"),
+			Port:        getEnvInt("DB_PORT", 3306),
+			User:        getEnvStr("DB_USER", "suiminnisshi"),
+			Password:    getEnvStr("DB_PASSWORD", "suiminnisshi_password"),
+			DBName:      getEnvStr("DB_NAME", "suiminnisshi"),
+			AutoMigrate: getEnvBool("DB_AUTO_MIGRATE", false),
+		},
+		Session: SessionConfig{
+			Lifetime: time.Duration(getEnvInt("SESSION_LIFETIME_HOURS", 168)) * time.Hour,
+		},
+		Login: LoginConfig{
+			MaxFailures:     getEnvInt("LOGIN_MAX_FAILURES", 5),
+			LockoutDuration: time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		},
+		Mail: MailConfig{
+			Driver:      getEnvStr("MAIL_DRIVER", "file"),
+			From:        getEnvStr("MAIL_FROM", "no-reply@localhost"),
+			FromName:    getEnvStr("MAIL_FROM_NAME", "睡眠日誌"),
+			Host:        getEnvStr("MAIL_HOST", "localhost"),
+			Port:        getEnvInt("MAIL_PORT", 587),
+			Username:    getEnvStr("MAIL_USERNAME", ""),
+			Password:    getEnvStr("MAIL_PASSWORD", ""),
+			OutboxDir:   getEnvStr("MAIL_OUTBOX_DIR", "data/outbox"),
+			MaxAttempts: getEnvInt("MAIL_MAX_ATTEMPTS", 5),
+		},
+		Reminder: ReminderConfig{
+			Enabled:  getEnvBool("REMINDER_ENABLED", true),
+			LeadTime: time.Duration(getEnvInt("REMINDER_LEAD_MINUTES", 30)) * time.Minute,
+		},
+		WeeklyReport: WeeklyReportConfig{
+			Enabled:   getEnvBool("WEEKLY_REPORT_ENABLED", false),
+			AttachPDF: getEnvBool("WEEKLY_REPORT_ATTACH_PDF", true),
+		},
+		Cache: CacheConfig{
+			Driver:         getEnvStr("CACHE_DRIVER", "memory"),
+			MaxMB:          getEnvInt("CACHE_MAX_MB", 64),
+			RedisAddr:      getEnvStr("REDIS_ADDR", "localhost:6379"),
+			RedisPassword:  getEnvStr("REDIS_PASSWORD", ""),
+			RedisDB:        getEnvInt("REDIS_DB", 0),
+			RedisPrefix:    getEnvStr("REDIS_PREFIX", "suiminnisshi:"),
+			MetricsEnabled: getEnvBool("CACHE_METRICS_ENABLED", false),
+			MetricsAddr:    getEnvStr("CACHE_METRICS_ADDR", "127.0.0.1:9090"),
 		},
 	}
 
@@ -80,3 +189,15 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+/*
+	環境変数から真偽値を取得
+*/
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
@@ -5,7 +5,6 @@ package handler
 // account_deletionは、アカウント削除画面のハンドラーを提供します。
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
@@ -32,11 +31,6 @@ func (h *AccountDeletionHandler) RegisterRoutes(r chi.Router) {
 	r.Post("/settings/account/delete", h.DeleteAccount)
 }
 
-// コンテキストからユーザーIDを取得
-func GetUserIDFromContext(ctx context.Context) int64 {
-	return ctx.Value(UserKey).(int64)
-}
-
 // アカウント削除確認画面を表示
 func (h *AccountDeletionHandler) ShowDeleteConfirmation(w http.ResponseWriter, r *http.Request) {
 	// コンテキストからユーザーIDを取得
@@ -57,7 +51,7 @@ func (h *AccountDeletionHandler) ShowDeleteConfirmation(w http.ResponseWriter, r
 				Message: "ユーザーが見つかりません",
 			},
 		}
-		h.templates.Render(w, "account-deletion.html", data)
+		h.templates.Render(w, r, "account-deletion.html", data)
 		return
 	}
 
@@ -68,7 +62,7 @@ func (h *AccountDeletionHandler) ShowDeleteConfirmation(w http.ResponseWriter, r
 		ActiveMenu: "settings",
 	}
 
-	err = h.templates.Render(w, "account-deletion.html", data)
+	err = h.templates.Render(w, r, "account-deletion.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -83,7 +77,7 @@ func (h *AccountDeletionHandler) DeleteAccount(w http.ResponseWriter, r *http.Re
 	}
 
 	// コンテキストからユーザーIDを取得
-	// userID = GetUserIDFromContext(r.Context())
+	userID := GetUserIDFromContext(r.Context())
 
 	// パスワードの検証
 	password := r.FormValue("password")
@@ -96,13 +90,11 @@ func (h *AccountDeletionHandler) DeleteAccount(w http.ResponseWriter, r *http.Re
 				Message: "パスワードを入力してください",
 			},
 		}
-		h.templates.Render(w, "account-deletion.html", data)
+		h.templates.Render(w, r, "account-deletion.html", data)
 		return
 	}
 
 	// パスワードの検証
-	// TODO: 実際のユーザーIDを使用
-	/*
 	if err := h.service.User().ValidatePassword(r.Context(), userID, password); err != nil {
 		data := &TemplateData{
 			Title:      "アカウント削除",
@@ -112,10 +104,9 @@ func (h *AccountDeletionHandler) DeleteAccount(w http.ResponseWriter, r *http.Re
 				Message: "パスワードが正しくありません",
 			},
 		}
-		h.templates.Render(w, "account-deletion.html", data)
+		h.templates.Render(w, r, "account-deletion.html", data)
 		return
 	}
-	*/
 
 	// 確認チェックボックスの検証
 	if r.FormValue("confirm") != "on" {
@@ -127,7 +118,7 @@ func (h *AccountDeletionHandler) DeleteAccount(w http.ResponseWriter, r *http.Re
 				Message: "削除の確認が必要です",
 			},
 		}
-		h.templates.Render(w, "account-deletion.html", data)
+		h.templates.Render(w, r, "account-deletion.html", data)
 		return
 	}
 
@@ -139,8 +130,6 @@ func (h *AccountDeletionHandler) DeleteAccount(w http.ResponseWriter, r *http.Re
 	// }
 
 	// アカウント削除の実行
-	// TODO: 実際のユーザーIDを使用
-	/*
 	if err := h.service.User().DeleteAccount(r.Context(), userID); err != nil {
 		data := &TemplateData{
 			Title:      "アカウント削除",
@@ -150,14 +139,12 @@ func (h *AccountDeletionHandler) DeleteAccount(w http.ResponseWriter, r *http.Re
 				Message: "アカウントの削除に失敗しました",
 			},
 		}
-		h.templates.Render(w, "account-deletion.html", data)
+		h.templates.Render(w, r, "account-deletion.html", data)
 		return
 	}
-	*/
 
-	// セッションの破棄
-	// TODO: セッション管理の実装
-	// ClearSession(r.Context())
+	// セッションの破棄（DB上のセッションはアカウント削除時に破棄済み）
+	clearSessionCookie(w)
 
 	// 完了ページにリダイレクト
 	http.Redirect(w, r, "/account-deleted", http.StatusSeeOther)
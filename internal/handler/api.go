@@ -0,0 +1,377 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/api.go
+// apiは、スクリプトやモバイルアプリから利用するJSON API（/api/v1）の共通処理を提供します。
+// 認証はパーソナルアクセストークン（Authorization: Bearer）またはログインセッションで行い、
+// レスポンスは成功時に {"data": ...}、失敗時に {"error": {"code": ..., "message": ...}} の形式で返します。
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/middleware"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// APIのパスのプレフィックス
+	apiV1Prefix = "/api/v1"
+	// 一覧の1ページあたりの既定の件数
+	apiDefaultPerPage = 20
+	// 一覧の1ページあたりの最大件数
+	apiMaxPerPage = 100
+	// リクエストボディの最大サイズ
+	apiMaxBodyBytes = 1 << 20
+)
+
+// APIのエラーコード
+const (
+	apiErrorBadRequest        = "bad_request"
+	apiErrorValidation        = "validation_failed"
+	apiErrorUnauthorized      = "unauthorized"
+	apiErrorInsufficientScope = "insufficient_scope"
+	apiErrorForbidden         = "forbidden"
+	apiErrorNotFound          = "not_found"
+	apiErrorMethodNotAllowed  = "method_not_allowed"
+	apiErrorInternal          = "internal_error"
+)
+
+// アクセストークンのコンテキストキー
+type accessTokenContextKey struct{}
+
+// JSON APIのハンドラー
+type APIHandler struct {
+	service *service.Service
+}
+
+// APIのエラーレスポンス
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// APIのエラーレスポンスの本体
+type APIErrorResponse struct {
+	Error *APIError `json:"error"`
+}
+
+// 一覧のページ情報
+type APIPagination struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// APIHandlerを作成
+func NewAPIHandler(svc *service.Service) *APIHandler {
+	return &APIHandler{
+		service: svc,
+	}
+}
+
+// ルーティングを登録
+// r にはセッションを読み込むミドルウェア（AuthHandler.LoadSession）を適用したルーターを渡す
+func (h *APIHandler) RegisterRoutes(r chi.Router) {
+	r.Route(apiV1Prefix, func(r chi.Router) {
+		r.Use(h.Authenticate)
+		r.Use(h.AuthorizeScope)
+		r.NotFound(h.notFound)
+		r.MethodNotAllowed(h.methodNotAllowed)
+
+		r.Get("/me", h.Me)
+
+		r.Get("/diaries", h.ListDiaries)
+		r.Post("/diaries", h.CreateDiary)
+		r.Get("/diaries/{id}", h.GetDiary)
+		r.Put("/diaries/{id}", h.UpdateDiary)
+		r.Delete("/diaries/{id}", h.DeleteDiary)
+
+		r.Get("/diaries/{id}/records", h.ListRecords)
+		r.Post("/diaries/{id}/records", h.CreateRecord)
+		r.Get("/records/{id}", h.GetRecord)
+		r.Put("/records/{id}", h.UpdateRecord)
+		r.Delete("/records/{id}", h.DeleteRecord)
+		r.Put("/nights/{date}", h.ReplaceNight)
+
+		r.Get("/preferences", h.GetPreferences)
+		r.Put("/preferences", h.UpdatePreferences)
+
+		r.Get("/sleep-states", h.ListSleepStates)
+		r.Get("/meal-types", h.ListMealTypes)
+	})
+}
+
+// リクエストを認証するミドルウェア
+// Authorization ヘッダーがある場合はアクセストークンのみで認証し、セッションは使わない
+// ヘッダーがない場合はログインセッションで認証し、画面と同じくCSRFトークンを検証する
+func (h *APIHandler) Authenticate(next http.Handler) http.Handler {
+	withCSRF := middleware.CSRF(h.csrfFailure)(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" {
+			if GetUserFromContext(r.Context()) == nil {
+				h.unauthorized(w, "認証が必要です")
+				return
+			}
+			withCSRF.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := bearerToken(authorization)
+		if !ok {
+			h.unauthorized(w, "Authorization ヘッダーは Bearer 形式で指定してください")
+			return
+		}
+
+		user, accessToken, err := h.service.AccessToken().Authenticate(r.Context(), token)
+		if errors.Is(err, service.ErrInvalidAccessToken) {
+			h.unauthorized(w, "アクセストークンが無効か期限切れです")
+			return
+		}
+		if err != nil {
+			h.internalError(w, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), UserKey, user)
+		ctx = context.WithValue(ctx, accessTokenContextKey{}, accessToken)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// アクセストークンの権限を確認するミドルウェア
+// 参照（GET・HEAD）には read、それ以外には write の権限が必要（セッションでの認証の場合は確認しない）
+func (h *APIHandler) AuthorizeScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := GetAccessTokenFromContext(r.Context())
+		if token == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scope := requiredScope(r.Method)
+		if !token.HasScope(scope) {
+			h.writeError(w, http.StatusForbidden, apiErrorInsufficientScope,
+				fmt.Sprintf("この操作にはアクセストークンの %s 権限が必要です", scope))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// リクエストのメソッドに必要なアクセストークンの権限を取得
+func requiredScope(method string) string {
+	if method == http.MethodGet || method == http.MethodHead {
+		return models.AccessTokenScopeRead
+	}
+	return models.AccessTokenScopeWrite
+}
+
+// コンテキストからアクセストークンを取得（セッションで認証した場合はnil）
+func GetAccessTokenFromContext(ctx context.Context) *models.PersonalAccessToken {
+	token, _ := ctx.Value(accessTokenContextKey{}).(*models.PersonalAccessToken)
+	return token
+}
+
+// Authorization ヘッダーからBearerトークンを取得
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// データをJSONで返す
+func (h *APIHandler) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.service.Logger().Error("APIのレスポンスの書き込みに失敗: error=%v", err)
+	}
+}
+
+// 1件のデータを返す
+func (h *APIHandler) writeData(w http.ResponseWriter, status int, data interface{}) {
+	h.writeJSON(w, status, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// 一覧のデータをページ情報とあわせて返す
+// 前後のページがある場合は Link ヘッダー（rel="prev"・rel="next"）も返す
+func (h *APIHandler) writeList(w http.ResponseWriter, r *http.Request, data interface{}, pagination *APIPagination) {
+	var links []string
+	if pagination.Page > 1 && pagination.Page <= pagination.TotalPages {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(r, pagination.Page-1, pagination.PerPage)))
+	}
+	if pagination.Page < pagination.TotalPages {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(r, pagination.Page+1, pagination.PerPage)))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":       data,
+		"pagination": pagination,
+	})
+}
+
+// エラーを返す
+func (h *APIHandler) writeError(w http.ResponseWriter, status int, code, message string) {
+	h.writeJSON(w, status, &APIErrorResponse{
+		Error: &APIError{Code: code, Message: message},
+	})
+}
+
+// 認証エラーを返す
+func (h *APIHandler) unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	h.writeError(w, http.StatusUnauthorized, apiErrorUnauthorized, message)
+}
+
+// リクエストの形式のエラーを返す
+func (h *APIHandler) badRequest(w http.ResponseWriter, message string) {
+	h.writeError(w, http.StatusBadRequest, apiErrorBadRequest, message)
+}
+
+// 入力値の検証エラーを返す
+func (h *APIHandler) validationFailed(w http.ResponseWriter, message string) {
+	h.writeError(w, http.StatusUnprocessableEntity, apiErrorValidation, message)
+}
+
+// 対象が見つからないエラーを返す
+func (h *APIHandler) notFound(w http.ResponseWriter, _ *http.Request) {
+	h.writeError(w, http.StatusNotFound, apiErrorNotFound, "対象が見つかりません")
+}
+
+// 許可されていないメソッドのエラーを返す
+func (h *APIHandler) methodNotAllowed(w http.ResponseWriter, _ *http.Request) {
+	h.writeError(w, http.StatusMethodNotAllowed, apiErrorMethodNotAllowed, "このメソッドは利用できません")
+}
+
+// CSRFトークンの検証エラーを返す
+func (h *APIHandler) csrfFailure(w http.ResponseWriter, _ *http.Request) {
+	h.writeError(w, http.StatusForbidden, apiErrorForbidden, "CSRFトークンが無効です")
+}
+
+// サーバー内部のエラーを返す（詳細はログにのみ出力する）
+func (h *APIHandler) internalError(w http.ResponseWriter, err error) {
+	h.service.Logger().Error("APIの処理に失敗: error=%v", err)
+	h.writeError(w, http.StatusInternalServerError, apiErrorInternal, "サーバーでエラーが発生しました")
+}
+
+// サービスのエラーをレスポンスに変換
+// 入力値の誤りによるエラーは 422、他のユーザーのデータを含め対象が見つからない場合は 404 を返す
+func (h *APIHandler) serviceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrDiaryNotFound),
+		errors.Is(err, service.ErrDiaryAccessDenied),
+		errors.Is(err, service.ErrRecordNotFound):
+		h.notFound(w, nil)
+	case errors.Is(err, service.ErrEmptyDiaryName),
+		errors.Is(err, service.ErrInvalidDiaryPeriod),
+		errors.Is(err, service.ErrInvalidDate),
+		errors.Is(err, service.ErrInvalidTimeSlot),
+		errors.Is(err, service.ErrInvalidSleepState),
+		errors.Is(err, service.ErrInvalidMealType),
+		errors.Is(err, service.ErrInvalidRecordType),
+		errors.Is(err, service.ErrInvalidSleepGoal),
+		errors.Is(err, service.ErrInvalidTimeRange),
+		errors.Is(err, service.ErrNightEntryOrder),
+		errors.Is(err, service.ErrNightEntryOutOfRange),
+		errors.Is(err, service.ErrInvalidWakePeriod),
+		errors.Is(err, service.ErrNoDiaryForDate):
+		h.validationFailed(w, err.Error())
+	default:
+		h.internalError(w, err)
+	}
+}
+
+// リクエストボディのJSONを読み込む（未知の項目はエラー）
+func (h *APIHandler) decodeJSON(w http.ResponseWriter, r *http.Request, dest interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		h.badRequest(w, "リクエストボディのJSONが正しくありません: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// URLのIDを取得
+func (h *APIHandler) urlID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		h.notFound(w, r)
+		return 0, false
+	}
+	return id, true
+}
+
+// クエリパラメーターからページ番号と1ページあたりの件数を取得
+func (h *APIHandler) pagination(w http.ResponseWriter, r *http.Request) (page, perPage int, ok bool) {
+	page, perPage = 1, apiDefaultPerPage
+
+	if value := r.URL.Query().Get("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			h.badRequest(w, "page は1以上の整数で指定してください")
+			return 0, 0, false
+		}
+		page = n
+	}
+	if value := r.URL.Query().Get("per_page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > apiMaxPerPage {
+			h.badRequest(w, fmt.Sprintf("per_page は1〜%dの整数で指定してください", apiMaxPerPage))
+			return 0, 0, false
+		}
+		perPage = n
+	}
+
+	return page, perPage, true
+}
+
+// 一覧のうち指定したページの範囲（開始・終了の添字）とページ情報を計算
+func paginate(total, page, perPage int) (start, end int, pagination *APIPagination) {
+	pagination = &APIPagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	}
+
+	start = (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end = start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end, pagination
+}
+
+// 指定したページのURL（他のクエリパラメーターは維持する）
+func pageURL(r *http.Request, page, perPage int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+	u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return u.String()
+}
@@ -0,0 +1,167 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/api_diaries.go
+// api_diariesは、JSON API（/api/v1）の睡眠日誌のハンドラーを提供します。
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 睡眠日誌の一覧（開始日の新しい順）
+func (h *APIHandler) ListDiaries(w http.ResponseWriter, r *http.Request) {
+	page, perPage, ok := h.pagination(w, r)
+	if !ok {
+		return
+	}
+
+	diaries, err := h.service.Diary().GetUserDiaries(r.Context(), GetUserIDFromContext(r.Context()))
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	start, end, pagination := paginate(len(diaries), page, perPage)
+	data := make([]*DiaryResponse, 0, end-start)
+	for _, diary := range diaries[start:end] {
+		data = append(data, newDiaryResponse(diary))
+	}
+
+	h.writeList(w, r, data, pagination)
+}
+
+// 睡眠日誌の作成
+func (h *APIHandler) CreateDiary(w http.ResponseWriter, r *http.Request) {
+	var req DiaryRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == nil || req.StartDate == nil || req.EndDate == nil {
+		h.validationFailed(w, "name・start_date・end_date は必須です")
+		return
+	}
+
+	startDate, ok := h.parseDate(w, "start_date", *req.StartDate)
+	if !ok {
+		return
+	}
+	endDate, ok := h.parseDate(w, "end_date", *req.EndDate)
+	if !ok {
+		return
+	}
+	note := ""
+	if req.Note != nil {
+		note = *req.Note
+	}
+
+	diary, err := h.service.Diary().CreateDiary(r.Context(), GetUserIDFromContext(r.Context()), startDate, endDate, *req.Name, note)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/diaries/"+strconv.FormatInt(diary.ID, 10))
+	h.writeData(w, http.StatusCreated, newDiaryResponse(diary))
+}
+
+// 睡眠日誌の取得
+func (h *APIHandler) GetDiary(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.urlID(w, r)
+	if !ok {
+		return
+	}
+
+	diary, err := h.service.Diary().GetUserDiary(r.Context(), GetUserIDFromContext(r.Context()), id)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	h.writeData(w, http.StatusOK, newDiaryResponse(diary))
+}
+
+// 睡眠日誌の更新（指定した項目のみ）
+func (h *APIHandler) UpdateDiary(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.urlID(w, r)
+	if !ok {
+		return
+	}
+
+	var req DiaryRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	diary, err := h.service.Diary().GetUserDiary(r.Context(), GetUserIDFromContext(r.Context()), id)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	if req.Name != nil {
+		diary.DiaryName = *req.Name
+	}
+	if req.StartDate != nil {
+		if diary.StartDate, ok = h.parseDate(w, "start_date", *req.StartDate); !ok {
+			return
+		}
+	}
+	if req.EndDate != nil {
+		if diary.EndDate, ok = h.parseDate(w, "end_date", *req.EndDate); !ok {
+			return
+		}
+	}
+	if req.Note != nil {
+		diary.Note = nullString(*req.Note)
+	}
+
+	if err := h.service.Diary().UpdateDiary(r.Context(), diary); err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	h.writeData(w, http.StatusOK, newDiaryResponse(diary))
+}
+
+// 睡眠日誌の削除（睡眠記録もあわせて削除）
+func (h *APIHandler) DeleteDiary(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.urlID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.service.Diary().GetUserDiary(r.Context(), GetUserIDFromContext(r.Context()), id); err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	if err := h.service.Diary().DeleteDiary(r.Context(), id); err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// 日付（YYYY-MM-DD）を読み込む
+func (h *APIHandler) parseDate(w http.ResponseWriter, field, value string) (time.Time, bool) {
+	t, err := time.Parse(apiDateFormat, strings.TrimSpace(value))
+	if err != nil {
+		h.validationFailed(w, field+" は YYYY-MM-DD の形式で指定してください")
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// 時刻（HH:MM）を読み込む
+func (h *APIHandler) parseTimeOfDay(w http.ResponseWriter, field, value string) (time.Time, bool) {
+	t, err := time.Parse(apiTimeFormat, strings.TrimSpace(value))
+	if err != nil {
+		h.validationFailed(w, field+" は HH:MM の形式で指定してください")
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,232 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/api_dto.go
+// api_dtoは、JSON API（/api/v1）のリクエスト・レスポンスの型を提供します。
+// 日時は ISO 8601（RFC 3339）形式、日付は YYYY-MM-DD、時刻は HH:MM で表します。
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+const (
+	// APIの日付の形式
+	apiDateFormat = "2006-01-02"
+	// APIの時刻の形式
+	apiTimeFormat = "15:04"
+)
+
+// ユーザー情報のレスポンス
+type MeResponse struct {
+	ID          int64                `json:"id"`
+	Email       string               `json:"email"`
+	DisplayName string               `json:"display_name"`
+	TimeZone    string               `json:"time_zone"`
+	Token       *AccessTokenResponse `json:"token"`
+}
+
+// アクセストークンのレスポンス（トークンそのものは含めない）
+type AccessTokenResponse struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	LastUsedAt *string  `json:"last_used_at"`
+	ExpiresAt  *string  `json:"expires_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// 睡眠日誌のレスポンス
+type DiaryResponse struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	StartDate string  `json:"start_date"`
+	EndDate   string  `json:"end_date"`
+	Note      *string `json:"note"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+// 睡眠日誌の作成・更新のリクエスト（更新では指定した項目のみを変更する）
+type DiaryRequest struct {
+	Name      *string `json:"name"`
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+	Note      *string `json:"note"`
+}
+
+// 睡眠記録のレスポンス
+// StartsAt は時間枠の開始日時（ユーザーのタイムゾーン）
+type RecordResponse struct {
+	ID         int64   `json:"id"`
+	DiaryID    int64   `json:"diary_id"`
+	Date       string  `json:"date"`
+	TimeSlot   string  `json:"time_slot"`
+	StartsAt   string  `json:"starts_at"`
+	RecordType string  `json:"record_type"`
+	SleepState string  `json:"sleep_state"`
+	MealType   *string `json:"meal_type"`
+	Note       *string `json:"note"`
+	CreatedAt  string  `json:"created_at"`
+	UpdatedAt  string  `json:"updated_at"`
+}
+
+// 睡眠記録の作成・更新のリクエスト（更新では指定した項目のみを変更する）
+// 睡眠状態・食事種別はコードで指定し、食事種別・メモは空文字で削除する
+type RecordRequest struct {
+	Date       *string `json:"date"`
+	TimeSlot   *string `json:"time_slot"`
+	RecordType *string `json:"record_type"`
+	SleepState *string `json:"sleep_state"`
+	MealType   *string `json:"meal_type"`
+	Note       *string `json:"note"`
+}
+
+// 夜の入力のリクエスト
+// 時刻は HH:MM で指定し、正午より前の時刻は翌日の時刻として扱う。食事種別はコードで指定する
+type NightEntryRequest struct {
+	BedTime        *string                  `json:"bed_time"`
+	LightsOut      *string                  `json:"lights_out"`
+	WakePeriods    []NightWakePeriodRequest `json:"wake_periods"`
+	FinalWake      *string                  `json:"final_wake"`
+	MedicationTime *string                  `json:"medication_time"`
+	Meals          []NightMealRequest       `json:"meals"`
+	Note           *string                  `json:"note"`
+}
+
+// 夜の入力の中途覚醒の時間帯
+type NightWakePeriodRequest struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// 夜の入力の食事
+type NightMealRequest struct {
+	MealType string `json:"meal_type"`
+	Time     string `json:"time"`
+}
+
+// 夜の入力のレスポンス（作成し直した一晩分の睡眠記録）
+type NightEntryResponse struct {
+	Date    string            `json:"date"`
+	Records []*RecordResponse `json:"records"`
+}
+
+// 睡眠設定のレスポンス
+type PreferencesResponse struct {
+	Bedtime         string `json:"bedtime"`
+	WakeupTime      string `json:"wakeup_time"`
+	SleepGoalHours  int    `json:"sleep_goal_hours"`
+	ReminderEnabled bool   `json:"reminder_enabled"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+// 睡眠設定の更新のリクエスト（指定した項目のみを変更する）
+type PreferencesRequest struct {
+	Bedtime         *string `json:"bedtime"`
+	WakeupTime      *string `json:"wakeup_time"`
+	SleepGoalHours  *int    `json:"sleep_goal_hours"`
+	ReminderEnabled *bool   `json:"reminder_enabled"`
+}
+
+// 睡眠状態・食事種別のマスターデータのレスポンス
+type MasterDataResponse struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+// 日時をAPIの形式（RFC 3339、UTC）に変換
+func apiTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// NULLを許容する日時をAPIの形式に変換（NULLの場合はnil）
+func apiNullTimestamp(t sql.NullTime) *string {
+	if !t.Valid {
+		return nil
+	}
+	value := apiTimestamp(t.Time)
+	return &value
+}
+
+// NULLを許容する文字列をAPIの形式に変換（NULLの場合はnil）
+func apiNullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+// 空文字をNULLとして扱う文字列に変換
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// アクセストークンのレスポンスを作成
+func newAccessTokenResponse(token *models.PersonalAccessToken) *AccessTokenResponse {
+	return &AccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.TokenPrefix,
+		Scopes:     token.ScopeList(),
+		LastUsedAt: apiNullTimestamp(token.LastUsed),
+		ExpiresAt:  apiNullTimestamp(token.Expires),
+		CreatedAt:  apiTimestamp(token.Created),
+	}
+}
+
+// 睡眠日誌のレスポンスを作成
+func newDiaryResponse(diary *models.SleepDiary) *DiaryResponse {
+	return &DiaryResponse{
+		ID:        diary.ID,
+		Name:      diary.DiaryName,
+		StartDate: diary.StartDate.Format(apiDateFormat),
+		EndDate:   diary.EndDate.Format(apiDateFormat),
+		Note:      apiNullString(diary.Note),
+		CreatedAt: apiTimestamp(diary.Created),
+		UpdatedAt: apiTimestamp(diary.Modified),
+	}
+}
+
+// 睡眠記録のレスポンスを作成
+// 睡眠状態・食事種別は、IDからコードへの対応表で変換する
+func newRecordResponse(record *models.SleepRecord, loc *time.Location, stateCodes, mealTypeCodes map[int64]string) *RecordResponse {
+	startsAt := time.Date(
+		record.RecordDate.Year(), record.RecordDate.Month(), record.RecordDate.Day(),
+		record.TimeSlot.Hour(), record.TimeSlot.Minute(), 0, 0,
+		loc,
+	)
+
+	res := &RecordResponse{
+		ID:         record.ID,
+		DiaryID:    record.SleepDiaryID,
+		Date:       record.RecordDate.Format(apiDateFormat),
+		TimeSlot:   record.TimeSlot.Format(apiTimeFormat),
+		StartsAt:   startsAt.Format(time.RFC3339),
+		RecordType: record.RecordType,
+		SleepState: stateCodes[record.SleepStateID],
+		Note:       apiNullString(record.Note),
+		CreatedAt:  apiTimestamp(record.Created),
+		UpdatedAt:  apiTimestamp(record.Modified),
+	}
+	if record.MealTypeID.Valid {
+		code := mealTypeCodes[record.MealTypeID.Int64]
+		res.MealType = &code
+	}
+	return res
+}
+
+// 睡眠設定のレスポンスを作成
+func newPreferencesResponse(pref *models.UserSleepPreference) *PreferencesResponse {
+	return &PreferencesResponse{
+		Bedtime:         pref.PreferredBedtime.Format(apiTimeFormat),
+		WakeupTime:      pref.PreferredWakeupTime.Format(apiTimeFormat),
+		SleepGoalHours:  pref.SleepGoalHours,
+		ReminderEnabled: pref.IsReminderEnabled,
+		UpdatedAt:       apiTimestamp(pref.Modified),
+	}
+}
@@ -0,0 +1,436 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/api_records.go
+// api_recordsは、JSON API（/api/v1）の睡眠記録と、睡眠状態・食事種別のマスターデータのハンドラーを提供します。
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// 睡眠状態・食事種別のIDとコードの対応表
+type apiMasterCodes struct {
+	stateCodes    map[int64]string
+	stateIDs      map[string]int64
+	mealTypeCodes map[int64]string
+	mealTypeIDs   map[string]int64
+}
+
+// 睡眠日誌の睡眠記録の一覧（日付・時間枠の順）
+// from・to（YYYY-MM-DD）を指定した場合は、その期間の睡眠記録のみを返す
+func (h *APIHandler) ListRecords(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.urlID(w, r)
+	if !ok {
+		return
+	}
+	page, perPage, ok := h.pagination(w, r)
+	if !ok {
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	diary, err := h.service.Diary().GetUserDiary(r.Context(), user.ID, id)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	var records []*models.SleepRecord
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" && to == "" {
+		records, err = h.service.Record().GetDiaryRecords(r.Context(), diary.ID)
+	} else {
+		startDate, endDate := diary.StartDate, diary.EndDate
+		if from != "" {
+			if startDate, ok = h.parseDate(w, "from", from); !ok {
+				return
+			}
+		}
+		if to != "" {
+			if endDate, ok = h.parseDate(w, "to", to); !ok {
+				return
+			}
+		}
+		records, err = h.service.Record().GetRecordsByDateRange(r.Context(), diary.ID, startDate, endDate)
+	}
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	codes, err := h.masterCodes(r.Context())
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	start, end, pagination := paginate(len(records), page, perPage)
+	data := make([]*RecordResponse, 0, end-start)
+	for _, record := range records[start:end] {
+		data = append(data, newRecordResponse(record, user.Location(), codes.stateCodes, codes.mealTypeCodes))
+	}
+
+	h.writeList(w, r, data, pagination)
+}
+
+// 睡眠記録の作成
+// record_type を省略した場合は STATE とする
+func (h *APIHandler) CreateRecord(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.urlID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RecordRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Date == nil || req.TimeSlot == nil || req.SleepState == nil {
+		h.validationFailed(w, "date・time_slot・sleep_state は必須です")
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	diary, err := h.service.Diary().GetUserDiary(r.Context(), user.ID, id)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	codes, err := h.masterCodes(r.Context())
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	record := &models.SleepRecord{
+		SleepDiaryID: diary.ID,
+		RecordType:   models.RecordTypeState,
+	}
+	if !h.applyRecordRequest(w, record, &req, diary, codes) {
+		return
+	}
+
+	if err := h.service.Record().CreateRecord(r.Context(), record); err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/records/"+strconv.FormatInt(record.ID, 10))
+	h.writeData(w, http.StatusCreated, newRecordResponse(record, user.Location(), codes.stateCodes, codes.mealTypeCodes))
+}
+
+// 睡眠記録の取得
+func (h *APIHandler) GetRecord(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.urlID(w, r)
+	if !ok {
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	record, err := h.service.Record().GetUserRecord(r.Context(), user.ID, id)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	codes, err := h.masterCodes(r.Context())
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	h.writeData(w, http.StatusOK, newRecordResponse(record, user.Location(), codes.stateCodes, codes.mealTypeCodes))
+}
+
+// 睡眠記録の更新（指定した項目のみ）
+func (h *APIHandler) UpdateRecord(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.urlID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RecordRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	record, err := h.service.Record().GetUserRecord(r.Context(), user.ID, id)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+	diary, err := h.service.Diary().GetUserDiary(r.Context(), user.ID, record.SleepDiaryID)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	codes, err := h.masterCodes(r.Context())
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	if !h.applyRecordRequest(w, record, &req, diary, codes) {
+		return
+	}
+
+	if err := h.service.Record().UpdateRecord(r.Context(), record); err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	h.writeData(w, http.StatusOK, newRecordResponse(record, user.Location(), codes.stateCodes, codes.mealTypeCodes))
+}
+
+// 睡眠記録の削除
+func (h *APIHandler) DeleteRecord(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.urlID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.service.Record().GetUserRecord(r.Context(), GetUserIDFromContext(r.Context()), id); err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	if err := h.service.Record().DeleteRecord(r.Context(), id); err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// 一晩分の睡眠記録を夜の入力から作成し直す
+// 睡眠日の正午から翌日の正午までの既存の睡眠記録は、すべて置き換えられる
+func (h *APIHandler) ReplaceNight(w http.ResponseWriter, r *http.Request) {
+	date, ok := h.parseDate(w, "date", chi.URLParam(r, "date"))
+	if !ok {
+		return
+	}
+
+	var req NightEntryRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.BedTime == nil || req.LightsOut == nil || req.FinalWake == nil {
+		h.validationFailed(w, "bed_time・lights_out・final_wake は必須です")
+		return
+	}
+
+	entry := &models.NightEntry{Date: date}
+	if entry.BedTime, ok = h.parseNightTime(w, date, "bed_time", *req.BedTime); !ok {
+		return
+	}
+	if entry.LightsOut, ok = h.parseNightTime(w, date, "lights_out", *req.LightsOut); !ok {
+		return
+	}
+	if entry.FinalWake, ok = h.parseNightTime(w, date, "final_wake", *req.FinalWake); !ok {
+		return
+	}
+	for _, period := range req.WakePeriods {
+		var wake models.NightWakePeriod
+		if wake.Start, ok = h.parseNightTime(w, date, "wake_periods.start", period.Start); !ok {
+			return
+		}
+		if wake.End, ok = h.parseNightTime(w, date, "wake_periods.end", period.End); !ok {
+			return
+		}
+		entry.WakePeriods = append(entry.WakePeriods, wake)
+	}
+	if req.MedicationTime != nil && *req.MedicationTime != "" {
+		medication, ok := h.parseNightTime(w, date, "medication_time", *req.MedicationTime)
+		if !ok {
+			return
+		}
+		entry.Medication = sql.NullTime{Time: medication, Valid: true}
+	}
+	for _, meal := range req.Meals {
+		at, ok := h.parseNightTime(w, date, "meals.time", meal.Time)
+		if !ok {
+			return
+		}
+		entry.Meals = append(entry.Meals, models.NightMeal{
+			MealTypeCode: strings.ToUpper(strings.TrimSpace(meal.MealType)),
+			At:           at,
+		})
+	}
+	if req.Note != nil {
+		entry.Note = *req.Note
+	}
+
+	user := GetUserFromContext(r.Context())
+	records, err := h.service.Record().ReplaceNightRecords(r.Context(), user.ID, entry)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	codes, err := h.masterCodes(r.Context())
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	data := &NightEntryResponse{
+		Date:    date.Format(apiDateFormat),
+		Records: make([]*RecordResponse, 0, len(records)),
+	}
+	for _, record := range records {
+		data.Records = append(data.Records, newRecordResponse(record, user.Location(), codes.stateCodes, codes.mealTypeCodes))
+	}
+
+	h.writeData(w, http.StatusOK, data)
+}
+
+// 睡眠状態の一覧
+func (h *APIHandler) ListSleepStates(w http.ResponseWriter, r *http.Request) {
+	states, err := h.service.Record().GetStatesList(r.Context())
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	data := make([]*MasterDataResponse, 0, len(states))
+	for _, state := range states {
+		data = append(data, &MasterDataResponse{
+			Code:   state.StateCode,
+			Name:   state.StateName,
+			Symbol: state.DisplaySymbol,
+		})
+	}
+
+	h.writeData(w, http.StatusOK, data)
+}
+
+// 食事種別の一覧
+func (h *APIHandler) ListMealTypes(w http.ResponseWriter, r *http.Request) {
+	mealTypes, err := h.service.Record().GetMealTypesList(r.Context())
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	data := make([]*MasterDataResponse, 0, len(mealTypes))
+	for _, mealType := range mealTypes {
+		data = append(data, &MasterDataResponse{
+			Code:   mealType.TypeCode,
+			Name:   mealType.TypeName,
+			Symbol: mealType.DisplaySymbol,
+		})
+	}
+
+	h.writeData(w, http.StatusOK, data)
+}
+
+// リクエストの内容を睡眠記録に反映
+// 形式の誤りや、日付が睡眠日誌の期間外の場合はエラーを返して false を返す
+func (h *APIHandler) applyRecordRequest(w http.ResponseWriter, record *models.SleepRecord, req *RecordRequest, diary *models.SleepDiary, codes *apiMasterCodes) bool {
+	var ok bool
+	if req.Date != nil {
+		if record.RecordDate, ok = h.parseDate(w, "date", *req.Date); !ok {
+			return false
+		}
+	}
+	// 日付はタイムゾーンの影響を受けないよう文字列で比較する
+	date := record.RecordDate.Format(apiDateFormat)
+	if date < diary.StartDate.Format(apiDateFormat) || date > diary.EndDate.Format(apiDateFormat) {
+		h.validationFailed(w, "date は睡眠日誌の期間内の日付を指定してください")
+		return false
+	}
+
+	if req.TimeSlot != nil {
+		if record.TimeSlot, ok = h.parseTimeOfDay(w, "time_slot", *req.TimeSlot); !ok {
+			return false
+		}
+	}
+
+	if req.RecordType != nil {
+		recordType := strings.ToUpper(strings.TrimSpace(*req.RecordType))
+		switch recordType {
+		case models.RecordTypeState, models.RecordTypeEvent, models.RecordTypeMeal:
+			record.RecordType = recordType
+		default:
+			h.validationFailed(w, "record_type は STATE・EVENT・MEAL のいずれかを指定してください")
+			return false
+		}
+	}
+
+	if req.SleepState != nil {
+		stateID, found := codes.stateIDs[strings.ToUpper(strings.TrimSpace(*req.SleepState))]
+		if !found {
+			h.validationFailed(w, "sleep_state に存在しない睡眠状態が指定されています")
+			return false
+		}
+		record.SleepStateID = stateID
+	}
+
+	if req.MealType != nil {
+		code := strings.ToUpper(strings.TrimSpace(*req.MealType))
+		if code == "" {
+			record.MealTypeID = sql.NullInt64{}
+		} else {
+			mealTypeID, found := codes.mealTypeIDs[code]
+			if !found {
+				h.validationFailed(w, "meal_type に存在しない食事種別が指定されています")
+				return false
+			}
+			record.MealTypeID = sql.NullInt64{Int64: mealTypeID, Valid: true}
+		}
+	}
+
+	if req.Note != nil {
+		record.Note = nullString(*req.Note)
+	}
+
+	return true
+}
+
+// 夜の入力の時刻（HH:MM）を読み込み、睡眠日の日時に変換する
+func (h *APIHandler) parseNightTime(w http.ResponseWriter, date time.Time, field, value string) (time.Time, bool) {
+	clock, ok := h.parseTimeOfDay(w, field, value)
+	if !ok {
+		return time.Time{}, false
+	}
+	return models.NightEntryTime(date, clock), true
+}
+
+// 睡眠状態・食事種別のIDとコードの対応表を作成
+func (h *APIHandler) masterCodes(ctx context.Context) (*apiMasterCodes, error) {
+	states, err := h.service.Record().GetStatesList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mealTypes, err := h.service.Record().GetMealTypesList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := &apiMasterCodes{
+		stateCodes:    make(map[int64]string, len(states)),
+		stateIDs:      make(map[string]int64, len(states)),
+		mealTypeCodes: make(map[int64]string, len(mealTypes)),
+		mealTypeIDs:   make(map[string]int64, len(mealTypes)),
+	}
+	for _, state := range states {
+		codes.stateCodes[state.ID] = state.StateCode
+		codes.stateIDs[state.StateCode] = state.ID
+	}
+	for _, mealType := range mealTypes {
+		codes.mealTypeCodes[mealType.ID] = mealType.TypeCode
+		codes.mealTypeIDs[mealType.TypeCode] = mealType.ID
+	}
+	return codes, nil
+}
@@ -0,0 +1,346 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/middleware"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// APIのレスポンスの本体
+type apiTestResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Pagination *APIPagination  `json:"pagination"`
+	Error      *APIError       `json:"error"`
+}
+
+// JSON APIのルーターを作成（session にユーザーを指定した場合は、ログインセッションで認証したものとする）
+func newAPITestRouter(app *testApp, session *models.User) http.Handler {
+	r := chi.NewRouter()
+	r.Use(withUser(session))
+	NewAPIHandler(app.service).RegisterRoutes(r)
+	return r
+}
+
+// アクセストークンを発行
+func (a *testApp) createToken(t *testing.T, userID int64, scopes ...string) (string, *models.PersonalAccessToken) {
+	t.Helper()
+	token, accessToken, err := a.service.AccessToken().CreateToken(context.Background(), userID, "test", scopes, 0)
+	if err != nil {
+		t.Fatalf("failed to create access token: %v", err)
+	}
+	return token, accessToken
+}
+
+// APIにリクエストを送信（token が空の場合は Authorization ヘッダーを付けない）
+func serveAPI(t *testing.T, h http.Handler, method, path, token, body string) (*httptest.ResponseRecorder, *apiTestResponse) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return serveAPIRequest(t, h, req)
+}
+
+// APIのリクエストを処理し、レスポンスの本体を読み込む
+func serveAPIRequest(t *testing.T, h http.Handler, req *http.Request) (*httptest.ResponseRecorder, *apiTestResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var res apiTestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s %s: invalid JSON response %q: %v", req.Method, req.URL, rec.Body.String(), err)
+	}
+	return rec, &res
+}
+
+// エラーレスポンスのステータスとエラーコードを確認
+func assertAPIError(t *testing.T, rec *httptest.ResponseRecorder, res *apiTestResponse, status int, code string) {
+	t.Helper()
+	if rec.Code != status || res.Error == nil || res.Error.Code != code {
+		t.Fatalf("status = %d, error = %+v, want %d %s", rec.Code, res.Error, status, code)
+	}
+}
+
+// アクセストークンがない・無効・無効化済み・期限切れの場合は 401 を返すことを確認
+func TestAPIAuthenticate(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "api@example.com")
+	now := time.Now()
+	app.service.AccessToken().SetClock(func() time.Time { return now })
+
+	valid, _ := app.createToken(t, user.ID, models.AccessTokenScopeRead)
+	revoked, revokedToken := app.createToken(t, user.ID, models.AccessTokenScopeRead)
+	if err := app.service.AccessToken().RevokeToken(context.Background(), user.ID, revokedToken.ID); err != nil {
+		t.Fatalf("failed to revoke access token: %v", err)
+	}
+	expired, _, err := app.service.AccessToken().CreateToken(context.Background(), user.ID, "expired", []string{models.AccessTokenScopeRead}, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create access token: %v", err)
+	}
+	// 期限切れのトークンは、発行から有効期間が過ぎた時点で検証する
+	app.service.AccessToken().SetClock(func() time.Time { return now.Add(time.Hour) })
+
+	tests := []struct {
+		name          string
+		authorization string
+	}{
+		{name: "ヘッダーなし"},
+		{name: "Bearer 形式でない", authorization: "Basic " + valid},
+		{name: "トークンが空", authorization: "Bearer "},
+		{name: "接頭辞がない", authorization: "Bearer " + strings.TrimPrefix(valid, "sn_")},
+		{name: "存在しないトークン", authorization: "Bearer sn_invalid"},
+		{name: "無効化したトークン", authorization: "Bearer " + revoked},
+		{name: "期限切れのトークン", authorization: "Bearer " + expired},
+	}
+
+	h := newAPITestRouter(app, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec, res := serveAPIRequest(t, h, req)
+			assertAPIError(t, rec, res, http.StatusUnauthorized, apiErrorUnauthorized)
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("WWW-Authenticate header is not set")
+			}
+		})
+	}
+
+	// 有効なトークンでは認証できる
+	rec, res := serveAPI(t, h, http.MethodGet, "/api/v1/me", valid, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("valid token: status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var me MeResponse
+	if err := json.Unmarshal(res.Data, &me); err != nil || me.ID != user.ID {
+		t.Fatalf("valid token: data = %s, err = %v", res.Data, err)
+	}
+
+	// Authorization ヘッダーがある場合は、ログインセッションがあってもトークンで認証する
+	rec, res = serveAPI(t, newAPITestRouter(app, user), http.MethodGet, "/api/v1/me", revoked, "")
+	assertAPIError(t, rec, res, http.StatusUnauthorized, apiErrorUnauthorized)
+}
+
+// 参照のみのトークンでは、作成・更新・削除ができないことを確認
+func TestAPIAuthorizeScope(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "api@example.com")
+	diary := app.createDiary(t, user.ID, "2024-04-01", "2024-04-07")
+	records, err := app.service.Record().GetDiaryRecords(context.Background(), diary.ID)
+	if err != nil || len(records) == 0 {
+		t.Fatalf("failed to get records: %v", err)
+	}
+	readOnly, _ := app.createToken(t, user.ID, models.AccessTokenScopeRead)
+	writable, _ := app.createToken(t, user.ID, models.AccessTokenScopeWrite)
+	h := newAPITestRouter(app, nil)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/api/v1/diaries", `{"name":"新しい日誌","start_date":"2024-05-01","end_date":"2024-05-07"}`},
+		{http.MethodPut, fmt.Sprintf("/api/v1/diaries/%d", diary.ID), `{"name":"変更"}`},
+		{http.MethodDelete, fmt.Sprintf("/api/v1/diaries/%d", diary.ID), ""},
+		{http.MethodPost, fmt.Sprintf("/api/v1/diaries/%d/records", diary.ID), `{"date":"2024-04-03","time_slot":"12:00","record_type":"STATE","sleep_state":"AWAKE"}`},
+		{http.MethodPut, fmt.Sprintf("/api/v1/records/%d", records[0].ID), `{"note":"変更"}`},
+		{http.MethodDelete, fmt.Sprintf("/api/v1/records/%d", records[0].ID), ""},
+		{http.MethodPut, "/api/v1/preferences", `{"sleep_goal_hours":7}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec, res := serveAPI(t, h, tt.method, tt.path, readOnly, tt.body)
+			assertAPIError(t, rec, res, http.StatusForbidden, apiErrorInsufficientScope)
+		})
+	}
+
+	// 拒否した操作は反映されていない
+	if got, _ := app.service.Record().GetDiaryRecords(context.Background(), diary.ID); len(got) != len(records) {
+		t.Fatalf("records = %d, want %d", len(got), len(records))
+	}
+	rec, res := serveAPI(t, h, http.MethodGet, fmt.Sprintf("/api/v1/diaries/%d", diary.ID), readOnly, "")
+	var got DiaryResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(res.Data, &got) != nil || got.Name != diary.DiaryName {
+		t.Fatalf("GET diary with read token: status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// write 権限では参照・更新ができる
+	if rec, _ := serveAPI(t, h, http.MethodGet, "/api/v1/diaries", writable, ""); rec.Code != http.StatusOK {
+		t.Fatalf("GET with write token: status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if rec, _ := serveAPI(t, h, http.MethodPut, fmt.Sprintf("/api/v1/diaries/%d", diary.ID), writable, `{"name":"変更"}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT with write token: status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
+
+// ログインセッションでの認証では、状態を変更するリクエストにCSRFトークンが必要なことを確認
+func TestAPISessionCSRF(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "api@example.com")
+	h := newAPITestRouter(app, user)
+	body := `{"name":"新しい日誌","start_date":"2024-05-01","end_date":"2024-05-07"}`
+
+	// 未ログインの場合は 401
+	rec, res := serveAPI(t, newAPITestRouter(app, nil), http.MethodGet, "/api/v1/diaries", "", "")
+	assertAPIError(t, rec, res, http.StatusUnauthorized, apiErrorUnauthorized)
+
+	// 参照ではCSRFトークンを発行する
+	rec, _ = serveAPI(t, h, http.MethodGet, "/api/v1/diaries", "", "")
+	token := responseCookie(rec, middleware.CSRFCookieName)
+	if rec.Code != http.StatusOK || token == "" {
+		t.Fatalf("GET: status = %d, csrf cookie = %q", rec.Code, token)
+	}
+
+	tests := []struct {
+		name   string
+		cookie string
+		header string
+	}{
+		{name: "クッキーもヘッダーもない"},
+		{name: "ヘッダーがない", cookie: token},
+		{name: "クッキーがない", header: token},
+		{name: "ヘッダーがクッキーと異なる", cookie: token, header: strings.Repeat("A", len(token))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/diaries", strings.NewReader(body))
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: middleware.CSRFCookieName, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(middleware.CSRFHeaderName, tt.header)
+			}
+			rec, res := serveAPIRequest(t, h, req)
+			assertAPIError(t, rec, res, http.StatusForbidden, apiErrorForbidden)
+		})
+	}
+
+	// クッキーと同じトークンをヘッダーで送信すると作成できる
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/diaries", strings.NewReader(body))
+	req.AddCookie(&http.Cookie{Name: middleware.CSRFCookieName, Value: token})
+	req.Header.Set(middleware.CSRFHeaderName, token)
+	if rec, _ := serveAPIRequest(t, h, req); rec.Code != http.StatusCreated {
+		t.Fatalf("POST with CSRF token: status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
+
+// 他のユーザーの睡眠日誌・睡眠記録は、存在しない場合と同じく 404 を返すことを確認
+func TestAPIOtherUsersData(t *testing.T) {
+	app := newTestApp(t)
+	owner := app.createUser(t, "owner@example.com")
+	other := app.createUser(t, "other@example.com")
+	diary := app.createDiary(t, owner.ID, "2024-04-01", "2024-04-07")
+	records, err := app.service.Record().GetDiaryRecords(context.Background(), diary.ID)
+	if err != nil || len(records) == 0 {
+		t.Fatalf("failed to get records: %v", err)
+	}
+	record := records[0]
+	token, _ := app.createToken(t, other.ID, models.AccessTokenScopeWrite)
+	h := newAPITestRouter(app, nil)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, fmt.Sprintf("/api/v1/diaries/%d", diary.ID), ""},
+		{http.MethodPut, fmt.Sprintf("/api/v1/diaries/%d", diary.ID), `{"name":"変更"}`},
+		{http.MethodDelete, fmt.Sprintf("/api/v1/diaries/%d", diary.ID), ""},
+		{http.MethodGet, fmt.Sprintf("/api/v1/diaries/%d/records", diary.ID), ""},
+		{http.MethodPost, fmt.Sprintf("/api/v1/diaries/%d/records", diary.ID), `{"date":"2024-04-03","time_slot":"12:00","record_type":"STATE","sleep_state":"AWAKE"}`},
+		{http.MethodGet, fmt.Sprintf("/api/v1/records/%d", record.ID), ""},
+		{http.MethodPut, fmt.Sprintf("/api/v1/records/%d", record.ID), `{"note":"変更"}`},
+		{http.MethodDelete, fmt.Sprintf("/api/v1/records/%d", record.ID), ""},
+		{http.MethodGet, fmt.Sprintf("/api/v1/diaries/%d", diary.ID+100), ""},
+		{http.MethodGet, "/api/v1/records/abc", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec, res := serveAPI(t, h, tt.method, tt.path, token, tt.body)
+			assertAPIError(t, rec, res, http.StatusNotFound, apiErrorNotFound)
+		})
+	}
+
+	// 所有者の睡眠日誌・睡眠記録は変更されていない
+	got, err := app.service.Record().GetUserRecord(context.Background(), owner.ID, record.ID)
+	if err != nil || got.Note.Valid {
+		t.Fatalf("record = %+v, err = %v", got, err)
+	}
+	if diaries, _ := app.service.Diary().GetUserDiaries(context.Background(), owner.ID); len(diaries) != 1 || diaries[0].DiaryName != diary.DiaryName {
+		t.Fatalf("owner's diaries = %+v", diaries)
+	}
+}
+
+// 一覧のページ情報と Link ヘッダー、per_page・page の範囲を確認
+func TestAPIPagination(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "api@example.com")
+	for i := 0; i < 5; i++ {
+		start := time.Date(2024, 4, 1+7*i, 0, 0, 0, 0, time.UTC)
+		app.createDiary(t, user.ID, start.Format("2006-01-02"), start.AddDate(0, 0, 6).Format("2006-01-02"))
+	}
+	token, _ := app.createToken(t, user.ID, models.AccessTokenScopeRead)
+	h := newAPITestRouter(app, nil)
+
+	tests := []struct {
+		query      string
+		wantLen    int
+		wantPage   int
+		wantPer    int
+		wantTotalP int
+		wantLink   string
+	}{
+		{query: "", wantLen: 5, wantPage: 1, wantPer: apiDefaultPerPage, wantTotalP: 1},
+		{query: "?per_page=2", wantLen: 2, wantPage: 1, wantPer: 2, wantTotalP: 3,
+			wantLink: `</api/v1/diaries?page=2&per_page=2>; rel="next"`},
+		{query: "?page=2&per_page=2", wantLen: 2, wantPage: 2, wantPer: 2, wantTotalP: 3,
+			wantLink: `</api/v1/diaries?page=1&per_page=2>; rel="prev", </api/v1/diaries?page=3&per_page=2>; rel="next"`},
+		{query: "?page=3&per_page=2", wantLen: 1, wantPage: 3, wantPer: 2, wantTotalP: 3,
+			wantLink: `</api/v1/diaries?page=2&per_page=2>; rel="prev"`},
+		{query: "?page=4&per_page=2", wantLen: 0, wantPage: 4, wantPer: 2, wantTotalP: 3},
+		{query: fmt.Sprintf("?per_page=%d", apiMaxPerPage), wantLen: 5, wantPage: 1, wantPer: apiMaxPerPage, wantTotalP: 1},
+	}
+	for _, tt := range tests {
+		t.Run("diaries"+tt.query, func(t *testing.T) {
+			rec, res := serveAPI(t, h, http.MethodGet, "/api/v1/diaries"+tt.query, token, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+			}
+			var data []*DiaryResponse
+			if err := json.Unmarshal(res.Data, &data); err != nil {
+				t.Fatalf("data = %s: %v", res.Data, err)
+			}
+			want := APIPagination{Page: tt.wantPage, PerPage: tt.wantPer, Total: 5, TotalPages: tt.wantTotalP}
+			if len(data) != tt.wantLen || res.Pagination == nil || *res.Pagination != want {
+				t.Fatalf("got %d items, pagination %+v, want %d items, %+v", len(data), res.Pagination, tt.wantLen, want)
+			}
+			if got := rec.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Link = %q, want %q", got, tt.wantLink)
+			}
+		})
+	}
+
+	// 範囲外の指定は 400
+	for _, query := range []string{"?per_page=0", fmt.Sprintf("?per_page=%d", apiMaxPerPage+1), "?per_page=abc", "?page=0", "?page=-1"} {
+		t.Run("invalid"+query, func(t *testing.T) {
+			rec, res := serveAPI(t, h, http.MethodGet, "/api/v1/diaries"+query, token, "")
+			assertAPIError(t, rec, res, http.StatusBadRequest, apiErrorBadRequest)
+		})
+	}
+}
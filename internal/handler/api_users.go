@@ -0,0 +1,74 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/api_users.go
+// api_usersは、JSON API（/api/v1）のユーザー情報と睡眠設定のハンドラーを提供します。
+
+import (
+	"net/http"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+)
+
+// 認証したユーザーの情報（アクセストークンで認証した場合はトークンの情報を含む）
+func (h *APIHandler) Me(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	res := &MeResponse{
+		ID:          user.ID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		TimeZone:    user.TimeZoneOrDefault(),
+	}
+	if token := GetAccessTokenFromContext(r.Context()); token != nil {
+		res.Token = newAccessTokenResponse(token)
+	}
+
+	h.writeData(w, http.StatusOK, res)
+}
+
+// 睡眠設定の取得
+func (h *APIHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	pref, err := h.service.User().GetSleepPreference(r.Context(), GetUserIDFromContext(r.Context()))
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	h.writeData(w, http.StatusOK, newPreferencesResponse(pref))
+}
+
+// 睡眠設定の更新（指定した項目のみ）
+func (h *APIHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req PreferencesRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	update := service.SleepPreferenceUpdate{
+		SleepGoalHours:  req.SleepGoalHours,
+		ReminderEnabled: req.ReminderEnabled,
+	}
+	if req.Bedtime != nil {
+		bedtime, ok := h.parseTimeOfDay(w, "bedtime", *req.Bedtime)
+		if !ok {
+			return
+		}
+		update.PreferredBedtime = &bedtime
+	}
+	if req.WakeupTime != nil {
+		wakeupTime, ok := h.parseTimeOfDay(w, "wakeup_time", *req.WakeupTime)
+		if !ok {
+			return
+		}
+		update.PreferredWakeupTime = &wakeupTime
+	}
+
+	pref, err := h.service.User().PatchSleepPreference(r.Context(), GetUserIDFromContext(r.Context()), update)
+	if err != nil {
+		h.serviceError(w, err)
+		return
+	}
+
+	h.writeData(w, http.StatusOK, newPreferencesResponse(pref))
+}
@@ -6,8 +6,15 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/223n-tech/SuiminNisshi-Go/internal/middleware"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
 )
 
@@ -17,6 +24,9 @@ type userContextKey string
 // ユーザー情報のコンテキストキー
 const UserKey userContextKey = "user"
 
+// セッションIDを保持するクッキー名
+const SessionCookieName = "session_id"
+
 // 認証関連のハンドラー
 type AuthHandler struct {
 	templates *TemplateManager
@@ -35,13 +45,13 @@ func NewAuthHandler(templates *TemplateManager, svc *service.Service) *AuthHandl
 func (h *AuthHandler) RegisterRoutes(r *RouterWrapper) {
 	r.Get("/login", h.LoginPage)
 	r.Post("/login", h.Login)
-	r.Get("/logout", h.Logout)
+	r.Post("/logout", h.Logout)
 }
 
 // ログイン画面を表示
 func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
 	// すでにログインしている場合はダッシュボードにリダイレクト
-	if user := r.Context().Value(UserKey); user != nil {
+	if user := GetUserFromContext(r.Context()); user != nil {
 		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 		return
 	}
@@ -58,7 +68,7 @@ func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err := h.templates.Render(w, "login.html", data)
+	err := h.templates.Render(w, r, "login.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -84,91 +94,193 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 				Message: "メールアドレスとパスワードを入力してください",
 			},
 		}
-		h.templates.Render(w, "login.html", data)
+		h.templates.Render(w, r, "login.html", data)
 		return
 	}
 
 	// ユーザー認証
-	_, err := h.service.User().Authenticate(r.Context(), email, password)
+	user, err := h.service.User().Authenticate(r.Context(), email, password, clientIP(r))
 	if err != nil {
 		data := &TemplateData{
 			Title: "ログイン",
 			Flash: &Flash{
 				Type:    "danger",
-				Message: "メールアドレスまたはパスワードが正しくありません",
+				Message: loginErrorMessage(err),
 			},
 		}
-		h.templates.Render(w, "login.html", data)
+		h.templates.Render(w, r, "login.html", data)
 		return
 	}
 
+	// 既存のセッションを破棄（セッション固定攻撃の対策）
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		if err := h.service.Session().DestroySession(r.Context(), cookie.Value); err != nil {
+			h.service.Logger().Error("既存セッションの破棄に失敗: error=%v", err)
+		}
+	}
+
 	// セッションの作成
-	// TODO: セッション管理の実装
-	// session := CreateSession(r.Context(), user)
+	session, err := h.service.Session().CreateSession(r.Context(), user.ID)
+	if err != nil {
+		h.service.Logger().Error("セッションの作成に失敗: error=%v, user_id=%d", err, user.ID)
+		data := &TemplateData{
+			Title: "ログイン",
+			Flash: &Flash{
+				Type:    "danger",
+				Message: "ログイン処理中にエラーが発生しました",
+			},
+		}
+		h.templates.Render(w, r, "login.html", data)
+		return
+	}
 
-	// TODO: セッションIDをクッキーに設定
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_id",
-		// Value:    session.ID,
-		Value:    "dummy-session-id",
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-	})
+	// セッションIDをクッキーに設定
+	setSessionCookie(w, session)
+
+	// ログイン前のCSRFトークンを引き継がないよう切り替え
+	if err := middleware.RotateCSRFToken(w, r); err != nil {
+		h.service.Logger().Error("CSRFトークンの切り替えに失敗: error=%v", err)
+	}
 
 	// ダッシュボードにリダイレクト
 	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 }
 
+// ログインの失敗理由に応じたメッセージを取得
+func loginErrorMessage(err error) string {
+	var throttleErr *service.LoginThrottleError
+	if errors.As(err, &throttleErr) {
+		minutes := int((throttleErr.RetryAfter + time.Minute - 1) / time.Minute)
+		if errors.Is(err, service.ErrIPLocked) {
+			return fmt.Sprintf("お使いのネットワークからのログインの失敗が続いたため、ログインを一時的に制限しています。%d分後に再度お試しください。", minutes)
+		}
+		if errors.Is(err, service.ErrAccountLocked) {
+			return fmt.Sprintf("ログインの失敗が続いたため、アカウントを一時的にロックしました。%d分後に再度お試しいただくか、パスワードを再設定してロックを解除してください。", minutes)
+		}
+		seconds := int((throttleErr.RetryAfter + time.Second - 1) / time.Second)
+		return fmt.Sprintf("ログインの試行が多すぎます。%d秒後に再度お試しください。", seconds)
+	}
+	return "メールアドレスまたはパスワードが正しくありません"
+}
+
+// リクエスト元のIPアドレスを取得（RealIPミドルウェアで解決済みのアドレス）
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
 // ログアウト処理
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	// セッションの破棄
-	// TODO: セッション管理の実装
-	// ClearSession(r.Context())
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		if err := h.service.Session().DestroySession(r.Context(), cookie.Value); err != nil {
+			h.service.Logger().Error("セッションの破棄に失敗: error=%v", err)
+		}
+	}
 
 	// セッションクッキーの削除
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_id",
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1,
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-	})
+	clearSessionCookie(w)
+
+	// ログイン中のCSRFトークンを引き継がないよう切り替え
+	if err := middleware.RotateCSRFToken(w, r); err != nil {
+		h.service.Logger().Error("CSRFトークンの切り替えに失敗: error=%v", err)
+	}
 
 	// ログインページにリダイレクト
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
-// 認証が必要なリクエストに対してミドルウェアを適用
-func RequireAuth(next http.Handler) http.Handler {
+// セッションクッキーからユーザー情報を読み込み、コンテキストに設定するミドルウェア
+func (h *AuthHandler) LoadSession(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// TODO: セッションの検証
-		// sessionID, err := r.Cookie("session_id")
-		// if err != nil {
-		// 	http.Redirect(w, r, "/login", http.StatusSeeOther)
-		// 	return
-		// }
-
-		// TODO: セッションからユーザー情報を取得
-		// user, err := GetUserFromSession(r.Context(), sessionID.Value)
-		// if err != nil {
-		// 	http.Redirect(w, r, "/login", http.StatusSeeOther)
-		// 	return
-		// }
+		cookie, err := r.Cookie(SessionCookieName)
+		if err != nil || cookie.Value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// セッションの検証
+		session, user, err := h.service.Session().ValidateSession(r.Context(), cookie.Value)
+		if err != nil {
+			if err != service.ErrSessionNotFound && err != service.ErrSessionExpired {
+				h.service.Logger().Error("セッションの検証に失敗: error=%v", err)
+			}
+			clearSessionCookie(w)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// 有効期限の延長
+		renewed, err := h.service.Session().RenewSession(r.Context(), session)
+		if err != nil {
+			h.service.Logger().Error("セッションの延長に失敗: error=%v", err)
+		}
+		if renewed {
+			setSessionCookie(w, session)
+		}
 
 		// ユーザー情報をコンテキストに設定
-		// ctx := context.WithValue(r.Context(), UserKey, user)
-		// next.ServeHTTP(w, r.WithContext(ctx))
+		ctx := context.WithValue(r.Context(), UserKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// 認証が必要なリクエストに対してミドルウェアを適用
+// LoadSessionの後段で使用します。
+func RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetUserFromContext(r.Context()) == nil {
+			// APIの場合はリダイレクトせずに401を返す
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
 
-		// 開発用に一時的にスキップ
 		next.ServeHTTP(w, r)
 	})
 }
 
 // コンテキストからユーザー情報を取得
-func GetUserFromContext(ctx context.Context) interface{} {
-	return ctx.Value(UserKey)
+func GetUserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(UserKey).(*models.User)
+	return user
+}
+
+// コンテキストからユーザーIDを取得
+func GetUserIDFromContext(ctx context.Context) int64 {
+	if user := GetUserFromContext(ctx); user != nil {
+		return user.ID
+	}
+	return 0
+}
+
+// セッションクッキーを設定
+func setSessionCookie(w http.ResponseWriter, session *models.Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    session.ID,
+		Path:     "/",
+		Expires:  session.Expires,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// セッションクッキーを削除
+func clearSessionCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
 }
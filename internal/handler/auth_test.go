@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// レスポンスで設定されたクッキーの値を取得
+func responseCookie(rec *httptest.ResponseRecorder, name string) string {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// クッキーを付けたフォームの送信リクエストを作成
+func newFormRequest(path string, form url.Values, cookies map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for name, value := range cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	return req
+}
+
+// ログインとログアウトでCSRFトークンが切り替わり、ログアウトはCSRFトークン付きのPOSTのみ受け付けることを確認
+func TestLoginLogoutCSRF(t *testing.T) {
+	app := newTestApp(t)
+	if _, err := app.service.User().Register(context.Background(), "user@example.com", "テスト", "password123"); err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+	r := chi.NewRouter()
+	RegisterAppRoutes(r, app.templates, app.service, app.errors, app.logger)
+
+	// ログイン前のCSRFトークン
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	anonymousToken := responseCookie(rec, middleware.CSRFCookieName)
+	if anonymousToken == "" {
+		t.Fatal("login page did not issue a CSRF token")
+	}
+
+	// ログイン
+	form := url.Values{"email": {"user@example.com"}, "password": {"password123"}, middleware.CSRFFieldName: {anonymousToken}}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newFormRequest("/login", form, map[string]string{middleware.CSRFCookieName: anonymousToken}))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/dashboard" {
+		t.Fatalf("login: status = %d location = %q, want redirect to /dashboard", rec.Code, rec.Header().Get("Location"))
+	}
+	sessionID := responseCookie(rec, SessionCookieName)
+	loginToken := responseCookie(rec, middleware.CSRFCookieName)
+	if sessionID == "" {
+		t.Fatal("login did not set a session cookie")
+	}
+	if loginToken == "" || loginToken == anonymousToken {
+		t.Fatalf("login did not rotate the CSRF token: before %q, after %q", anonymousToken, loginToken)
+	}
+	cookies := map[string]string{SessionCookieName: sessionID, middleware.CSRFCookieName: loginToken}
+
+	// GETではログアウトできない
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/logout", nil)
+	req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: sessionID})
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /logout: status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+
+	// ログイン前のトークンではログアウトできない
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newFormRequest("/logout", url.Values{middleware.CSRFFieldName: {anonymousToken}}, cookies))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("POST /logout with stale token: status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	// ログアウト
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, newFormRequest("/logout", url.Values{middleware.CSRFFieldName: {loginToken}}, cookies))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login" {
+		t.Fatalf("POST /logout: status = %d location = %q, want redirect to /login", rec.Code, rec.Header().Get("Location"))
+	}
+	if token := responseCookie(rec, middleware.CSRFCookieName); token == "" || token == loginToken {
+		t.Fatalf("logout did not rotate the CSRF token: before %q, after %q", loginToken, token)
+	}
+	if _, _, err := app.service.Session().ValidateSession(context.Background(), sessionID); err == nil {
+		t.Fatal("session is still valid after logout")
+	}
+}
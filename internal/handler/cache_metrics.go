@@ -0,0 +1,63 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/cache_metrics.go
+// cache_metricsは、集計データのキャッシュの使用状況を /metrics/cache で提供するハンドラーを実装しています。
+// 利用者に公開しないよう、アプリケーションのルーターには登録せず、main で内部向けのアドレス（CACHE_METRICS_ADDR）のサーバーに登録します。
+// 監視項目とアラート条件は doc/cache.md の「5. 監視と運用」を参照してください。
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/cache"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// キャッシュの使用状況のアドレス
+const cacheMetricsPath = "/metrics/cache"
+
+// キャッシュの使用状況のハンドラー
+type CacheMetricsHandler struct {
+	service *service.Service
+}
+
+// キャッシュの使用状況のレスポンス
+// 回数は起動時からの累計、率は累計から求めた値（%）
+type CacheMetricsResponse struct {
+	cache.Stats
+	HitRate           float64  `json:"hit_rate"`
+	UpdateSuccessRate float64  `json:"update_success_rate"`
+	MemoryUsage       float64  `json:"memory_usage"`
+	Alerts            []string `json:"alerts"`
+}
+
+// CacheMetricsHandlerを作成
+func NewCacheMetricsHandler(s *service.Service) *CacheMetricsHandler {
+	return &CacheMetricsHandler{service: s}
+}
+
+// ルートの登録
+func (h *CacheMetricsHandler) RegisterRoutes(r chi.Router) {
+	r.Get(cacheMetricsPath, h.Metrics)
+}
+
+// キャッシュの使用状況を返す
+func (h *CacheMetricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	stats := h.service.Summary().CacheStats()
+	alerts := stats.Alerts(0)
+	if alerts == nil {
+		alerts = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(CacheMetricsResponse{
+		Stats:             stats,
+		HitRate:           stats.HitRate(),
+		UpdateSuccessRate: stats.UpdateSuccessRate(),
+		MemoryUsage:       stats.MemoryUsage(),
+		Alerts:            alerts,
+	})
+}
@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
 )
 
@@ -40,19 +39,12 @@ func (h *DashboardHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
-	userModel, ok := user.(*models.User)
-	if !ok {
-		// TODO: 実装
-	}
-
-	// TODO: 実際のユーザーIDを使用
-	// userID := user.ID
-	var userID int64 = 1 // 開発用
+	userID := user.ID
 
 	// 睡眠設定の取得
 	pref, err := h.service.User().GetSleepPreference(r.Context(), userID)
 	if err != nil {
-		h.templates.Render(w, "500.html", &TemplateData{
+		h.templates.Render(w, r, "500.html", &TemplateData{
 			Title: "Internal Server Error",
 		})
 		return
@@ -63,7 +55,7 @@ func (h *DashboardHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 	startDate := endDate.AddDate(0, 0, -7)
 	diary, err := h.service.Diary().GetDiaryByDateRange(r.Context(), userID, startDate, endDate)
 	if err != nil {
-		h.templates.Render(w, "500.html", &TemplateData{
+		h.templates.Render(w, r, "500.html", &TemplateData{
 			Title: "Internal Server Error",
 		})
 		return
@@ -74,7 +66,7 @@ func (h *DashboardHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 	if len(diary) > 0 {
 		stats, err = h.service.Record().GetDashboardStats(r.Context(), diary[0].ID)
 		if err != nil {
-			h.templates.Render(w, "500.html", &TemplateData{
+			h.templates.Render(w, r, "500.html", &TemplateData{
 				Title: "Internal Server Error",
 			})
 			return
@@ -84,7 +76,7 @@ func (h *DashboardHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 	data := &TemplateData{
 		Title:      "ダッシュボード",
 		ActiveMenu: "dashboard",
-		User:       userModel,
+		User:       user,
 		Data: map[string]interface{}{
 			"Preferences": pref,
 			"Statistics": stats,
@@ -93,7 +85,7 @@ func (h *DashboardHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err = h.templates.Render(w, "dashboard.html", data)
+	err = h.templates.Render(w, r, "dashboard.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -102,8 +94,7 @@ func (h *DashboardHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 
 // ダッシュボードのサマリーデータを取得
 func (h *DashboardHandler) GetDashboardSummary(w http.ResponseWriter, r *http.Request) {
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// 期間の取得
 	endDate := time.Now()
@@ -0,0 +1,425 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/diary.go
+// diaryは、睡眠日誌の一覧・詳細・作成・編集・終了・削除の画面のハンドラーを提供します。
+// 睡眠日誌の期間は重ならないため、今日を含む睡眠日誌を「現在の睡眠日誌」として扱います。
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// 新しい睡眠日誌の既定の日数（2週間）
+	defaultDiaryDays = 14
+	// 1日の時間枠の数（30分ごと）
+	diaryDaySlots = 48
+)
+
+// 睡眠日誌の画面のハンドラー
+type DiaryHandler struct {
+	templates *TemplateManager
+	service   *service.Service
+	errors    *ErrorHandler
+}
+
+// 睡眠日誌の詳細画面に表示する1日分の記録
+type diaryDay struct {
+	Date    time.Time
+	Records int      // 記録数
+	Meals   int      // 食事の記録数
+	Slots   []string // 時間枠ごとの睡眠状態の記号（未記録の場合は空文字）
+}
+
+// DiaryHandlerを作成
+func NewDiaryHandler(templates *TemplateManager, svc *service.Service, errorHandler *ErrorHandler) *DiaryHandler {
+	return &DiaryHandler{
+		templates: templates,
+		service:   svc,
+		errors:    errorHandler,
+	}
+}
+
+// ルーティングを登録
+func (h *DiaryHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/diaries", h.List)
+	r.Get("/diaries/new", h.New)
+	r.Post("/diaries", h.Create)
+	r.Get("/diaries/{id}", h.Show)
+	r.Get("/diaries/{id}/edit", h.Edit)
+	r.Post("/diaries/{id}", h.Update)
+	r.Post("/diaries/{id}/close", h.Close)
+	r.Post("/diaries/{id}/delete", h.Delete)
+}
+
+// 睡眠日誌の一覧を表示（開始日の新しい順）
+func (h *DiaryHandler) List(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	diaries, err := h.service.Diary().GetUserDiaries(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "睡眠日誌の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	current, err := h.service.Diary().GetCurrentDiary(r.Context(), user)
+	if err != nil {
+		http.Error(w, "睡眠日誌の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	data := &TemplateData{
+		Title:      "睡眠日誌",
+		ActiveMenu: "diaries",
+		User:       user,
+		Flash:      flashFromQuery(r),
+		Data: map[string]interface{}{
+			"Diaries":      diaries,
+			"CurrentDiary": current,
+			"Today":        h.today(user),
+		},
+	}
+
+	if err := h.templates.Render(w, r, "diaries.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// 睡眠日誌の作成画面を表示
+// 既定の期間は、今日から2週間
+func (h *DiaryHandler) New(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	today := h.today(user)
+
+	h.renderForm(w, r, nil, flashFromQuery(r), map[string]string{
+		"name":       "睡眠日誌 " + today.Format("2006/01/02") + "〜",
+		"start_date": today.Format("2006-01-02"),
+		"end_date":   today.AddDate(0, 0, defaultDiaryDays-1).Format("2006-01-02"),
+		"note":       "",
+	})
+}
+
+// 睡眠日誌の作成
+func (h *DiaryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "フォームの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+
+	form := diaryForm(r)
+	startDate, endDate, ok := parseDiaryPeriod(form)
+	if !ok {
+		h.renderForm(w, r, nil, &Flash{Type: "danger", Message: "開始日と終了日を正しく入力してください"}, form)
+		return
+	}
+
+	diary, err := h.service.Diary().CreateDiary(r.Context(), GetUserIDFromContext(r.Context()), startDate, endDate, form["name"], strings.TrimSpace(form["note"]))
+	if message, ok := diaryErrorMessage(err); ok {
+		h.renderForm(w, r, nil, &Flash{Type: "danger", Message: message}, form)
+		return
+	}
+	if err != nil {
+		http.Error(w, "睡眠日誌の作成に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	redirectWithFlash(w, r, "/diaries/"+strconv.FormatInt(diary.ID, 10), "success", "睡眠日誌を作成しました")
+}
+
+// 睡眠日誌の詳細を表示
+// 期間の日ごとに、記録数と時間枠ごとの睡眠状態を表示する
+func (h *DiaryHandler) Show(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	diary, ok := h.userDiary(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := h.service.Record().GetDiaryRecords(r.Context(), diary.ID)
+	if err != nil {
+		http.Error(w, "睡眠記録の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	states, err := h.service.Record().GetStatesList(r.Context())
+	if err != nil {
+		http.Error(w, "睡眠状態の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	data := &TemplateData{
+		Title:      diary.DiaryName,
+		ActiveMenu: "diaries",
+		User:       user,
+		Flash:      flashFromQuery(r),
+		Data: map[string]interface{}{
+			"Diary":       diary,
+			"Days":        buildDiaryDays(diary, records, states),
+			"States":      states,
+			"RecordCount": len(records),
+			"Today":       h.today(user),
+		},
+	}
+
+	if err := h.templates.Render(w, r, "diaries-detail.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// 睡眠日誌の編集画面を表示
+func (h *DiaryHandler) Edit(w http.ResponseWriter, r *http.Request) {
+	diary, ok := h.userDiary(w, r)
+	if !ok {
+		return
+	}
+
+	h.renderForm(w, r, diary, flashFromQuery(r), map[string]string{
+		"name":       diary.DiaryName,
+		"start_date": diary.StartDate.Format("2006-01-02"),
+		"end_date":   diary.EndDate.Format("2006-01-02"),
+		"note":       diary.Note.String,
+	})
+}
+
+// 睡眠日誌の更新（名前・期間・メモ）
+func (h *DiaryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	diary, ok := h.userDiary(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "フォームの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+
+	form := diaryForm(r)
+	startDate, endDate, ok := parseDiaryPeriod(form)
+	if !ok {
+		h.renderForm(w, r, diary, &Flash{Type: "danger", Message: "開始日と終了日を正しく入力してください"}, form)
+		return
+	}
+
+	diary.DiaryName = form["name"]
+	diary.StartDate = startDate
+	diary.EndDate = endDate
+	diary.Note = nullString(strings.TrimSpace(form["note"]))
+
+	err := h.service.Diary().UpdateDiary(r.Context(), diary)
+	if message, ok := diaryErrorMessage(err); ok {
+		h.renderForm(w, r, diary, &Flash{Type: "danger", Message: message}, form)
+		return
+	}
+	if err != nil {
+		http.Error(w, "睡眠日誌の更新に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	redirectWithFlash(w, r, "/diaries/"+strconv.FormatInt(diary.ID, 10), "success", "睡眠日誌を更新しました")
+}
+
+// 睡眠日誌を終了（終了日を今日にする）
+func (h *DiaryHandler) Close(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	diaryID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errors.Handle404(w, r)
+		return
+	}
+
+	_, err = h.service.Diary().CloseDiary(r.Context(), user.ID, diaryID, h.today(user))
+	if errors.Is(err, service.ErrDiaryNotFound) || errors.Is(err, service.ErrDiaryAccessDenied) {
+		h.errors.Handle404(w, r)
+		return
+	}
+
+	location := "/diaries/" + strconv.FormatInt(diaryID, 10)
+	switch {
+	case errors.Is(err, service.ErrDiaryAlreadyClosed):
+		redirectWithFlash(w, r, location, "warning", "この睡眠日誌はすでに終了しています")
+	case errors.Is(err, service.ErrInvalidDiaryPeriod):
+		redirectWithFlash(w, r, location, "warning", "開始前の睡眠日誌は終了できません。削除するか期間を変更してください")
+	case errors.Is(err, service.ErrDiaryRecordsOutOfPeriod):
+		redirectWithFlash(w, r, location, "danger", "明日以降の睡眠記録があるため終了できません")
+	case err != nil:
+		http.Error(w, "睡眠日誌の終了に失敗しました", http.StatusInternalServerError)
+	default:
+		redirectWithFlash(w, r, location, "success", "睡眠日誌を終了しました")
+	}
+}
+
+// 睡眠日誌の削除（睡眠記録もあわせて削除）
+func (h *DiaryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	diary, ok := h.userDiary(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Diary().DeleteDiary(r.Context(), diary.ID); err != nil {
+		http.Error(w, "睡眠日誌の削除に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	redirectWithFlash(w, r, "/diaries", "success", "睡眠日誌「"+diary.DiaryName+"」を削除しました")
+}
+
+// 作成・編集画面を表示（diary がnilの場合は作成）
+func (h *DiaryHandler) renderForm(w http.ResponseWriter, r *http.Request, diary *models.SleepDiary, flash *Flash, form map[string]string) {
+	title := "睡眠日誌の作成"
+	if diary != nil {
+		title = "睡眠日誌の編集"
+	}
+
+	data := &TemplateData{
+		Title:      title,
+		ActiveMenu: "diaries",
+		User:       GetUserFromContext(r.Context()),
+		Flash:      flash,
+		Data: map[string]interface{}{
+			"Diary": diary,
+			"Form":  form,
+		},
+	}
+
+	if err := h.templates.Render(w, r, "diaries-form.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// URLのIDからログイン中のユーザーの睡眠日誌を取得
+// 見つからない場合と他のユーザーの睡眠日誌の場合は、404を返して false を返す
+func (h *DiaryHandler) userDiary(w http.ResponseWriter, r *http.Request) (*models.SleepDiary, bool) {
+	diaryID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errors.Handle404(w, r)
+		return nil, false
+	}
+
+	diary, err := h.service.Diary().GetUserDiary(r.Context(), GetUserIDFromContext(r.Context()), diaryID)
+	if errors.Is(err, service.ErrDiaryNotFound) || errors.Is(err, service.ErrDiaryAccessDenied) {
+		h.errors.Handle404(w, r)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "睡眠日誌の取得に失敗しました", http.StatusInternalServerError)
+		return nil, false
+	}
+	return diary, true
+}
+
+// ユーザーのタイムゾーンでの今日の日付
+func (h *DiaryHandler) today(user *models.User) time.Time {
+	now := time.Now().In(user.Location())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// 作成・編集フォームの入力値を取得
+func diaryForm(r *http.Request) map[string]string {
+	return map[string]string{
+		"name":       r.PostFormValue("name"),
+		"start_date": r.PostFormValue("start_date"),
+		"end_date":   r.PostFormValue("end_date"),
+		"note":       r.PostFormValue("note"),
+	}
+}
+
+// フォームの開始日・終了日を読み込む
+func parseDiaryPeriod(form map[string]string) (time.Time, time.Time, bool) {
+	startDate, err := time.Parse("2006-01-02", form["start_date"])
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endDate, err := time.Parse("2006-01-02", form["end_date"])
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return startDate, endDate, true
+}
+
+// 入力値の誤りによる睡眠日誌のエラーを画面に表示するメッセージに変換
+func diaryErrorMessage(err error) (string, bool) {
+	switch {
+	case errors.Is(err, service.ErrEmptyDiaryName):
+		return "睡眠日誌の名前を入力してください", true
+	case errors.Is(err, service.ErrInvalidDate):
+		return "開始日と終了日を入力してください", true
+	case errors.Is(err, service.ErrInvalidDiaryPeriod):
+		return "終了日は開始日以降の日付を指定してください", true
+	case errors.Is(err, service.ErrDiaryPeriodOverlap):
+		return "期間が他の睡眠日誌と重なっています。睡眠日誌の期間は重ならないように指定してください", true
+	case errors.Is(err, service.ErrDiaryRecordsOutOfPeriod):
+		return "変更後の期間の外になる睡眠記録があるため、期間を変更できません", true
+	default:
+		return "", false
+	}
+}
+
+// 睡眠日誌の期間の日ごとの記録を作成
+func buildDiaryDays(diary *models.SleepDiary, records []*models.SleepRecord, states []*models.SleepState) []*diaryDay {
+	symbols := make(map[int64]string, len(states))
+	for _, state := range states {
+		symbols[state.ID] = state.DisplaySymbol
+	}
+
+	days := make([]*diaryDay, 0, diary.TotalDays())
+	index := make(map[string]*diaryDay, diary.TotalDays())
+	for date := diary.StartDate; !date.After(diary.EndDate); date = date.AddDate(0, 0, 1) {
+		day := &diaryDay{
+			Date:  date,
+			Slots: make([]string, diaryDaySlots),
+		}
+		days = append(days, day)
+		index[date.Format("2006-01-02")] = day
+	}
+
+	for _, record := range records {
+		day, ok := index[record.RecordDate.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		day.Records++
+		switch record.RecordType {
+		case models.RecordTypeMeal:
+			day.Meals++
+		case models.RecordTypeState:
+			slot := record.TimeSlot.Hour()*2 + record.TimeSlot.Minute()/30
+			day.Slots[slot] = symbols[record.SleepStateID]
+		}
+	}
+
+	return days
+}
+
+// クエリパラメーター（message・type）からフラッシュメッセージを取得
+func flashFromQuery(r *http.Request) *Flash {
+	message := r.URL.Query().Get("message")
+	if message == "" {
+		return nil
+	}
+	return &Flash{
+		Type:    r.URL.Query().Get("type"),
+		Message: message,
+	}
+}
+
+// フラッシュメッセージをクエリパラメーターに付けてリダイレクト
+func redirectWithFlash(w http.ResponseWriter, r *http.Request, location, flashType, message string) {
+	query := url.Values{}
+	query.Set("message", message)
+	query.Set("type", flashType)
+	http.Redirect(w, r, location+"?"+query.Encode(), http.StatusSeeOther)
+}
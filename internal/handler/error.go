@@ -30,7 +30,7 @@ func NewErrorHandler(templates *TemplateManager, svc *service.Service, logger *l
 }
 
 // エラーページを表示
-func (h *ErrorHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request, status int, err error) {
+func (h *ErrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, status int, err error) {
 	// エラーのログ記録
 	if err != nil {
 		h.logger.Printf("Error occurred: %v\nStack trace:\n%s", err, debug.Stack())
@@ -57,7 +57,7 @@ func (h *ErrorHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request, status
 	}
 
 	// テンプレートのレンダリング
-	if err := h.templates.Render(w, templateName, data); err != nil {
+	if err := h.templates.Render(w, r, templateName, data); err != nil {
 		h.logger.Printf("Error rendering error template: %v", err)
 		http.Error(w, http.StatusText(status), status)
 	}
@@ -113,6 +113,8 @@ func (h *ErrorHandler) getTemplateNameForStatus(status int) string {
 // エラーメッセージを取得
 func (h *ErrorHandler) getErrorMessage(status int, err error) string {
 	switch status {
+	case http.StatusBadRequest:
+		return "リクエストの内容が正しくありません。入力内容をご確認ください。"
 	case http.StatusNotFound:
 		return "お探しのページが見つかりませんでした。"
 	case http.StatusForbidden:
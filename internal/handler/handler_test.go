@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/memory"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+)
+
+// ハンドラーのテストに使うサービス・テンプレート
+type testApp struct {
+	repo      repository.Repository
+	service   *service.Service
+	templates *TemplateManager
+	errors    *ErrorHandler
+	logger    *log.Logger
+}
+
+// インメモリのリポジトリとテンプレートを使うテスト環境を作成
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	logger := log.New(io.Discard, "", 0)
+	repo := memory.NewMemoryRepository()
+	svc := service.NewService(repo, service.ErrorLevel, logger)
+
+	templates := NewTemplateManager("../../web/views", nil, logger, svc)
+	if err := templates.LoadTemplates(); err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	return &testApp{
+		repo:      repo,
+		service:   svc,
+		templates: templates,
+		errors:    NewErrorHandler(templates, svc, logger),
+		logger:    logger,
+	}
+}
+
+// ユーザーを作成
+func (a *testApp) createUser(t *testing.T, email string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email, DisplayName: "テスト", PasswordHash: "hash", TimeZone: models.DefaultTimeZone}
+	if err := a.repo.User().Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+// 睡眠日誌と一晩分の睡眠記録を作成（日付は YYYY-MM-DD）
+func (a *testApp) createDiary(t *testing.T, userID int64, start, end string) *models.SleepDiary {
+	t.Helper()
+	ctx := context.Background()
+	startDate, _ := time.Parse("2006-01-02", start)
+	endDate, _ := time.Parse("2006-01-02", end)
+	diary, err := a.service.Diary().CreateDiary(ctx, userID, startDate, endDate, "テスト", "")
+	if err != nil {
+		t.Fatalf("failed to create diary: %v", err)
+	}
+
+	entry := &models.NightEntry{
+		Date:      startDate,
+		BedTime:   startDate.Add(23 * time.Hour),
+		LightsOut: startDate.Add(23 * time.Hour),
+		FinalWake: startDate.Add(31 * time.Hour),
+	}
+	if _, err := a.service.Record().ReplaceNightRecords(ctx, userID, entry); err != nil {
+		t.Fatalf("failed to save night: %v", err)
+	}
+	return diary
+}
+
+// ログイン中のユーザーをコンテキストに設定するミドルウェア（user が nil の場合は未ログイン）
+func withUser(user *models.User) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user != nil {
+				r = r.WithContext(context.WithValue(r.Context(), UserKey, user))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,482 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/openapi.go
+// openapiは、APIの仕様書（OpenAPI 3）を生成して /api/openapi.json で提供するハンドラーを実装しています。
+// 仕様書は openapi_operations.go に定義したAPIの操作の一覧と、レスポンスなどのGoの型から生成します。
+// 登録したルートと仕様書の内容が一致しているかは、テスト（openapi_test.go）から CheckRoutes を呼び出して確認します。
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// OpenAPIのバージョン
+	openAPIVersion = "3.0.3"
+	// 仕様書のアドレス
+	openAPIPath = "/api/openapi.json"
+	// 仕様書と照合するルートのプレフィックス
+	openAPIRoutePrefix = "/api/"
+)
+
+// パスパラメーター（{id} など）
+var openAPIPathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// OpenAPIの仕様書
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Tags       []openAPITag                            `json:"tags"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+// 仕様書の概要
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// 操作の分類
+type openAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// 操作
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags"`
+	Security    []map[string][]string       `json:"security"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+// パラメーター
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+// リクエストボディ
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+// レスポンス
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+// メディアタイプ
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+// スキーマ
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// 共通で使うスキーマなど
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+// 認証方式
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// APIの仕様書のハンドラー
+type OpenAPIHandler struct {
+	document *openAPIDocument
+	body     []byte
+}
+
+// OpenAPIHandlerを作成
+// 仕様書は作成時に一度だけ生成する
+func NewOpenAPIHandler() *OpenAPIHandler {
+	document := newOpenAPIDocument(apiOperations())
+	body, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		// 仕様書はGoの型から生成するため、ここで失敗するのは実装の誤り
+		panic(fmt.Sprintf("OpenAPIの仕様書の生成に失敗しました: %v", err))
+	}
+
+	return &OpenAPIHandler{
+		document: document,
+		body:     body,
+	}
+}
+
+// ルーティングを登録
+func (h *OpenAPIHandler) RegisterRoutes(r chi.Router) {
+	r.Get(openAPIPath, h.Document)
+}
+
+// 仕様書を返す
+func (h *OpenAPIHandler) Document(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(h.body)
+}
+
+// 登録したルートと仕様書の内容が一致しているかを確認
+// /api/ で始まるルートのうち仕様書にないものと、仕様書にあってルートが登録されていないものをエラーとして返す
+// すべてのルートを登録したルーターを渡す
+func (h *OpenAPIHandler) CheckRoutes(routes chi.Routes) error {
+	registered := make(map[string]bool)
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		route = strings.Replace(route, "/*/", "/", -1)
+		if strings.HasPrefix(route, openAPIRoutePrefix) {
+			registered[method+" "+route] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ルートの取得に失敗しました: %w", err)
+	}
+
+	documented := make(map[string]bool)
+	for path, operations := range h.document.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var problems []string
+	for route := range registered {
+		if !documented[route] {
+			problems = append(problems, "仕様書に記載がありません: "+route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			problems = append(problems, "ルートが登録されていません: "+route)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("APIのルートとOpenAPIの仕様書が一致しません:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// APIの操作の一覧から仕様書を生成
+func newOpenAPIDocument(operations []apiOperation) *openAPIDocument {
+	generator := newOpenAPISchemaGenerator()
+
+	document := &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: openAPIInfo{
+			Title:       "睡眠日誌 API",
+			Description: "睡眠日誌のJSON APIです。/api/v1 はパーソナルアクセストークン（Bearer）またはログインセッションで、/api のその他の操作はログインセッションで認証します。",
+			Version:     "1.0.0",
+		},
+		Tags:  apiTags(),
+		Paths: make(map[string]map[string]*openAPIOperation),
+		Components: openAPIComponents{
+			Schemas: generator.schemas,
+			SecuritySchemes: map[string]*openAPISecurityScheme{
+				openAPISecurityBearer: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "設定画面で発行したパーソナルアクセストークン（read・write の権限）",
+				},
+				openAPISecuritySession: {
+					Type:        "apiKey",
+					In:          "cookie",
+					Name:        SessionCookieName,
+					Description: "ログインセッション（GET以外のリクエストでは X-CSRF-Token ヘッダーが必要）",
+				},
+			},
+		},
+	}
+
+	for _, op := range operations {
+		if document.Paths[op.Path] == nil {
+			document.Paths[op.Path] = make(map[string]*openAPIOperation)
+		}
+		document.Paths[op.Path][strings.ToLower(op.Method)] = newOpenAPIOperation(op, generator)
+	}
+
+	return document
+}
+
+// APIの操作から仕様書の操作を生成
+func newOpenAPIOperation(op apiOperation, generator *openAPISchemaGenerator) *openAPIOperation {
+	operation := &openAPIOperation{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Tags:        []string{op.Tag},
+		Security:    []map[string][]string{},
+		Responses:   make(map[string]*openAPIResponse),
+	}
+
+	// 認証方式（Security が空の場合は認証不要）
+	// OpenAPI 3.0 では Bearer 認証に権限を記載できないため、必要な権限は説明に記載する
+	switch op.Auth {
+	case apiAuthToken:
+		operation.Description = fmt.Sprintf("アクセストークンで認証する場合は %s 権限が必要です。", requiredScope(op.Method))
+		operation.Security = []map[string][]string{
+			{openAPISecurityBearer: {}},
+			{openAPISecuritySession: {}},
+		}
+	case apiAuthSession:
+		operation.Security = []map[string][]string{
+			{openAPISecuritySession: {}},
+		}
+	}
+
+	// パスパラメーター（{date} は日付、それ以外はID）
+	for _, match := range openAPIPathParam.FindAllStringSubmatch(op.Path, -1) {
+		schema := &openAPISchema{Type: "integer", Format: "int64"}
+		if match[1] == "date" {
+			schema = &openAPISchema{Type: "string", Format: "date"}
+		}
+		operation.Parameters = append(operation.Parameters, &openAPIParameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+	// クエリパラメーター
+	for _, param := range op.Query {
+		operation.Parameters = append(operation.Parameters, &openAPIParameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      generator.schemaFor(reflect.TypeOf(param.Type)),
+		})
+	}
+
+	// リクエストボディ
+	if op.Request != nil {
+		operation.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content: map[string]*openAPIMediaType{
+				"application/json": {Schema: generator.schemaFor(reflect.TypeOf(op.Request))},
+			},
+		}
+	}
+
+	// 成功時のレスポンス
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &openAPIResponse{Description: http.StatusText(status)}
+	if op.Response != nil {
+		success.Content = map[string]*openAPIMediaType{
+			"application/json": {Schema: generator.envelope(op.Envelope, reflect.TypeOf(op.Response))},
+		}
+	}
+	operation.Responses[fmt.Sprint(status)] = success
+
+	// エラー時のレスポンス（/api/v1 はJSON、それ以外はテキスト）
+	for _, code := range op.Errors {
+		response := &openAPIResponse{Description: http.StatusText(code)}
+		if strings.HasPrefix(op.Path, apiV1Prefix+"/") {
+			response.Content = map[string]*openAPIMediaType{
+				"application/json": {Schema: generator.schemaFor(reflect.TypeOf(APIErrorResponse{}))},
+			}
+		} else {
+			response.Content = map[string]*openAPIMediaType{
+				"text/plain": {Schema: &openAPISchema{Type: "string"}},
+			}
+		}
+		operation.Responses[fmt.Sprint(code)] = response
+	}
+
+	return operation
+}
+
+// Goの型からスキーマを生成する
+// 名前のある構造体は components.schemas に登録し、$ref で参照する
+type openAPISchemaGenerator struct {
+	schemas map[string]*openAPISchema
+	names   map[reflect.Type]string
+}
+
+// openAPISchemaGeneratorを作成
+func newOpenAPISchemaGenerator() *openAPISchemaGenerator {
+	return &openAPISchemaGenerator{
+		schemas: make(map[string]*openAPISchema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// レスポンスの形式に応じてスキーマを生成
+func (g *openAPISchemaGenerator) envelope(envelope string, t reflect.Type) *openAPISchema {
+	switch envelope {
+	case apiEnvelopeData:
+		return &openAPISchema{
+			Type:       "object",
+			Properties: map[string]*openAPISchema{"data": g.schemaFor(t)},
+			Required:   []string{"data"},
+		}
+	case apiEnvelopeList:
+		return &openAPISchema{
+			Type: "object",
+			Properties: map[string]*openAPISchema{
+				"data":       {Type: "array", Items: g.schemaFor(t)},
+				"pagination": g.schemaFor(reflect.TypeOf(APIPagination{})),
+			},
+			Required: []string{"data", "pagination"},
+		}
+	default:
+		return g.schemaFor(t)
+	}
+}
+
+// Goの型からスキーマを生成
+// encoding/json と同じ規則（jsonタグ、埋め込み、ポインターはnull）で変換する
+func (g *openAPISchemaGenerator) schemaFor(t reflect.Type) *openAPISchema {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case reflect.TypeOf(json.RawMessage{}):
+		return &openAPISchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := g.schemaFor(t.Elem())
+		if schema.Ref != "" {
+			// $ref には他のキーワードを併記できないため、ポインターでも null は表さない
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + g.register(t)}
+	default:
+		// interface{} など、形式を限定できない型
+		return &openAPISchema{}
+	}
+}
+
+// 名前のある構造体を components.schemas に登録し、スキーマ名を返す
+// 別パッケージに同じ名前の型がある場合は、パッケージ名を前に付ける
+func (g *openAPISchemaGenerator) register(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, exists := g.schemas[name]; exists {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	// 再帰的な型に備えて、スキーマを生成する前に名前を登録する
+	g.names[t] = name
+	g.schemas[name] = &openAPISchema{}
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+// 構造体のスキーマを生成
+func (g *openAPISchemaGenerator) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*openAPISchema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		// 埋め込みの構造体は、フィールドを展開する
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := g.structSchema(embedded)
+				for key, value := range inner.Properties {
+					schema.Properties[key] = value
+				}
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schemaFor(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	sort.Strings(schema.Required)
+	return schema
+}
@@ -0,0 +1,253 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/openapi_operations.go
+// openapi_operationsは、OpenAPIの仕様書に記載するAPIの操作の一覧を定義しています。
+// /api で始まるルートを追加・変更した場合は、この一覧もあわせて更新してください。
+// 一覧とルートが一致しない場合は、起動時の OpenAPIHandler.CheckRoutes でエラーになります。
+
+import (
+	"net/http"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+)
+
+// APIの認証方式
+const (
+	// 認証不要
+	apiAuthNone = ""
+	// アクセストークンまたはログインセッション（/api/v1）
+	apiAuthToken = "token"
+	// ログインセッションのみ
+	apiAuthSession = "session"
+)
+
+// レスポンスの形式
+const (
+	// 型のまま返す
+	apiEnvelopeNone = ""
+	// {"data": ...}
+	apiEnvelopeData = "data"
+	// {"data": [...], "pagination": {...}}
+	apiEnvelopeList = "list"
+)
+
+// 仕様書の認証方式の名前
+const (
+	openAPISecurityBearer  = "bearerAuth"
+	openAPISecuritySession = "sessionCookie"
+)
+
+// 操作の分類
+const (
+	apiTagUsers      = "users"
+	apiTagDiaries    = "diaries"
+	apiTagRecords    = "records"
+	apiTagMasters    = "masters"
+	apiTagDashboard  = "dashboard"
+	apiTagStatistics = "statistics"
+	apiTagMeta       = "meta"
+)
+
+// APIの操作
+type apiOperation struct {
+	ID       string          // operationId
+	Method   string          // HTTPメソッド
+	Path     string          // chiのルートと同じ形式のパス
+	Tag      string          // 分類
+	Summary  string          // 概要
+	Auth     string          // 認証方式
+	Query    []apiQueryParam // クエリパラメーター
+	Request  interface{}     // リクエストボディの型（nilの場合はなし）
+	Response interface{}     // 成功時のレスポンスの型（nilの場合は本文なし）
+	Envelope string          // レスポンスの形式
+	Status   int             // 成功時のステータスコード（0の場合は200）
+	Errors   []int           // エラー時のステータスコード
+}
+
+// クエリパラメーター
+type apiQueryParam struct {
+	Name        string
+	Description string
+	Required    bool
+	Type        interface{} // 値の型（例: "" や 0）
+}
+
+// 一覧のページ指定のクエリパラメーター
+var apiPaginationParams = []apiQueryParam{
+	{Name: "page", Description: "ページ番号（1から）", Type: 0},
+	{Name: "per_page", Description: "1ページあたりの件数（1〜100、既定は20）", Type: 0},
+}
+
+// 操作の分類の一覧
+func apiTags() []openAPITag {
+	return []openAPITag{
+		{Name: apiTagUsers, Description: "ユーザー情報・睡眠設定"},
+		{Name: apiTagDiaries, Description: "睡眠日誌"},
+		{Name: apiTagRecords, Description: "睡眠記録"},
+		{Name: apiTagMasters, Description: "睡眠状態・食事種別のマスターデータ"},
+		{Name: apiTagDashboard, Description: "ダッシュボード（ログインセッションのみ）"},
+		{Name: apiTagStatistics, Description: "統計情報（ログインセッションのみ）"},
+		{Name: apiTagMeta, Description: "APIの仕様書"},
+	}
+}
+
+// APIの操作の一覧
+func apiOperations() []apiOperation {
+	// 参照・作成・更新・削除で共通のエラー
+	readErrors := []int{http.StatusUnauthorized, http.StatusInternalServerError}
+	listErrors := []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError}
+	itemErrors := []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}
+	writeErrors := []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity, http.StatusInternalServerError}
+	itemWriteErrors := []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError}
+	deleteErrors := []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError}
+
+	return []apiOperation{
+		// JSON API（/api/v1）
+		{
+			ID: "getMe", Method: http.MethodGet, Path: apiV1Prefix + "/me", Tag: apiTagUsers,
+			Summary: "ログイン中のユーザーと、認証に使ったアクセストークンの情報を取得",
+			Auth:    apiAuthToken, Response: MeResponse{}, Envelope: apiEnvelopeData, Errors: readErrors,
+		},
+		{
+			ID: "listDiaries", Method: http.MethodGet, Path: apiV1Prefix + "/diaries", Tag: apiTagDiaries,
+			Summary: "睡眠日誌の一覧を取得（開始日の新しい順）",
+			Auth:    apiAuthToken, Query: apiPaginationParams,
+			Response: DiaryResponse{}, Envelope: apiEnvelopeList, Errors: listErrors,
+		},
+		{
+			ID: "createDiary", Method: http.MethodPost, Path: apiV1Prefix + "/diaries", Tag: apiTagDiaries,
+			Summary: "睡眠日誌を作成（name・start_date・end_date は必須）",
+			Auth:    apiAuthToken, Request: DiaryRequest{},
+			Response: DiaryResponse{}, Envelope: apiEnvelopeData, Status: http.StatusCreated, Errors: writeErrors,
+		},
+		{
+			ID: "getDiary", Method: http.MethodGet, Path: apiV1Prefix + "/diaries/{id}", Tag: apiTagDiaries,
+			Summary: "睡眠日誌を取得",
+			Auth:    apiAuthToken, Response: DiaryResponse{}, Envelope: apiEnvelopeData, Errors: itemErrors,
+		},
+		{
+			ID: "updateDiary", Method: http.MethodPut, Path: apiV1Prefix + "/diaries/{id}", Tag: apiTagDiaries,
+			Summary: "睡眠日誌を更新（指定した項目のみ）",
+			Auth:    apiAuthToken, Request: DiaryRequest{},
+			Response: DiaryResponse{}, Envelope: apiEnvelopeData, Errors: itemWriteErrors,
+		},
+		{
+			ID: "deleteDiary", Method: http.MethodDelete, Path: apiV1Prefix + "/diaries/{id}", Tag: apiTagDiaries,
+			Summary: "睡眠日誌を削除（睡眠記録もあわせて削除）",
+			Auth:    apiAuthToken, Status: http.StatusNoContent, Errors: deleteErrors,
+		},
+		{
+			ID: "listRecords", Method: http.MethodGet, Path: apiV1Prefix + "/diaries/{id}/records", Tag: apiTagRecords,
+			Summary: "睡眠日誌の睡眠記録の一覧を取得（日付・時間枠の順）",
+			Auth:    apiAuthToken,
+			Query: append([]apiQueryParam{
+				{Name: "from", Description: "期間の開始日（YYYY-MM-DD）", Type: ""},
+				{Name: "to", Description: "期間の終了日（YYYY-MM-DD）", Type: ""},
+			}, apiPaginationParams...),
+			Response: RecordResponse{}, Envelope: apiEnvelopeList,
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		},
+		{
+			ID: "createRecord", Method: http.MethodPost, Path: apiV1Prefix + "/diaries/{id}/records", Tag: apiTagRecords,
+			Summary: "睡眠記録を作成（date・time_slot・sleep_state は必須）",
+			Auth:    apiAuthToken, Request: RecordRequest{},
+			Response: RecordResponse{}, Envelope: apiEnvelopeData, Status: http.StatusCreated, Errors: itemWriteErrors,
+		},
+		{
+			ID: "getRecord", Method: http.MethodGet, Path: apiV1Prefix + "/records/{id}", Tag: apiTagRecords,
+			Summary: "睡眠記録を取得",
+			Auth:    apiAuthToken, Response: RecordResponse{}, Envelope: apiEnvelopeData, Errors: itemErrors,
+		},
+		{
+			ID: "updateRecord", Method: http.MethodPut, Path: apiV1Prefix + "/records/{id}", Tag: apiTagRecords,
+			Summary: "睡眠記録を更新（指定した項目のみ）",
+			Auth:    apiAuthToken, Request: RecordRequest{},
+			Response: RecordResponse{}, Envelope: apiEnvelopeData, Errors: itemWriteErrors,
+		},
+		{
+			ID: "deleteRecord", Method: http.MethodDelete, Path: apiV1Prefix + "/records/{id}", Tag: apiTagRecords,
+			Summary: "睡眠記録を削除",
+			Auth:    apiAuthToken, Status: http.StatusNoContent, Errors: deleteErrors,
+		},
+		{
+			ID: "replaceNight", Method: http.MethodPut, Path: apiV1Prefix + "/nights/{date}", Tag: apiTagRecords,
+			Summary: "一晩分（睡眠日の正午から翌日の正午まで）の睡眠記録を、就床・消灯・中途覚醒・最終覚醒などの時刻から作成し直す（bed_time・lights_out・final_wake は必須）",
+			Auth:    apiAuthToken, Request: NightEntryRequest{},
+			Response: NightEntryResponse{}, Envelope: apiEnvelopeData, Errors: writeErrors,
+		},
+		{
+			ID: "getPreferences", Method: http.MethodGet, Path: apiV1Prefix + "/preferences", Tag: apiTagUsers,
+			Summary: "睡眠設定を取得",
+			Auth:    apiAuthToken, Response: PreferencesResponse{}, Envelope: apiEnvelopeData, Errors: readErrors,
+		},
+		{
+			ID: "updatePreferences", Method: http.MethodPut, Path: apiV1Prefix + "/preferences", Tag: apiTagUsers,
+			Summary: "睡眠設定を更新（指定した項目のみ）",
+			Auth:    apiAuthToken, Request: PreferencesRequest{},
+			Response: PreferencesResponse{}, Envelope: apiEnvelopeData, Errors: writeErrors,
+		},
+		{
+			ID: "listSleepStates", Method: http.MethodGet, Path: apiV1Prefix + "/sleep-states", Tag: apiTagMasters,
+			Summary: "睡眠状態の一覧を取得",
+			Auth:    apiAuthToken, Response: []*MasterDataResponse{}, Envelope: apiEnvelopeData, Errors: readErrors,
+		},
+		{
+			ID: "listMealTypes", Method: http.MethodGet, Path: apiV1Prefix + "/meal-types", Tag: apiTagMasters,
+			Summary: "食事種別の一覧を取得",
+			Auth:    apiAuthToken, Response: []*MasterDataResponse{}, Envelope: apiEnvelopeData, Errors: readErrors,
+		},
+
+		// 画面から利用するAPI（DashboardHandler）
+		{
+			ID: "getDashboardSummary", Method: http.MethodGet, Path: "/api/dashboard/summary", Tag: apiTagDashboard,
+			Summary: "直近1週間の睡眠日誌のダッシュボードの集計を取得",
+			Auth:    apiAuthSession, Response: service.DashboardStats{},
+			Errors: []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+		},
+
+		// 画面から利用するAPI（SleepRecordHandler）
+		{
+			ID: "listRecentSleepRecords", Method: http.MethodGet, Path: "/api/sleep-records", Tag: apiTagRecords,
+			Summary: "直近30日間の睡眠記録を取得",
+			Auth:    apiAuthSession, Response: []*models.SleepRecord{}, Errors: readErrors,
+		},
+		{
+			ID: "filterSleepRecords", Method: http.MethodPost, Path: "/api/sleep-records/filter", Tag: apiTagRecords,
+			Summary: "条件で絞り込んだ睡眠記録を取得（X-CSRF-Token ヘッダーが必要）",
+			Auth:    apiAuthSession, Request: service.SleepRecordFilter{}, Response: []*models.SleepRecord{},
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError},
+		},
+
+		// 画面から利用するAPI（StatisticsHandler）
+		{
+			ID: "getStatistics", Method: http.MethodGet, Path: "/api/statistics/data", Tag: apiTagStatistics,
+			Summary: "期間の睡眠統計を取得",
+			Auth:    apiAuthSession,
+			Query: []apiQueryParam{
+				{Name: "start", Description: "期間の開始日（YYYY-MM-DD）", Required: true, Type: ""},
+				{Name: "end", Description: "期間の終了日（YYYY-MM-DD）", Required: true, Type: ""},
+			},
+			Response: service.SleepStatistics{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+		},
+		{
+			ID: "getWeeklyStatistics", Method: http.MethodGet, Path: "/api/statistics/weekly", Tag: apiTagStatistics,
+			Summary: "直近1週間の週ごとの睡眠統計を取得",
+			Auth:    apiAuthSession, Response: service.PeriodStatistics{}, Errors: readErrors,
+		},
+		{
+			ID: "getMonthlyStatistics", Method: http.MethodGet, Path: "/api/statistics/monthly", Tag: apiTagStatistics,
+			Summary: "直近1か月の月ごとの睡眠統計を取得",
+			Auth:    apiAuthSession, Response: service.PeriodStatistics{}, Errors: readErrors,
+		},
+
+		// 仕様書
+		{
+			ID: "getOpenAPIDocument", Method: http.MethodGet, Path: openAPIPath, Tag: apiTagMeta,
+			Summary: "このAPIの仕様書（OpenAPI 3）を取得",
+			Auth:    apiAuthNone, Response: map[string]interface{}{}, Envelope: apiEnvelopeNone,
+		},
+	}
+}
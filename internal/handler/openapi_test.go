@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// main と同じ構成ですべてのルートを登録したルーターを作成
+func newOpenAPITestRouter(t *testing.T) (*chi.Mux, *OpenAPIHandler) {
+	t.Helper()
+	app := newTestApp(t)
+
+	r := chi.NewRouter()
+	RegisterAppRoutes(r, app.templates, app.service, app.errors, app.logger)
+	return r, NewOpenAPIHandler()
+}
+
+// 登録したAPIのルートと仕様書の内容が一致していることを確認
+func TestOpenAPICheckRoutes(t *testing.T) {
+	r, openAPIHandler := newOpenAPITestRouter(t)
+	if err := openAPIHandler.CheckRoutes(r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 仕様書にないルートと、登録されていないルートを検出できることを確認
+func TestOpenAPICheckRoutesMismatch(t *testing.T) {
+	r, openAPIHandler := newOpenAPITestRouter(t)
+	r.Get("/api/v1/undocumented", func(http.ResponseWriter, *http.Request) {})
+
+	err := openAPIHandler.CheckRoutes(r)
+	if err == nil || !strings.Contains(err.Error(), "GET /api/v1/undocumented") {
+		t.Fatalf("CheckRoutes: error = %v, want undocumented route", err)
+	}
+
+	empty := chi.NewRouter()
+	openAPIHandler.RegisterRoutes(empty)
+	err = openAPIHandler.CheckRoutes(empty)
+	if err == nil || !strings.Contains(err.Error(), "GET /api/v1/diaries") {
+		t.Fatalf("CheckRoutes: error = %v, want missing route", err)
+	}
+}
+
+// 仕様書をJSONとして取得できることを確認
+func TestOpenAPIDocument(t *testing.T) {
+	r, _ := newOpenAPITestRouter(t)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var document openAPIDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &document); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if document.OpenAPI != openAPIVersion || len(document.Paths) == 0 {
+		t.Fatalf("document = %s %d paths, want %s with paths", document.OpenAPI, len(document.Paths), openAPIVersion)
+	}
+}
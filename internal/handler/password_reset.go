@@ -5,6 +5,7 @@ package handler
 // password_resetは、パスワードリセット関連のハンドラーを提供します。
 
 import (
+	"errors"
 	"net/http"
 	"regexp"
 
@@ -48,7 +49,7 @@ func (h *PasswordResetHandler) ShowForgotPasswordForm(w http.ResponseWriter, r *
 		}
 	}
 
-	err := h.templates.Render(w, "forgot-password.html", data)
+	err := h.templates.Render(w, r, "forgot-password.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -71,7 +72,7 @@ func (h *PasswordResetHandler) HandleForgotPassword(w http.ResponseWriter, r *ht
 				Message: "メールアドレスを入力してください",
 			},
 		}
-		h.templates.Render(w, "forgot-password.html", data)
+		h.templates.Render(w, r, "forgot-password.html", data)
 		return
 	}
 
@@ -85,7 +86,7 @@ func (h *PasswordResetHandler) HandleForgotPassword(w http.ResponseWriter, r *ht
 				Message: "有効なメールアドレスを入力してください",
 			},
 		}
-		h.templates.Render(w, "forgot-password.html", data)
+		h.templates.Render(w, r, "forgot-password.html", data)
 		return
 	}
 
@@ -100,7 +101,7 @@ func (h *PasswordResetHandler) HandleForgotPassword(w http.ResponseWriter, r *ht
 				Message: "パスワードリセットの処理中にエラーが発生しました",
 			},
 		}
-		h.templates.Render(w, "forgot-password.html", data)
+		h.templates.Render(w, r, "forgot-password.html", data)
 		return
 	}
 
@@ -113,7 +114,7 @@ func (h *PasswordResetHandler) HandleForgotPassword(w http.ResponseWriter, r *ht
 		},
 	}
 
-	h.templates.Render(w, "forgot-password.html", data)
+	h.templates.Render(w, r, "forgot-password.html", data)
 }
 
 // パスワード再設定フォームの表示
@@ -134,7 +135,7 @@ func (h *PasswordResetHandler) ShowResetPasswordForm(w http.ResponseWriter, r *h
 				Message: "パスワードリセットリンクが無効か期限切れです。再度パスワードリセットを実行してください。",
 			},
 		}
-		h.templates.Render(w, "forgot-password.html", data)
+		h.templates.Render(w, r, "forgot-password.html", data)
 		return
 	}
 
@@ -145,7 +146,7 @@ func (h *PasswordResetHandler) ShowResetPasswordForm(w http.ResponseWriter, r *h
 		},
 	}
 
-	err = h.templates.Render(w, "reset-password.html", data)
+	err = h.templates.Render(w, r, "reset-password.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -180,13 +181,25 @@ func (h *PasswordResetHandler) HandleResetPassword(w http.ResponseWriter, r *htt
 				"Token": token,
 			},
 		}
-		h.templates.Render(w, "reset-password.html", data)
+		h.templates.Render(w, r, "reset-password.html", data)
 		return
 	}
 
 	// パスワードの更新
 	err := h.service.User().CompletePasswordReset(r.Context(), token, password)
+	if errors.Is(err, service.ErrInvalidResetToken) {
+		data := &TemplateData{
+			Title: "無効なリンク",
+			Flash: &Flash{
+				Type:    "danger",
+				Message: "パスワードリセットリンクが無効か期限切れです。再度パスワードリセットを実行してください。",
+			},
+		}
+		h.templates.Render(w, r, "forgot-password.html", data)
+		return
+	}
 	if err != nil {
+		h.service.Logger().Error("パスワードの再設定に失敗: error=%v", err)
 		data := &TemplateData{
 			Title: "新しいパスワードの設定",
 			Flash: &Flash{
@@ -197,7 +210,7 @@ func (h *PasswordResetHandler) HandleResetPassword(w http.ResponseWriter, r *htt
 				"Token": token,
 			},
 		}
-		h.templates.Render(w, "reset-password.html", data)
+		h.templates.Render(w, r, "reset-password.html", data)
 		return
 	}
 
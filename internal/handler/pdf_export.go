@@ -0,0 +1,172 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/pdf_export.go
+// pdf_exportは、睡眠日誌・統計情報のPDFダウンロードのハンドラーを提供します。
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// PDFダウンロードのハンドラー
+type PDFExportHandler struct {
+	templates *TemplateManager
+	service   *service.Service
+	errors    *ErrorHandler
+}
+
+// PDFExportHandlerを作成
+func NewPDFExportHandler(templates *TemplateManager, svc *service.Service, errorHandler *ErrorHandler) *PDFExportHandler {
+	return &PDFExportHandler{
+		templates: templates,
+		service:   svc,
+		errors:    errorHandler,
+	}
+}
+
+// ルーティングを登録
+func (h *PDFExportHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/diaries/{id}/pdf", h.DiaryPDF)
+	r.Get("/statistics/pdf", h.StatisticsPDF)
+}
+
+// 睡眠日誌のPDFをダウンロード
+func (h *PDFExportHandler) DiaryPDF(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromContext(r.Context())
+
+	diaryID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errors.Handle404(w, r)
+		return
+	}
+
+	loc := exportLocation(r)
+	opts, err := parsePDFExportOptions(r, loc)
+	if err != nil {
+		h.errors.ServeHTTP(w, r, http.StatusBadRequest, nil)
+		return
+	}
+
+	pdf, err := h.service.PDF().GenerateSleepDiaryPDF(r.Context(), userID, diaryID, opts)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("sleep-diary-%d-%s.pdf", diaryID, time.Now().In(loc).Format("2006-01-02"))
+	writePDF(w, filename, pdf)
+}
+
+// 統計情報のPDFをダウンロード
+func (h *PDFExportHandler) StatisticsPDF(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromContext(r.Context())
+
+	loc := exportLocation(r)
+	opts, err := parsePDFExportOptions(r, loc)
+	if err != nil {
+		h.errors.ServeHTTP(w, r, http.StatusBadRequest, nil)
+		return
+	}
+
+	// 期間の指定がない場合は、ユーザーのタイムゾーンでの今日までの30日間
+	if opts.EndDate.IsZero() {
+		now := time.Now().In(loc)
+		opts.EndDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	}
+	if opts.StartDate.IsZero() {
+		opts.StartDate = opts.EndDate.AddDate(0, 0, -29)
+	}
+
+	pdf, err := h.service.PDF().GenerateStatisticsPDF(r.Context(), userID, opts)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("sleep-statistics-%s-%s.pdf",
+		opts.StartDate.Format("20060102"), opts.EndDate.Format("20060102"))
+	writePDF(w, filename, pdf)
+}
+
+// サービスのエラーをエラーページに変換
+func (h *PDFExportHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrDiaryNotFound):
+		h.errors.Handle404(w, r)
+	case errors.Is(err, service.ErrDiaryAccessDenied):
+		h.errors.Handle403(w, r)
+	case errors.Is(err, service.ErrInvalidTimeRange):
+		h.errors.ServeHTTP(w, r, http.StatusBadRequest, nil)
+	default:
+		h.errors.Handle500(w, r, fmt.Errorf("PDFの生成に失敗しました: %w", err))
+	}
+}
+
+// ログイン中のユーザーのタイムゾーン（ユーザーがない場合はサーバーのタイムゾーン）
+func exportLocation(r *http.Request) *time.Location {
+	if user := GetUserFromContext(r.Context()); user != nil {
+		return user.Location()
+	}
+	return time.Local
+}
+
+// クエリパラメータからPDF出力設定を取得
+// start, end: 期間（YYYY-MM-DD または YYYY/MM/DD、loc のタイムゾーンの日付）
+// include_note: メモを含めるか（1, true, on）
+// lang: 出力言語（ja, en）
+// quality: 出力品質（high, medium, low）
+func parsePDFExportOptions(r *http.Request, loc *time.Location) (*models.PDFExportOptions, error) {
+	query := r.URL.Query()
+	opts := &models.PDFExportOptions{
+		Language: query.Get("lang"),
+		Quality:  query.Get("quality"),
+	}
+
+	var err error
+	if v := query.Get("start"); v != "" {
+		if opts.StartDate, err = parseExportDate(v, loc); err != nil {
+			return nil, err
+		}
+	}
+	if v := query.Get("end"); v != "" {
+		if opts.EndDate, err = parseExportDate(v, loc); err != nil {
+			return nil, err
+		}
+	}
+	if !opts.StartDate.IsZero() && !opts.EndDate.IsZero() && opts.StartDate.After(opts.EndDate) {
+		return nil, service.ErrInvalidTimeRange
+	}
+
+	switch query.Get("include_note") {
+	case "1", "true", "on":
+		opts.IncludeNote = true
+	}
+
+	return opts, nil
+}
+
+// 日付文字列を解析（エクスポート画面の日付範囲ピッカーの形式にも対応）
+func parseExportDate(v string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006/01/02"} {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", v)
+}
+
+// PDFをダウンロードとしてレスポンスに書き込み
+func writePDF(w http.ResponseWriter, filename string, pdf []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.Write(pdf)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestPDFExportHandler(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "pdf@example.com")
+	other := app.createUser(t, "other@example.com")
+	diary := app.createDiary(t, user.ID, "2024-04-01", "2024-04-07")
+	// 日付はユーザーのタイムゾーンで決める
+	now := time.Now().In(user.Location())
+	today := now.Format("2006-01-02")
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantFile string
+	}{
+		{
+			name:     "睡眠日誌",
+			path:     fmt.Sprintf("/diaries/%d/pdf", diary.ID),
+			wantCode: http.StatusOK,
+			wantFile: fmt.Sprintf("sleep-diary-%d-%s.pdf", diary.ID, today),
+		},
+		{
+			name:     "睡眠日誌（期間・メモ・英語）",
+			path:     fmt.Sprintf("/diaries/%d/pdf?start=2024/04/02&end=2024-04-03&include_note=1&lang=en", diary.ID),
+			wantCode: http.StatusOK,
+			wantFile: fmt.Sprintf("sleep-diary-%d-%s.pdf", diary.ID, today),
+		},
+		{
+			name:     "存在しない睡眠日誌",
+			path:     fmt.Sprintf("/diaries/%d/pdf", diary.ID+100),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "睡眠日誌のIDが数値でない",
+			path:     "/diaries/abc/pdf",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "日付が正しくない",
+			path:     fmt.Sprintf("/diaries/%d/pdf?start=2024-13-01", diary.ID),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "統計情報",
+			path:     "/statistics/pdf?start=2024-04-01&end=2024-04-07",
+			wantCode: http.StatusOK,
+			wantFile: "sleep-statistics-20240401-20240407.pdf",
+		},
+		{
+			name:     "統計情報（期間の指定なし）",
+			path:     "/statistics/pdf",
+			wantCode: http.StatusOK,
+			wantFile: fmt.Sprintf("sleep-statistics-%s-%s.pdf", end.AddDate(0, 0, -29).Format("20060102"), end.Format("20060102")),
+		},
+		{
+			name:     "統計情報（終了日のみ）",
+			path:     "/statistics/pdf?end=2024-03-01",
+			wantCode: http.StatusOK,
+			wantFile: "sleep-statistics-20240201-20240301.pdf",
+		},
+		{
+			name:     "統計情報の期間が逆",
+			path:     "/statistics/pdf?start=2024-04-07&end=2024-04-01",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	router := chi.NewRouter()
+	router.Use(withUser(user))
+	NewPDFExportHandler(app.templates, app.service, app.errors).RegisterRoutes(router)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/pdf" {
+				t.Errorf("Content-Type = %q, want application/pdf", got)
+			}
+			wantDisposition := fmt.Sprintf("attachment; filename=%q", tt.wantFile)
+			if got := rec.Header().Get("Content-Disposition"); got != wantDisposition {
+				t.Errorf("Content-Disposition = %q, want %q", got, wantDisposition)
+			}
+			if got := rec.Header().Get("Content-Length"); got != fmt.Sprint(rec.Body.Len()) {
+				t.Errorf("Content-Length = %q, want %d", got, rec.Body.Len())
+			}
+			if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+				t.Errorf("body is not a PDF")
+			}
+		})
+	}
+
+	// 他のユーザーの睡眠日誌は出力できない
+	t.Run("他のユーザーの睡眠日誌", func(t *testing.T) {
+		router := chi.NewRouter()
+		router.Use(withUser(other))
+		NewPDFExportHandler(app.templates, app.service, app.errors).RegisterRoutes(router)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/diaries/%d/pdf", diary.ID), nil))
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+		}
+		if bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+			t.Fatalf("must not return the PDF of another user's diary")
+		}
+	})
+}
+
+// 日付はタイムゾーンの0時として読み込むことを確認
+func TestParseExportDate(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, v := range []string{"2024-04-01", "2024/04/01"} {
+		got, err := parseExportDate(v, tokyo)
+		if err != nil {
+			t.Fatalf("parseExportDate(%q): %v", v, err)
+		}
+		if want := time.Date(2024, 4, 1, 0, 0, 0, 0, tokyo); !got.Equal(want) || got.Location() != tokyo {
+			t.Errorf("parseExportDate(%q) = %v, want %v", v, got, want)
+		}
+	}
+	if _, err := parseExportDate("2024-04-31", tokyo); err == nil {
+		t.Error("parseExportDate(2024-04-31): want an error")
+	}
+}
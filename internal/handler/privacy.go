@@ -5,11 +5,9 @@ package handler
 // privacyは、プライバシーポリシーページのハンドラーを提供します。
 
 import (
-	"database/sql"
 	"net/http"
 	"time"
 
-	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
 	"github.com/go-chi/chi/v5"
 )
@@ -34,29 +32,9 @@ func (h *PrivacyHandler) RegisterRoutes(r chi.Router) {
 }
 
 // プライバシーポリシーページを表示
-func (h *PrivacyHandler) Privacy(w http.ResponseWriter, _ *http.Request) {
-	// ユーザー情報の取得（オプション）
-
-	// ダミーユーザー情報
-	user := &models.User{
-		ID:                1,
-		Email:             "test@example.com",
-		DisplayName:       "テストユーザー",
-		LastLoginDatetime: sql.NullTime{ Time: time.Now(), Valid: true },
-		Created:           time.Now(),
-		Modified:          time.Now(),
-		Deleted:           sql.NullTime{},
-	}
-
-	// TODO: UserKeyを使ってユーザー情報を取得
-	// if userCtx := r.Context().Value(UserKey); userCtx != nil {
-	// 	userID = GetUserIDFromContext(userCtx)
-	// 	user, err := h.service.User().GetUserByID(r.Context(), userID)
-	// 	if err != nil {
-	// 		http.Error(w, err.Error(), http.StatusInternalServerError)
-	// 		return
-	// 	}
-	// }
+func (h *PrivacyHandler) Privacy(w http.ResponseWriter, r *http.Request) {
+	// ログイン中のユーザー情報（未ログインの場合はnil）
+	user := GetUserFromContext(r.Context())
 
 	// プライバシーポリシーのメタデータ
 	metadata := map[string]interface{}{
@@ -77,7 +55,7 @@ func (h *PrivacyHandler) Privacy(w http.ResponseWriter, _ *http.Request) {
 		},
 	}
 
-	err := h.templates.Render(w, "privacy.html", data)
+	err := h.templates.Render(w, r, "privacy.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
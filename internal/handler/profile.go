@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
@@ -48,15 +49,7 @@ func (h *ProfileHandler) RegisterRoutes(r *RouterWrapper) {
 
 // プロフィール画面を表示
 func (h *ProfileHandler) Profile(w http.ResponseWriter, r *http.Request) {
-	// ユーザー情報の取得
-	userCtx := r.Context().Value(UserKey)
-	if userCtx == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
-		return
-	}
-	
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// ユーザー情報の取得
 	user, err := h.service.User().GetUserByID(r.Context(), userID)
@@ -91,7 +84,7 @@ func (h *ProfileHandler) Profile(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err = h.templates.Render(w, "profile.html", data)
+	err = h.templates.Render(w, r, "profile.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -105,8 +98,7 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// リクエストデータの取得
 	email := r.FormValue("email")
@@ -123,7 +115,7 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 				Message: err.Error(),
 			},
 		}
-		h.templates.Render(w, "profile.html", data)
+		h.templates.Render(w, r, "profile.html", data)
 		return
 	}
 
@@ -132,6 +124,7 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		ID:          userID,
 		Email:       email,
 		DisplayName: displayName,
+		TimeZone:    timezone,
 	}
 
 	err := h.service.User().UpdateProfile(r.Context(), user)
@@ -144,7 +137,7 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 				Message: "プロフィールの更新に失敗しました",
 			},
 		}
-		h.templates.Render(w, "profile.html", data)
+		h.templates.Render(w, r, "profile.html", data)
 		return
 	}
 
@@ -158,7 +151,7 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 			Message: "プロフィールを更新しました",
 		},
 	}
-	h.templates.Render(w, "profile.html", data)
+	h.templates.Render(w, r, "profile.html", data)
 }
 
 // パスワードの更新
@@ -168,8 +161,7 @@ func (h *ProfileHandler) UpdatePassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	currentPassword := r.FormValue("current_password")
 	newPassword := r.FormValue("new_password")
@@ -184,7 +176,7 @@ func (h *ProfileHandler) UpdatePassword(w http.ResponseWriter, r *http.Request)
 				Message: "新しいパスワードが一致しません",
 			},
 		}
-		h.templates.Render(w, "profile.html", data)
+		h.templates.Render(w, r, "profile.html", data)
 		return
 	}
 
@@ -199,7 +191,7 @@ func (h *ProfileHandler) UpdatePassword(w http.ResponseWriter, r *http.Request)
 				Message: "パスワードの更新に失敗しました: " + err.Error(),
 			},
 		}
-		h.templates.Render(w, "profile.html", data)
+		h.templates.Render(w, r, "profile.html", data)
 		return
 	}
 
@@ -212,7 +204,7 @@ func (h *ProfileHandler) UpdatePassword(w http.ResponseWriter, r *http.Request)
 			Message: "パスワードを更新しました",
 		},
 	}
-	h.templates.Render(w, "profile.html", data)
+	h.templates.Render(w, r, "profile.html", data)
 }
 
 // 睡眠設定の更新
@@ -222,8 +214,7 @@ func (h *ProfileHandler) UpdatePreferences(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// 設定データの取得と更新
 	pref := &models.UserSleepPreference{
@@ -244,7 +235,7 @@ func (h *ProfileHandler) UpdatePreferences(w http.ResponseWriter, r *http.Reques
 				Message: "睡眠設定の更新に失敗しました",
 			},
 		}
-		h.templates.Render(w, "profile.html", data)
+		h.templates.Render(w, r, "profile.html", data)
 		return
 	}
 
@@ -257,7 +248,7 @@ func (h *ProfileHandler) UpdatePreferences(w http.ResponseWriter, r *http.Reques
 			Message: "睡眠設定を更新しました",
 		},
 	}
-	h.templates.Render(w, "profile.html", data)
+	h.templates.Render(w, r, "profile.html", data)
 }
 
 // プロフィールデータのバリデーション
@@ -279,7 +270,10 @@ func (h *ProfileHandler) validateProfileData(displayName, email, timezone string
 		return service.ErrEmptyTimezone
 	}
 
-	// TODO: タイムゾーンの有効性チェック
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return service.ErrInvalidTimezone
+	}
+
 	return nil
 }
 
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"regexp"
 
+	"github.com/223n-tech/SuiminNisshi-Go/internal/middleware"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
 	"github.com/go-chi/chi/v5"
 )
@@ -46,7 +47,7 @@ func (h *RegisterHandler) RegisterRoutes(r chi.Router) {
 // 新規登録画面を表示
 func (h *RegisterHandler) RegisterPage(w http.ResponseWriter, r *http.Request) {
 	// すでにログインしている場合はダッシュボードにリダイレクト
-	if user := r.Context().Value(UserKey); user != nil {
+	if user := GetUserFromContext(r.Context()); user != nil {
 		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 		return
 	}
@@ -58,7 +59,7 @@ func (h *RegisterHandler) RegisterPage(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err := h.templates.Render(w, "register.html", data)
+	err := h.templates.Render(w, r, "register.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -93,7 +94,7 @@ func (h *RegisterHandler) Register(w http.ResponseWriter, r *http.Request) {
 				Message: err.Error(),
 			},
 		}
-		h.templates.Render(w, "register.html", data)
+		h.templates.Render(w, r, "register.html", data)
 		return
 	}
 
@@ -112,7 +113,7 @@ func (h *RegisterHandler) Register(w http.ResponseWriter, r *http.Request) {
 					Message: "このメールアドレスは既に登録されています",
 				},
 			}
-			h.templates.Render(w, "register.html", data)
+			h.templates.Render(w, r, "register.html", data)
 			return
 		}
 
@@ -128,7 +129,7 @@ func (h *RegisterHandler) Register(w http.ResponseWriter, r *http.Request) {
 				Message: "ユーザー登録に失敗しました",
 			},
 		}
-		h.templates.Render(w, "register.html", data)
+		h.templates.Render(w, r, "register.html", data)
 		return
 	}
 
@@ -138,6 +139,11 @@ func (h *RegisterHandler) Register(w http.ResponseWriter, r *http.Request) {
 		h.service.Logger().Printf("[ERROR] 確認メール送信エラー: error=%v", err)
 	}
 
+	// 登録前のCSRFトークンを引き継がないよう切り替え
+	if err := middleware.RotateCSRFToken(w, r); err != nil {
+		h.service.Logger().Printf("[ERROR] CSRFトークンの切り替えエラー: error=%v", err)
+	}
+
 	// 登録成功時の処理
 	http.Redirect(w, r, "/register/complete", http.StatusSeeOther)
 }
@@ -192,7 +198,7 @@ func (h *RegisterHandler) RegistrationComplete(w http.ResponseWriter, r *http.Re
 		},
 	}
 
-	err := h.templates.Render(w, "register-complete.html", data)
+	err := h.templates.Render(w, r, "register-complete.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,111 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/routes.go
+// routesは、アプリケーションのすべてのハンドラーを初期化してルーターに登録する処理を実装しています。
+// main とテスト（openapi_test.go）が同じルートの構成を使うため、登録処理をこのファイルにまとめています。
+
+import (
+	"log"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/middleware"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterAppRoutes は、すべてのハンドラーを初期化してルーターに登録します。
+// 登録したAPIのルートと仕様書の内容が一致していることは openapi_test.go で確認します。
+func RegisterAppRoutes(r chi.Router, tm *TemplateManager, svc *service.Service, errorHandler *ErrorHandler, logger *log.Logger) {
+	// サービスをハンドラーに渡す
+	logger.Printf("[Initialize] Passing service to handlers...")
+	authHandler := NewAuthHandler(tm, svc)
+
+	// ルートの登録
+	// セッションからユーザー情報を読み込むルートと、ログインが必要なルートを分ける
+	// 画面のルートでは、フォームの送信をCSRFトークンで検証する
+	logger.Printf("[Initialize] Registering routes...")
+	web := r.With(authHandler.LoadSession, middleware.CSRF(errorHandler.Handle403))
+	router := NewRouter(web)
+	protected := web.With(RequireAuth)
+	protectedRouter := NewRouter(protected)
+
+	// 認証ハンドラーの登録
+	authHandler.RegisterRoutes(router)
+
+	// アカウント削除ハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering account deletion routes...")
+	accountDeletionHandler := NewAccountDeletionHandler(tm, svc)
+	accountDeletionHandler.RegisterRoutes(protected)
+
+	// ダッシュボードハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering dashboard routes...")
+	dashboardHandler := NewDashboardHandler(tm, svc)
+	dashboardHandler.RegisterRoutes(protectedRouter)
+
+	// 睡眠日誌ハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering diary routes...")
+	diaryHandler := NewDiaryHandler(tm, svc, errorHandler)
+	diaryHandler.RegisterRoutes(protected)
+
+	// PDFダウンロードハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering PDF export routes...")
+	pdfExportHandler := NewPDFExportHandler(tm, svc, errorHandler)
+	pdfExportHandler.RegisterRoutes(protected)
+
+	// パスワードリセットハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering password reset routes...")
+	passwordResetHandler := NewPasswordResetHandler(tm, svc)
+	passwordResetHandler.RegisterRoutes(web)
+
+	// プライバシーポリシーハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering privacy policy routes...")
+	privacyPolicyHandler := NewPrivacyHandler(tm, svc)
+	privacyPolicyHandler.RegisterRoutes(web)
+
+	// プロフィールハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering profile routes...")
+	profileHandler := NewProfileHandler(tm, svc)
+	profileHandler.RegisterRoutes(protectedRouter)
+
+	// ユーザー登録ハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering registration routes...")
+	registrationHandler := NewRegisterHandler(tm, svc)
+	registrationHandler.RegisterRoutes(web)
+
+	// 設定ハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering settings routes...")
+	settingsHandler := NewSettingsHandler(tm, svc)
+	settingsHandler.RegisterRoutes(protected)
+
+	// 睡眠記録ハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering sleep record routes...")
+	sleepRecordHandler := NewSleepRecordHandler(tm, svc)
+	sleepRecordHandler.RegisterRoutes(protected)
+
+	// 統計情報ハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering statistics routes...")
+	statisticsHandler := NewStatisticsHandler(tm, svc)
+	statisticsHandler.RegisterRoutes(protected)
+
+	// 利用規約ハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering terms routes...")
+	termsHandler := NewTermsHandler(tm, svc)
+	termsHandler.RegisterRoutes(web)
+
+	// 週間レポートハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering weekly report routes...")
+	weeklyReportHandler := NewWeeklyReportHandler(tm, svc)
+	weeklyReportHandler.RegisterRoutes(protected)
+
+	// JSON APIハンドラーの初期化と登録
+	// APIはアクセストークンでも認証するため、画面のCSRF検証は適用せずにハンドラー側で行う
+	logger.Printf("[Initialize] Registering API routes...")
+	apiHandler := NewAPIHandler(svc)
+	apiHandler.RegisterRoutes(r.With(authHandler.LoadSession))
+
+	// APIの仕様書ハンドラーの初期化と登録
+	logger.Printf("[Initialize] Registering OpenAPI document route...")
+	openAPIHandler := NewOpenAPIHandler()
+	openAPIHandler.RegisterRoutes(r)
+}
@@ -5,10 +5,11 @@ package handler
 // settingsは、設定画面のハンドラーを提供します。
 
 import (
-	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
@@ -36,23 +37,34 @@ func (h *SettingsHandler) RegisterRoutes(r chi.Router) {
 	r.Post("/settings/profile", h.UpdateProfile)
 	r.Post("/settings/password", h.UpdatePassword)
 	r.Post("/settings/notifications", h.UpdateNotifications)
+	r.Post("/settings/tokens", h.CreateAccessToken)
+	r.Post("/settings/tokens/{id}/revoke", h.RevokeAccessToken)
+	r.Get("/settings/export", h.ExportData)
 	r.Get("/settings/export/csv", h.ExportCSV)
 	r.Get("/settings/export/json", h.ExportJSON)
+	r.Get(settingsImportPath, h.ShowImport)
+	r.Post("/settings/import/csv", h.ImportCSV)
+	r.Post("/settings/import/json", h.RestoreJSON)
 	r.Get("/settings/account/delete", h.ShowDeleteAccountPage)
 	r.Post("/settings/account/delete", h.DeleteAccount)
 }
 
 // 設定画面を表示
 func (h *SettingsHandler) Settings(w http.ResponseWriter, r *http.Request) {
-	// ユーザー情報の取得
-	userCtx := r.Context().Value(UserKey)
-	if userCtx == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
-		return
+	var flash *Flash
+	if msg := r.URL.Query().Get("message"); msg != "" {
+		flash = &Flash{
+			Type:    r.URL.Query().Get("type"),
+			Message: msg,
+		}
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	h.renderSettings(w, r, flash, "")
+}
+
+// 設定画面を表示（newToken は発行したアクセストークンで、発行直後の1回だけ表示する）
+func (h *SettingsHandler) renderSettings(w http.ResponseWriter, r *http.Request, flash *Flash, newToken string) {
+	userID := GetUserIDFromContext(r.Context())
 
 	// ユーザー情報の取得
 	user, err := h.service.User().GetUserByID(r.Context(), userID)
@@ -68,24 +80,36 @@ func (h *SettingsHandler) Settings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 通知設定の取得
+	notifications, err := h.service.User().GetNotificationSettings(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "通知設定の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	// アクセストークンの取得
+	tokens, err := h.service.AccessToken().ListTokens(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "アクセストークンの取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
 	data := &TemplateData{
 		Title:      "設定",
 		ActiveMenu: "settings",
 		User:       user,
+		Flash:      flash,
 		Data: map[string]interface{}{
-			"Preferences": pref,
+			"Preferences":    pref,
+			"Notifications":  notifications,
+			"AccessTokens":   tokens,
+			"NewAccessToken": newToken,
+			"Now":            time.Now(),
+			"Location":       user.Location(),
 		},
 	}
 
-	// フラッシュメッセージの設定
-	if msg := r.URL.Query().Get("message"); msg != "" {
-		data.Flash = &Flash{
-			Type:    r.URL.Query().Get("type"),
-			Message: msg,
-		}
-	}
-
-	err = h.templates.Render(w, "settings.html", data)
+	err = h.templates.Render(w, r, "settings.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -99,8 +123,7 @@ func (h *SettingsHandler) UpdateProfile(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// プロフィール情報の更新
 	user := &models.User{
@@ -126,8 +149,7 @@ func (h *SettingsHandler) UpdatePassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	currentPassword := r.FormValue("current_password")
 	newPassword := r.FormValue("new_password")
@@ -142,21 +164,27 @@ func (h *SettingsHandler) UpdatePassword(w http.ResponseWriter, r *http.Request)
 }
 
 // 通知設定の更新
+// フォームに含まれる項目のみを更新する（チェックボックスは、チェックなしの場合も送信されるよう hidden の "off" とあわせて送信する）
 func (h *SettingsHandler) UpdateNotifications(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		http.Error(w, "フォームの解析に失敗しました", http.StatusBadRequest)
 		return
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
-	pref := &models.UserSleepPreference{
-		UserID:            userID,
-		IsReminderEnabled: r.FormValue("reminder_enabled") == "on",
+	update := service.NotificationSettingsUpdate{
+		EmailEnabled:    formBool(r, "email_enabled"),
+		BedtimeReminder: formBool(r, "reminder_enabled"),
+		ReminderTime:    formString(r, "reminder_time"),
+		WeeklyReport:    formBool(r, "weekly_report"),
 	}
 
-	err := h.service.User().UpdateSleepPreference(r.Context(), pref)
+	_, err := h.service.User().UpdateNotificationSettings(r.Context(), userID, update)
+	if errors.Is(err, service.ErrInvalidReminderTime) {
+		http.Redirect(w, r, "/settings?message=リマインダーの時刻はHH:MMの形式で指定してください&type=danger", http.StatusSeeOther)
+		return
+	}
 	if err != nil {
 		http.Redirect(w, r, "/settings?message=通知設定の更新に失敗しました&type=danger", http.StatusSeeOther)
 		return
@@ -165,70 +193,180 @@ func (h *SettingsHandler) UpdateNotifications(w http.ResponseWriter, r *http.Req
 	http.Redirect(w, r, "/settings?message=通知設定を更新しました&type=success", http.StatusSeeOther)
 }
 
+// フォームの真偽値を取得（項目がない場合はnil、同名の項目が複数ある場合は最後の値）
+func formBool(r *http.Request, key string) *bool {
+	values, ok := r.PostForm[key]
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	value := values[len(values)-1] == "on"
+	return &value
+}
+
+// フォームの文字列を取得（項目がない場合はnil）
+func formString(r *http.Request, key string) *string {
+	values, ok := r.PostForm[key]
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	return &values[0]
+}
+
+// アクセストークンの発行
+// 発行したトークンは保存しないため、リダイレクトせずに設定画面を表示して一度だけ表示する
+func (h *SettingsHandler) CreateAccessToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "フォームの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+
+	userID := GetUserIDFromContext(r.Context())
+
+	// 有効期間（日数、0は無期限）
+	days, err := strconv.Atoi(r.PostFormValue("expires_in_days"))
+	if err != nil {
+		days = -1
+	}
+
+	token, _, err := h.service.AccessToken().CreateToken(r.Context(), userID, r.PostFormValue("name"), r.PostForm["scopes"], time.Duration(days)*24*time.Hour)
+	switch {
+	case errors.Is(err, service.ErrInvalidTokenName):
+		http.Redirect(w, r, "/settings?message=トークンの名前は1〜100文字で入力してください&type=danger", http.StatusSeeOther)
+		return
+	case errors.Is(err, service.ErrInvalidTokenScope):
+		http.Redirect(w, r, "/settings?message=トークンの権限を選択してください&type=danger", http.StatusSeeOther)
+		return
+	case errors.Is(err, service.ErrInvalidTokenLifetime):
+		http.Redirect(w, r, "/settings?message=トークンの有効期間が正しくありません&type=danger", http.StatusSeeOther)
+		return
+	case err != nil:
+		http.Redirect(w, r, "/settings?message=アクセストークンの発行に失敗しました&type=danger", http.StatusSeeOther)
+		return
+	}
+
+	h.renderSettings(w, r, &Flash{
+		Type:    "success",
+		Message: "アクセストークンを発行しました。トークンはこの画面を離れると二度と表示できません",
+	}, token)
+}
+
+// アクセストークンの無効化
+func (h *SettingsHandler) RevokeAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromContext(r.Context())
+
+	tokenID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/settings?message=アクセストークンが見つかりません&type=danger", http.StatusSeeOther)
+		return
+	}
+
+	err = h.service.AccessToken().RevokeToken(r.Context(), userID, tokenID)
+	if errors.Is(err, service.ErrAccessTokenNotFound) {
+		http.Redirect(w, r, "/settings?message=アクセストークンが見つかりません&type=danger", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		http.Redirect(w, r, "/settings?message=アクセストークンの無効化に失敗しました&type=danger", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/settings?message=アクセストークンを無効化しました&type=success", http.StatusSeeOther)
+}
+
+// エクスポート画面を表示
+func (h *SettingsHandler) ExportData(w http.ResponseWriter, r *http.Request) {
+	data := &TemplateData{
+		Title:      "データのエクスポート",
+		ActiveMenu: "settings",
+		User:       GetUserFromContext(r.Context()),
+	}
+
+	err := h.templates.Render(w, r, "export-data.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // CSVエクスポート
+// layout（long・wide）で形式、encoding（utf8・utf8bom・sjis）で文字コード、start・end（YYYY-MM-DD または YYYY/MM/DD）で期間を指定する
 func (h *SettingsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
-    // TODO: 実際のユーザーIDを使用
-    var userID int64 = 1 // 開発用
-
-    // CSVファイル名の設定
-    filename := fmt.Sprintf("sleep-records-%s.csv", time.Now().Format("2006-01-02"))
-    w.Header().Set("Content-Type", "text/csv")
-    w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-
-    writer := csv.NewWriter(w)
-    defer writer.Flush()
-
-    // ヘッダーの書き込み
-    headers := []string{"日付", "時間枠", "状態", "種別", "メモ"}
-    if err := writer.Write(headers); err != nil {
-        http.Error(w, "CSVの書き込みに失敗しました", http.StatusInternalServerError)
-        return
-    }
-
-    // データの取得と書き込み
-    records, err := h.service.Record().GetAllRecords(r.Context(), userID)
-    if err != nil {
-        http.Error(w, "データの取得に失敗しました", http.StatusInternalServerError)
-        return
-    }
-
-    for _, record := range records {
-        // 実際のSleepRecord構造体のフィールドに基づいて行を作成
-        row := []string{
-            record.RecordDate.Format("2006-01-02"),
-            record.TimeSlot.Format("15:04"),
-            fmt.Sprintf("%d", record.SleepStateID), // 本来は状態名を取得すべき
-            record.RecordType,
-            record.Note.String,
-        }
-        if err := writer.Write(row); err != nil {
-            http.Error(w, "CSVの書き込みに失敗しました", http.StatusInternalServerError)
-            return
-        }
-    }
-}
+	userID := GetUserIDFromContext(r.Context())
+	query := r.URL.Query()
 
-// JSONエクスポート
-func (h *SettingsHandler) ExportJSON(w http.ResponseWriter, r *http.Request) {
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	opts := service.CSVExportOptions{
+		Layout:   query.Get("layout"),
+		Encoding: query.Get("encoding"),
+	}
+	var err error
+	if v := query.Get("start"); v != "" {
+		if opts.StartDate, err = parseExportDate(v, exportLocation(r)); err != nil {
+			http.Error(w, "開始日の形式が正しくありません", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("end"); v != "" {
+		if opts.EndDate, err = parseExportDate(v, exportLocation(r)); err != nil {
+			http.Error(w, "終了日の形式が正しくありません", http.StatusBadRequest)
+			return
+		}
+	}
 
-	// JSONファイル名の設定
-	filename := fmt.Sprintf("sleep-records-%s.json", time.Now().Format("2006-01-02"))
-	w.Header().Set("Content-Type", "application/json")
+	// データの取得（ヘッダーを設定する前に取得し、失敗した場合はエラーを返す）
+	rows, err := h.service.Export().CSVRows(r.Context(), userID, opts)
+	switch {
+	case errors.Is(err, service.ErrCSVExportLayout):
+		http.Error(w, "CSVの形式は long または wide を指定してください", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrCSVExportEncoding):
+		http.Error(w, "CSVの文字コードは utf8・utf8bom・sjis のいずれかを指定してください", http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrInvalidTimeRange):
+		http.Error(w, "終了日は開始日以降の日付を指定してください", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "データの取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	// CSVファイル名の設定
+	name := "sleep-records"
+	if opts.Layout == service.CSVLayoutWide {
+		name = "sleep-diary"
+	}
+	filename := fmt.Sprintf("%s-%s.csv", name, time.Now().Format("2006-01-02"))
+	charset := "utf-8"
+	if opts.Encoding == service.CSVEncodingShiftJIS {
+		charset = "Shift_JIS"
+	}
+	w.Header().Set("Content-Type", "text/csv; charset="+charset)
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 
+	if err := h.service.Export().WriteCSV(w, rows, opts.Encoding); err != nil {
+		h.service.Logger().Error("CSVの書き込みに失敗: error=%v", err)
+	}
+}
+
+// JSONエクスポート（復元に使えるバックアップを出力する）
+func (h *SettingsHandler) ExportJSON(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserIDFromContext(r.Context())
+
 	// データの取得
-	records, err := h.service.Record().GetAllRecords(r.Context(), userID)
+	backup, err := h.service.Backup().Export(r.Context(), userID)
 	if err != nil {
 		http.Error(w, "データの取得に失敗しました", http.StatusInternalServerError)
 		return
 	}
 
+	// JSONファイル名の設定
+	filename := fmt.Sprintf("suiminnisshi-backup-%s.json", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+
 	// JSONエンコード
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(records); err != nil {
+	if err := encoder.Encode(backup); err != nil {
 		http.Error(w, "JSONの書き込みに失敗しました", http.StatusInternalServerError)
 		return
 	}
@@ -241,7 +379,7 @@ func (h *SettingsHandler) ShowDeleteAccountPage(w http.ResponseWriter, r *http.R
 		ActiveMenu: "settings",
 	}
 
-	err := h.templates.Render(w, "delete-account.html", data)
+	err := h.templates.Render(w, r, "delete-account.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -255,8 +393,7 @@ func (h *SettingsHandler) DeleteAccount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// password := r.FormValue("password")
 	confirm := r.FormValue("confirm") == "on"
@@ -272,9 +409,8 @@ func (h *SettingsHandler) DeleteAccount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// セッションのクリア
-	// TODO: セッション管理の実装
-	// ClearSession(r)
+	// セッションのクリア（DB上のセッションはアカウント削除時に破棄済み）
+	clearSessionCookie(w)
 
 	http.Redirect(w, r, "/account-deleted", http.StatusSeeOther)
 }
@@ -0,0 +1,171 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/settings_import.go
+// settings_importは、設定画面からCSVファイルの睡眠記録を取り込む画面と、バックアップ（JSON）から復元するハンドラーを提供します。
+// アップロードしたCSVは、まず取り込む内容と行ごとの誤りを確認画面に表示し、確定したときに1つのトランザクションで取り込みます。
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+)
+
+// 取り込み画面のアドレス
+const settingsImportPath = "/settings/import"
+
+// CSV取り込み画面を表示
+func (h *SettingsHandler) ShowImport(w http.ResponseWriter, r *http.Request) {
+	h.renderImport(w, r, flashFromQuery(r), nil, "")
+}
+
+// CSVファイルを取り込む
+// confirm が指定されていない場合は確認画面を表示し、指定されている場合は確認画面から送信したCSVを取り込む
+func (h *SettingsHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(service.CSVImportMaxBytes); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		redirectWithFlash(w, r, settingsImportPath, "danger", "ファイルの読み込みに失敗しました")
+		return
+	}
+
+	data, err := readImportCSV(r)
+	if err != nil {
+		redirectWithFlash(w, r, settingsImportPath, "danger", "CSVファイルを選択してください")
+		return
+	}
+
+	userID := GetUserIDFromContext(r.Context())
+	if r.FormValue("confirm") == "" {
+		result, err := h.service.Import().PreviewCSV(r.Context(), userID, bytes.NewReader(data))
+		if err != nil {
+			h.importFailed(w, r, err)
+			return
+		}
+		// 確認画面から送信し直すCSVは、Shift_JISのファイルでもUTF-8にしておく
+		h.renderImport(w, r, nil, result, string(service.DecodeCSVImport(data)))
+		return
+	}
+
+	result, err := h.service.Import().ImportCSV(r.Context(), userID, bytes.NewReader(data))
+	if errors.Is(err, service.ErrCSVImportInvalid) {
+		// 確認後に睡眠日誌が削除された場合などは、改めて確認画面を表示する
+		h.renderImport(w, r, &Flash{Type: "danger", Message: "取り込めない行があるため、取り込みませんでした"}, result, string(service.DecodeCSVImport(data)))
+		return
+	}
+	if err != nil {
+		h.importFailed(w, r, err)
+		return
+	}
+
+	redirectWithFlash(w, r, "/settings", "success", fmt.Sprintf("%d件の睡眠記録を取り込みました", result.Imported))
+}
+
+// CSV取り込み画面を表示（result が指定されている場合は確認画面として表示する）
+func (h *SettingsHandler) renderImport(w http.ResponseWriter, r *http.Request, flash *Flash, result *service.CSVImport, csvData string) {
+	data := &TemplateData{
+		Title:      "データの取り込み",
+		ActiveMenu: "settings",
+		User:       GetUserFromContext(r.Context()),
+		Flash:      flash,
+		Data: map[string]interface{}{
+			"Result":  result,
+			"CSVData": csvData,
+		},
+	}
+
+	err := h.templates.Render(w, r, "settings-import.html", data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CSVファイル全体を取り込めない場合に、理由を表示して取り込み画面に戻る
+func (h *SettingsHandler) importFailed(w http.ResponseWriter, r *http.Request, err error) {
+	var message string
+	switch {
+	case errors.Is(err, service.ErrCSVImportTooLarge):
+		message = "CSVファイルが大きすぎます（5MB・20,000行まで）"
+	case errors.Is(err, service.ErrCSVImportHeader):
+		message = "CSVの1行目に「日付」「時間枠」「状態」の列が必要です"
+	case errors.Is(err, service.ErrCSVImportEmpty):
+		message = "CSVファイルに取り込む行がありません"
+	default:
+		h.service.Logger().Error("CSVの取り込みに失敗: error=%v", err)
+		message = "CSVの取り込みに失敗しました"
+	}
+	redirectWithFlash(w, r, settingsImportPath, "danger", message)
+}
+
+// 送信されたCSVを取得（確認画面からの送信は csv_data、それ以外はアップロードしたファイル）
+func readImportCSV(r *http.Request) ([]byte, error) {
+	if data := r.FormValue("csv_data"); data != "" {
+		return []byte(data), nil
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, service.CSVImportMaxBytes+1))
+}
+
+// バックアップ（JSON）から睡眠日誌・睡眠記録などを復元する
+// 復元できるのは睡眠日誌のないアカウントのみで、復元できない場合は理由を表示して取り込み画面に戻る
+func (h *SettingsHandler) RestoreJSON(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(service.BackupMaxBytes); err != nil {
+		redirectWithFlash(w, r, settingsImportPath, "danger", "ファイルの読み込みに失敗しました")
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		redirectWithFlash(w, r, settingsImportPath, "danger", "バックアップファイルを選択してください")
+		return
+	}
+	defer file.Close()
+
+	backup, err := h.service.Backup().Decode(file)
+	if err != nil {
+		h.restoreFailed(w, r, err)
+		return
+	}
+	result, err := h.service.Backup().Restore(r.Context(), GetUserIDFromContext(r.Context()), backup)
+	if err != nil {
+		h.restoreFailed(w, r, err)
+		return
+	}
+
+	redirectWithFlash(w, r, "/settings", "success", fmt.Sprintf("バックアップから睡眠日誌%d件・睡眠記録%d件を復元しました", result.Diaries, result.Records))
+}
+
+// バックアップから復元できない場合に、理由を表示して取り込み画面に戻る
+func (h *SettingsHandler) restoreFailed(w http.ResponseWriter, r *http.Request, err error) {
+	var message string
+	switch {
+	case errors.Is(err, service.ErrBackupTooLarge):
+		message = "バックアップファイルが大きすぎます（32MBまで）"
+	case errors.Is(err, service.ErrBackupFormat):
+		message = "バックアップファイルの形式が正しくありません"
+	case errors.Is(err, service.ErrBackupVersion):
+		message = "このバックアップファイルは新しい版のアプリケーションで作成されたため、復元できません"
+	case errors.Is(err, service.ErrBackupAccountNotEmpty):
+		message = "睡眠日誌があるアカウントには復元できません（新しいアカウントで復元してください）"
+	case errors.Is(err, service.ErrBackupUnknownCode):
+		message = "バックアップに、このサーバーにない睡眠状態・食事種別が含まれています"
+	case errors.Is(err, service.ErrBackupInvalidRecord):
+		message = "バックアップに正しくない睡眠記録が含まれています"
+	case errors.Is(err, service.ErrEmptyDiaryName), errors.Is(err, service.ErrInvalidDiaryPeriod), errors.Is(err, service.ErrDiaryPeriodOverlap):
+		message = "バックアップに正しくない睡眠日誌が含まれています"
+	case errors.Is(err, service.ErrInvalidTimezone), errors.Is(err, service.ErrInvalidSleepGoal):
+		message = "バックアップのプロフィールまたは睡眠設定が正しくありません"
+	default:
+		h.service.Logger().Error("バックアップからの復元に失敗: error=%v", err)
+		message = "バックアップからの復元に失敗しました"
+	}
+	redirectWithFlash(w, r, settingsImportPath, "danger", message)
+}
@@ -7,6 +7,7 @@ package handler
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -35,6 +36,8 @@ func (h *SleepRecordHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/sleep-records", h.List)
 	r.Get("/sleep-records/new", h.New)
 	r.Post("/sleep-records", h.Create)
+	r.Get("/sleep-records/night", h.NightForm)
+	r.Post("/sleep-records/night", h.SaveNight)
 	r.Get("/sleep-records/{id}", h.Show)
 	r.Get("/sleep-records/{id}/edit", h.Edit)
 	r.Put("/sleep-records/{id}", h.Update)
@@ -47,8 +50,7 @@ func (h *SleepRecordHandler) RegisterRoutes(r chi.Router) {
 
 // 睡眠記録一覧の表示
 func (h *SleepRecordHandler) List(w http.ResponseWriter, r *http.Request) {
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// 睡眠状態のマスターデータを取得
 	states, err := h.service.Record().GetStatesList(r.Context())
@@ -87,7 +89,7 @@ func (h *SleepRecordHandler) List(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err = h.templates.Render(w, "sleep-records.html", data)
+	err = h.templates.Render(w, r, "sleep-records.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -120,7 +122,7 @@ func (h *SleepRecordHandler) New(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err = h.templates.Render(w, "sleep-records-form.html", data)
+	err = h.templates.Render(w, r, "sleep-records-form.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -151,8 +153,12 @@ func (h *SleepRecordHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// 記録の作成
-	err := h.service.Record().CreateRecord(r.Context(), record)
+	// 記録の作成（睡眠日誌は記録日から自動で割り当てる）
+	err := h.service.Record().CreateUserRecord(r.Context(), GetUserIDFromContext(r.Context()), record)
+	if errors.Is(err, service.ErrNoDiaryForDate) {
+		http.Redirect(w, r, "/diaries/new?message=記録日を含む睡眠日誌がありません。先に睡眠日誌を作成してください&type=warning", http.StatusSeeOther)
+		return
+	}
 	if err != nil {
 		http.Error(w, "睡眠記録の作成に失敗しました", http.StatusInternalServerError)
 		return
@@ -188,7 +194,7 @@ func (h *SleepRecordHandler) Show(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err = h.templates.Render(w, "sleep-records-detail.html", data)
+	err = h.templates.Render(w, r, "sleep-records-detail.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -231,7 +237,7 @@ func (h *SleepRecordHandler) Edit(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err = h.templates.Render(w, "sleep-records-form.html", data)
+	err = h.templates.Render(w, r, "sleep-records-form.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -277,8 +283,16 @@ func (h *SleepRecordHandler) Update(w http.ResponseWriter, r *http.Request) {
         }
     }
 
-    // 記録の更新
-    err := h.service.Record().UpdateRecord(r.Context(), updatedRecord)
+    // 記録の更新（記録日を変更した場合は睡眠日誌も移す）
+    err := h.service.Record().UpdateUserRecord(r.Context(), GetUserIDFromContext(r.Context()), updatedRecord)
+    if errors.Is(err, service.ErrRecordNotFound) {
+        http.Error(w, "記録が見つかりません", http.StatusNotFound)
+        return
+    }
+    if errors.Is(err, service.ErrNoDiaryForDate) {
+        http.Error(w, "記録日を含む睡眠日誌がありません", http.StatusBadRequest)
+        return
+    }
     if err != nil {
         http.Error(w, "睡眠記録の更新に失敗しました", http.StatusInternalServerError)
         return
@@ -306,8 +320,7 @@ func (h *SleepRecordHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 // 睡眠記録一覧のAPI
 func (h *SleepRecordHandler) ListAPI(w http.ResponseWriter, r *http.Request) {
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	startDate := time.Now().AddDate(0, 0, -30)
 	endDate := time.Now()
@@ -330,8 +343,7 @@ func (h *SleepRecordHandler) FilterAPI(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	records, err := h.service.Record().FilterRecords(r.Context(), userID, filter)
 	if err != nil {
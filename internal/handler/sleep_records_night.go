@@ -0,0 +1,266 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/sleep_records_night.go
+// sleep_records_nightは、就床・消灯・中途覚醒・最終覚醒などの時刻から一晩分の睡眠記録をまとめて入力する画面のハンドラーを提供します。
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+)
+
+// 夜の入力フォームの入力値
+type nightEntryForm struct {
+	Date       string
+	BedTime    string
+	LightsOut  string
+	FinalWake  string
+	Medication string
+	Note       string
+	Wakes      []nightWakeForm
+	Meals      map[string]string // 食事種別コードごとの時刻
+}
+
+// 夜の入力フォームの中途覚醒の時間帯
+type nightWakeForm struct {
+	Start string
+	End   string
+}
+
+// 夜の入力画面を表示
+// 既存の記録がある夜は、記録から再構成した就床・入眠・最終覚醒の時刻を初期値とする
+func (h *SleepRecordHandler) NightForm(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	date, err := time.Parse("2006-01-02", r.URL.Query().Get("date"))
+	if err != nil {
+		date = defaultNightDate(user)
+	}
+
+	form := &nightEntryForm{
+		Date:  date.Format("2006-01-02"),
+		Meals: map[string]string{},
+	}
+
+	episodes, err := h.service.Episode().GetUserEpisodes(r.Context(), user.ID, date, date)
+	if err != nil {
+		http.Error(w, "睡眠記録の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+	for _, episode := range episodes {
+		if episode.IsNap {
+			continue
+		}
+		form.BedTime = episode.BedTime.Format("15:04")
+		form.LightsOut = episode.SleepOnset.Format("15:04")
+		form.FinalWake = episode.FinalWake.Format("15:04")
+		break
+	}
+	if form.BedTime == "" {
+		pref, err := h.service.User().GetSleepPreference(r.Context(), user.ID)
+		if err != nil {
+			http.Error(w, "睡眠設定の取得に失敗しました", http.StatusInternalServerError)
+			return
+		}
+		form.BedTime = pref.PreferredBedtime.Format("15:04")
+		form.LightsOut = form.BedTime
+		form.FinalWake = pref.PreferredWakeupTime.Format("15:04")
+	}
+
+	h.renderNightForm(w, r, form, flashFromQuery(r))
+}
+
+// 夜の入力を保存（その夜の既存の記録は置き換える）
+func (h *SleepRecordHandler) SaveNight(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "フォームの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+
+	form := parseNightEntryForm(r)
+	entry, message := form.entry()
+	if message != "" {
+		h.renderNightForm(w, r, form, &Flash{Type: "danger", Message: message})
+		return
+	}
+
+	records, err := h.service.Record().ReplaceNightRecords(r.Context(), GetUserIDFromContext(r.Context()), entry)
+	if err != nil {
+		message, ok := nightEntryErrorMessage(err)
+		if !ok {
+			http.Error(w, "睡眠記録の保存に失敗しました", http.StatusInternalServerError)
+			return
+		}
+		h.renderNightForm(w, r, form, &Flash{Type: "danger", Message: message})
+		return
+	}
+
+	message = entry.Date.Format("2006/01/02") + " の夜の睡眠記録を保存しました"
+	if len(records) == 0 {
+		redirectWithFlash(w, r, "/sleep-records", "success", message)
+		return
+	}
+	redirectWithFlash(w, r, "/diaries/"+strconv.FormatInt(records[0].SleepDiaryID, 10), "success", message)
+}
+
+// 夜の入力画面を描画
+func (h *SleepRecordHandler) renderNightForm(w http.ResponseWriter, r *http.Request, form *nightEntryForm, flash *Flash) {
+	user := GetUserFromContext(r.Context())
+
+	mealTypes, err := h.service.Record().GetMealTypesList(r.Context())
+	if err != nil {
+		http.Error(w, "食事種別の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	// 置き換えられる既存の記録の件数
+	var existing int
+	if date, err := time.Parse("2006-01-02", form.Date); err == nil {
+		records, err := h.service.Record().GetNightRecords(r.Context(), user.ID, date)
+		if err != nil {
+			http.Error(w, "睡眠記録の取得に失敗しました", http.StatusInternalServerError)
+			return
+		}
+		existing = len(records)
+	}
+
+	data := &TemplateData{
+		Title:      "夜の入力",
+		ActiveMenu: "sleep-records",
+		User:       user,
+		Flash:      flash,
+		Data: map[string]interface{}{
+			"Form":      form,
+			"MealTypes": mealTypes,
+			"Existing":  existing,
+		},
+	}
+
+	if err := h.templates.Render(w, r, "sleep-records-night.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// フォームの入力値を読み込む
+// 中途覚醒は wake_start・wake_end、食事は meal_<食事種別コード> で受け取る
+func parseNightEntryForm(r *http.Request) *nightEntryForm {
+	form := &nightEntryForm{
+		Date:       strings.TrimSpace(r.FormValue("date")),
+		BedTime:    strings.TrimSpace(r.FormValue("bed_time")),
+		LightsOut:  strings.TrimSpace(r.FormValue("lights_out")),
+		FinalWake:  strings.TrimSpace(r.FormValue("final_wake")),
+		Medication: strings.TrimSpace(r.FormValue("medication_time")),
+		Note:       strings.TrimSpace(r.FormValue("note")),
+		Meals:      map[string]string{},
+	}
+
+	starts, ends := r.Form["wake_start"], r.Form["wake_end"]
+	for i := range starts {
+		wake := nightWakeForm{Start: strings.TrimSpace(starts[i])}
+		if i < len(ends) {
+			wake.End = strings.TrimSpace(ends[i])
+		}
+		if wake.Start == "" && wake.End == "" {
+			continue
+		}
+		form.Wakes = append(form.Wakes, wake)
+	}
+
+	for key, values := range r.Form {
+		code, ok := strings.CutPrefix(key, "meal_")
+		if !ok || len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+			continue
+		}
+		form.Meals[strings.ToUpper(code)] = strings.TrimSpace(values[0])
+	}
+
+	return form
+}
+
+// 入力値を夜の入力に変換（形式の誤りがある場合はメッセージを返す）
+func (f *nightEntryForm) entry() (*models.NightEntry, string) {
+	date, err := time.Parse("2006-01-02", f.Date)
+	if err != nil {
+		return nil, "日付を正しく入力してください"
+	}
+
+	parse := func(value string) (time.Time, bool) {
+		clock, err := time.Parse("15:04", value)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return models.NightEntryTime(date, clock), true
+	}
+
+	entry := &models.NightEntry{Date: date, Note: f.Note}
+	var ok bool
+	if entry.BedTime, ok = parse(f.BedTime); !ok {
+		return nil, "就床時刻を正しく入力してください"
+	}
+	if entry.LightsOut, ok = parse(f.LightsOut); !ok {
+		return nil, "消灯時刻を正しく入力してください"
+	}
+	if entry.FinalWake, ok = parse(f.FinalWake); !ok {
+		return nil, "最終覚醒の時刻を正しく入力してください"
+	}
+	for _, wake := range f.Wakes {
+		var period models.NightWakePeriod
+		if period.Start, ok = parse(wake.Start); !ok {
+			return nil, "中途覚醒の開始時刻を正しく入力してください"
+		}
+		if period.End, ok = parse(wake.End); !ok {
+			return nil, "中途覚醒の終了時刻を正しく入力してください"
+		}
+		entry.WakePeriods = append(entry.WakePeriods, period)
+	}
+	if f.Medication != "" {
+		medication, ok := parse(f.Medication)
+		if !ok {
+			return nil, "睡眠薬の服用時刻を正しく入力してください"
+		}
+		entry.Medication = sql.NullTime{Time: medication, Valid: true}
+	}
+	for code, value := range f.Meals {
+		at, ok := parse(value)
+		if !ok {
+			return nil, "食事の時刻を正しく入力してください"
+		}
+		entry.Meals = append(entry.Meals, models.NightMeal{MealTypeCode: code, At: at})
+	}
+
+	return entry, ""
+}
+
+// 夜の入力の初期値の日付（ユーザーのタイムゾーンで、正午より前は前日の夜）
+func defaultNightDate(user *models.User) time.Time {
+	local := time.Now().In(user.Location()).Add(-12 * time.Hour)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// 夜の入力の保存時のエラーを画面に表示するメッセージに変換
+// 入力値の誤りによるエラーでない場合は false を返す
+func nightEntryErrorMessage(err error) (string, bool) {
+	switch {
+	case errors.Is(err, service.ErrNightEntryOrder):
+		return "就床・消灯・最終覚醒の時刻は、この順に入力してください", true
+	case errors.Is(err, service.ErrNightEntryOutOfRange):
+		return "時刻は、睡眠日の正午から翌日の正午までの範囲で入力してください", true
+	case errors.Is(err, service.ErrInvalidWakePeriod):
+		return "中途覚醒は、消灯から最終覚醒までの間で、重ならないように入力してください", true
+	case errors.Is(err, service.ErrNoDiaryForDate):
+		return "この夜を含む睡眠日誌がありません。先に睡眠日誌を作成してください", true
+	case errors.Is(err, service.ErrInvalidMealType):
+		return "食事の種別が正しくありません", true
+	case errors.Is(err, service.ErrInvalidDate):
+		return "日付を正しく入力してください", true
+	}
+	return "", false
+}
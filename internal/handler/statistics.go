@@ -39,15 +39,7 @@ func (h *StatisticsHandler) RegisterRoutes(r chi.Router) {
 
 // 統計情報画面を表示
 func (h *StatisticsHandler) Statistics(w http.ResponseWriter, r *http.Request) {
-	// ユーザー情報の取得
-	userCtx := r.Context().Value(UserKey)
-	if userCtx == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
-		return
-	}
-
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// デフォルトの期間を設定（直近30日）
 	endDate := time.Now()
@@ -70,7 +62,7 @@ func (h *StatisticsHandler) Statistics(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	err = h.templates.Render(w, "statistics.html", data)
+	err = h.templates.Render(w, r, "statistics.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -79,8 +71,7 @@ func (h *StatisticsHandler) Statistics(w http.ResponseWriter, r *http.Request) {
 
 // 統計データを取得
 func (h *StatisticsHandler) GetStatisticsData(w http.ResponseWriter, r *http.Request) {
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// クエリパラメータから期間を取得
 	startDate := r.URL.Query().Get("start")
@@ -113,8 +104,7 @@ func (h *StatisticsHandler) GetStatisticsData(w http.ResponseWriter, r *http.Req
 
 // 週間統計を取得
 func (h *StatisticsHandler) GetWeeklyStats(w http.ResponseWriter, r *http.Request) {
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// 直近の週間統計を取得
 	endDate := time.Now()
@@ -133,8 +123,7 @@ func (h *StatisticsHandler) GetWeeklyStats(w http.ResponseWriter, r *http.Reques
 
 // 月間統計を取得
 func (h *StatisticsHandler) GetMonthlyStats(w http.ResponseWriter, r *http.Request) {
-	// TODO: 実際のユーザーIDを使用
-	var userID int64 = 1 // 開発用
+	userID := GetUserIDFromContext(r.Context())
 
 	// 直近の月間統計を取得
 	endDate := time.Now()
@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/223n-tech/SuiminNisshi-Go/internal/middleware"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
 )
@@ -27,6 +28,7 @@ type TemplateData struct {
 	Data       map[string]interface{}
 	Flash      *Flash
 	Meta       map[string]interface{}
+	CSRFToken  string // フォームの csrf_token フィールドと X-CSRF-Token ヘッダーに設定するトークン
 }
 
 // フラッシュメッセージの構造体
@@ -210,7 +212,7 @@ func (tm *TemplateManager) LoadTemplates() error {
 }
 
 // テンプレートをレンダリング
-func (tm *TemplateManager) Render(w http.ResponseWriter, name string, data *TemplateData) error {
+func (tm *TemplateManager) Render(w http.ResponseWriter, r *http.Request, name string, data *TemplateData) error {
 	tm.mutex.RLock()
 	tmpl, exists := tm.templates[name]
 	tm.mutex.RUnlock()
@@ -229,6 +231,9 @@ func (tm *TemplateManager) Render(w http.ResponseWriter, name string, data *Temp
 	if data.Meta == nil {
 		data.Meta = make(map[string]interface{})
 	}
+	if data.CSRFToken == "" {
+		data.CSRFToken = middleware.CSRFToken(r)
+	}
 
 	// Content-Typeの設定
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -5,11 +5,9 @@ package handler
 // termsは、利用規約画面のハンドラーを提供します。
 
 import (
-	"database/sql"
 	"net/http"
 	"time"
 
-	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
 	"github.com/go-chi/chi/v5"
 )
@@ -35,22 +33,9 @@ func (h *TermsHandler) RegisterRoutes(r chi.Router) {
 }
 
 // 利用規約画面を表示
-func (h *TermsHandler) Terms(w http.ResponseWriter, _ *http.Request) {
-	// ダミーユーザー情報
-	user := &models.User{
-		ID:                1,
-		Email:             "test@example.com",
-		DisplayName:       "テストユーザー",
-		LastLoginDatetime: sql.NullTime{ Time: time.Now(), Valid: true },
-		Created:           time.Now(),
-		Modified:          time.Now(),
-		Deleted:           sql.NullTime{},
-	}
-
-	// TODO: UserKeyを使ってユーザー情報を取得
-	// if userCtx := r.Context().Value(UserKey); userCtx != nil {
-	// 	user = userCtx
-	// }
+func (h *TermsHandler) Terms(w http.ResponseWriter, r *http.Request) {
+	// ログイン中のユーザー情報（未ログインの場合はnil）
+	user := GetUserFromContext(r.Context())
 
 	// 利用規約のメタデータ
 	metadata := map[string]interface{}{
@@ -83,7 +68,7 @@ func (h *TermsHandler) Terms(w http.ResponseWriter, _ *http.Request) {
 		},
 	}
 
-	err := h.templates.Render(w, "terms.html", data)
+	err := h.templates.Render(w, r, "terms.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -91,7 +76,7 @@ func (h *TermsHandler) Terms(w http.ResponseWriter, _ *http.Request) {
 }
 
 // 利用規約の更新履歴を表示
-func (h *TermsHandler) TermsHistory(w http.ResponseWriter, _ *http.Request) {
+func (h *TermsHandler) TermsHistory(w http.ResponseWriter, r *http.Request) {
 	// 更新履歴データ
 	history := []map[string]interface{}{
 		{
@@ -118,7 +103,7 @@ func (h *TermsHandler) TermsHistory(w http.ResponseWriter, _ *http.Request) {
 		},
 	}
 
-	err := h.templates.Render(w, "terms-history.html", data)
+	err := h.templates.Render(w, r, "terms-history.html", data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,97 @@
+// Package handler provides HTTP handlers for the application.
+package handler
+
+// internal/handler/weekly_report.go
+// weekly_reportは、週間レポートのプレビュー画面のハンドラーを実装しています。
+// 週間レポートのメールと同じ内容をブラウザで表示し、PDFをダウンロードできます。
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// 週間レポート関連のハンドラー
+type WeeklyReportHandler struct {
+	templates *TemplateManager
+	service   *service.Service
+}
+
+// WeeklyReportHandlerを作成
+func NewWeeklyReportHandler(templates *TemplateManager, svc *service.Service) *WeeklyReportHandler {
+	return &WeeklyReportHandler{
+		templates: templates,
+		service:   svc,
+	}
+}
+
+// ルーティングを登録
+func (h *WeeklyReportHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/reports/weekly", h.Preview)
+	r.Get("/reports/weekly/pdf", h.DownloadPDF)
+}
+
+// 週間レポートのプレビューを表示
+// start が指定されていない場合は今週（ユーザーのタイムゾーン）のレポートを表示する
+func (h *WeeklyReportHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	date, err := h.reportDate(r)
+	if err != nil {
+		http.Error(w, "無効な日付", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.WeeklyReport().BuildReport(r.Context(), user.ID, date)
+	if err != nil {
+		http.Error(w, "週間レポートの作成に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	reportURL := h.service.WeeklyReport().ReportURL(report.StartDate)
+	content, err := h.service.Email().RenderWeeklyReportEmail(user.DisplayName, report, reportURL)
+	if err != nil {
+		http.Error(w, "週間レポートの表示に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(content.HTML))
+}
+
+// 週間レポートのPDFをダウンロード
+func (h *WeeklyReportHandler) DownloadPDF(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+
+	date, err := h.reportDate(r)
+	if err != nil {
+		http.Error(w, "無効な日付", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.WeeklyReport().BuildReport(r.Context(), user.ID, date)
+	if err != nil {
+		http.Error(w, "週間レポートの作成に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	pdf, err := h.service.WeeklyReport().GeneratePDF(r.Context(), user.ID, report)
+	if err != nil {
+		http.Error(w, "PDFの生成に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	writePDF(w, fmt.Sprintf("sleep-report_%s.pdf", report.StartDate.Format("2006-01-02")), pdf)
+}
+
+// クエリパラメータからレポートの対象日を取得（未指定の場合は今週）
+func (h *WeeklyReportHandler) reportDate(r *http.Request) (time.Time, error) {
+	start := r.URL.Query().Get("start")
+	if start == "" {
+		return h.service.WeeklyReport().CurrentWeek(GetUserFromContext(r.Context())), nil
+	}
+	return time.Parse("2006-01-02", start)
+}
@@ -0,0 +1,87 @@
+// internal/mail/capture.go
+// captureは、送信したメールを記録するだけのテスト用の Mailer を提供します。
+
+// Package mail provides mail composition and delivery.
+package mail
+
+import (
+	"context"
+	"sync"
+)
+
+// 送信したメールを記録するだけの Mailer（テスト用）
+type CaptureMailer struct {
+	mutex    sync.Mutex
+	messages []Message
+	err      error
+	failures int   // FailNext で設定した、残りの失敗させる回数
+	failErr  error // FailNext で設定したエラー
+	attempts int   // 失敗を含む送信の試行回数
+}
+
+// 新しいCaptureMailerを作成
+func NewCaptureMailer() *CaptureMailer {
+	return &CaptureMailer{}
+}
+
+// メールを記録
+// SetError または FailNext でエラーが設定されている場合は記録せずにエラーを返す
+func (m *CaptureMailer) Send(_ context.Context, msg *Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.attempts++
+	if m.err != nil {
+		return m.err
+	}
+	if m.failures > 0 {
+		m.failures--
+		return m.failErr
+	}
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+// 記録したメールを取得
+func (m *CaptureMailer) Messages() []Message {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	messages := make([]Message, len(m.messages))
+	copy(messages, m.messages)
+	return messages
+}
+
+// 送信の試行回数を取得（失敗した送信を含む）
+func (m *CaptureMailer) Attempts() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.attempts
+}
+
+// 送信時に返すエラーを設定（nilで解除）
+func (m *CaptureMailer) SetError(err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.err = err
+}
+
+// 次の n 回の送信で返すエラーを設定（その後は記録する）
+func (m *CaptureMailer) FailNext(n int, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.failures = n
+	m.failErr = err
+}
+
+// 記録したメールと試行回数を消去
+func (m *CaptureMailer) Reset() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.messages = nil
+	m.attempts = 0
+}
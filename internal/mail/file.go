@@ -0,0 +1,47 @@
+// internal/mail/file.go
+// fileは、メールを送信せずにファイルとして出力する開発用の Mailer を提供します。
+
+// Package mail provides mail composition and delivery.
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// メールを .eml ファイルとして出力する Mailer（開発用）
+// 出力したファイルはメールクライアントで開いて内容を確認できる
+type FileMailer struct {
+	Dir string // 出力先のディレクトリ
+}
+
+// 新しいFileMailerを作成
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{Dir: dir}
+}
+
+// メールをファイルに出力
+func (m *FileMailer) Send(_ context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := msg.Bytes(now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102-150405"), hex.EncodeToString(b))
+
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o644)
+}
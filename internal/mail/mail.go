@@ -0,0 +1,251 @@
+// internal/mail/mail.go
+// mailは、メールの組み立てと送信の仕組みを提供します。
+// 送信方法は Mailer インターフェイスで差し替えられ、SMTP・ファイル出力（開発用）・送信内容の記録（テスト用）の実装があります。
+
+// Package mail provides mail composition and delivery.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoRecipients 宛先が指定されていません
+	ErrNoRecipients = errors.New("no recipients / 宛先が指定されていません")
+	// ErrNoSender 送信元が指定されていません
+	ErrNoSender = errors.New("no sender / 送信元が指定されていません")
+)
+
+// メールの送信インターフェイス
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// 送信するメール
+type Message struct {
+	From        string // 送信元（"名前 <address>" 形式も可）
+	To          []string
+	Subject     string
+	Text        string // プレーンテキストの本文
+	HTML        string // HTMLの本文（空の場合はプレーンテキストのみ）
+	Attachments []Attachment
+}
+
+// メールの添付ファイル
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// 送信元のメールアドレス（エンベロープ用）を取得
+func (m *Message) FromAddress() (string, error) {
+	if m.From == "" {
+		return "", ErrNoSender
+	}
+	addr, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender %q: %w", m.From, err)
+	}
+	return addr.Address, nil
+}
+
+// 宛先のメールアドレス（エンベロープ用）を取得
+func (m *Message) ToAddresses() ([]string, error) {
+	if len(m.To) == 0 {
+		return nil, ErrNoRecipients
+	}
+	addresses := make([]string, 0, len(m.To))
+	for _, to := range m.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+		addresses = append(addresses, addr.Address)
+	}
+	return addresses, nil
+}
+
+// RFC 5322 形式のメールデータを作成
+// 本文はプレーンテキストとHTMLの multipart/alternative とし、添付ファイルがある場合は multipart/mixed で包む
+func (m *Message) Bytes(now time.Time) ([]byte, error) {
+	from, err := m.FromAddress()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.ToAddresses(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", encodeAddress(m.From))
+	to := make([]string, len(m.To))
+	for i, addr := range m.To {
+		to[i] = encodeAddress(addr)
+	}
+	writeHeader(&buf, "To", strings.Join(to, ", "))
+	writeHeader(&buf, "Subject", mime.BEncoding.Encode("UTF-8", m.Subject))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(from))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if len(m.Attachments) == 0 {
+		if err := m.writeBody(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+
+	var body bytes.Buffer
+	if err := m.writeBody(&body); err != nil {
+		return nil, err
+	}
+	header, content := splitPart(body.Bytes())
+	part, err := mixed.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range m.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", contentType)
+		header.Set("Content-Transfer-Encoding", "base64")
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+		part, err := mixed.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 本文を Content-Type などのヘッダーとあわせて書き込み
+func (m *Message) writeBody(buf *bytes.Buffer) error {
+	if m.HTML == "" {
+		writeHeader(buf, "Content-Type", "text/plain; charset=UTF-8")
+		writeHeader(buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		return writeQuotedPrintable(buf, m.Text)
+	}
+
+	alternative := multipart.NewWriter(buf)
+	writeHeader(buf, "Content-Type", "multipart/alternative; boundary="+alternative.Boundary())
+	buf.WriteString("\r\n")
+
+	for _, body := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", m.Text},
+		{"text/html; charset=UTF-8", m.HTML},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", body.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		part, err := alternative.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if err := writeQuotedPrintable(part, body.content); err != nil {
+			return err
+		}
+	}
+
+	return alternative.Close()
+}
+
+// ヘッダーを書き込み
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+// 表示名をエンコードしたアドレス
+func encodeAddress(address string) string {
+	addr, err := mail.ParseAddress(address)
+	if err != nil {
+		return address
+	}
+	return addr.String()
+}
+
+// ヘッダーと本文に分割（本文のパートを multipart/mixed に入れるため）
+func splitPart(data []byte) (textproto.MIMEHeader, []byte) {
+	header := textproto.MIMEHeader{}
+	raw, content, _ := bytes.Cut(data, []byte("\r\n\r\n"))
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			header.Add(key, value)
+		}
+	}
+	return header, content
+}
+
+// quoted-printable で書き込み（改行はCRLFに統一）
+func writeQuotedPrintable(w io.Writer, content string) error {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\n", "\r\n")
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// base64 で76文字ごとに改行して書き込み
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := w.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+// Message-ID を生成
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
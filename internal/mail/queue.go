@@ -0,0 +1,192 @@
+// internal/mail/queue.go
+// queueは、メールをバックグラウンドで送信し、失敗時に再試行する送信キューを提供します。
+
+// Package mail provides mail composition and delivery.
+package mail
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull 送信キューがいっぱいです
+	ErrQueueFull = errors.New("mail queue is full / 送信キューがいっぱいです")
+	// ErrQueueClosed 送信キューは停止しています
+	ErrQueueClosed = errors.New("mail queue is closed / 送信キューは停止しています")
+)
+
+// 送信キューの設定
+type QueueOptions struct {
+	Size        int           // キューに積めるメールの数
+	Workers     int           // 同時に送信する数
+	MaxAttempts int           // 1通あたりの送信の試行回数
+	Backoff     time.Duration // 再試行までの待ち時間（失敗のたびに倍増）
+	MaxBackoff  time.Duration // 再試行までの待ち時間の上限
+}
+
+// 既定の送信キューの設定
+func DefaultQueueOptions() QueueOptions {
+	return QueueOptions{
+		Size:        100,
+		Workers:     1,
+		MaxAttempts: 5,
+		Backoff:     2 * time.Second,
+		MaxBackoff:  5 * time.Minute,
+	}
+}
+
+// バックグラウンドでメールを送信する Mailer
+// Send はキューに積むだけですぐに戻り、送信に失敗した場合は待ち時間を空けて再試行する
+type Queue struct {
+	mailer  Mailer
+	logger  *log.Logger
+	options QueueOptions
+	jobs    chan *Message
+	abort   chan struct{}                        // 停止の期限を過ぎた場合に再試行の待ちを打ち切る
+	after   func(time.Duration) <-chan time.Time // 再試行までの待ち（テストで差し替える）
+	wg      sync.WaitGroup
+	mutex   sync.RWMutex
+	started bool
+	closed  bool
+}
+
+// 新しいQueueを作成（0以下の設定項目は既定値を使う）
+func NewQueue(mailer Mailer, logger *log.Logger, options QueueOptions) *Queue {
+	defaults := DefaultQueueOptions()
+	if options.Size <= 0 {
+		options.Size = defaults.Size
+	}
+	if options.Workers <= 0 {
+		options.Workers = defaults.Workers
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaults.MaxAttempts
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = defaults.Backoff
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaults.MaxBackoff
+	}
+
+	return &Queue{
+		mailer:  mailer,
+		logger:  logger,
+		options: options,
+		jobs:    make(chan *Message, options.Size),
+		abort:   make(chan struct{}),
+		after:   time.After,
+	}
+}
+
+// 送信を開始
+func (q *Queue) Start() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.started || q.closed {
+		return
+	}
+	q.started = true
+
+	for i := 0; i < q.options.Workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for msg := range q.jobs {
+				q.deliver(msg)
+			}
+		}()
+	}
+}
+
+// メールをキューに積む
+func (q *Queue) Send(_ context.Context, msg *Message) error {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	copied := *msg
+	select {
+	case q.jobs <- &copied:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// 送信を停止
+// キューに残っているメールの送信が終わるまで待ち、ctxの期限を過ぎた場合は再試行をやめて残りを1回ずつ送信する
+func (q *Queue) Close(ctx context.Context) error {
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.jobs)
+	started := q.started
+	q.mutex.Unlock()
+
+	if !started {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		close(q.abort)
+		<-done
+		return ctx.Err()
+	}
+}
+
+// メールを送信（失敗した場合は再試行）
+func (q *Queue) deliver(msg *Message) {
+	backoff := q.options.Backoff
+	for attempt := 1; ; attempt++ {
+		err := q.mailer.Send(context.Background(), msg)
+		if err == nil {
+			return
+		}
+
+		if attempt >= q.options.MaxAttempts {
+			q.logf("[NG] Failed to send mail: to=%v, subject=%q, attempts=%d, error=%v", msg.To, msg.Subject, attempt, err)
+			return
+		}
+		q.logf("[Mail] Retrying mail: to=%v, subject=%q, attempt=%d, error=%v", msg.To, msg.Subject, attempt, err)
+
+		select {
+		case <-q.after(backoff):
+		case <-q.abort:
+			q.logf("[NG] Gave up sending mail on shutdown: to=%v, subject=%q, error=%v", msg.To, msg.Subject, err)
+			return
+		}
+
+		backoff *= 2
+		if backoff > q.options.MaxBackoff {
+			backoff = q.options.MaxBackoff
+		}
+	}
+}
+
+// ログを出力
+func (q *Queue) logf(format string, v ...interface{}) {
+	if q.logger != nil {
+		q.logger.Printf(format, v...)
+	}
+}
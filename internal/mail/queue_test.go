@@ -0,0 +1,230 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errTestSend = errors.New("send failed")
+
+// 再試行までの待ち時間を記録し、すぐに再試行させる
+type recordedBackoff struct {
+	mutex sync.Mutex
+	waits []time.Duration
+}
+
+// 待ち時間を記録して、すぐに通知するチャネルを返す
+func (b *recordedBackoff) after(d time.Duration) <-chan time.Time {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.waits = append(b.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+// 記録した待ち時間を取得
+func (b *recordedBackoff) Waits() []time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return append([]time.Duration(nil), b.waits...)
+}
+
+// 出力したログを保持するロガー
+type testLog struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+// ログを書き込む
+func (l *testLog) Write(p []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.buf.Write(p)
+}
+
+// 出力したログを取得
+func (l *testLog) String() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.buf.String()
+}
+
+// 待ち時間を記録する送信キューを作成
+func newTestQueue(mailer Mailer, options QueueOptions) (*Queue, *recordedBackoff, *testLog) {
+	logs := &testLog{}
+	queue := NewQueue(mailer, log.New(logs, "", 0), options)
+	backoff := &recordedBackoff{}
+	queue.after = backoff.after
+	return queue, backoff, logs
+}
+
+// テスト用のメール
+func testMessage(subject string) *Message {
+	return &Message{To: []string{"user@example.com"}, Subject: subject, Text: "本文"}
+}
+
+// キューを停止（テストの期限内に停止しない場合は失敗）
+func closeTestQueue(t *testing.T, queue *Queue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := queue.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// 記録したメールの件名
+func subjects(messages []Message) []string {
+	var subjects []string
+	for _, msg := range messages {
+		subjects = append(subjects, msg.Subject)
+	}
+	return subjects
+}
+
+// 送信に失敗した場合に、待ち時間を倍増させながら再試行することを確認
+func TestQueueRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		wantWaits []time.Duration
+	}{
+		{name: "success", failures: 0, wantWaits: nil},
+		{name: "one failure", failures: 1, wantWaits: []time.Duration{time.Second}},
+		{name: "capped at max backoff", failures: 4, wantWaits: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := NewCaptureMailer()
+			mailer.FailNext(tt.failures, errTestSend)
+			queue, backoff, _ := newTestQueue(mailer, QueueOptions{MaxAttempts: 5, Backoff: time.Second, MaxBackoff: 3 * time.Second})
+			queue.Start()
+
+			if err := queue.Send(context.Background(), testMessage("retry")); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			closeTestQueue(t, queue)
+
+			if got := subjects(mailer.Messages()); !reflect.DeepEqual(got, []string{"retry"}) {
+				t.Fatalf("messages = %v, want [retry]", got)
+			}
+			if got := mailer.Attempts(); got != tt.failures+1 {
+				t.Errorf("attempts = %d, want %d", got, tt.failures+1)
+			}
+			if got := backoff.Waits(); !reflect.DeepEqual(got, tt.wantWaits) {
+				t.Errorf("waits = %v, want %v", got, tt.wantWaits)
+			}
+		})
+	}
+}
+
+// 試行回数の上限まで失敗したメールを破棄し、次のメールの送信を続けることを確認
+func TestQueueDropAfterMaxAttempts(t *testing.T) {
+	mailer := NewCaptureMailer()
+	mailer.FailNext(3, errTestSend)
+	queue, backoff, logs := newTestQueue(mailer, QueueOptions{Workers: 1, MaxAttempts: 3, Backoff: time.Second, MaxBackoff: time.Minute})
+
+	// 1通目は3回失敗して破棄され、2通目は送信される
+	for _, subject := range []string{"dropped", "delivered"} {
+		if err := queue.Send(context.Background(), testMessage(subject)); err != nil {
+			t.Fatalf("Send %s: %v", subject, err)
+		}
+	}
+	queue.Start()
+	closeTestQueue(t, queue)
+
+	if got := subjects(mailer.Messages()); !reflect.DeepEqual(got, []string{"delivered"}) {
+		t.Fatalf("messages = %v, want [delivered]", got)
+	}
+	if got := mailer.Attempts(); got != 4 {
+		t.Errorf("attempts = %d, want 4", got)
+	}
+	if got, want := backoff.Waits(), []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("waits = %v, want %v", got, want)
+	}
+	if want := `[NG] Failed to send mail: to=[user@example.com], subject="dropped", attempts=3`; !strings.Contains(logs.String(), want) {
+		t.Errorf("log does not contain %q:\n%s", want, logs)
+	}
+}
+
+// 停止時にキューに残っているメールをすべて送信してから戻ることを確認
+func TestQueueCloseDrains(t *testing.T) {
+	mailer := NewCaptureMailer()
+	mailer.FailNext(2, errTestSend)
+	queue, _, _ := newTestQueue(mailer, QueueOptions{Size: 10, Workers: 2, MaxAttempts: 3})
+
+	var want []string
+	for i := 0; i < 10; i++ {
+		subject := fmt.Sprintf("message %d", i)
+		want = append(want, subject)
+		if err := queue.Send(context.Background(), testMessage(subject)); err != nil {
+			t.Fatalf("Send %s: %v", subject, err)
+		}
+	}
+	// キューがいっぱいの場合は積まない
+	if err := queue.Send(context.Background(), testMessage("overflow")); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Send (full): error = %v, want %v", err, ErrQueueFull)
+	}
+
+	queue.Start()
+	closeTestQueue(t, queue)
+
+	got := subjects(mailer.Messages())
+	if len(got) != len(want) {
+		t.Fatalf("messages = %v, want %v", got, want)
+	}
+	sent := map[string]bool{}
+	for _, subject := range got {
+		sent[subject] = true
+	}
+	for _, subject := range want {
+		if !sent[subject] {
+			t.Errorf("%s was not sent before Close returned", subject)
+		}
+	}
+
+	// 停止後は積めない
+	if err := queue.Send(context.Background(), testMessage("after close")); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("Send (closed): error = %v, want %v", err, ErrQueueClosed)
+	}
+	// 2回目の停止は何もしない
+	closeTestQueue(t, queue)
+}
+
+// 停止の期限を過ぎた場合は再試行をやめて、残りのメールを1回ずつ送信することを確認
+func TestQueueCloseDeadline(t *testing.T) {
+	mailer := NewCaptureMailer()
+	mailer.SetError(errTestSend)
+	// 再試行の待ちは停止の期限より長い
+	queue := NewQueue(mailer, nil, QueueOptions{Workers: 1, MaxAttempts: 5, Backoff: time.Hour})
+
+	for _, subject := range []string{"first", "second", "third"} {
+		if err := queue.Send(context.Background(), testMessage(subject)); err != nil {
+			t.Fatalf("Send %s: %v", subject, err)
+		}
+	}
+	queue.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := queue.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close: error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := mailer.Attempts(); got != 3 {
+		t.Errorf("attempts = %d, want 3 (one per message after the deadline)", got)
+	}
+}
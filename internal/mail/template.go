@@ -0,0 +1,138 @@
+// internal/mail/template.go
+// templateは、埋め込みテンプレートからメールの件名と本文（プレーンテキスト・HTML）を作成します。
+// テンプレートは templates/<名前>.txt と templates/<名前>.html で定義し、件名は .txt の "subject" で定義します。
+// HTMLの本文は "content" で定義し、templates/layout.html に埋め込みます。
+
+// Package mail provides mail composition and delivery.
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// メールのテンプレート名
+const (
+	TemplateWelcome         = "welcome"
+	TemplatePasswordReset   = "password_reset"
+	TemplateWeeklyReport    = "weekly_report"
+	TemplateAccountDeletion = "account_deletion"
+	TemplateBedtimeReminder = "bedtime_reminder"
+)
+
+// テンプレートから作成したメールの件名と本文
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// メールのテンプレートを管理する構造体
+type Renderer struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// テンプレートで使用する関数
+var templateFuncs = map[string]interface{}{
+	"date":     formatDate,
+	"duration": formatDuration,
+}
+
+// 新しいRendererを作成（埋め込みテンプレートをすべて読み込む）
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+
+	names, err := fs.Glob(templateFiles, "templates/*.txt")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range names {
+		name := strings.TrimSuffix(path.Base(file), ".txt")
+
+		text, err := texttemplate.New(path.Base(file)).Funcs(templateFuncs).ParseFS(templateFiles, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", file, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("mail template %s does not define \"subject\"", file)
+		}
+		r.text[name] = text
+
+		htmlFile := "templates/" + name + ".html"
+		if _, err := fs.Stat(templateFiles, htmlFile); err != nil {
+			continue
+		}
+		html, err := htmltemplate.New("layout.html").Funcs(templateFuncs).ParseFS(templateFiles, "templates/layout.html", htmlFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", htmlFile, err)
+		}
+		r.html[name] = html
+	}
+
+	return r, nil
+}
+
+// テンプレートから件名と本文を作成
+func (r *Renderer) Render(name string, data interface{}) (*Content, error) {
+	text, ok := r.text[name]
+	if !ok {
+		return nil, fmt.Errorf("mail template not found: %s", name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return nil, err
+	}
+
+	content := &Content{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    body.String(),
+	}
+
+	if html, ok := r.html[name]; ok {
+		var buf bytes.Buffer
+		if err := html.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		content.HTML = buf.String()
+	}
+
+	return content, nil
+}
+
+// 日付を表示用に整形
+func formatDate(t time.Time) string {
+	return t.Format("2006年1月2日")
+}
+
+// 時間を表示用に整形（例: 7時間30分）
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%d分", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%d時間", hours)
+	default:
+		return fmt.Sprintf("%d時間%d分", hours, minutes)
+	}
+}
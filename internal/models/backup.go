@@ -0,0 +1,108 @@
+// internal/models/backup.go
+// backupは、ユーザーのデータを別のアカウントやサーバーに移すためのバックアップ（JSON）の形式を提供します。
+// 睡眠状態・食事種別はサーバーごとにIDが異なるため、IDではなくコードで記録します。
+
+// Package models provides data models for the application.
+package models
+
+import (
+	"time"
+)
+
+/*
+	バックアップの形式を表す定数
+	形式を変更した場合は BackupVersion を上げ、復元時に古い版を読み替える
+	版1には通知設定と睡眠記録の作成日時・更新日時がない（復元時は通知設定の既定値と復元した日時を使う）
+*/
+const (
+	BackupFormat  = "suiminnisshi-backup"
+	BackupVersion = 2
+)
+
+/*
+	ユーザーのデータのバックアップ
+*/
+type Backup struct {
+	Format          string                 `json:"format"`  // 常に BackupFormat
+	Version         int                    `json:"version"` // 形式の版
+	ExportedAt      time.Time              `json:"exported_at"`
+	Profile         BackupProfile          `json:"profile"`
+	SleepPreference *BackupSleepPreference `json:"sleep_preference,omitempty"` // 睡眠設定を保存していない場合は省略
+	// 通知設定を保存していない場合は省略
+	NotificationSettings *BackupNotificationSettings `json:"notification_settings,omitempty"`
+	SleepStates          []BackupSleepState          `json:"sleep_states"` // 睡眠記録で使っている睡眠状態
+	MealTypes            []BackupMealType            `json:"meal_types"`   // 睡眠記録で使っている食事種別
+	Diaries              []BackupDiary               `json:"diaries"`
+}
+
+/*
+	バックアップのプロフィール
+*/
+type BackupProfile struct {
+	Email       string `json:"email"`
+	DisplayName string `json:"display_name"`
+	TimeZone    string `json:"time_zone"`
+}
+
+/*
+	バックアップの睡眠設定（時刻は HH:MM）
+*/
+type BackupSleepPreference struct {
+	PreferredBedtime    string `json:"preferred_bedtime"`
+	PreferredWakeupTime string `json:"preferred_wakeup_time"`
+	SleepGoalHours      int    `json:"sleep_goal_hours"`
+	IsReminderEnabled   bool   `json:"is_reminder_enabled"`
+}
+
+/*
+	バックアップの通知設定（リマインダーの送信時刻は HH:MM、空の場合は就寝時刻の一定時間前）
+*/
+type BackupNotificationSettings struct {
+	EmailEnabled    bool   `json:"email_enabled"`
+	BedtimeReminder bool   `json:"bedtime_reminder"`
+	ReminderTime    string `json:"reminder_time"`
+	WeeklyReport    bool   `json:"weekly_report"`
+}
+
+/*
+	バックアップの睡眠状態（名前と記号は確認用で、復元時はコードのみを使う）
+*/
+type BackupSleepState struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+/*
+	バックアップの食事種別（名前と記号は確認用で、復元時はコードのみを使う）
+*/
+type BackupMealType struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+/*
+	バックアップの睡眠日誌（日付は YYYY-MM-DD）
+*/
+type BackupDiary struct {
+	StartDate string         `json:"start_date"`
+	EndDate   string         `json:"end_date"`
+	Name      string         `json:"name"`
+	Note      string         `json:"note,omitempty"`
+	Records   []BackupRecord `json:"records"`
+}
+
+/*
+	バックアップの睡眠記録（記録日は YYYY-MM-DD、時間枠は HH:MM、作成日時・更新日時はUTC）
+*/
+type BackupRecord struct {
+	RecordDate   string    `json:"record_date"`
+	TimeSlot     string    `json:"time_slot"`
+	RecordType   string    `json:"record_type"`
+	StateCode    string    `json:"state_code"`
+	MealTypeCode string    `json:"meal_type_code,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	Created      time.Time `json:"created"`
+	Modified     time.Time `json:"modified"`
+}
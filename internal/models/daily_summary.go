@@ -0,0 +1,113 @@
+// internal/models/daily_summary.go
+// daily_summaryは、睡眠エピソードから集計した1日分の睡眠の概要（日別集計データ）を提供します。
+
+// Package models provides data models for the application.
+package models
+
+import (
+	"math"
+	"time"
+)
+
+/*
+	日別集計データ（睡眠日ごとの睡眠の概要）
+	キャッシュのデータ構造は doc/cache.md の 1-1 を参照
+	記録のある日は daily_summaries テーブルにも保存する（ID・ユーザーID・作成日時・更新日時はキャッシュには含めない）
+	期間の睡眠統計は、主睡眠と仮眠の項目から集計する（時間は30分単位のため分の整数で保持する）
+*/
+type DailySummary struct {
+	ID                  int64     `json:"-"`
+	UserID              int64     `json:"-"`
+	SummaryDate         string    `json:"summary_date"`          // 睡眠日（YYYY-MM-DD）
+	TotalSleepDuration  float64   `json:"total_sleep_duration"`  // 総睡眠時間（時間、仮眠を含む）
+	AvgSleepQuality     float64   `json:"avg_sleep_quality"`     // 睡眠効率の平均（%）
+	SleepStartTime      string    `json:"sleep_start_time"`      // 主睡眠の入眠時刻（HH:MM、記録がない場合は空）
+	SleepEndTime        string    `json:"sleep_end_time"`        // 主睡眠の最終覚醒時刻（HH:MM、記録がない場合は空）
+	SleepSegmentCount   int       `json:"sleep_segment_count"`   // 睡眠の分割数
+	IsWithinTargetTime  bool      `json:"is_within_target_time"` // 主睡眠の総睡眠時間が目標睡眠時間以上か
+	BedTime             string    `json:"bed_time"`              // 主睡眠の就床時刻（HH:MM、記録がない場合は空）
+	MainSleepMinutes    int       `json:"main_sleep_minutes"`    // 主睡眠の総睡眠時間（分）
+	TimeInBedMinutes    int       `json:"time_in_bed_minutes"`   // 主睡眠の床上時間（分）
+	SleepLatencyMinutes int       `json:"sleep_latency_minutes"` // 主睡眠の入眠潜時（分）
+	WASOMinutes         int       `json:"waso_minutes"`          // 主睡眠の中途覚醒時間（分）
+	NapCount            int       `json:"nap_count"`             // 仮眠の回数
+	NapMinutes          int       `json:"nap_minutes"`           // 仮眠の総睡眠時間の合計（分）
+	Created             time.Time `json:"-"`
+	Modified            time.Time `json:"-"`
+}
+
+/*
+	睡眠日の睡眠エピソードから日別集計データを作成
+	episodes には睡眠日が date のエピソードのみを渡す（空の場合は記録のない日の集計データになる）
+*/
+func NewDailySummary(date time.Time, episodes []*SleepEpisode, goalHours int) *DailySummary {
+	summary := &DailySummary{
+		SummaryDate: date.Format("2006-01-02"),
+	}
+	if len(episodes) == 0 {
+		return summary
+	}
+
+	var totalSleep time.Duration
+	var efficiency float64
+	var main *SleepEpisode
+	for _, episode := range episodes {
+		totalSleep += episode.TotalSleepTime
+		efficiency += episode.SleepEfficiency()
+		summary.SleepSegmentCount += episode.Segments
+		if episode.IsNap {
+			summary.NapCount++
+			summary.NapMinutes += int(episode.TotalSleepTime / time.Minute)
+			continue
+		}
+		if main == nil || episode.TotalSleepTime > main.TotalSleepTime {
+			main = episode
+		}
+	}
+
+	summary.TotalSleepDuration = roundSummaryValue(totalSleep.Hours())
+	summary.AvgSleepQuality = roundSummaryValue(efficiency / float64(len(episodes)))
+	if main != nil {
+		summary.SleepStartTime = main.SleepOnset.Format("15:04")
+		summary.SleepEndTime = main.FinalWake.Format("15:04")
+		summary.IsWithinTargetTime = goalHours > 0 && main.TotalSleepTime >= time.Duration(goalHours)*time.Hour
+		summary.BedTime = main.BedTime.Format("15:04")
+		summary.MainSleepMinutes = int(main.TotalSleepTime / time.Minute)
+		summary.TimeInBedMinutes = int(main.TimeInBed / time.Minute)
+		summary.SleepLatencyMinutes = int(main.SleepLatency / time.Minute)
+		summary.WASOMinutes = int(main.WakeAfterSleepOnset / time.Minute)
+	}
+
+	return summary
+}
+
+/*
+	記録のある日の集計データか（記録のない日は daily_summaries テーブルに保存しない）
+*/
+func (s *DailySummary) HasRecords() bool {
+	return s.SleepSegmentCount > 0
+}
+
+/*
+	主睡眠の記録がある日か（仮眠のみの日は含めない）
+*/
+func (s *DailySummary) HasMainSleep() bool {
+	return s.SleepStartTime != ""
+}
+
+/*
+	主睡眠の睡眠効率（総睡眠時間 / 床上時間）を百分率で計算
+*/
+func (s *DailySummary) MainSleepEfficiency() float64 {
+	if s.TimeInBedMinutes <= 0 {
+		return 0
+	}
+	return float64(s.MainSleepMinutes) / float64(s.TimeInBedMinutes) * 100
+}
+
+/*
+	集計値を小数点以下2桁に丸める
+*/
+func roundSummaryValue(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,28 @@
+// internal/models/login_failure.go
+// login_failureは、ログイン失敗の監査記録を管理する構造体を提供します。
+
+// Package models provides data models for the application.
+package models
+
+import "time"
+
+/*
+	ログイン失敗の理由
+*/
+const (
+	LoginFailureInvalidCredentials = "INVALID_CREDENTIALS" // メールアドレスまたはパスワードの誤り
+	LoginFailureThrottled          = "THROTTLED"           // 試行間隔の制限中
+	LoginFailureLocked             = "LOCKED"              // アカウントの一時ロック中
+)
+
+/*
+	ログイン失敗の監査記録を管理する構造体
+	失敗したログインは存在しないメールアドレスも含めて記録する
+*/
+type LoginFailure struct {
+	ID        int64     `db:"id"`
+	Email     string    `db:"email"`
+	IPAddress string    `db:"ip_address"`
+	Reason    string    `db:"reason"`
+	Created   time.Time `db:"created"`
+}
@@ -0,0 +1,143 @@
+// internal/models/night_entry.go
+// night_entryは、就床・消灯・中途覚醒・最終覚醒などの時刻から一晩分の睡眠記録をまとめて作成するための構造体を提供します。
+
+// Package models provides data models for the application.
+package models
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
+
+/*
+	一晩分の入力（夜の入力）
+	時刻はすべて記録日・時間枠と同じく、タイムゾーンを持たない壁時計の日時（UTC）で表す
+*/
+type NightEntry struct {
+	Date        time.Time         // 睡眠日（就床した夜の日付）
+	BedTime     time.Time         // 就床時刻
+	LightsOut   time.Time         // 消灯時刻
+	WakePeriods []NightWakePeriod // 中途覚醒の時間帯
+	FinalWake   time.Time         // 最終覚醒時刻
+	Medication  sql.NullTime      // 睡眠薬の服用時刻
+	Meals       []NightMeal       // 食事
+	Note        string            // メモ（作成するすべての記録に設定する）
+}
+
+/*
+	中途覚醒の時間帯
+*/
+type NightWakePeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+/*
+	食事の時刻
+*/
+type NightMeal struct {
+	MealTypeCode string
+	At           time.Time
+}
+
+/*
+	夜の入力を展開した時間枠1つ分の記録
+	StateCode は睡眠状態のコード、MealTypeCode は食事の記録の場合のみ設定する
+*/
+type NightEntrySlot struct {
+	At           time.Time
+	RecordType   string
+	StateCode    string
+	MealTypeCode string
+}
+
+/*
+	睡眠日と時刻（HH:MM）から夜の入力の日時を作成
+	睡眠日の区切り時刻（正午）より前の時刻は翌日の時刻として扱う
+*/
+func NightEntryTime(date time.Time, clock time.Time) time.Time {
+	t := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+	if t.Hour() < int(sleepDayOffset/time.Hour) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+/*
+	夜の入力の対象となる時間帯（睡眠日の正午から翌日の正午まで）
+	この時間帯の既存の記録は、夜の入力の保存時に置き換えられる
+*/
+func (e *NightEntry) Window() (time.Time, time.Time) {
+	start := time.Date(e.Date.Year(), e.Date.Month(), e.Date.Day(), 0, 0, 0, 0, time.UTC).Add(sleepDayOffset)
+	return start, start.Add(24 * time.Hour)
+}
+
+/*
+	夜の入力を30分単位の時間枠の記録に展開する
+	時刻は最も近い時間枠の境界に丸め、就床から消灯までを床で覚醒、消灯から最終覚醒までを睡眠中とする。
+	中途覚醒は床で覚醒とし、丸めると時間枠がなくなる短い覚醒も1枠として記録する。
+	睡眠薬の服用は、その時間枠に睡眠状態がなければ睡眠状態として、あればイベントとして記録する。
+	食事は通常覚醒の食事の記録とする。
+*/
+func (e *NightEntry) Slots() []NightEntrySlot {
+	states := make(map[time.Time]string)
+
+	bedTime := roundToSlot(e.BedTime)
+	lightsOut := roundToSlot(e.LightsOut)
+	finalWake := roundToSlot(e.FinalWake)
+	for t := bedTime; t.Before(lightsOut); t = t.Add(SlotDuration) {
+		states[t] = StateCodeAwakeInBed
+	}
+	for t := lightsOut; t.Before(finalWake); t = t.Add(SlotDuration) {
+		states[t] = StateCodeSleeping
+	}
+	for _, period := range e.WakePeriods {
+		start := roundToSlot(period.Start)
+		end := roundToSlot(period.End)
+		if !end.After(start) {
+			end = start.Add(SlotDuration)
+		}
+		for t := start; t.Before(end); t = t.Add(SlotDuration) {
+			if _, ok := states[t]; ok {
+				states[t] = StateCodeAwakeInBed
+			}
+		}
+	}
+
+	slots := make([]NightEntrySlot, 0, len(states)+len(e.Meals)+1)
+	for at, code := range states {
+		slots = append(slots, NightEntrySlot{At: at, RecordType: RecordTypeState, StateCode: code})
+	}
+
+	if e.Medication.Valid {
+		at := roundToSlot(e.Medication.Time)
+		recordType := RecordTypeState
+		if _, ok := states[at]; ok {
+			recordType = RecordTypeEvent
+		}
+		slots = append(slots, NightEntrySlot{At: at, RecordType: recordType, StateCode: StateCodeMedication})
+	}
+
+	for _, meal := range e.Meals {
+		slots = append(slots, NightEntrySlot{
+			At:           roundToSlot(meal.At),
+			RecordType:   RecordTypeMeal,
+			StateCode:    StateCodeAwake,
+			MealTypeCode: meal.MealTypeCode,
+		})
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].At.Before(slots[j].At)
+	})
+
+	return slots
+}
+
+/*
+	日時を最も近い時間枠の境界に丸める（ちょうど中間の場合は後の境界）
+*/
+func roundToSlot(t time.Time) time.Time {
+	return t.Add(SlotDuration / 2).Truncate(SlotDuration)
+}
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 睡眠日（2024-04-01）と時刻（HH:MM）から夜の入力の日時を作成
+func testNightTime(t *testing.T, clock string) time.Time {
+	t.Helper()
+	c, err := time.Parse("15:04", clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NightEntryTime(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), c)
+}
+
+// 時間枠の記録を "01-02 15:04 種別 状態コード [食事種別コード]" の形にする
+func testSlotStrings(slots []NightEntrySlot) []string {
+	var got []string
+	for _, slot := range slots {
+		got = append(got, strings.TrimSpace(strings.Join([]string{slot.At.Format("01-02 15:04"), slot.RecordType, slot.StateCode, slot.MealTypeCode}, " ")))
+	}
+	return got
+}
+
+// 睡眠日の区切り（正午）より前の時刻は翌日として扱うことを確認
+func TestNightEntryTime(t *testing.T) {
+	tests := map[string]string{
+		"12:00": "2024-04-01 12:00",
+		"23:30": "2024-04-01 23:30",
+		"00:00": "2024-04-02 00:00",
+		"11:59": "2024-04-02 11:59",
+	}
+	for clock, want := range tests {
+		if got := testNightTime(t, clock).Format("2006-01-02 15:04"); got != want {
+			t.Errorf("NightEntryTime(%s) = %s, want %s", clock, got, want)
+		}
+	}
+}
+
+// 夜の入力の時間枠への展開を確認
+func TestNightEntrySlots(t *testing.T) {
+	type wake struct{ start, end string }
+	tests := []struct {
+		name       string
+		bed        string
+		lightsOut  string
+		finalWake  string
+		wakes      []wake
+		medication string
+		meals      map[string]string // 食事種別コード → 時刻
+		want       []string
+	}{
+		{
+			name: "就床から消灯までは床で覚醒",
+			bed:  "23:00", lightsOut: "23:30", finalWake: "01:00",
+			want: []string{
+				"04-01 23:00 STATE AWAKE_IN_BED",
+				"04-01 23:30 STATE SLEEPING",
+				"04-02 00:00 STATE SLEEPING",
+				"04-02 00:30 STATE SLEEPING",
+			},
+		},
+		{
+			name: "時刻は最も近い時間枠の境界に丸める",
+			bed:  "22:44", lightsOut: "22:45", finalWake: "00:15",
+			want: []string{
+				"04-01 22:30 STATE AWAKE_IN_BED",
+				"04-01 23:00 STATE SLEEPING",
+				"04-01 23:30 STATE SLEEPING",
+				"04-02 00:00 STATE SLEEPING",
+			},
+		},
+		{
+			name: "日付をまたぐ中途覚醒",
+			bed:  "22:00", lightsOut: "22:00", finalWake: "01:30",
+			wakes: []wake{{"23:30", "00:30"}},
+			want: []string{
+				"04-01 22:00 STATE SLEEPING",
+				"04-01 22:30 STATE SLEEPING",
+				"04-01 23:00 STATE SLEEPING",
+				"04-01 23:30 STATE AWAKE_IN_BED",
+				"04-02 00:00 STATE AWAKE_IN_BED",
+				"04-02 00:30 STATE SLEEPING",
+				"04-02 01:00 STATE SLEEPING",
+			},
+		},
+		{
+			name: "丸めると時間枠がなくなる短い中途覚醒も1枠として記録する",
+			bed:  "00:00", lightsOut: "00:00", finalWake: "02:00",
+			wakes: []wake{{"01:05", "01:10"}},
+			want: []string{
+				"04-02 00:00 STATE SLEEPING",
+				"04-02 00:30 STATE SLEEPING",
+				"04-02 01:00 STATE AWAKE_IN_BED",
+				"04-02 01:30 STATE SLEEPING",
+			},
+		},
+		{
+			name: "床にいない時間の中途覚醒は記録しない",
+			bed:  "00:00", lightsOut: "00:00", finalWake: "01:00",
+			wakes: []wake{{"00:30", "02:00"}},
+			want: []string{
+				"04-02 00:00 STATE SLEEPING",
+				"04-02 00:30 STATE AWAKE_IN_BED",
+			},
+		},
+		{
+			name: "床に入る前の睡眠薬は睡眠状態として記録する",
+			bed:  "23:00", lightsOut: "23:00", finalWake: "00:00", medication: "22:20",
+			want: []string{
+				"04-01 22:30 STATE MEDICATION",
+				"04-01 23:00 STATE SLEEPING",
+				"04-01 23:30 STATE SLEEPING",
+			},
+		},
+		{
+			name: "床にいる間の睡眠薬はイベントとして記録する",
+			bed:  "23:00", lightsOut: "23:30", finalWake: "00:00", medication: "23:00",
+			want: []string{
+				"04-01 23:00 STATE AWAKE_IN_BED",
+				"04-01 23:00 EVENT MEDICATION",
+				"04-01 23:30 STATE SLEEPING",
+			},
+		},
+		{
+			name: "食事は通常覚醒の食事の記録",
+			bed:  "23:00", lightsOut: "23:00", finalWake: "00:00",
+			meals: map[string]string{MealCodeSnack: "22:10", MealCodeBreakfast: "07:40"},
+			want: []string{
+				"04-01 22:00 MEAL AWAKE SNACK",
+				"04-01 23:00 STATE SLEEPING",
+				"04-01 23:30 STATE SLEEPING",
+				"04-02 07:30 MEAL AWAKE BREAKFAST",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &NightEntry{
+				Date:      time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				BedTime:   testNightTime(t, tt.bed),
+				LightsOut: testNightTime(t, tt.lightsOut),
+				FinalWake: testNightTime(t, tt.finalWake),
+			}
+			for _, w := range tt.wakes {
+				entry.WakePeriods = append(entry.WakePeriods, NightWakePeriod{Start: testNightTime(t, w.start), End: testNightTime(t, w.end)})
+			}
+			if tt.medication != "" {
+				entry.Medication = sql.NullTime{Time: testNightTime(t, tt.medication), Valid: true}
+			}
+			for code, at := range tt.meals {
+				entry.Meals = append(entry.Meals, NightMeal{MealTypeCode: code, At: testNightTime(t, at)})
+			}
+
+			if got := testSlotStrings(entry.Slots()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Slots =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// 夜の入力の対象となる時間帯は睡眠日の正午から翌日の正午までであることを確認
+func TestNightEntryWindow(t *testing.T) {
+	entry := &NightEntry{Date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}
+	start, end := entry.Window()
+	if start != time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC) || end != time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC) {
+		t.Errorf("Window = %v - %v", start, end)
+	}
+}
@@ -0,0 +1,29 @@
+// internal/models/notification_delivery.go
+// notification_deliveryは、通知の送信履歴を管理する構造体を提供します。
+
+// Package models provides data models for the application.
+package models
+
+import "time"
+
+/*
+	通知の種類
+*/
+const (
+	NotificationKindBedtimeReminder = "BEDTIME_REMINDER" // 就寝時刻のリマインダー
+	NotificationKindWeeklyReport    = "WEEKLY_REPORT"    // 週間レポート
+)
+
+/*
+	通知の送信履歴を管理する構造体
+	同じ通知を二重に送信しないよう、ユーザー・通知の種類・対象期間（PeriodKey）の組み合わせで記録する
+	PeriodKey は通知の種類ごとの対象期間を表す文字列
+	（就寝時刻のリマインダーの場合はユーザーのタイムゾーンでの就寝日、週間レポートの場合は対象週の月曜日で、いずれも YYYY-MM-DD）
+*/
+type NotificationDelivery struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Kind      string    `db:"kind"`
+	PeriodKey string    `db:"period_key"`
+	Created   time.Time `db:"created"`
+}
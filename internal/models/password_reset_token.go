@@ -0,0 +1,26 @@
+// internal/models/password_reset_token.go
+// password_reset_tokenは、パスワード再設定用のトークンを管理する構造体を提供します。
+
+// Package models provides data models for the application.
+package models
+
+import "time"
+
+/*
+	パスワード再設定用のトークンを管理する構造体
+	トークンそのものは保存せず、SHA-256のハッシュ値のみを保存する
+*/
+type PasswordResetToken struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	TokenHash string    `db:"token_hash"`
+	Expires   time.Time `db:"expires"`
+	Created   time.Time `db:"created"`
+}
+
+/*
+	トークンが有効期限切れかチェック
+*/
+func (t *PasswordResetToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.Expires)
+}
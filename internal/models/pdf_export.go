@@ -4,18 +4,28 @@
 // Package models provides data models for the application.
 package models
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/signintech/gopdf"
+)
 
 /*
 	PDF出力用のデータを管理する構造体
 */
 type PDFExportData struct {
-	User        User
-	SleepDiary  SleepDiary
-	Records     []*SleepRecord
-	States      map[int64]SleepState
-	MealTypes   map[int64]MealType
-	Preferences UserSleepPreference
+	User           User
+	SleepDiary     SleepDiary
+	Records        []*SleepRecord
+	States         map[int64]SleepState
+	MealTypes      map[int64]MealType
+	Preferences    UserSleepPreference
+	Statistics     *PDFStatistics  // 統計情報のPDFの場合のみ設定
+	DailySummaries []*DailySummary // 出力期間の日別集計データ（合計睡眠時間の列に使用）
+	Options        PDFExportOptions
 }
 
 /*
@@ -80,7 +90,7 @@ type PDFStatistics struct {
 	PDFテンプレート用の設定
 */
 type PDFTemplate struct {
-	FontPath   string  // フォントパス
+	FontData   []byte  // フォント（TrueType）のデータ
 	PageWidth  float64 // ページ幅
 	PageHeight float64 // ページ高さ
 	Margin     float64 // マージン
@@ -90,18 +100,191 @@ type PDFTemplate struct {
 	時間枠の定義
 */
 type PDFTimeSlot struct {
-	Hour     int    // 時
-	Symbol   string // 表示記号
-	IsAwake  bool   // 覚醒状態か
-	HasEvent bool   // イベントがあるか
+	Hour       int    // 時
+	Minute     int    // 分（0 または 30）
+	Symbol     string // 表示記号
+	MealSymbol string // 食事の表示記号
+	IsAwake    bool   // 覚醒状態か
+	HasEvent   bool   // イベントがあるか
+}
+
+/*
+	PDFの睡眠日誌グリッドのレイアウト定数
+	1行を1日とし、正午から翌日正午までの30分枠48列で表示する
+*/
+const (
+	pdfFontFamily       = "gothic"
+	pdfGridStartHour    = 12   // グリッドの開始時刻（夜間の睡眠が1行に収まるよう正午始まり）
+	pdfSlotsPerDay      = 48   // 1日の時間枠数
+	pdfDateColumnWidth  = 58   // 日付列の幅
+	pdfTotalColumnWidth = 40   // 睡眠時間列の幅
+	pdfTitleHeight      = 44   // タイトル部の高さ
+	pdfTickHeight       = 14   // 時刻目盛りの高さ
+	pdfMealBandHeight   = 7    // 食事記号の帯の高さ
+	pdfStateBandHeight  = 12   // 睡眠状態記号の帯の高さ
+	pdfLegendHeight     = 30   // 凡例の高さ
+	pdfNoteLineHeight   = 14   // メモ一覧の1行の高さ
+	pdfLineWidthThin    = 0.2  // 30分枠の罫線の太さ
+	pdfLineWidthBold    = 0.6  // 1時間枠・外枠の罫線の太さ
+	pdfGridLineGray     = 0.6  // 30分枠の罫線の濃さ
+	pdfRowHeight        = pdfMealBandHeight + pdfStateBandHeight
+)
+
+/*
+	フォントにない文字を置き換える文字
+	置き換えない文字は空白として出力される
+*/
+var pdfGlyphSubstitutes = map[rune]rune{
+	'╱': '／', // 床で覚醒
+}
+
+/*
+	フォントにない文字の代わりに出力する文字を取得
+*/
+func pdfGlyphSubstitute(r rune) rune {
+	if sub, ok := pdfGlyphSubstitutes[r]; ok {
+		return sub
+	}
+	return gopdf.DefaultOnGlyphNotFoundSubstitute(r)
+}
+
+/*
+	PDFに表示する文言（言語ごと）
+*/
+type pdfLabels struct {
+	DiaryTitle      string
+	StatisticsTitle string
+	NotesTitle      string
+	Diary           string
+	Name            string
+	Period          string
+	SleepGoal       string
+	Date            string
+	SleepTime       string
+	SleepStates     string
+	Meals           string
+	AverageSleep    string
+	AverageBedTime  string
+	AverageWakeTime string
+	HoursFormat     string
+	Weekdays        [7]string
+	States          map[string]string // 睡眠状態コードごとの表示名（未設定の場合はマスターの名称）
+	MealTypes       map[string]string // 食事種別コードごとの表示名（未設定の場合はマスターの名称）
+}
+
+/*
+	言語ごとの文言
+*/
+var pdfLabelSets = map[string]*pdfLabels{
+	"ja": {
+		DiaryTitle:      "睡眠日誌",
+		StatisticsTitle: "睡眠統計レポート",
+		NotesTitle:      "メモ",
+		Diary:           "日誌",
+		Name:            "氏名",
+		Period:          "期間",
+		SleepGoal:       "目標睡眠時間",
+		Date:            "日付",
+		SleepTime:       "睡眠時間",
+		SleepStates:     "睡眠状態",
+		Meals:           "食事",
+		AverageSleep:    "平均睡眠時間",
+		AverageBedTime:  "平均就床時刻",
+		AverageWakeTime: "平均起床時刻",
+		HoursFormat:     "%.1f時間",
+		Weekdays:        [7]string{"日", "月", "火", "水", "木", "金", "土"},
+	},
+	"en": {
+		DiaryTitle:      "Sleep Diary",
+		StatisticsTitle: "Sleep Statistics Report",
+		NotesTitle:      "Notes",
+		Diary:           "Diary",
+		Name:            "Name",
+		Period:          "Period",
+		SleepGoal:       "Sleep goal",
+		Date:            "Date",
+		SleepTime:       "Sleep",
+		SleepStates:     "States",
+		Meals:           "Meals",
+		AverageSleep:    "Avg. sleep",
+		AverageBedTime:  "Avg. bedtime",
+		AverageWakeTime: "Avg. wake time",
+		HoursFormat:     "%.1fh",
+		Weekdays:        [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		States: map[string]string{
+			StateCodeSleeping:   "Asleep",
+			StateCodeAwakeInBed: "Awake in bed",
+			StateCodeAwake:      "Awake",
+			StateCodeDrowsiness: "Drowsy",
+			StateCodeMedication: "Sleep medication",
+		},
+		MealTypes: map[string]string{
+			MealCodeBreakfast: "Breakfast",
+			MealCodeLunch:     "Lunch",
+			MealCodeDinner:    "Dinner",
+			MealCodeSnack:     "Snack",
+		},
+	},
+}
+
+/*
+	出力言語の文言を取得（未対応の言語は日本語）
+*/
+func pdfLabelsFor(language string) *pdfLabels {
+	if labels, ok := pdfLabelSets[language]; ok {
+		return labels
+	}
+	return pdfLabelSets["ja"]
 }
 
 /*
 	PDFを生成
+	睡眠日誌の期間を1日1行、30分枠48列のグリッドで出力し、行がページに収まらない場合は改ページする
 */
-func (d *PDFExportData) GeneratePDF(_ PDFTemplate) ([]byte, error) {
-	// TODO: 実装
-	return nil, nil
+func (d *PDFExportData) GeneratePDF(tpl PDFTemplate) ([]byte, error) {
+	if err := d.ValidateForPDF(); err != nil {
+		return nil, err
+	}
+
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: gopdf.Rect{W: tpl.PageWidth, H: tpl.PageHeight}})
+	err := pdf.AddTTFFontDataWithOption(pdfFontFamily, tpl.FontData, gopdf.TtfOption{
+		Style:                     gopdf.Regular,
+		OnGlyphNotFoundSubstitute: pdfGlyphSubstitute,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add font: %v", err)
+	}
+
+	r := newPDFGridRenderer(pdf, d, tpl)
+	days := d.diaryDays()
+	rowsPerPage := r.rowsPerPage()
+	pages := (len(days) + rowsPerPage - 1) / rowsPerPage
+
+	for page := 0; page < pages; page++ {
+		from := page * rowsPerPage
+		to := from + rowsPerPage
+		if to > len(days) {
+			to = len(days)
+		}
+
+		pdf.AddPage()
+		if err := r.drawPage(days[from:to], page+1, pages); err != nil {
+			return nil, fmt.Errorf("failed to draw page %d: %v", page+1, err)
+		}
+	}
+
+	if d.Options.IncludeNote {
+		if err := r.drawNotes(d.notes(days)); err != nil {
+			return nil, fmt.Errorf("failed to draw notes: %v", err)
+		}
+	}
+
+	b, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %v", err)
+	}
+	return b, nil
 }
 
 /*
@@ -121,11 +304,48 @@ func (d *PDFExportData) CalculateStatistics() PDFStatistics {
 
 /*
 	時間枠データを整形
+	指定日の正午から翌日正午までの48枠について、睡眠状態と食事の記号を設定する
+	同じ枠に複数の状態記録がある場合は、最後に更新された記録を採用する
 */
-func (d *PDFExportData) FormatTimeSlots(_ time.Time) []PDFTimeSlot {
-	slots := make([]PDFTimeSlot, 48) // 30分単位で48枠
+func (d *PDFExportData) FormatTimeSlots(date time.Time) []PDFTimeSlot {
+	slots := make([]PDFTimeSlot, pdfSlotsPerDay) // 30分単位で48枠
 
-	// TODO: 指定日の時間枠データを整形
+	start := time.Date(date.Year(), date.Month(), date.Day(), pdfGridStartHour, 0, 0, 0, date.Location())
+	index := make(map[string]int, pdfSlotsPerDay)
+	for i := range slots {
+		t := start.Add(time.Duration(i) * SlotDuration)
+		slots[i].Hour = t.Hour()
+		slots[i].Minute = t.Minute()
+		index[pdfSlotKey(t)] = i
+	}
+
+	latest := make(map[int]*SleepRecord, pdfSlotsPerDay)
+	for _, record := range d.Records {
+		i, ok := index[pdfSlotKey(record.SlotStart())]
+		if !ok {
+			continue
+		}
+
+		switch record.RecordType {
+		case RecordTypeState:
+			if current, ok := latest[i]; ok && !record.Modified.After(current.Modified) {
+				continue
+			}
+			state, ok := d.States[record.SleepStateID]
+			if !ok {
+				continue
+			}
+			latest[i] = record
+			slots[i].Symbol = state.DisplaySymbol
+			slots[i].IsAwake = state.StateCode != StateCodeSleeping
+		case RecordTypeMeal:
+			if mealType, ok := d.MealTypes[record.MealTypeID.Int64]; record.MealTypeID.Valid && ok {
+				slots[i].MealSymbol = mealType.DisplaySymbol
+			}
+		case RecordTypeEvent:
+			slots[i].HasEvent = true
+		}
+	}
 
 	return slots
 }
@@ -134,6 +354,378 @@ func (d *PDFExportData) FormatTimeSlots(_ time.Time) []PDFTimeSlot {
 	PDF出力前のデータ検証
 */
 func (d *PDFExportData) ValidateForPDF() error {
-	// TODO: データ検証の実装
+	if d.SleepDiary.StartDate.IsZero() || d.SleepDiary.EndDate.IsZero() {
+		return errors.New("diary period is not set")
+	}
+	if d.SleepDiary.EndDate.Before(d.SleepDiary.StartDate) {
+		return errors.New("diary end date is before start date")
+	}
+	return nil
+}
+
+/*
+	日誌の期間の日付一覧を取得
+*/
+func (d *PDFExportData) diaryDays() []time.Time {
+	start := d.SleepDiary.StartDate
+	end := d.SleepDiary.EndDate
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, start.Location())
+
+	var days []time.Time
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+/*
+	睡眠日ごとの合計睡眠時間（仮眠を含む）を日別集計データから取得
+*/
+func (d *PDFExportData) dailySleepTotals() map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	for _, summary := range d.DailySummaries {
+		if summary == nil || !summary.HasRecords() {
+			continue
+		}
+		totals[summary.SummaryDate] = time.Duration(summary.TotalSleepDuration * float64(time.Hour))
+	}
+	return totals
+}
+
+/*
+	出力期間内のメモ付きの記録を時刻順に取得
+*/
+func (d *PDFExportData) notes(days []time.Time) []*SleepRecord {
+	inPeriod := make(map[string]bool, len(days))
+	for _, day := range days {
+		inPeriod[day.Format("2006-01-02")] = true
+	}
+
+	var notes []*SleepRecord
+	for _, record := range d.Records {
+		if record.Note.Valid && record.Note.String != "" && inPeriod[record.RecordDate.Format("2006-01-02")] {
+			notes = append(notes, record)
+		}
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return pdfSlotKey(notes[i].SlotStart()) < pdfSlotKey(notes[j].SlotStart())
+	})
+	return notes
+}
+
+/*
+	時間枠を照合するためのキー（タイムゾーンの違いを吸収するため壁時計の時刻で比較）
+*/
+func pdfSlotKey(t time.Time) string {
+	return t.Format("2006-01-02 15:04")
+}
+
+/*
+	睡眠日誌グリッドの描画を担う構造体
+*/
+type pdfGridRenderer struct {
+	pdf    *gopdf.GoPdf
+	data   *PDFExportData
+	tpl    PDFTemplate
+	gridX  float64 // グリッド（48枠）の左端
+	cellW  float64 // 1枠の幅
+	totals map[string]time.Duration
+	labels *pdfLabels
+}
+
+/*
+	睡眠日誌グリッドの描画を担う構造体を作成
+*/
+func newPDFGridRenderer(pdf *gopdf.GoPdf, data *PDFExportData, tpl PDFTemplate) *pdfGridRenderer {
+	gridWidth := tpl.PageWidth - 2*tpl.Margin - pdfDateColumnWidth - pdfTotalColumnWidth
+	return &pdfGridRenderer{
+		pdf:    pdf,
+		data:   data,
+		tpl:    tpl,
+		gridX:  tpl.Margin + pdfDateColumnWidth,
+		cellW:  gridWidth / pdfSlotsPerDay,
+		totals: data.dailySleepTotals(),
+		labels: pdfLabelsFor(data.Options.Language),
+	}
+}
+
+/*
+	1ページに収まる行数を計算
+*/
+func (r *pdfGridRenderer) rowsPerPage() int {
+	available := r.tpl.PageHeight - 2*r.tpl.Margin - r.titleHeight() - pdfTickHeight - pdfLegendHeight
+	rows := int(available / pdfRowHeight)
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+/*
+	1ページ分（タイトル、時刻目盛り、日ごとの行、凡例）を描画
+*/
+func (r *pdfGridRenderer) drawPage(days []time.Time, page, pages int) error {
+	y := r.tpl.Margin
+	if err := r.drawTitle(y, page, pages); err != nil {
+		return err
+	}
+	y += r.titleHeight()
+
+	if err := r.drawHourTicks(y); err != nil {
+		return err
+	}
+	y += pdfTickHeight
+
+	top := y
+	for _, day := range days {
+		if err := r.drawRow(y, day); err != nil {
+			return err
+		}
+		y += pdfRowHeight
+	}
+	r.drawGridLines(top, y)
+
+	return r.drawLegend(y + 8)
+}
+
+/*
+	タイトル部の高さを取得（統計情報がある場合は1行追加）
+*/
+func (r *pdfGridRenderer) titleHeight() float64 {
+	if r.data.Statistics != nil {
+		return pdfTitleHeight + 14
+	}
+	return pdfTitleHeight
+}
+
+/*
+	タイトル部を描画
+*/
+func (r *pdfGridRenderer) drawTitle(y float64, page, pages int) error {
+	left := r.tpl.Margin
+	right := r.tpl.PageWidth - r.tpl.Margin
+	width := right - left
+	labels := r.labels
+
+	title := labels.DiaryTitle
+	if r.data.Statistics != nil {
+		title = labels.StatisticsTitle
+	}
+	if err := r.text(left, y, width, 18, title, 16, gopdf.Left|gopdf.Middle); err != nil {
+		return err
+	}
+	if err := r.text(left, y, width, 18, fmt.Sprintf("%d / %d", page, pages), 9, gopdf.Right|gopdf.Middle); err != nil {
+		return err
+	}
+
+	diary := r.data.SleepDiary
+	info := fmt.Sprintf("%s: %s　　%s: %s 〜 %s",
+		labels.Name, r.data.User.DisplayName,
+		labels.Period, diary.StartDate.Format("2006/01/02"), diary.EndDate.Format("2006/01/02"))
+	if diary.DiaryName != "" {
+		info = fmt.Sprintf("%s: %s　　%s", labels.Diary, diary.DiaryName, info)
+	}
+	if r.data.Preferences.SleepGoalHours > 0 {
+		goal := fmt.Sprintf(labels.HoursFormat, float64(r.data.Preferences.SleepGoalHours))
+		info = fmt.Sprintf("%s　　%s: %s", info, labels.SleepGoal, goal)
+	}
+	if err := r.text(left, y+22, width, 14, info, 10, gopdf.Left|gopdf.Middle); err != nil {
+		return err
+	}
+
+	if stats := r.data.Statistics; stats != nil {
+		summary := fmt.Sprintf("%s: %s　　%s: %s　　%s: %s",
+			labels.AverageSleep, fmt.Sprintf(labels.HoursFormat, stats.AverageDuration),
+			labels.AverageBedTime, pdfOrDash(stats.AverageBedTime),
+			labels.AverageWakeTime, pdfOrDash(stats.AverageWakeTime))
+		if err := r.text(left, y+36, width, 14, summary, 10, gopdf.Left|gopdf.Middle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	時刻目盛りを描画（1時間ごとに時刻を表示）
+*/
+func (r *pdfGridRenderer) drawHourTicks(y float64) error {
+	if err := r.text(r.tpl.Margin, y, pdfDateColumnWidth, pdfTickHeight, r.labels.Date, 8, gopdf.Center|gopdf.Middle); err != nil {
+		return err
+	}
+	if err := r.text(r.gridX+r.cellW*pdfSlotsPerDay, y, pdfTotalColumnWidth, pdfTickHeight, r.labels.SleepTime, 7, gopdf.Center|gopdf.Middle); err != nil {
+		return err
+	}
+
+	for i := 0; i <= pdfSlotsPerDay; i += 2 {
+		hour := (pdfGridStartHour + i/2) % 24
+		x := r.gridX + float64(i)*r.cellW
+		if err := r.text(x-r.cellW, y, 2*r.cellW, pdfTickHeight, fmt.Sprintf("%d", hour), 7, gopdf.Center|gopdf.Middle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	1日分の行を描画
+*/
+func (r *pdfGridRenderer) drawRow(y float64, day time.Time) error {
+	label := fmt.Sprintf("%s(%s)", day.Format("01/02"), r.labels.Weekdays[day.Weekday()])
+	if err := r.text(r.tpl.Margin, y, pdfDateColumnWidth, pdfRowHeight, label, 8, gopdf.Center|gopdf.Middle); err != nil {
+		return err
+	}
+
+	for i, slot := range r.data.FormatTimeSlots(day) {
+		x := r.gridX + float64(i)*r.cellW
+		if slot.MealSymbol != "" {
+			if err := r.text(x, y, r.cellW, pdfMealBandHeight, slot.MealSymbol, 5, gopdf.Center|gopdf.Middle); err != nil {
+				return err
+			}
+		}
+		if slot.Symbol != "" {
+			if err := r.text(x, y+pdfMealBandHeight, r.cellW, pdfStateBandHeight, slot.Symbol, 9, gopdf.Center|gopdf.Middle); err != nil {
+				return err
+			}
+		}
+	}
+
+	if total, ok := r.totals[day.Format("2006-01-02")]; ok {
+		x := r.gridX + r.cellW*pdfSlotsPerDay
+		if err := r.text(x, y, pdfTotalColumnWidth, pdfRowHeight, fmt.Sprintf("%.1fh", total.Hours()), 8, gopdf.Center|gopdf.Middle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	グリッドの罫線を描画
+*/
+func (r *pdfGridRenderer) drawGridLines(top, bottom float64) {
+	left := r.tpl.Margin
+	gridRight := r.gridX + r.cellW*pdfSlotsPerDay
+	right := gridRight + pdfTotalColumnWidth
+
+	// 30分枠の縦線と食事帯の区切り
+	r.pdf.SetLineWidth(pdfLineWidthThin)
+	r.pdf.SetGrayStroke(pdfGridLineGray)
+	for i := 1; i < pdfSlotsPerDay; i += 2 {
+		x := r.gridX + float64(i)*r.cellW
+		r.pdf.Line(x, top, x, bottom)
+	}
+	for y := top; y < bottom; y += pdfRowHeight {
+		r.pdf.Line(r.gridX, y+pdfMealBandHeight, gridRight, y+pdfMealBandHeight)
+	}
+
+	// 1時間枠の縦線と日ごとの横線
+	r.pdf.SetLineWidth(pdfLineWidthBold)
+	r.pdf.SetGrayStroke(0)
+	for i := 0; i <= pdfSlotsPerDay; i += 2 {
+		x := r.gridX + float64(i)*r.cellW
+		r.pdf.Line(x, top, x, bottom)
+	}
+	for y := top; y <= bottom+0.01; y += pdfRowHeight {
+		r.pdf.Line(left, y, right, y)
+	}
+	r.pdf.Line(left, top, left, bottom)
+	r.pdf.Line(right, top, right, bottom)
+}
+
+/*
+	凡例を描画（睡眠状態と食事種別の記号）
+*/
+func (r *pdfGridRenderer) drawLegend(y float64) error {
+	x := r.tpl.Margin
+	if err := r.text(x, y, 50, 10, r.labels.SleepStates+":", 8, gopdf.Left|gopdf.Middle); err != nil {
+		return err
+	}
+	x += 50
+	for _, state := range DefaultSleepStates() {
+		name := state.StateName
+		if translated, ok := r.labels.States[state.StateCode]; ok {
+			name = translated
+		}
+		label := fmt.Sprintf("%s %s", state.DisplaySymbol, name)
+		if err := r.text(x, y, 70, 10, label, 8, gopdf.Left|gopdf.Middle); err != nil {
+			return err
+		}
+		x += 70
+	}
+
+	x = r.tpl.Margin
+	y += 12
+	if err := r.text(x, y, 50, 10, r.labels.Meals+":", 8, gopdf.Left|gopdf.Middle); err != nil {
+		return err
+	}
+	x += 50
+	for _, mealType := range DefaultMealTypes() {
+		name := mealType.TypeName
+		if translated, ok := r.labels.MealTypes[mealType.TypeCode]; ok {
+			name = translated
+		}
+		label := fmt.Sprintf("%s %s", mealType.DisplaySymbol, name)
+		if err := r.text(x, y, 70, 10, label, 8, gopdf.Left|gopdf.Middle); err != nil {
+			return err
+		}
+		x += 70
+	}
+	return nil
+}
+
+/*
+	メモの一覧を描画（グリッドの後に改ページして出力）
+*/
+func (r *pdfGridRenderer) drawNotes(notes []*SleepRecord) error {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	left := r.tpl.Margin
+	width := r.tpl.PageWidth - 2*r.tpl.Margin
+	bottom := r.tpl.PageHeight - r.tpl.Margin
+
+	y := bottom
+	for _, note := range notes {
+		if y+pdfNoteLineHeight > bottom {
+			r.pdf.AddPage()
+			y = r.tpl.Margin
+			if err := r.text(left, y, width, 18, r.labels.NotesTitle, 14, gopdf.Left|gopdf.Middle); err != nil {
+				return err
+			}
+			y += 24
+		}
+
+		slot := note.SlotStart()
+		when := fmt.Sprintf("%s(%s) %s", slot.Format("2006/01/02"), r.labels.Weekdays[slot.Weekday()], slot.Format("15:04"))
+		if err := r.text(left, y, 110, pdfNoteLineHeight, when, 9, gopdf.Left|gopdf.Middle); err != nil {
+			return err
+		}
+		if err := r.text(left+110, y, width-110, pdfNoteLineHeight, note.Note.String, 9, gopdf.Left|gopdf.Middle); err != nil {
+			return err
+		}
+		y += pdfNoteLineHeight
+	}
 	return nil
 }
+
+/*
+	空の場合は"-"を返す
+*/
+func pdfOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+/*
+	指定した矩形内に文字列を描画
+*/
+func (r *pdfGridRenderer) text(x, y, w, h float64, s string, size float64, align int) error {
+	if err := r.pdf.SetFont(pdfFontFamily, "", size); err != nil {
+		return err
+	}
+	r.pdf.SetXY(x, y)
+	return r.pdf.CellWithOption(&gopdf.Rect{W: w, H: h}, s, gopdf.CellOption{Align: align})
+}
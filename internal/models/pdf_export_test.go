@@ -0,0 +1,65 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/assets"
+	"github.com/signintech/gopdf/fontmaker/core"
+)
+
+// PDFに出力する記号と文言が、埋め込みのフォント（置き換えを含む）で表示できることを確認
+func TestPDFFontCoversLabels(t *testing.T) {
+	var parser core.TTFParser
+	if err := parser.ParseFontData(assets.GothicFont); err != nil {
+		t.Fatalf("failed to parse font: %v", err)
+	}
+	chars := parser.Chars()
+
+	texts := map[string]string{}
+	for _, state := range DefaultSleepStates() {
+		texts["state "+state.StateCode] = state.DisplaySymbol + state.StateName
+	}
+	for _, mealType := range DefaultMealTypes() {
+		texts["meal "+mealType.TypeCode] = mealType.DisplaySymbol + mealType.TypeName
+	}
+	for language, labels := range pdfLabelSets {
+		collectStrings(reflect.ValueOf(*labels), func(s string) {
+			texts[language+" "+s] = s
+		})
+	}
+
+	for name, text := range texts {
+		for _, r := range text {
+			if _, ok := chars[int(r)]; ok {
+				continue
+			}
+			if sub, ok := pdfGlyphSubstitutes[r]; ok {
+				if _, ok := chars[int(sub)]; ok {
+					continue
+				}
+			}
+			t.Errorf("%s: font has no glyph for %q in %q", name, r, text)
+		}
+	}
+}
+
+// 構造体・配列・マップに含まれる文字列をすべて取り出す
+func collectStrings(v reflect.Value, fn func(string)) {
+	switch v.Kind() {
+	case reflect.String:
+		fn(v.String())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			collectStrings(v.Field(i), fn)
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectStrings(v.Index(i), fn)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			collectStrings(v.MapIndex(key), fn)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// internal/models/personal_access_token.go
+// personal_access_tokenは、APIで利用するパーソナルアクセストークンを管理する構造体を提供します。
+
+// Package models provides data models for the application.
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+/*
+	パーソナルアクセストークンの権限（スコープ）
+	write は read を含む
+*/
+const (
+	AccessTokenScopeRead  = "read"  // データの参照
+	AccessTokenScopeWrite = "write" // データの作成・更新・削除
+)
+
+/*
+	パーソナルアクセストークンの権限の一覧
+*/
+func AccessTokenScopes() []string {
+	return []string{AccessTokenScopeRead, AccessTokenScopeWrite}
+}
+
+/*
+	パーソナルアクセストークンを管理する構造体
+	トークンそのものは保存せず、SHA-256のハッシュ値と、一覧での識別用にトークンの先頭部分（TokenPrefix）のみを保存する
+	Scopes は権限をスペース区切りで保持し、Expires が未設定の場合は無期限とする
+*/
+type PersonalAccessToken struct {
+	ID          int64        `db:"id"`
+	UserID      int64        `db:"user_id"`
+	Name        string       `db:"name"`
+	TokenPrefix string       `db:"token_prefix"`
+	TokenHash   string       `db:"token_hash"`
+	Scopes      string       `db:"scopes"`
+	LastUsed    sql.NullTime `db:"last_used"`
+	Expires     sql.NullTime `db:"expires"`
+	Revoked     sql.NullTime `db:"revoked"`
+	Created     time.Time    `db:"created"`
+}
+
+/*
+	権限の一覧を取得
+*/
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+/*
+	指定した権限を持つかチェック（write は read を含む）
+*/
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope || (s == AccessTokenScopeWrite && scope == AccessTokenScopeRead) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	トークンが有効期限切れかチェック
+*/
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.Expires.Valid && !now.Before(t.Expires.Time)
+}
+
+/*
+	トークンが無効化されているかチェック
+*/
+func (t *PersonalAccessToken) IsRevoked() bool {
+	return t.Revoked.Valid
+}
+
+/*
+	トークンが利用可能かチェック
+*/
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return !t.IsRevoked() && !t.IsExpired(now)
+}
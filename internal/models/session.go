@@ -0,0 +1,25 @@
+// internal/models/session.go
+// sessionは、ログインセッションを管理する構造体を提供します。
+
+// Package models provides data models for the application.
+package models
+
+import "time"
+
+/*
+	ログインセッションを管理する構造体
+*/
+type Session struct {
+	ID       string    `db:"id"`
+	UserID   int64     `db:"user_id"`
+	Expires  time.Time `db:"expires"`
+	Created  time.Time `db:"created"`
+	Modified time.Time `db:"modified"`
+}
+
+/*
+	セッションが有効期限切れかチェック
+*/
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.Expires)
+}
@@ -9,6 +9,15 @@ import (
 	"time"
 )
 
+/*
+	睡眠日誌の状態
+*/
+const (
+	DiaryStatusUpcoming = "upcoming" // 開始前
+	DiaryStatusActive   = "active"   // 記録中（期間に今日を含む）
+	DiaryStatusClosed   = "closed"   // 終了
+)
+
 /*
 	睡眠日誌の基本情報を管理する構造体
 */
@@ -38,3 +47,34 @@ func (d *SleepDiary) Validate() error {
 func (d *SleepDiary) CalculateDuration() int {
 	return int(d.EndDate.Sub(d.StartDate).Hours() / 24)
 }
+
+/*
+	睡眠日誌の期間の日数（開始日と終了日を含む）
+*/
+func (d *SleepDiary) TotalDays() int {
+	return d.CalculateDuration() + 1
+}
+
+/*
+	睡眠日誌の期間に日付が含まれるか
+	タイムゾーンの影響を受けないよう、日付の文字列で比較する
+*/
+func (d *SleepDiary) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return d.StartDate.Format("2006-01-02") <= day && day <= d.EndDate.Format("2006-01-02")
+}
+
+/*
+	今日の日付から睡眠日誌の状態を取得
+*/
+func (d *SleepDiary) Status(today time.Time) string {
+	day := today.Format("2006-01-02")
+	switch {
+	case day < d.StartDate.Format("2006-01-02"):
+		return DiaryStatusUpcoming
+	case day > d.EndDate.Format("2006-01-02"):
+		return DiaryStatusClosed
+	default:
+		return DiaryStatusActive
+	}
+}
@@ -0,0 +1,254 @@
+// internal/models/sleep_episode.go
+// sleep_episodeは、30分単位の睡眠記録から再構成した一晩分の睡眠（睡眠エピソード）を提供します。
+
+// Package models provides data models for the application.
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+/*
+	睡眠記録の時間枠の長さ
+*/
+const SlotDuration = 30 * time.Minute
+
+/*
+	分割睡眠として同じエピソードにまとめる離床時間の上限
+*/
+const EpisodeMergeGap = 2 * time.Hour
+
+/*
+	日中の短い睡眠を仮眠とみなす総睡眠時間の上限
+*/
+const NapMaxDuration = 3 * time.Hour
+
+/*
+	睡眠日の区切り時刻
+	就床時刻からこの時間を引いた日付を睡眠日とする（正午を境に前日の夜として扱う）
+*/
+const sleepDayOffset = 12 * time.Hour
+
+/*
+	一晩分（または仮眠1回分）の睡眠を表す構造体
+*/
+type SleepEpisode struct {
+	Date                time.Time     // 睡眠日（就床した夜の日付）
+	BedTime             time.Time     // 就床時刻
+	SleepOnset          time.Time     // 入眠時刻
+	FinalWake           time.Time     // 最終覚醒時刻
+	OutOfBed            time.Time     // 離床時刻
+	TimeInBed           time.Duration // 床上時間
+	TotalSleepTime      time.Duration // 総睡眠時間
+	SleepLatency        time.Duration // 入眠潜時
+	WakeAfterSleepOnset time.Duration // 中途覚醒時間（WASO）
+	Awakenings          int           // 中途覚醒回数
+	Segments            int           // 睡眠の分割数
+	IsNap               bool          // 仮眠かどうか
+}
+
+/*
+	睡眠効率（総睡眠時間 / 床上時間）を百分率で計算
+*/
+func (e *SleepEpisode) SleepEfficiency() float64 {
+	if e.TimeInBed <= 0 {
+		return 0
+	}
+	return float64(e.TotalSleepTime) / float64(e.TimeInBed) * 100
+}
+
+/*
+	時間枠1つ分の状態
+*/
+type slotState struct {
+	at       time.Time
+	sleeping bool
+	inBed    bool
+}
+
+/*
+	睡眠記録から睡眠エピソードを再構成する
+	STATE種別の記録のみを対象とし、日付をまたぐ睡眠・分割睡眠・仮眠を扱う。
+	記録のない時間枠は離床中の覚醒として扱う。
+*/
+func BuildSleepEpisodes(records []*SleepRecord, states map[int64]SleepState) []*SleepEpisode {
+	slots := collectSlotStates(records, states)
+	if len(slots) == 0 {
+		return nil
+	}
+
+	// 床上の連続区間（バウト）に分割
+	var bouts [][]slotState
+	var current []slotState
+	for _, slot := range slots {
+		if !slot.inBed {
+			if len(current) > 0 {
+				bouts = append(bouts, current)
+				current = nil
+			}
+			continue
+		}
+		if len(current) > 0 && slot.at.Sub(current[len(current)-1].at) != SlotDuration {
+			bouts = append(bouts, current)
+			current = nil
+		}
+		current = append(current, slot)
+	}
+	if len(current) > 0 {
+		bouts = append(bouts, current)
+	}
+
+	// 離床時間が短いバウトは分割睡眠として同じエピソードにまとめる
+	var groups [][][]slotState
+	for _, bout := range bouts {
+		if n := len(groups); n > 0 {
+			last := groups[n-1]
+			lastBout := last[len(last)-1]
+			gap := bout[0].at.Sub(lastBout[len(lastBout)-1].at.Add(SlotDuration))
+			if gap <= EpisodeMergeGap {
+				groups[n-1] = append(last, bout)
+				continue
+			}
+		}
+		groups = append(groups, [][]slotState{bout})
+	}
+
+	var episodes []*SleepEpisode
+	for _, group := range groups {
+		if episode := newSleepEpisode(group); episode != nil {
+			episodes = append(episodes, episode)
+		}
+	}
+
+	markNaps(episodes)
+
+	return episodes
+}
+
+/*
+	STATE種別の記録を時刻順の時間枠に変換
+	同じ時間枠に複数の記録がある場合は、後に更新された記録を優先する
+*/
+func collectSlotStates(records []*SleepRecord, states map[int64]SleepState) []slotState {
+	latest := make(map[time.Time]*SleepRecord)
+	for _, record := range records {
+		if record == nil || record.RecordType != RecordTypeState || record.Deleted.Valid {
+			continue
+		}
+		at := record.SlotStart()
+		if prev, ok := latest[at]; ok && prev.Modified.After(record.Modified) {
+			continue
+		}
+		latest[at] = record
+	}
+
+	slots := make([]slotState, 0, len(latest))
+	for at, record := range latest {
+		code := states[record.SleepStateID].StateCode
+		slots = append(slots, slotState{
+			at:       at,
+			sleeping: code == StateCodeSleeping,
+			inBed:    code == StateCodeSleeping || code == StateCodeAwakeInBed,
+		})
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].at.Before(slots[j].at)
+	})
+
+	return slots
+}
+
+/*
+	バウトのまとまりから睡眠エピソードを作成
+	睡眠中の時間枠を1つも含まない場合はnilを返す
+	床上時間・入眠潜時・中途覚醒時間は床上の時間枠のみで数え、分割睡眠の間の離床時間は含めない
+	（床上時間 = 入眠潜時 + 総睡眠時間 + 中途覚醒時間 + 最終覚醒から離床までの時間）
+*/
+func newSleepEpisode(bouts [][]slotState) *SleepEpisode {
+	var first, last *slotState
+	var sleepSlots int
+	var inBedSlots int
+	for i := range bouts {
+		for j := range bouts[i] {
+			slot := &bouts[i][j]
+			inBedSlots++
+			if !slot.sleeping {
+				continue
+			}
+			sleepSlots++
+			if first == nil {
+				first = slot
+			}
+			last = slot
+		}
+	}
+	if first == nil {
+		return nil
+	}
+
+	// 入眠前と、入眠から最終覚醒までの床上で覚醒していた時間枠を数える
+	var latencySlots, wasoSlots int
+	for _, bout := range bouts {
+		for _, slot := range bout {
+			switch {
+			case slot.sleeping:
+			case slot.at.Before(first.at):
+				latencySlots++
+			case slot.at.Before(last.at):
+				wasoSlots++
+			}
+		}
+	}
+
+	lastBout := bouts[len(bouts)-1]
+	episode := &SleepEpisode{
+		BedTime:        bouts[0][0].at,
+		SleepOnset:     first.at,
+		FinalWake:      last.at.Add(SlotDuration),
+		OutOfBed:       lastBout[len(lastBout)-1].at.Add(SlotDuration),
+		TimeInBed:      time.Duration(inBedSlots) * SlotDuration,
+		TotalSleepTime: time.Duration(sleepSlots) * SlotDuration,
+	}
+	episode.SleepLatency = time.Duration(latencySlots) * SlotDuration
+	episode.WakeAfterSleepOnset = time.Duration(wasoSlots) * SlotDuration
+
+	bedTime := episode.BedTime.Add(-sleepDayOffset)
+	episode.Date = time.Date(bedTime.Year(), bedTime.Month(), bedTime.Day(), 0, 0, 0, 0, bedTime.Location())
+
+	// 入眠から最終覚醒までの睡眠区間数と中途覚醒回数を数える
+	var prev time.Time
+	for _, bout := range bouts {
+		for _, slot := range bout {
+			if !slot.sleeping || slot.at.Before(episode.SleepOnset) {
+				continue
+			}
+			if prev.IsZero() || slot.at.Sub(prev) != SlotDuration {
+				episode.Segments++
+			}
+			prev = slot.at
+		}
+	}
+	episode.Awakenings = episode.Segments - 1
+
+	return episode
+}
+
+/*
+	同じ睡眠日の中で総睡眠時間が最長のエピソードを主睡眠とし、それ以外を仮眠とする
+	ただし、日中（9時〜18時）に入眠した短い睡眠は最長であっても仮眠とする
+*/
+func markNaps(episodes []*SleepEpisode) {
+	main := make(map[time.Time]*SleepEpisode)
+	for _, episode := range episodes {
+		if current, ok := main[episode.Date]; !ok || episode.TotalSleepTime > current.TotalSleepTime {
+			main[episode.Date] = episode
+		}
+	}
+	for _, episode := range episodes {
+		hour := episode.SleepOnset.Hour()
+		daytime := hour >= 9 && hour < 18
+		episode.IsNap = main[episode.Date] != episode || (daytime && episode.TotalSleepTime < NapMaxDuration)
+	}
+}
@@ -0,0 +1,314 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// 睡眠状態のマスター（IDは初期データの並び順）
+func testSleepStates() (map[int64]SleepState, map[string]int64) {
+	states := make(map[int64]SleepState)
+	ids := make(map[string]int64)
+	for i, state := range DefaultSleepStates() {
+		state.ID = int64(i + 1)
+		states[state.ID] = state
+		ids[state.StateCode] = state.ID
+	}
+	return states, ids
+}
+
+// 時間帯の睡眠記録
+type testSpan struct {
+	from, to string // "2006-01-02 15:04"（to は含まない）
+	code     string
+}
+
+// 時間帯ごとの睡眠状態から30分ごとの睡眠記録を作成
+func testRecords(t *testing.T, ids map[string]int64, spans ...testSpan) []*SleepRecord {
+	t.Helper()
+	var records []*SleepRecord
+	for _, span := range spans {
+		from, err := time.Parse("2006-01-02 15:04", span.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, err := time.Parse("2006-01-02 15:04", span.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for at := from; at.Before(to); at = at.Add(SlotDuration) {
+			records = append(records, &SleepRecord{
+				SleepStateID: ids[span.code],
+				RecordDate:   time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC),
+				TimeSlot:     time.Date(0, 1, 1, at.Hour(), at.Minute(), 0, 0, time.UTC),
+				RecordType:   RecordTypeState,
+			})
+		}
+	}
+	return records
+}
+
+// 期待する睡眠エピソード
+type wantEpisode struct {
+	date       string
+	bed        string // "01-02 15:04"
+	onset      string
+	finalWake  string
+	outOfBed   string
+	inBed      time.Duration
+	sleep      time.Duration
+	latency    time.Duration
+	waso       time.Duration
+	awakenings int
+	nap        bool
+}
+
+func TestBuildSleepEpisodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		spans []testSpan
+		want  []wantEpisode
+	}{
+		{
+			name: "日付をまたぐ夜間の睡眠",
+			spans: []testSpan{
+				{"2024-04-01 22:30", "2024-04-01 23:00", StateCodeAwakeInBed},
+				{"2024-04-01 23:00", "2024-04-02 06:30", StateCodeSleeping},
+				{"2024-04-02 06:30", "2024-04-02 07:00", StateCodeAwakeInBed},
+			},
+			want: []wantEpisode{{
+				date: "2024-04-01", bed: "04-01 22:30", onset: "04-01 23:00", finalWake: "04-02 06:30", outOfBed: "04-02 07:00",
+				inBed: 8*time.Hour + 30*time.Minute, sleep: 7*time.Hour + 30*time.Minute, latency: 30 * time.Minute,
+			}},
+		},
+		{
+			name: "床上の中途覚醒",
+			spans: []testSpan{
+				{"2024-04-01 23:00", "2024-04-02 02:00", StateCodeSleeping},
+				{"2024-04-02 02:00", "2024-04-02 03:00", StateCodeAwakeInBed},
+				{"2024-04-02 03:00", "2024-04-02 06:00", StateCodeSleeping},
+			},
+			want: []wantEpisode{{
+				date: "2024-04-01", bed: "04-01 23:00", onset: "04-01 23:00", finalWake: "04-02 06:00", outOfBed: "04-02 06:00",
+				inBed: 7 * time.Hour, sleep: 6 * time.Hour, waso: time.Hour, awakenings: 1,
+			}},
+		},
+		{
+			name: "離床を挟む分割睡眠（離床時間は中途覚醒時間に含めない）",
+			spans: []testSpan{
+				{"2024-04-01 23:00", "2024-04-02 02:00", StateCodeSleeping},
+				{"2024-04-02 02:00", "2024-04-02 02:30", StateCodeAwakeInBed},
+				{"2024-04-02 02:30", "2024-04-02 04:30", StateCodeAwake},
+				{"2024-04-02 04:30", "2024-04-02 07:00", StateCodeSleeping},
+			},
+			want: []wantEpisode{{
+				date: "2024-04-01", bed: "04-01 23:00", onset: "04-01 23:00", finalWake: "04-02 07:00", outOfBed: "04-02 07:00",
+				inBed: 6 * time.Hour, sleep: 5*time.Hour + 30*time.Minute, waso: 30 * time.Minute, awakenings: 1,
+			}},
+		},
+		{
+			name: "入眠前の離床は入眠潜時に含めない",
+			spans: []testSpan{
+				{"2024-04-01 22:00", "2024-04-01 22:30", StateCodeAwakeInBed},
+				{"2024-04-01 22:30", "2024-04-01 23:00", StateCodeAwake},
+				{"2024-04-01 23:00", "2024-04-02 06:00", StateCodeSleeping},
+			},
+			want: []wantEpisode{{
+				date: "2024-04-01", bed: "04-01 22:00", onset: "04-01 23:00", finalWake: "04-02 06:00", outOfBed: "04-02 06:00",
+				inBed: 7*time.Hour + 30*time.Minute, sleep: 7 * time.Hour, latency: 30 * time.Minute,
+			}},
+		},
+		{
+			name: "離床が2時間以内ならまとめる（記録のない時間枠は離床）",
+			spans: []testSpan{
+				{"2024-04-01 22:00", "2024-04-02 00:00", StateCodeSleeping},
+				{"2024-04-02 02:00", "2024-04-02 07:00", StateCodeSleeping},
+			},
+			want: []wantEpisode{{
+				date: "2024-04-01", bed: "04-01 22:00", onset: "04-01 22:00", finalWake: "04-02 07:00", outOfBed: "04-02 07:00",
+				inBed: 7 * time.Hour, sleep: 7 * time.Hour, awakenings: 1,
+			}},
+		},
+		{
+			name: "離床が2時間を超える場合は別のエピソード（短い方は仮眠）",
+			spans: []testSpan{
+				{"2024-04-01 21:00", "2024-04-01 23:00", StateCodeSleeping},
+				{"2024-04-02 01:30", "2024-04-02 07:00", StateCodeSleeping},
+			},
+			want: []wantEpisode{
+				{
+					date: "2024-04-01", bed: "04-01 21:00", onset: "04-01 21:00", finalWake: "04-01 23:00", outOfBed: "04-01 23:00",
+					inBed: 2 * time.Hour, sleep: 2 * time.Hour, nap: true,
+				},
+				{
+					date: "2024-04-01", bed: "04-02 01:30", onset: "04-02 01:30", finalWake: "04-02 07:00", outOfBed: "04-02 07:00",
+					inBed: 5*time.Hour + 30*time.Minute, sleep: 5*time.Hour + 30*time.Minute,
+				},
+			},
+		},
+		{
+			name: "正午より前の睡眠は前日の睡眠日（正午をまたぐ仮眠）",
+			spans: []testSpan{
+				{"2024-04-01 23:00", "2024-04-02 06:00", StateCodeSleeping},
+				{"2024-04-02 11:30", "2024-04-02 12:30", StateCodeSleeping},
+				{"2024-04-02 15:00", "2024-04-02 16:00", StateCodeSleeping},
+			},
+			want: []wantEpisode{
+				{
+					date: "2024-04-01", bed: "04-01 23:00", onset: "04-01 23:00", finalWake: "04-02 06:00", outOfBed: "04-02 06:00",
+					inBed: 7 * time.Hour, sleep: 7 * time.Hour,
+				},
+				{
+					date: "2024-04-01", bed: "04-02 11:30", onset: "04-02 11:30", finalWake: "04-02 12:30", outOfBed: "04-02 12:30",
+					inBed: time.Hour, sleep: time.Hour, nap: true,
+				},
+				{
+					date: "2024-04-02", bed: "04-02 15:00", onset: "04-02 15:00", finalWake: "04-02 16:00", outOfBed: "04-02 16:00",
+					inBed: time.Hour, sleep: time.Hour, nap: true,
+				},
+			},
+		},
+		{
+			name: "夜勤明けの日中の長い睡眠は主睡眠",
+			spans: []testSpan{
+				{"2024-04-02 09:00", "2024-04-02 14:00", StateCodeSleeping},
+			},
+			want: []wantEpisode{{
+				date: "2024-04-01", bed: "04-02 09:00", onset: "04-02 09:00", finalWake: "04-02 14:00", outOfBed: "04-02 14:00",
+				inBed: 5 * time.Hour, sleep: 5 * time.Hour,
+			}},
+		},
+		{
+			name: "夕方の短い睡眠はその日の唯一の睡眠なら主睡眠",
+			spans: []testSpan{
+				{"2024-04-01 19:00", "2024-04-01 20:00", StateCodeSleeping},
+			},
+			want: []wantEpisode{{
+				date: "2024-04-01", bed: "04-01 19:00", onset: "04-01 19:00", finalWake: "04-01 20:00", outOfBed: "04-01 20:00",
+				inBed: time.Hour, sleep: time.Hour,
+			}},
+		},
+		{
+			name: "睡眠を含まない床上の時間はエピソードにしない",
+			spans: []testSpan{
+				{"2024-04-01 23:00", "2024-04-02 01:00", StateCodeAwakeInBed},
+			},
+		},
+	}
+
+	states, ids := testSleepStates()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			episodes := BuildSleepEpisodes(testRecords(t, ids, tt.spans...), states)
+			if len(episodes) != len(tt.want) {
+				t.Fatalf("got %d episodes, want %d", len(episodes), len(tt.want))
+			}
+			for i, want := range tt.want {
+				assertEpisode(t, i, episodes[i], want)
+			}
+		})
+	}
+}
+
+// 睡眠エピソードを比較し、床上時間の内訳が一致することも確認する
+func assertEpisode(t *testing.T, i int, got *SleepEpisode, want wantEpisode) {
+	t.Helper()
+	times := []struct {
+		name string
+		got  time.Time
+		want string
+	}{
+		{"BedTime", got.BedTime, want.bed},
+		{"SleepOnset", got.SleepOnset, want.onset},
+		{"FinalWake", got.FinalWake, want.finalWake},
+		{"OutOfBed", got.OutOfBed, want.outOfBed},
+	}
+	if got.Date.Format("2006-01-02") != want.date {
+		t.Errorf("[%d] Date = %s, want %s", i, got.Date.Format("2006-01-02"), want.date)
+	}
+	for _, tm := range times {
+		if tm.got.Format("01-02 15:04") != tm.want {
+			t.Errorf("[%d] %s = %s, want %s", i, tm.name, tm.got.Format("01-02 15:04"), tm.want)
+		}
+	}
+	durations := []struct {
+		name      string
+		got, want time.Duration
+	}{
+		{"TimeInBed", got.TimeInBed, want.inBed},
+		{"TotalSleepTime", got.TotalSleepTime, want.sleep},
+		{"SleepLatency", got.SleepLatency, want.latency},
+		{"WakeAfterSleepOnset", got.WakeAfterSleepOnset, want.waso},
+	}
+	for _, d := range durations {
+		if d.got != d.want {
+			t.Errorf("[%d] %s = %v, want %v", i, d.name, d.got, d.want)
+		}
+	}
+	if got.Awakenings != want.awakenings || got.Segments != want.awakenings+1 {
+		t.Errorf("[%d] Awakenings = %d, Segments = %d, want %d and %d", i, got.Awakenings, got.Segments, want.awakenings, want.awakenings+1)
+	}
+	if got.IsNap != want.nap {
+		t.Errorf("[%d] IsNap = %v, want %v", i, got.IsNap, want.nap)
+	}
+
+	// 床上時間は、入眠潜時・総睡眠時間・中途覚醒時間・最終覚醒後の床上時間の合計
+	afterWake := got.OutOfBed.Sub(got.FinalWake)
+	if sum := got.SleepLatency + got.TotalSleepTime + got.WakeAfterSleepOnset + afterWake; afterWake >= 0 && sum != got.TimeInBed {
+		t.Errorf("[%d] latency + sleep + WASO + after wake = %v, want TimeInBed %v", i, sum, got.TimeInBed)
+	}
+	if got.SleepEfficiency() > 100 {
+		t.Errorf("[%d] SleepEfficiency = %v, must not exceed 100", i, got.SleepEfficiency())
+	}
+}
+
+func TestMarkNaps(t *testing.T) {
+	day := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	episode := func(onset string, sleep time.Duration) *SleepEpisode {
+		at, _ := time.Parse("2006-01-02 15:04", onset)
+		return &SleepEpisode{Date: day, SleepOnset: at, TotalSleepTime: sleep}
+	}
+
+	tests := []struct {
+		name     string
+		episodes []*SleepEpisode
+		want     []bool
+	}{
+		{
+			name:     "同じ睡眠日の最長の睡眠以外は仮眠",
+			episodes: []*SleepEpisode{episode("2024-04-01 14:00", time.Hour), episode("2024-04-01 23:00", 7*time.Hour), episode("2024-04-02 10:00", 2*time.Hour)},
+			want:     []bool{true, false, true},
+		},
+		{
+			name:     "日中の3時間未満の睡眠は最長でも仮眠",
+			episodes: []*SleepEpisode{episode("2024-04-01 13:00", 2*time.Hour+30*time.Minute)},
+			want:     []bool{true},
+		},
+		{
+			name:     "日中でも3時間以上の睡眠は主睡眠",
+			episodes: []*SleepEpisode{episode("2024-04-01 13:00", 3*time.Hour)},
+			want:     []bool{false},
+		},
+		{
+			name:     "日中の範囲（9時〜18時）の境界",
+			episodes: []*SleepEpisode{episode("2024-04-02 08:30", time.Hour)},
+			want:     []bool{false},
+		},
+		{
+			name:     "18時以降の短い睡眠は主睡眠",
+			episodes: []*SleepEpisode{episode("2024-04-01 18:00", time.Hour)},
+			want:     []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markNaps(tt.episodes)
+			for i, episode := range tt.episodes {
+				if episode.IsNap != tt.want[i] {
+					t.Errorf("[%d] IsNap = %v, want %v", i, episode.IsNap, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -53,6 +53,21 @@ func (r *SleepRecord) Validate() error {
 	return nil
 }
 
+/*
+	作成時に保存する作成日時・更新日時を取得
+	設定されていない場合は now を使う（バックアップからの復元では、元の日時を設定して作成する）
+*/
+func (r *SleepRecord) CreateTimestamps(now time.Time) (created, modified time.Time) {
+	created, modified = r.Created, r.Modified
+	if created.IsZero() {
+		created = now
+	}
+	if modified.IsZero() {
+		modified = created
+	}
+	return created, modified
+}
+
 /*
 	時間枠が30分単位かチェック
 */
@@ -60,3 +75,14 @@ func (r *SleepRecord) IsValidTimeSlot() bool {
 	minutes := r.TimeSlot.Minute()
 	return minutes == 0 || minutes == 30
 }
+
+/*
+	記録日と時間枠から時間枠の開始日時を取得
+*/
+func (r *SleepRecord) SlotStart() time.Time {
+	return time.Date(
+		r.RecordDate.Year(), r.RecordDate.Month(), r.RecordDate.Day(),
+		r.TimeSlot.Hour(), r.TimeSlot.Minute(), 0, 0,
+		r.RecordDate.Location(),
+	)
+}
@@ -9,6 +9,11 @@ import (
 	"time"
 )
 
+/*
+	ユーザーのタイムゾーンの既定値
+*/
+const DefaultTimeZone = "Asia/Tokyo"
+
 /*
 	ユーザー情報を管理する構造体
 */
@@ -27,12 +32,61 @@ type User struct {
 /*
 	通知設定の構造体
 	既存の構造体は残しつつ、新しい要件に合わせて拡張
+	ReminderTime は就寝時刻のリマインダーを送信する時刻（HH:MM、ユーザーのタイムゾーン）で、
+	空の場合は就寝時刻の一定時間前に送信する
 */
 type NotificationSettings struct {
-	EmailEnabled    bool
-	BedtimeReminder bool
-	ReminderTime    string
-	WeeklyReport    bool
+	ID              int64     `db:"id"`
+	UserID          int64     `db:"user_id"`
+	EmailEnabled    bool      `db:"email_enabled"`
+	BedtimeReminder bool      `db:"bedtime_reminder"`
+	ReminderTime    string    `db:"reminder_time"`
+	WeeklyReport    bool      `db:"weekly_report"`
+	Created         time.Time `db:"created"`
+	Modified        time.Time `db:"modified"`
+}
+
+/*
+	リマインダーの送信時刻の形式
+*/
+const ReminderTimeFormat = "15:04"
+
+/*
+	通知設定の既定値
+	週間レポートは、ユーザーが通知設定で有効にした場合のみ送信する
+*/
+func DefaultNotificationSettings(userID int64) *NotificationSettings {
+	return &NotificationSettings{
+		UserID:          userID,
+		EmailEnabled:    true,
+		BedtimeReminder: true,
+		ReminderTime:    "",
+		WeeklyReport:    false,
+	}
+}
+
+/*
+	タイムゾーンを取得（未設定の場合は既定値）
+*/
+func (u *User) TimeZoneOrDefault() string {
+	if u.TimeZone == "" {
+		return DefaultTimeZone
+	}
+	return u.TimeZone
+}
+
+/*
+	タイムゾーンのロケーションを取得
+	不正なタイムゾーン名の場合は既定のタイムゾーンを使う
+*/
+func (u *User) Location() *time.Location {
+	if loc, err := time.LoadLocation(u.TimeZoneOrDefault()); err == nil {
+		return loc
+	}
+	if loc, err := time.LoadLocation(DefaultTimeZone); err == nil {
+		return loc
+	}
+	return time.Local
 }
 
 /*
@@ -0,0 +1,79 @@
+// internal/repository/memory/daily_summary_repository.go
+// daily_summary_repositoryは、日別集計データのインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// DailySummaryRepositoryのインメモリ実装
+type DailySummaryRepository struct {
+	repo *MemoryRepository
+}
+
+// 日付範囲でユーザーの日別集計データを検索（睡眠日の昇順）
+func (r *DailySummaryRepository) GetByDateRange(_ context.Context, userID int64, startDate, endDate string) ([]*models.DailySummary, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var summaries []*models.DailySummary
+	for _, summary := range data.summaries {
+		if summary.UserID == userID && between(summary.SummaryDate, startDate, endDate) {
+			summary := summary
+			summaries = append(summaries, &summary)
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].SummaryDate < summaries[j].SummaryDate
+	})
+
+	return summaries, nil
+}
+
+// 新規日別集計データを作成
+func (r *DailySummaryRepository) Create(_ context.Context, summary *models.DailySummary) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	if _, ok := data.users[summary.UserID]; !ok {
+		return foreignKeyError("users", "id", summary.UserID)
+	}
+	for _, existing := range data.summaries {
+		if existing.UserID == summary.UserID && existing.SummaryDate == summary.SummaryDate {
+			return duplicateEntryError(fmt.Sprintf("%d-%s", summary.UserID, summary.SummaryDate), "user_date_uq")
+		}
+	}
+
+	now := time.Now()
+	summary.ID = data.nextID("daily_summaries")
+	summary.Created = now
+	summary.Modified = now
+	data.summaries[summary.ID] = *summary
+
+	return nil
+}
+
+// 日付範囲のユーザーの日別集計データを削除（削除した件数を返す）
+func (r *DailySummaryRepository) DeleteByDateRange(_ context.Context, userID int64, startDate, endDate string) (int64, error) {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	var count int64
+	for id, summary := range data.summaries {
+		if summary.UserID == userID && between(summary.SummaryDate, startDate, endDate) {
+			delete(data.summaries, id)
+			count++
+		}
+	}
+	return count, nil
+}
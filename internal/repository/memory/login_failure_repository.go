@@ -0,0 +1,72 @@
+// internal/repository/memory/login_failure_repository.go
+// login_failure_repositoryは、ログイン失敗の監査記録のインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// LoginFailureRepositoryのインメモリ実装
+type LoginFailureRepository struct {
+	repo *MemoryRepository
+}
+
+// ログイン失敗を記録
+func (r *LoginFailureRepository) Create(_ context.Context, failure *models.LoginFailure) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	failure.ID = data.nextID("login_failures")
+	failure.Created = time.Now()
+	data.loginFailures[failure.ID] = *failure
+
+	return nil
+}
+
+// メールアドレスで指定日時以降のログイン失敗を検索（新しい順）
+func (r *LoginFailureRepository) GetByEmail(_ context.Context, email string, since time.Time) ([]*models.LoginFailure, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var failures []*models.LoginFailure
+	for _, failure := range data.loginFailures {
+		if failure.Email == email && !failure.Created.Before(since) {
+			failure := failure
+			failures = append(failures, &failure)
+		}
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		if !failures[i].Created.Equal(failures[j].Created) {
+			return failures[i].Created.After(failures[j].Created)
+		}
+		return failures[i].ID > failures[j].ID
+	})
+
+	return failures, nil
+}
+
+// 指定日時より前のログイン失敗を削除し、削除件数を返す
+func (r *LoginFailureRepository) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	var count int64
+	for id, failure := range data.loginFailures {
+		if failure.Created.Before(before) {
+			delete(data.loginFailures, id)
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,134 @@
+// internal/repository/memory/meal_type_repository.go
+// meal_type_repositoryは、食事種別のインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// MealTypeRepositoryのインメモリ実装
+type MealTypeRepository struct {
+	repo *MemoryRepository
+}
+
+// IDで食事種別を検索
+func (r *MealTypeRepository) GetByID(_ context.Context, id int64) (*models.MealType, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	mealType, ok := data.mealTypes[id]
+	if !ok || mealType.Deleted.Valid {
+		return nil, nil
+	}
+	return &mealType, nil
+}
+
+// すべての食事種別を検索（表示順）
+func (r *MealTypeRepository) GetAll(_ context.Context) ([]*models.MealType, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var mealTypes []*models.MealType
+	for _, mealType := range data.mealTypes {
+		if !mealType.Deleted.Valid {
+			mealType := mealType
+			mealTypes = append(mealTypes, &mealType)
+		}
+	}
+	sort.Slice(mealTypes, func(i, j int) bool {
+		if mealTypes[i].DisplayOrder != mealTypes[j].DisplayOrder {
+			return mealTypes[i].DisplayOrder < mealTypes[j].DisplayOrder
+		}
+		return mealTypes[i].ID < mealTypes[j].ID
+	})
+	return mealTypes, nil
+}
+
+// 種別コードで食事種別を検索
+func (r *MealTypeRepository) GetByCode(_ context.Context, code string) (*models.MealType, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	for _, mealType := range data.mealTypes {
+		if mealType.TypeCode == code && !mealType.Deleted.Valid {
+			return &mealType, nil
+		}
+	}
+	return nil, nil
+}
+
+// 新規食事種別を作成
+func (r *MealTypeRepository) Create(_ context.Context, mealType *models.MealType) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	for _, existing := range data.mealTypes {
+		if existing.TypeCode == mealType.TypeCode {
+			return duplicateEntryError(mealType.TypeCode, "meal_types_type_code_UNIQUE")
+		}
+	}
+
+	now := time.Now()
+	mealType.ID = data.nextID("meal_types")
+	mealType.Created = now
+	mealType.Modified = now
+	mealType.Deleted = sql.NullTime{}
+	data.mealTypes[mealType.ID] = *mealType
+
+	return nil
+}
+
+// 食事種別を更新
+func (r *MealTypeRepository) Update(_ context.Context, mealType *models.MealType) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	existing, ok := data.mealTypes[mealType.ID]
+	if !ok || existing.Deleted.Valid {
+		return nil
+	}
+	for id, other := range data.mealTypes {
+		if id != mealType.ID && other.TypeCode == mealType.TypeCode {
+			return duplicateEntryError(mealType.TypeCode, "meal_types_type_code_UNIQUE")
+		}
+	}
+
+	now := time.Now()
+	existing.TypeName = mealType.TypeName
+	existing.TypeCode = mealType.TypeCode
+	existing.DisplaySymbol = mealType.DisplaySymbol
+	existing.DisplayOrder = mealType.DisplayOrder
+	existing.Modified = now
+	data.mealTypes[mealType.ID] = existing
+
+	mealType.Modified = now
+	return nil
+}
+
+// 食事種別を論理削除
+func (r *MealTypeRepository) Delete(_ context.Context, id int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	mealType, ok := data.mealTypes[id]
+	if !ok || mealType.Deleted.Valid {
+		return nil
+	}
+	mealType.Deleted = sql.NullTime{Time: time.Now(), Valid: true}
+	data.mealTypes[id] = mealType
+
+	return nil
+}
@@ -0,0 +1,283 @@
+// internal/repository/memory/memory_repository.go
+// memory_repositoryは、メモリ上で動作するリポジトリの実装を提供します。
+// データベースを用意できないテストや開発環境での利用を想定しています。
+// MySQL実装と同じく、削除は論理削除（deleted の設定）で、検索時は削除済みのデータを除外します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+)
+
+// インメモリリポジトリの実装
+type MemoryRepository struct {
+	data *store
+	txMu *sync.Mutex // トランザクションの直列化用（トランザクション中のリポジトリではnil）
+	inTx bool
+}
+
+// テーブルに相当するデータの集合
+type store struct {
+	mutex         sync.RWMutex
+	users         map[int64]models.User
+	diaries       map[int64]models.SleepDiary
+	records       map[int64]models.SleepRecord
+	states        map[int64]models.SleepState
+	mealTypes     map[int64]models.MealType
+	preferences   map[int64]models.UserSleepPreference
+	sessions      map[string]models.Session
+	loginFailures map[int64]models.LoginFailure
+	resetTokens   map[int64]models.PasswordResetToken
+	deliveries    map[int64]models.NotificationDelivery
+	notifications map[int64]models.NotificationSettings
+	accessTokens  map[int64]models.PersonalAccessToken
+	summaries     map[int64]models.DailySummary
+	lastInsertID  map[string]int64
+}
+
+// 新しいインメモリリポジトリを作成
+// 睡眠状態と食事種別の初期データ（doc/table.md の初期データ）を登録した状態で作成する
+func NewMemoryRepository() repository.Repository {
+	data := newStore()
+
+	now := time.Now()
+	for _, state := range models.DefaultSleepStates() {
+		state.ID = data.nextID("sleep_states")
+		state.Created = now
+		state.Modified = now
+		data.states[state.ID] = state
+	}
+	for _, mealType := range models.DefaultMealTypes() {
+		mealType.ID = data.nextID("meal_types")
+		mealType.Created = now
+		mealType.Modified = now
+		data.mealTypes[mealType.ID] = mealType
+	}
+
+	return &MemoryRepository{
+		data: data,
+		txMu: &sync.Mutex{},
+	}
+}
+
+// 空のデータの集合を作成
+func newStore() *store {
+	return &store{
+		users:         make(map[int64]models.User),
+		diaries:       make(map[int64]models.SleepDiary),
+		records:       make(map[int64]models.SleepRecord),
+		states:        make(map[int64]models.SleepState),
+		mealTypes:     make(map[int64]models.MealType),
+		preferences:   make(map[int64]models.UserSleepPreference),
+		sessions:      make(map[string]models.Session),
+		loginFailures: make(map[int64]models.LoginFailure),
+		resetTokens:   make(map[int64]models.PasswordResetToken),
+		deliveries:    make(map[int64]models.NotificationDelivery),
+		notifications: make(map[int64]models.NotificationSettings),
+		accessTokens:  make(map[int64]models.PersonalAccessToken),
+		summaries:     make(map[int64]models.DailySummary),
+		lastInsertID:  make(map[string]int64),
+	}
+}
+
+// UserRepositoryを取得
+func (r *MemoryRepository) User() repository.UserRepository {
+	return &UserRepository{repo: r}
+}
+
+// SleepDiaryRepositoryを取得
+func (r *MemoryRepository) SleepDiary() repository.SleepDiaryRepository {
+	return &SleepDiaryRepository{repo: r}
+}
+
+// SleepRecordRepositoryを取得
+func (r *MemoryRepository) SleepRecord() repository.SleepRecordRepository {
+	return &SleepRecordRepository{repo: r}
+}
+
+// SleepStateRepositoryを取得
+func (r *MemoryRepository) SleepState() repository.SleepStateRepository {
+	return &SleepStateRepository{repo: r}
+}
+
+// MealTypeRepositoryを取得
+func (r *MemoryRepository) MealType() repository.MealTypeRepository {
+	return &MealTypeRepository{repo: r}
+}
+
+// UserSleepPreferenceRepositoryを取得
+func (r *MemoryRepository) UserSleepPreference() repository.UserSleepPreferenceRepository {
+	return &UserSleepPreferenceRepository{repo: r}
+}
+
+// SessionStoreを取得
+func (r *MemoryRepository) Session() repository.SessionStore {
+	return &SessionStore{repo: r}
+}
+
+// LoginFailureRepositoryを取得
+func (r *MemoryRepository) LoginFailure() repository.LoginFailureRepository {
+	return &LoginFailureRepository{repo: r}
+}
+
+// PasswordResetTokenRepositoryを取得
+func (r *MemoryRepository) PasswordResetToken() repository.PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{repo: r}
+}
+
+// NotificationDeliveryRepositoryを取得
+func (r *MemoryRepository) NotificationDelivery() repository.NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{repo: r}
+}
+
+// NotificationSettingsRepositoryを取得
+func (r *MemoryRepository) NotificationSettings() repository.NotificationSettingsRepository {
+	return &NotificationSettingsRepository{repo: r}
+}
+
+// PersonalAccessTokenRepositoryを取得
+func (r *MemoryRepository) PersonalAccessToken() repository.PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{repo: r}
+}
+
+// DailySummaryRepositoryを取得
+func (r *MemoryRepository) DailySummary() repository.DailySummaryRepository {
+	return &DailySummaryRepository{repo: r}
+}
+
+// トランザクションを実行
+// データの複製に対して処理を行い、成功した場合のみ元のデータを置き換える
+// トランザクションは直列に実行され、すでにトランザクション中の場合は入れ子のトランザクションとして実行し、
+// エラーの場合は入れ子の開始時点までの変更のみを取り消す（SQL実装のセーブポイントに相当）
+// fnがパニックした場合も同様に変更を取り消し、パニックは呼び出し元に伝える
+// テスト用の簡易実装のため、トランザクション中にトランザクション外で行われた更新はコミット時に失われる
+func (r *MemoryRepository) Transaction(ctx context.Context, fn func(repository.Repository) error) error {
+	if r.inTx {
+		snapshot := r.data.clone()
+		defer func() {
+			if p := recover(); p != nil {
+				r.data.replace(snapshot)
+				panic(p)
+			}
+		}()
+		if err := fn(r); err != nil {
+			r.data.replace(snapshot)
+			return err
+		}
+		return nil
+	}
+
+	r.txMu.Lock()
+	defer r.txMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	repo := &MemoryRepository{
+		data: r.data.clone(),
+		inTx: true,
+	}
+
+	if err := fn(repo); err != nil {
+		return err
+	}
+
+	r.data.replace(repo.data)
+	return nil
+}
+
+// データの集合を複製
+func (s *store) clone() *store {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	c := newStore()
+	for k, v := range s.users {
+		c.users[k] = v
+	}
+	for k, v := range s.diaries {
+		c.diaries[k] = v
+	}
+	for k, v := range s.records {
+		c.records[k] = v
+	}
+	for k, v := range s.states {
+		c.states[k] = v
+	}
+	for k, v := range s.mealTypes {
+		c.mealTypes[k] = v
+	}
+	for k, v := range s.preferences {
+		c.preferences[k] = v
+	}
+	for k, v := range s.sessions {
+		c.sessions[k] = v
+	}
+	for k, v := range s.loginFailures {
+		c.loginFailures[k] = v
+	}
+	for k, v := range s.resetTokens {
+		c.resetTokens[k] = v
+	}
+	for k, v := range s.deliveries {
+		c.deliveries[k] = v
+	}
+	for k, v := range s.notifications {
+		c.notifications[k] = v
+	}
+	for k, v := range s.accessTokens {
+		c.accessTokens[k] = v
+	}
+	for k, v := range s.summaries {
+		c.summaries[k] = v
+	}
+	for k, v := range s.lastInsertID {
+		c.lastInsertID[k] = v
+	}
+	return c
+}
+
+// データの集合を別の集合の内容で置き換え（トランザクションのコミット）
+func (s *store) replace(src *store) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.users = src.users
+	s.diaries = src.diaries
+	s.records = src.records
+	s.states = src.states
+	s.mealTypes = src.mealTypes
+	s.preferences = src.preferences
+	s.sessions = src.sessions
+	s.loginFailures = src.loginFailures
+	s.resetTokens = src.resetTokens
+	s.deliveries = src.deliveries
+	s.notifications = src.notifications
+	s.accessTokens = src.accessTokens
+	s.summaries = src.summaries
+	s.lastInsertID = src.lastInsertID
+}
+
+// テーブルごとの連番を採番（AUTO_INCREMENT 相当）
+func (s *store) nextID(table string) int64 {
+	s.lastInsertID[table]++
+	return s.lastInsertID[table]
+}
+
+// 外部キー制約違反のエラー
+func foreignKeyError(table, column string, id int64) error {
+	return fmt.Errorf("foreign key constraint fails: %s.%s = %d does not exist", table, column, id)
+}
+
+// 一意制約違反のエラー
+func duplicateEntryError(value, key string) error {
+	return fmt.Errorf("duplicate entry '%s' for key '%s'", value, key)
+}
@@ -0,0 +1,12 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository { return NewMemoryRepository() })
+}
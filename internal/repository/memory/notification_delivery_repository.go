@@ -0,0 +1,69 @@
+// internal/repository/memory/notification_delivery_repository.go
+// notification_delivery_repositoryは、通知の送信履歴のインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// NotificationDeliveryRepositoryのインメモリ実装
+type NotificationDeliveryRepository struct {
+	repo *MemoryRepository
+}
+
+// 送信済みの通知かチェック
+func (r *NotificationDeliveryRepository) Exists(_ context.Context, userID int64, kind, periodKey string) (bool, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	for _, delivery := range data.deliveries {
+		if delivery.UserID == userID && delivery.Kind == kind && delivery.PeriodKey == periodKey {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// 通知の送信を記録
+func (r *NotificationDeliveryRepository) Create(_ context.Context, delivery *models.NotificationDelivery) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	if _, ok := data.users[delivery.UserID]; !ok {
+		return foreignKeyError("users", "id", delivery.UserID)
+	}
+	for _, existing := range data.deliveries {
+		if existing.UserID == delivery.UserID && existing.Kind == delivery.Kind && existing.PeriodKey == delivery.PeriodKey {
+			return duplicateEntryError(delivery.PeriodKey, "user_kind_period_uq")
+		}
+	}
+
+	delivery.ID = data.nextID("notification_deliveries")
+	delivery.Created = time.Now()
+	data.deliveries[delivery.ID] = *delivery
+
+	return nil
+}
+
+// 指定日時より前の送信履歴を削除し、削除件数を返す
+func (r *NotificationDeliveryRepository) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	var count int64
+	for id, delivery := range data.deliveries {
+		if delivery.Created.Before(before) {
+			delete(data.deliveries, id)
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,93 @@
+// internal/repository/memory/notification_settings_repository.go
+// notification_settings_repositoryは、ユーザーの通知設定のインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// NotificationSettingsRepositoryのインメモリ実装
+type NotificationSettingsRepository struct {
+	repo *MemoryRepository
+}
+
+// ユーザーIDで通知設定を検索
+func (r *NotificationSettingsRepository) GetByUserID(_ context.Context, userID int64) (*models.NotificationSettings, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	for _, settings := range data.notifications {
+		if settings.UserID == userID {
+			return &settings, nil
+		}
+	}
+	return nil, nil
+}
+
+// 新規通知設定を作成
+func (r *NotificationSettingsRepository) Create(_ context.Context, settings *models.NotificationSettings) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	if _, ok := data.users[settings.UserID]; !ok {
+		return foreignKeyError("users", "id", settings.UserID)
+	}
+	for _, existing := range data.notifications {
+		if existing.UserID == settings.UserID {
+			return duplicateEntryError(strconv.FormatInt(settings.UserID, 10), "user_id_uq")
+		}
+	}
+
+	now := time.Now()
+	settings.ID = data.nextID("user_notification_settings")
+	settings.Created = now
+	settings.Modified = now
+	data.notifications[settings.ID] = *settings
+
+	return nil
+}
+
+// 通知設定を更新
+func (r *NotificationSettingsRepository) Update(_ context.Context, settings *models.NotificationSettings) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	for id, existing := range data.notifications {
+		if existing.UserID != settings.UserID {
+			continue
+		}
+		now := time.Now()
+		existing.EmailEnabled = settings.EmailEnabled
+		existing.BedtimeReminder = settings.BedtimeReminder
+		existing.ReminderTime = settings.ReminderTime
+		existing.WeeklyReport = settings.WeeklyReport
+		existing.Modified = now
+		data.notifications[id] = existing
+		settings.Modified = now
+		return nil
+	}
+	return nil
+}
+
+// 通知設定を削除
+func (r *NotificationSettingsRepository) Delete(_ context.Context, userID int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	for id, settings := range data.notifications {
+		if settings.UserID == userID {
+			delete(data.notifications, id)
+		}
+	}
+	return nil
+}
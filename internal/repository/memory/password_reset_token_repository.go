@@ -0,0 +1,83 @@
+// internal/repository/memory/password_reset_token_repository.go
+// password_reset_token_repositoryは、パスワード再設定用トークンのインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// PasswordResetTokenRepositoryのインメモリ実装
+type PasswordResetTokenRepository struct {
+	repo *MemoryRepository
+}
+
+// トークンのハッシュ値で検索
+func (r *PasswordResetTokenRepository) GetByTokenHash(_ context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	for _, token := range data.resetTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
+
+// 新規トークンを作成
+func (r *PasswordResetTokenRepository) Create(_ context.Context, token *models.PasswordResetToken) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	if _, ok := data.users[token.UserID]; !ok {
+		return foreignKeyError("users", "id", token.UserID)
+	}
+	for _, existing := range data.resetTokens {
+		if existing.TokenHash == token.TokenHash {
+			return duplicateEntryError(token.TokenHash, "token_hash_uq")
+		}
+	}
+
+	token.ID = data.nextID("password_reset_tokens")
+	token.Created = time.Now()
+	data.resetTokens[token.ID] = *token
+
+	return nil
+}
+
+// ユーザーの全トークンを削除
+func (r *PasswordResetTokenRepository) DeleteByUserID(_ context.Context, userID int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	for id, token := range data.resetTokens {
+		if token.UserID == userID {
+			delete(data.resetTokens, id)
+		}
+	}
+	return nil
+}
+
+// 有効期限切れのトークンを削除
+func (r *PasswordResetTokenRepository) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	var count int64
+	for id, token := range data.resetTokens {
+		if token.IsExpired(now) {
+			delete(data.resetTokens, id)
+			count++
+		}
+	}
+	return count, nil
+}
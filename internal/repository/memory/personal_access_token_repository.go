@@ -0,0 +1,131 @@
+// internal/repository/memory/personal_access_token_repository.go
+// personal_access_token_repositoryは、パーソナルアクセストークンのインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// PersonalAccessTokenRepositoryのインメモリ実装
+type PersonalAccessTokenRepository struct {
+	repo *MemoryRepository
+}
+
+// IDでトークンを検索
+func (r *PersonalAccessTokenRepository) GetByID(_ context.Context, id int64) (*models.PersonalAccessToken, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	token, ok := data.accessTokens[id]
+	if !ok {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// トークンのハッシュ値で検索
+func (r *PersonalAccessTokenRepository) GetByTokenHash(_ context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	for _, token := range data.accessTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
+
+// ユーザーの全トークンを作成順に取得（無効化したトークンを含む）
+func (r *PersonalAccessTokenRepository) GetByUserID(_ context.Context, userID int64) ([]*models.PersonalAccessToken, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var tokens []*models.PersonalAccessToken
+	for _, token := range data.accessTokens {
+		if token.UserID == userID {
+			token := token
+			tokens = append(tokens, &token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].ID < tokens[j].ID
+	})
+	return tokens, nil
+}
+
+// 新規トークンを作成
+func (r *PersonalAccessTokenRepository) Create(_ context.Context, token *models.PersonalAccessToken) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	if _, ok := data.users[token.UserID]; !ok {
+		return foreignKeyError("users", "id", token.UserID)
+	}
+	for _, existing := range data.accessTokens {
+		if existing.TokenHash == token.TokenHash {
+			return duplicateEntryError(token.TokenHash, "token_hash_uq")
+		}
+	}
+
+	token.ID = data.nextID("personal_access_tokens")
+	token.Created = time.Now()
+	data.accessTokens[token.ID] = *token
+
+	return nil
+}
+
+// 最終利用日時を更新
+func (r *PersonalAccessTokenRepository) UpdateLastUsed(_ context.Context, id int64, lastUsed time.Time) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	token, ok := data.accessTokens[id]
+	if !ok {
+		return nil
+	}
+	token.LastUsed = sql.NullTime{Time: lastUsed, Valid: true}
+	data.accessTokens[id] = token
+	return nil
+}
+
+// トークンを無効化（無効化済みの場合は何もしない）
+func (r *PersonalAccessTokenRepository) Revoke(_ context.Context, id int64, revoked time.Time) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	token, ok := data.accessTokens[id]
+	if !ok || token.Revoked.Valid {
+		return nil
+	}
+	token.Revoked = sql.NullTime{Time: revoked, Valid: true}
+	data.accessTokens[id] = token
+	return nil
+}
+
+// ユーザーの全トークンを削除
+func (r *PersonalAccessTokenRepository) DeleteByUserID(_ context.Context, userID int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	for id, token := range data.accessTokens {
+		if token.UserID == userID {
+			delete(data.accessTokens, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// internal/repository/memory/session_store.go
+// session_storeは、ログインセッションのインメモリストアを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// SessionStoreのインメモリ実装
+type SessionStore struct {
+	repo *MemoryRepository
+}
+
+// IDでセッションを検索
+func (r *SessionStore) GetByID(_ context.Context, id string) (*models.Session, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	session, ok := data.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// 新規セッションを作成
+func (r *SessionStore) Create(_ context.Context, session *models.Session) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	if _, ok := data.users[session.UserID]; !ok {
+		return foreignKeyError("users", "id", session.UserID)
+	}
+
+	now := time.Now()
+	session.Created = now
+	session.Modified = now
+	data.sessions[session.ID] = *session
+
+	return nil
+}
+
+// セッションの有効期限を更新
+func (r *SessionStore) UpdateExpires(_ context.Context, id string, expires time.Time) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	session, ok := data.sessions[id]
+	if !ok {
+		return nil
+	}
+	session.Expires = expires
+	session.Modified = time.Now()
+	data.sessions[id] = session
+
+	return nil
+}
+
+// セッションを削除
+func (r *SessionStore) Delete(_ context.Context, id string) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	delete(data.sessions, id)
+	return nil
+}
+
+// ユーザーの全セッションを削除
+func (r *SessionStore) DeleteByUserID(_ context.Context, userID int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	for id, session := range data.sessions {
+		if session.UserID == userID {
+			delete(data.sessions, id)
+		}
+	}
+	return nil
+}
+
+// 有効期限切れのセッションを削除
+func (r *SessionStore) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	var count int64
+	for id, session := range data.sessions {
+		if session.IsExpired(now) {
+			delete(data.sessions, id)
+			count++
+		}
+	}
+	return count, nil
+}
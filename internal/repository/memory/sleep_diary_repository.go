@@ -0,0 +1,151 @@
+// internal/repository/memory/sleep_diary_repository.go
+// sleep_diary_repositoryは、睡眠日誌のインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// SleepDiaryRepositoryのインメモリ実装
+type SleepDiaryRepository struct {
+	repo *MemoryRepository
+}
+
+// IDで睡眠日誌を検索
+func (r *SleepDiaryRepository) GetByID(_ context.Context, id int64) (*models.SleepDiary, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	diary, ok := data.diaries[id]
+	if !ok || diary.Deleted.Valid {
+		return nil, nil
+	}
+	return &diary, nil
+}
+
+// ユーザーIDで睡眠日誌を検索（開始日の降順）
+func (r *SleepDiaryRepository) GetByUserID(_ context.Context, userID int64) ([]*models.SleepDiary, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var diaries []*models.SleepDiary
+	for _, diary := range data.diaries {
+		if diary.UserID == userID && !diary.Deleted.Valid {
+			diary := diary
+			diaries = append(diaries, &diary)
+		}
+	}
+	sort.Slice(diaries, func(i, j int) bool {
+		if !diaries[i].StartDate.Equal(diaries[j].StartDate) {
+			return diaries[i].StartDate.After(diaries[j].StartDate)
+		}
+		return diaries[i].ID < diaries[j].ID
+	})
+	return diaries, nil
+}
+
+// 日付範囲で睡眠日誌を検索（期間が重なる日誌を開始日の昇順）
+func (r *SleepDiaryRepository) GetByDateRange(_ context.Context, userID int64, startDate, endDate string) ([]*models.SleepDiary, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var diaries []*models.SleepDiary
+	for _, diary := range data.diaries {
+		if diary.UserID != userID || diary.Deleted.Valid {
+			continue
+		}
+		start := formatDate(diary.StartDate)
+		end := formatDate(diary.EndDate)
+		if between(start, startDate, endDate) || between(end, startDate, endDate) ||
+			(start <= startDate && end >= endDate) {
+			diary := diary
+			diaries = append(diaries, &diary)
+		}
+	}
+	sort.Slice(diaries, func(i, j int) bool {
+		if !diaries[i].StartDate.Equal(diaries[j].StartDate) {
+			return diaries[i].StartDate.Before(diaries[j].StartDate)
+		}
+		return diaries[i].ID < diaries[j].ID
+	})
+	return diaries, nil
+}
+
+// 新規睡眠日誌を作成
+func (r *SleepDiaryRepository) Create(_ context.Context, diary *models.SleepDiary) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	if _, ok := data.users[diary.UserID]; !ok {
+		return foreignKeyError("users", "id", diary.UserID)
+	}
+
+	now := time.Now()
+	diary.ID = data.nextID("sleep_diaries")
+	diary.Created = now
+	diary.Modified = now
+	diary.Deleted = sql.NullTime{}
+	data.diaries[diary.ID] = *diary
+
+	return nil
+}
+
+// 睡眠日誌を更新
+func (r *SleepDiaryRepository) Update(_ context.Context, diary *models.SleepDiary) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	existing, ok := data.diaries[diary.ID]
+	if !ok || existing.Deleted.Valid {
+		return nil
+	}
+
+	now := time.Now()
+	existing.StartDate = diary.StartDate
+	existing.EndDate = diary.EndDate
+	existing.DiaryName = diary.DiaryName
+	existing.Note = diary.Note
+	existing.Modified = now
+	data.diaries[diary.ID] = existing
+
+	diary.Modified = now
+	return nil
+}
+
+// 睡眠日誌を論理削除
+func (r *SleepDiaryRepository) Delete(_ context.Context, id int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	diary, ok := data.diaries[id]
+	if !ok || diary.Deleted.Valid {
+		return nil
+	}
+	diary.Deleted = sql.NullTime{Time: time.Now(), Valid: true}
+	data.diaries[id] = diary
+
+	return nil
+}
+
+// 日付を比較用の文字列に変換（MySQLのDATE型の比較に相当）
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// 値が範囲内にあるか（SQLの BETWEEN に相当）
+func between(value, from, to string) bool {
+	return value >= from && value <= to
+}
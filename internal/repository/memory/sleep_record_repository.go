@@ -0,0 +1,195 @@
+// internal/repository/memory/sleep_record_repository.go
+// sleep_record_repositoryは、睡眠記録のインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+)
+
+// SleepRecordRepositoryのインメモリ実装
+type SleepRecordRepository struct {
+	repo *MemoryRepository
+}
+
+// IDで睡眠記録を検索
+func (r *SleepRecordRepository) GetByID(_ context.Context, id int64) (*models.SleepRecord, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	record, ok := data.records[id]
+	if !ok || record.Deleted.Valid {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// 日誌IDで睡眠記録を検索（記録日・時間枠の昇順）
+func (r *SleepRecordRepository) GetByDiaryID(_ context.Context, diaryID int64) ([]*models.SleepRecord, error) {
+	return r.find(func(record models.SleepRecord) bool {
+		return record.SleepDiaryID == diaryID
+	}), nil
+}
+
+// 日付範囲で睡眠記録を検索（記録日・時間枠の昇順）
+func (r *SleepRecordRepository) GetByDateRange(_ context.Context, diaryID int64, startDate, endDate string) ([]*models.SleepRecord, error) {
+	return r.find(func(record models.SleepRecord) bool {
+		return record.SleepDiaryID == diaryID && between(formatDate(record.RecordDate), startDate, endDate)
+	}), nil
+}
+
+// 関連データを含めて睡眠記録を検索
+func (r *SleepRecordRepository) GetWithRelations(_ context.Context, id int64) (*models.SleepRecordWithRelations, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	record, ok := data.records[id]
+	if !ok || record.Deleted.Valid {
+		return nil, nil
+	}
+
+	// MySQL実装のLEFT JOINと同じく、関連データは削除済みでも取得する
+	result := &models.SleepRecordWithRelations{
+		SleepRecord: record,
+		State:       data.states[record.SleepStateID],
+		Diary:       data.diaries[record.SleepDiaryID],
+	}
+	if record.MealTypeID.Valid {
+		if mealType, ok := data.mealTypes[record.MealTypeID.Int64]; ok {
+			result.MealType = &mealType
+		}
+	}
+	return result, nil
+}
+
+// 新規睡眠記録を作成（作成日時・更新日時が設定されている場合はその値で保存する）
+func (r *SleepRecordRepository) Create(_ context.Context, record *models.SleepRecord) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	return r.insert(data, record, time.Now())
+}
+
+// 睡眠記録を更新
+func (r *SleepRecordRepository) Update(_ context.Context, record *models.SleepRecord) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	existing, ok := data.records[record.ID]
+	if !ok || existing.Deleted.Valid {
+		return nil
+	}
+	if err := checkRecordReferences(data, record); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	existing.SleepDiaryID = record.SleepDiaryID
+	existing.SleepStateID = record.SleepStateID
+	existing.RecordDate = record.RecordDate
+	existing.TimeSlot = record.TimeSlot
+	existing.RecordType = record.RecordType
+	existing.MealTypeID = record.MealTypeID
+	existing.Note = record.Note
+	existing.Modified = now
+	data.records[record.ID] = existing
+
+	record.Modified = now
+	return nil
+}
+
+// 睡眠記録を論理削除
+func (r *SleepRecordRepository) Delete(_ context.Context, id int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	record, ok := data.records[id]
+	if !ok || record.Deleted.Valid {
+		return nil
+	}
+	record.Deleted = sql.NullTime{Time: time.Now(), Valid: true}
+	data.records[id] = record
+
+	return nil
+}
+
+// 複数の睡眠記録を一括作成
+// いずれかの作成に失敗した場合は、すべての作成を取り消す
+func (r *SleepRecordRepository) BulkCreate(ctx context.Context, records []*models.SleepRecord) error {
+	return r.repo.Transaction(ctx, func(repo repository.Repository) error {
+		for _, record := range records {
+			if err := repo.SleepRecord().Create(ctx, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 条件に一致する削除されていない睡眠記録を記録日・時間枠の昇順で取得
+func (r *SleepRecordRepository) find(match func(models.SleepRecord) bool) []*models.SleepRecord {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var records []*models.SleepRecord
+	for _, record := range data.records {
+		if !record.Deleted.Valid && match(record) {
+			record := record
+			records = append(records, &record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if da, db := formatDate(a.RecordDate), formatDate(b.RecordDate); da != db {
+			return da < db
+		}
+		if ta, tb := a.TimeSlot.Format("15:04:05"), b.TimeSlot.Format("15:04:05"); ta != tb {
+			return ta < tb
+		}
+		return a.ID < b.ID
+	})
+	return records
+}
+
+// 睡眠記録を登録（呼び出し元でロックを取得していること）
+func (r *SleepRecordRepository) insert(data *store, record *models.SleepRecord, now time.Time) error {
+	if err := checkRecordReferences(data, record); err != nil {
+		return err
+	}
+
+	record.ID = data.nextID("sleep_records")
+	record.Created, record.Modified = record.CreateTimestamps(now)
+	record.Deleted = sql.NullTime{}
+	data.records[record.ID] = *record
+
+	return nil
+}
+
+// 睡眠日誌・睡眠状態・食事種別の外部キー制約を確認
+func checkRecordReferences(data *store, record *models.SleepRecord) error {
+	if _, ok := data.diaries[record.SleepDiaryID]; !ok {
+		return foreignKeyError("sleep_diaries", "id", record.SleepDiaryID)
+	}
+	if _, ok := data.states[record.SleepStateID]; !ok {
+		return foreignKeyError("sleep_states", "id", record.SleepStateID)
+	}
+	if record.MealTypeID.Valid {
+		if _, ok := data.mealTypes[record.MealTypeID.Int64]; !ok {
+			return foreignKeyError("meal_types", "id", record.MealTypeID.Int64)
+		}
+	}
+	return nil
+}
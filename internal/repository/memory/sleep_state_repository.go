@@ -0,0 +1,135 @@
+// internal/repository/memory/sleep_state_repository.go
+// sleep_state_repositoryは、睡眠状態のインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// SleepStateRepositoryのインメモリ実装
+type SleepStateRepository struct {
+	repo *MemoryRepository
+}
+
+// IDで睡眠状態を検索
+func (r *SleepStateRepository) GetByID(_ context.Context, id int64) (*models.SleepState, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	state, ok := data.states[id]
+	if !ok || state.Deleted.Valid {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// すべての睡眠状態を検索（表示順）
+func (r *SleepStateRepository) GetAll(_ context.Context) ([]*models.SleepState, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var states []*models.SleepState
+	for _, state := range data.states {
+		if !state.Deleted.Valid {
+			state := state
+			states = append(states, &state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].DisplayOrder != states[j].DisplayOrder {
+			return states[i].DisplayOrder < states[j].DisplayOrder
+		}
+		return states[i].ID < states[j].ID
+	})
+	return states, nil
+}
+
+// 状態コードで睡眠状態を検索
+func (r *SleepStateRepository) GetByCode(_ context.Context, code string) (*models.SleepState, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	for _, state := range data.states {
+		if state.StateCode == code && !state.Deleted.Valid {
+			return &state, nil
+		}
+	}
+	return nil, nil
+}
+
+// 新規睡眠状態を作成
+func (r *SleepStateRepository) Create(_ context.Context, state *models.SleepState) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	for _, existing := range data.states {
+		if existing.StateCode == state.StateCode {
+			return duplicateEntryError(state.StateCode, "sleep_states_state_code_UNIQUE")
+		}
+	}
+
+	now := time.Now()
+	state.ID = data.nextID("sleep_states")
+	state.Created = now
+	state.Modified = now
+	state.Deleted = sql.NullTime{}
+	data.states[state.ID] = *state
+
+	return nil
+}
+
+// 睡眠状態を更新
+func (r *SleepStateRepository) Update(_ context.Context, state *models.SleepState) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	existing, ok := data.states[state.ID]
+	if !ok || existing.Deleted.Valid {
+		return nil
+	}
+	for id, other := range data.states {
+		if id != state.ID && other.StateCode == state.StateCode {
+			return duplicateEntryError(state.StateCode, "sleep_states_state_code_UNIQUE")
+		}
+	}
+
+	now := time.Now()
+	existing.StateName = state.StateName
+	existing.StateCode = state.StateCode
+	existing.StateDescription = state.StateDescription
+	existing.DisplaySymbol = state.DisplaySymbol
+	existing.DisplayOrder = state.DisplayOrder
+	existing.Modified = now
+	data.states[state.ID] = existing
+
+	state.Modified = now
+	return nil
+}
+
+// 睡眠状態を論理削除
+func (r *SleepStateRepository) Delete(_ context.Context, id int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	state, ok := data.states[id]
+	if !ok || state.Deleted.Valid {
+		return nil
+	}
+	state.Deleted = sql.NullTime{Time: time.Now(), Valid: true}
+	data.states[id] = state
+
+	return nil
+}
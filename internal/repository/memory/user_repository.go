@@ -0,0 +1,155 @@
+// internal/repository/memory/user_repository.go
+// user_repositoryは、ユーザーのインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// UserRepositoryのインメモリ実装
+type UserRepository struct {
+	repo *MemoryRepository
+}
+
+// IDでユーザーを検索
+func (r *UserRepository) GetByID(_ context.Context, id int64) (*models.User, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	user, ok := data.users[id]
+	if !ok || user.Deleted.Valid {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+// メールアドレスでユーザーを検索
+func (r *UserRepository) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	for _, user := range data.users {
+		if user.Email == email && !user.Deleted.Valid {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+// 論理削除されていないすべてのユーザーを取得（ID順）
+func (r *UserRepository) GetAll(_ context.Context) ([]*models.User, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var users []*models.User
+	for _, user := range data.users {
+		if user.Deleted.Valid {
+			continue
+		}
+		user := user
+		users = append(users, &user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	return users, nil
+}
+
+// 新規ユーザーを作成
+func (r *UserRepository) Create(_ context.Context, user *models.User) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	// メールアドレスの一意制約（論理削除済みのユーザーも対象）
+	for _, existing := range data.users {
+		if existing.Email == user.Email {
+			return duplicateEntryError(user.Email, "users_email_UNIQUE")
+		}
+	}
+
+	now := time.Now()
+	user.ID = data.nextID("users")
+	user.TimeZone = user.TimeZoneOrDefault()
+	user.Created = now
+	user.Modified = now
+	user.LastLoginDatetime = sql.NullTime{}
+	user.Deleted = sql.NullTime{}
+	data.users[user.ID] = *user
+
+	return nil
+}
+
+// ユーザー情報を更新
+func (r *UserRepository) Update(_ context.Context, user *models.User) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	existing, ok := data.users[user.ID]
+	if !ok || existing.Deleted.Valid {
+		return nil
+	}
+	for id, other := range data.users {
+		if id != user.ID && other.Email == user.Email {
+			return duplicateEntryError(user.Email, "users_email_UNIQUE")
+		}
+	}
+
+	now := time.Now()
+	existing.Email = user.Email
+	existing.DisplayName = user.DisplayName
+	existing.PasswordHash = user.PasswordHash
+	existing.TimeZone = user.TimeZoneOrDefault()
+	existing.Modified = now
+	data.users[user.ID] = existing
+
+	user.Modified = now
+	return nil
+}
+
+// ユーザーを論理削除
+func (r *UserRepository) Delete(_ context.Context, id int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	user, ok := data.users[id]
+	if !ok || user.Deleted.Valid {
+		return nil
+	}
+	user.Deleted = sql.NullTime{Time: time.Now(), Valid: true}
+	data.users[id] = user
+
+	return nil
+}
+
+// 最終ログイン日時を更新
+func (r *UserRepository) UpdateLastLogin(_ context.Context, id int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	user, ok := data.users[id]
+	if !ok || user.Deleted.Valid {
+		return nil
+	}
+	now := time.Now()
+	user.LastLoginDatetime = sql.NullTime{Time: now, Valid: true}
+	user.Modified = now
+	data.users[id] = user
+
+	return nil
+}
@@ -0,0 +1,137 @@
+// internal/repository/memory/user_sleep_preference_repository.go
+// user_sleep_preference_repositoryは、睡眠設定のインメモリリポジトリを提供します。
+
+// Package memory provides in-memory repository implementations.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// UserSleepPreferenceRepositoryのインメモリ実装
+type UserSleepPreferenceRepository struct {
+	repo *MemoryRepository
+}
+
+// ユーザーIDで睡眠設定を検索
+func (r *UserSleepPreferenceRepository) GetByUserID(_ context.Context, userID int64) (*models.UserSleepPreference, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var found *models.UserSleepPreference
+	for _, pref := range data.preferences {
+		if pref.UserID == userID && !pref.Deleted.Valid && (found == nil || pref.ID < found.ID) {
+			pref := pref
+			found = &pref
+		}
+	}
+	return found, nil
+}
+
+// リマインダーが有効な睡眠設定をすべて取得（削除済みのユーザーは除く、ユーザーID順）
+func (r *UserSleepPreferenceRepository) GetReminderEnabled(_ context.Context) ([]*models.UserSleepPreference, error) {
+	data := r.repo.data
+	data.mutex.RLock()
+	defer data.mutex.RUnlock()
+
+	var prefs []*models.UserSleepPreference
+	for _, pref := range data.preferences {
+		if !pref.IsReminderEnabled || pref.Deleted.Valid {
+			continue
+		}
+		if user, ok := data.users[pref.UserID]; !ok || user.Deleted.Valid {
+			continue
+		}
+		pref := pref
+		prefs = append(prefs, &pref)
+	}
+
+	sort.Slice(prefs, func(i, j int) bool {
+		if prefs[i].UserID != prefs[j].UserID {
+			return prefs[i].UserID < prefs[j].UserID
+		}
+		return prefs[i].ID < prefs[j].ID
+	})
+
+	return prefs, nil
+}
+
+// 新規睡眠設定を作成
+func (r *UserSleepPreferenceRepository) Create(_ context.Context, pref *models.UserSleepPreference) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	if _, ok := data.users[pref.UserID]; !ok {
+		return foreignKeyError("users", "id", pref.UserID)
+	}
+
+	now := time.Now()
+	pref.ID = data.nextID("users_sleep_preferences")
+	pref.Created = now
+	pref.Modified = now
+	pref.Deleted = sql.NullTime{}
+	data.preferences[pref.ID] = *pref
+
+	return nil
+}
+
+// 睡眠設定を更新
+func (r *UserSleepPreferenceRepository) Update(_ context.Context, pref *models.UserSleepPreference) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	existing, ok := data.preferences[pref.ID]
+	if !ok || existing.Deleted.Valid {
+		return nil
+	}
+
+	now := time.Now()
+	existing.PreferredBedtime = pref.PreferredBedtime
+	existing.PreferredWakeupTime = pref.PreferredWakeupTime
+	existing.SleepGoalHours = pref.SleepGoalHours
+	existing.IsReminderEnabled = pref.IsReminderEnabled
+	existing.Modified = now
+	data.preferences[pref.ID] = existing
+
+	pref.Modified = now
+	return nil
+}
+
+// 睡眠設定を論理削除
+func (r *UserSleepPreferenceRepository) Delete(_ context.Context, userID int64) error {
+	data := r.repo.data
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+
+	now := time.Now()
+	for id, pref := range data.preferences {
+		if pref.UserID == userID && !pref.Deleted.Valid {
+			pref.Deleted = sql.NullTime{Time: now, Valid: true}
+			data.preferences[id] = pref
+		}
+	}
+
+	return nil
+}
+
+// デフォルトの睡眠設定を生成
+func (r *UserSleepPreferenceRepository) GetDefaultPreference(userID int64) *models.UserSleepPreference {
+	defaultBedtime, _ := time.Parse("15:04", "23:00")
+	defaultWakeupTime, _ := time.Parse("15:04", "07:00")
+
+	return &models.UserSleepPreference{
+		UserID:              userID,
+		PreferredBedtime:    defaultBedtime,
+		PreferredWakeupTime: defaultWakeupTime,
+		SleepGoalHours:      8,
+		IsReminderEnabled:   true,
+	}
+}
@@ -0,0 +1,343 @@
+// internal/repository/migration/migration.go
+// migrationは、埋め込みSQLによるバージョン管理されたスキーママイグレーションを提供します。
+// マイグレーションは sql/<方言>/NNNN_名前.up.sql（適用）と NNNN_名前.down.sql（取り消し）で定義し、
+// 適用済みのバージョンは schema_migrations テーブルで管理します。
+
+// Package migration provides versioned schema migrations.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql
+var files embed.FS
+
+// データベースの方言
+type Dialect string
+
+const (
+	DialectMySQL  Dialect = "mysql"
+	DialectSQLite Dialect = "sqlite"
+)
+
+var (
+	// ErrUnknownDialect 対応していないデータベースです
+	ErrUnknownDialect = errors.New("unknown dialect / 対応していないデータベースです")
+	// ErrUnknownVersion 定義されていないマイグレーションが適用されています
+	ErrUnknownVersion = errors.New("unknown migration version / 定義されていないマイグレーションが適用されています")
+)
+
+// マイグレーション
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx *sql.Tx) error
+	Down    func(ctx context.Context, tx *sql.Tx) error
+}
+
+// マイグレーションの適用状況
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// マイグレーションの実行
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// 新しいMigratorを作成
+func NewMigrator(db *sql.DB, dialect Dialect) (*Migrator, error) {
+	migrations, err := load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+// ドライバー名から方言を取得（未指定の場合はMySQL）
+func DialectFor(driver string) (Dialect, error) {
+	switch driver {
+	case "mysql", "":
+		return DialectMySQL, nil
+	case "sqlite":
+		return DialectSQLite, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownDialect, driver)
+	}
+}
+
+// 未適用のマイグレーションをすべて適用し、適用したマイグレーションを返す
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := m.run(ctx, migration, true); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// 適用済みのマイグレーションを新しいものから steps 件取り消し、取り消したマイグレーションを返す
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := m.run(ctx, migration, false); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// すべてのマイグレーションの適用状況を取得
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// マイグレーションを1件実行し、schema_migrations を更新
+// MySQLではDDLが暗黙的にコミットされるため、途中で失敗した場合は手動での復旧が必要になる
+func (m *Migrator) run(ctx context.Context, migration Migration, up bool) error {
+	direction, fn := "up", migration.Up
+	if !up {
+		direction, fn = "down", migration.Down
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, tx); err != nil {
+		return fmt.Errorf("migration %04d_%s (%s) failed: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, applied) VALUES (?, ?, ?)",
+			migration.Version, migration.Name, time.Now().UTC().Format("2006-01-02 15:04:05"),
+		)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// 適用済みのバージョンと適用日時を取得
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := make(map[int]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+	}
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		if !known[version] {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+		}
+		t, _ := time.ParseInLocation("2006-01-02 15:04:05", appliedAt, time.UTC)
+		applied[version] = t.Local()
+	}
+	return applied, rows.Err()
+}
+
+// schema_migrations テーブルの作成
+// 適用日時はドライバーによる日時の変換の違いを避けるため、UTCの文字列で保存する
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER NOT NULL PRIMARY KEY,
+			name    VARCHAR(255) NOT NULL,
+			applied VARCHAR(19) NOT NULL
+		)
+	`)
+	return err
+}
+
+// 方言ごとのマイグレーションを読み込み、バージョン順に並べる
+func load(dialect Dialect) ([]Migration, error) {
+	if dialect != DialectMySQL && dialect != DialectSQLite {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, dialect)
+	}
+
+	dir := path.Join("sql", string(dialect))
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		version, name, direction, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		body, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("duplicate migration version: %04d (%s, %s)", version, migration.Name, name)
+		}
+		if direction == "up" {
+			migration.Up = execStatements(string(body))
+		} else {
+			migration.Down = execStatements(string(body))
+		}
+	}
+
+	// Goで定義するマイグレーション（初期データの登録など）
+	for _, migration := range goMigrations(dialect) {
+		if _, ok := byVersion[migration.Version]; ok {
+			return nil, fmt.Errorf("duplicate migration version: %04d (%s)", migration.Version, migration.Name)
+		}
+		migration := migration
+		byVersion[migration.Version] = &migration
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == nil || migration.Down == nil {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// ファイル名（NNNN_名前.up.sql / NNNN_名前.down.sql）を解析
+func parseFileName(fileName string) (int, string, string, error) {
+	base, ok := strings.CutSuffix(fileName, ".sql")
+	if !ok {
+		return 0, "", "", fmt.Errorf("invalid migration file name: %s", fileName)
+	}
+	direction := path.Ext(base)
+	if direction != ".up" && direction != ".down" {
+		return 0, "", "", fmt.Errorf("invalid migration file name: %s", fileName)
+	}
+	number, name, ok := strings.Cut(strings.TrimSuffix(base, direction), "_")
+	if !ok {
+		return 0, "", "", fmt.Errorf("invalid migration file name: %s", fileName)
+	}
+	version, err := strconv.Atoi(number)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("invalid migration version: %s", fileName)
+	}
+	return version, name, strings.TrimPrefix(direction, "."), nil
+}
+
+// SQLファイルの文を順に実行する関数を作成
+func execStatements(body string) func(ctx context.Context, tx *sql.Tx) error {
+	statements := splitStatements(body)
+	return func(ctx context.Context, tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// SQLを文ごとに分割（行末のセミコロンで区切り、"--" で始まるコメント行は除く）
+func splitStatements(body string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
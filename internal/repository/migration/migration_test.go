@@ -0,0 +1,252 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// インメモリのSQLiteデータベースを作成（接続ごとに別のデータベースになるため接続は1つにする）
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file::memory:?_loc=auto&_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// テーブル名の一覧を取得
+func tableNames(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// 適用済みのバージョンの一覧を取得
+func appliedVersions(t *testing.T, m *Migrator) []int {
+	t.Helper()
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	var versions []int
+	for _, status := range statuses {
+		if status.Applied {
+			if status.AppliedAt.IsZero() {
+				t.Errorf("%04d_%s: applied time is not set", status.Version, status.Name)
+			}
+			versions = append(versions, status.Version)
+		} else if !status.AppliedAt.IsZero() {
+			t.Errorf("%04d_%s: not applied but has applied time %v", status.Version, status.Name, status.AppliedAt)
+		}
+	}
+	return versions
+}
+
+// マイグレーションのバージョンの一覧
+func migrationVersions(migrations []Migration) []int {
+	versions := make([]int, 0, len(migrations))
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+// SQLiteで適用・取り消し・再適用ができることを確認
+func TestMigratorSQLite(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m, err := NewMigrator(db, DialectSQLite)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	all := migrationVersions(m.migrations)
+	latest := all[len(all)-1]
+
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Fatalf("applied before Up = %v, want none", got)
+	}
+
+	done, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if got := migrationVersions(done); !reflect.DeepEqual(got, all) {
+		t.Fatalf("Up applied %v, want %v", got, all)
+	}
+	if got := appliedVersions(t, m); !reflect.DeepEqual(got, all) {
+		t.Fatalf("applied = %v, want %v", got, all)
+	}
+	tables := tableNames(t, db)
+	for _, table := range []string{"schema_migrations", "users", "sleep_diaries", "sleep_records", "user_notification_settings", "daily_summaries"} {
+		if !slices.Contains(tables, table) {
+			t.Errorf("table %s was not created: %v", table, tables)
+		}
+	}
+	var states int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sleep_states").Scan(&states); err != nil || states == 0 {
+		t.Errorf("sleep states = %d, %v, want the seeded master data", states, err)
+	}
+
+	// 適用済みの場合は何もしない
+	if done, err := m.Up(ctx); err != nil || len(done) != 0 {
+		t.Fatalf("Up again = %v, %v, want nothing applied", migrationVersions(done), err)
+	}
+
+	// 最新の1件を取り消し、もう一度適用する
+	done, err = m.Down(ctx, 1)
+	if err != nil {
+		t.Fatalf("Down(1): %v", err)
+	}
+	if got := migrationVersions(done); !reflect.DeepEqual(got, []int{latest}) {
+		t.Fatalf("Down(1) reverted %v, want [%d]", got, latest)
+	}
+	if got, want := appliedVersions(t, m), all[:len(all)-1]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("applied after Down(1) = %v, want %v", got, want)
+	}
+	done, err = m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up after Down(1): %v", err)
+	}
+	if got := migrationVersions(done); !reflect.DeepEqual(got, []int{latest}) {
+		t.Fatalf("Up after Down(1) applied %v, want [%d]", got, latest)
+	}
+
+	// すべて取り消すと schema_migrations 以外のテーブルはなくなり、再び適用できる
+	done, err = m.Down(ctx, len(all)+1)
+	if err != nil {
+		t.Fatalf("Down(all): %v", err)
+	}
+	if len(done) != len(all) {
+		t.Fatalf("Down(all) reverted %d migrations, want %d", len(done), len(all))
+	}
+	if got := tableNames(t, db); !reflect.DeepEqual(got, []string{"schema_migrations"}) {
+		t.Fatalf("tables after Down(all) = %v, want only schema_migrations", got)
+	}
+	if done, err := m.Up(ctx); err != nil || len(done) != len(all) {
+		t.Fatalf("Up after Down(all) = %d migrations, %v, want %d", len(done), err, len(all))
+	}
+}
+
+// 定義されていないバージョンが適用されている場合は ErrUnknownVersion を返すことを確認
+func TestMigratorUnknownVersion(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m, err := NewMigrator(db, DialectSQLite)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	// 新しいバージョンのアプリケーションで適用した後に、古いバージョンに戻した場合など
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, name, applied) VALUES (9999, 'future', '2024-04-01 00:00:00')"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Up(ctx); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Up: error = %v, want %v", err, ErrUnknownVersion)
+	}
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Down: error = %v, want %v", err, ErrUnknownVersion)
+	}
+	if _, err := m.Status(ctx); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Status: error = %v, want %v", err, ErrUnknownVersion)
+	}
+}
+
+// MySQLとSQLiteで同じバージョン・名前のマイグレーションを定義していることを確認
+func TestLoadDialects(t *testing.T) {
+	mysql, err := load(DialectMySQL)
+	if err != nil {
+		t.Fatalf("load mysql: %v", err)
+	}
+	sqlite, err := load(DialectSQLite)
+	if err != nil {
+		t.Fatalf("load sqlite: %v", err)
+	}
+	if len(mysql) != len(sqlite) {
+		t.Fatalf("mysql has %d migrations, sqlite has %d", len(mysql), len(sqlite))
+	}
+	for i := range mysql {
+		if mysql[i].Version != sqlite[i].Version || mysql[i].Name != sqlite[i].Name {
+			t.Errorf("migration %d: mysql %04d_%s, sqlite %04d_%s", i, mysql[i].Version, mysql[i].Name, sqlite[i].Version, sqlite[i].Name)
+		}
+		if i > 0 && mysql[i].Version <= mysql[i-1].Version {
+			t.Errorf("migrations are not sorted: %04d after %04d", mysql[i].Version, mysql[i-1].Version)
+		}
+	}
+
+	if _, err := load("postgres"); !errors.Is(err, ErrUnknownDialect) {
+		t.Errorf("load postgres: error = %v, want %v", err, ErrUnknownDialect)
+	}
+}
+
+// マイグレーションのファイル名の解析を確認
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		fileName  string
+		version   int
+		name      string
+		direction string
+		wantErr   bool
+	}{
+		{fileName: "0001_create_tables.up.sql", version: 1, name: "create_tables", direction: "up"},
+		{fileName: "0012_change_weekly_report_default.down.sql", version: 12, name: "change_weekly_report_default", direction: "down"},
+		{fileName: "10_add_index.up.sql", version: 10, name: "add_index", direction: "up"},
+		{fileName: "0001_create_tables.sql", wantErr: true},
+		{fileName: "0001_create_tables.up.txt", wantErr: true},
+		{fileName: "0001.up.sql", wantErr: true},
+		{fileName: "abcd_create_tables.up.sql", wantErr: true},
+		{fileName: "0000_create_tables.up.sql", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			version, name, direction, err := parseFileName(tt.fileName)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseFileName = %d, %s, %s, want an error", version, name, direction)
+				}
+				return
+			}
+			if err != nil || version != tt.version || name != tt.name || direction != tt.direction {
+				t.Fatalf("parseFileName = %d, %s, %s, %v, want %d, %s, %s", version, name, direction, err, tt.version, tt.name, tt.direction)
+			}
+		})
+	}
+}
+
+// SQLの文の分割を確認
+func TestSplitStatements(t *testing.T) {
+	body := "-- コメント\n\nCREATE TABLE a (\n\tid INTEGER -- 行末のコメントは残る\n);\n  -- 字下げしたコメント\nINSERT INTO a VALUES (1);\nDELETE FROM a\n"
+	want := []string{
+		"CREATE TABLE a (\n\tid INTEGER -- 行末のコメントは残る\n)",
+		"INSERT INTO a VALUES (1)",
+		"DELETE FROM a",
+	}
+	if got := splitStatements(body); !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitStatements = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+// internal/repository/migration/seed.go
+// seedは、睡眠状態・食事種別の初期データを登録するマイグレーションを提供します。
+
+// Package migration provides versioned schema migrations.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// Goで定義するマイグレーション
+func goMigrations(dialect Dialect) []Migration {
+	return []Migration{
+		{
+			Version: 3,
+			Name:    "seed_master_data",
+			Up: func(ctx context.Context, tx *sql.Tx) error {
+				return seedMasterData(ctx, tx, dialect)
+			},
+			Down: deleteMasterData,
+		},
+	}
+}
+
+// 睡眠状態・食事種別の初期データを登録（models.DefaultSleepStates / models.DefaultMealTypes）
+// 既に同じコードのデータがある場合は登録しない
+func seedMasterData(ctx context.Context, tx *sql.Tx, dialect Dialect) error {
+	insert := "INSERT IGNORE INTO"
+	if dialect == DialectSQLite {
+		insert = "INSERT OR IGNORE INTO"
+	}
+
+	now := time.Now()
+	for _, state := range models.DefaultSleepStates() {
+		_, err := tx.ExecContext(ctx, insert+` sleep_states (
+				state_name, state_code, display_symbol, display_order, created, modified
+			) VALUES (?, ?, ?, ?, ?, ?)
+		`, state.StateName, state.StateCode, state.DisplaySymbol, state.DisplayOrder, now, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, mealType := range models.DefaultMealTypes() {
+		_, err := tx.ExecContext(ctx, insert+` meal_types (
+				type_name, type_code, display_symbol, display_order, created, modified
+			) VALUES (?, ?, ?, ?, ?, ?)
+		`, mealType.TypeName, mealType.TypeCode, mealType.DisplaySymbol, mealType.DisplayOrder, now, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 初期データの削除（睡眠記録から参照されている場合は外部キー制約により失敗する）
+func deleteMasterData(ctx context.Context, tx *sql.Tx) error {
+	for _, state := range models.DefaultSleepStates() {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM sleep_states WHERE state_code = ?", state.StateCode); err != nil {
+			return err
+		}
+	}
+	for _, mealType := range models.DefaultMealTypes() {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM meal_types WHERE type_code = ?", mealType.TypeCode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
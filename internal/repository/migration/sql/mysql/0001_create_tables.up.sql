@@ -0,0 +1,95 @@
+-- 基本テーブルの作成（doc/table.md）
+
+CREATE TABLE IF NOT EXISTS users (
+	id                  INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	email               VARCHAR(255) NOT NULL,
+	display_name        VARCHAR(100) NOT NULL,
+	password_hash       VARCHAR(255) NOT NULL,
+	last_login_datetime DATETIME NULL DEFAULT NULL,
+	created             DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified            DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	deleted             DATETIME NULL DEFAULT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY users_email_UNIQUE (email),
+	KEY email_idx (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS sleep_diaries (
+	id         INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	user_id    INT(10) UNSIGNED NOT NULL,
+	start_date DATE NOT NULL,
+	end_date   DATE NOT NULL,
+	diary_name VARCHAR(100) NOT NULL,
+	note       TEXT NULL,
+	created    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	deleted    DATETIME NULL DEFAULT NULL,
+	PRIMARY KEY (id),
+	KEY user_id_idx (user_id),
+	KEY start_date_idx (start_date),
+	CONSTRAINT fk_sleep_diaries_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS sleep_states (
+	id                INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	state_name        VARCHAR(50) NOT NULL,
+	state_code        VARCHAR(20) NOT NULL,
+	state_description VARCHAR(255) NULL DEFAULT NULL,
+	display_symbol    VARCHAR(10) NOT NULL,
+	display_order     INT(10) UNSIGNED NOT NULL DEFAULT 0,
+	created           DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified          DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	deleted           DATETIME NULL DEFAULT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY sleep_states_state_code_UNIQUE (state_code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS meal_types (
+	id             INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	type_name      VARCHAR(50) NOT NULL,
+	type_code      VARCHAR(20) NOT NULL,
+	display_symbol VARCHAR(10) NOT NULL,
+	display_order  INT(10) UNSIGNED NOT NULL DEFAULT 0,
+	created        DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	deleted        DATETIME NULL DEFAULT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY meal_types_type_code_UNIQUE (type_code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS sleep_records (
+	id             INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	sleep_diary_id INT(10) UNSIGNED NOT NULL,
+	sleep_state_id INT(10) UNSIGNED NOT NULL,
+	record_date    DATE NOT NULL,
+	time_slot      TIME NOT NULL,
+	record_type    ENUM('STATE', 'EVENT', 'MEAL') NOT NULL DEFAULT 'STATE',
+	meal_type_id   INT(10) UNSIGNED NULL DEFAULT NULL,
+	note           TEXT NULL,
+	created        DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	deleted        DATETIME NULL DEFAULT NULL,
+	PRIMARY KEY (id),
+	KEY sleep_diary_id_idx (sleep_diary_id),
+	KEY sleep_state_id_idx (sleep_state_id),
+	KEY record_date_idx (record_date),
+	KEY time_slot_idx (record_date, time_slot),
+	CONSTRAINT fk_sleep_records_sleep_diary FOREIGN KEY (sleep_diary_id) REFERENCES sleep_diaries (id),
+	CONSTRAINT fk_sleep_records_sleep_state FOREIGN KEY (sleep_state_id) REFERENCES sleep_states (id),
+	CONSTRAINT fk_sleep_records_meal_type FOREIGN KEY (meal_type_id) REFERENCES meal_types (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS users_sleep_preferences (
+	id                    INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	user_id               INT(10) UNSIGNED NOT NULL,
+	preferred_bedtime     TIME NOT NULL,
+	preferred_wakeup_time TIME NOT NULL,
+	sleep_goal_hours      INT(3) NOT NULL DEFAULT 8,
+	is_reminder_enabled   BOOLEAN NOT NULL DEFAULT TRUE,
+	created               DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	deleted               DATETIME NULL DEFAULT NULL,
+	PRIMARY KEY (id),
+	KEY user_id_idx (user_id),
+	CONSTRAINT fk_users_sleep_preferences_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
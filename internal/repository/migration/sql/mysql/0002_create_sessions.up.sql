@@ -0,0 +1,13 @@
+-- ログインセッションテーブルの作成
+
+CREATE TABLE IF NOT EXISTS sessions (
+	id       VARCHAR(64) NOT NULL,
+	user_id  INT(10) UNSIGNED NOT NULL,
+	expires  DATETIME NOT NULL,
+	created  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	KEY user_id_idx (user_id),
+	KEY expires_idx (expires),
+	CONSTRAINT fk_sessions_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
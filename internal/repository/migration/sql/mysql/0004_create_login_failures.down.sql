@@ -0,0 +1,3 @@
+-- ログイン失敗の監査記録テーブルの削除
+
+DROP TABLE IF EXISTS login_failures;
@@ -0,0 +1,13 @@
+-- ログイン失敗の監査記録テーブルの作成
+-- 存在しないメールアドレスへの試行も記録するため、users への外部キーは設定しない
+
+CREATE TABLE IF NOT EXISTS login_failures (
+	id         INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	email      VARCHAR(255) NOT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	reason     ENUM('INVALID_CREDENTIALS', 'THROTTLED', 'LOCKED') NOT NULL,
+	created    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	KEY email_created_idx (email, created),
+	KEY created_idx (created)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
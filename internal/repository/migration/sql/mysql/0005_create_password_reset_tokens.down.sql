@@ -0,0 +1,3 @@
+-- パスワード再設定用トークンテーブルの削除
+
+DROP TABLE IF EXISTS password_reset_tokens;
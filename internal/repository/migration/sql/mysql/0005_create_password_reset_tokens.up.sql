@@ -0,0 +1,15 @@
+-- パスワード再設定用トークンテーブルの作成
+-- トークンそのものは保存せず、SHA-256のハッシュ値（16進数）のみを保存する
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+	id         INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	user_id    INT(10) UNSIGNED NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	expires    DATETIME NOT NULL,
+	created    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE KEY token_hash_uq (token_hash),
+	KEY user_id_idx (user_id),
+	KEY expires_idx (expires),
+	CONSTRAINT fk_password_reset_tokens_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
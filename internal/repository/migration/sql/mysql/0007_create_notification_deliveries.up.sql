@@ -0,0 +1,14 @@
+-- 通知の送信履歴テーブルの作成
+-- 同じ通知を二重に送信しないよう、ユーザー・通知の種類・対象期間の組み合わせを一意にする
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+	id         INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	user_id    INT(10) UNSIGNED NOT NULL,
+	kind       VARCHAR(32) NOT NULL,
+	period_key VARCHAR(32) NOT NULL,
+	created    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE KEY user_kind_period_uq (user_id, kind, period_key),
+	KEY created_idx (created),
+	CONSTRAINT fk_notification_deliveries_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
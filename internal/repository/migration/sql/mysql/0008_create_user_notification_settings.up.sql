@@ -0,0 +1,16 @@
+-- ユーザーの通知設定テーブルの作成
+-- 通知設定はユーザーごとに1件とし、未登録の場合は既定値を使う
+
+CREATE TABLE IF NOT EXISTS user_notification_settings (
+	id               INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	user_id          INT(10) UNSIGNED NOT NULL,
+	email_enabled    BOOLEAN NOT NULL DEFAULT TRUE,
+	bedtime_reminder BOOLEAN NOT NULL DEFAULT TRUE,
+	reminder_time    VARCHAR(5) NOT NULL DEFAULT '',
+	weekly_report    BOOLEAN NOT NULL DEFAULT TRUE,
+	created          DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified         DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE KEY user_id_uq (user_id),
+	CONSTRAINT fk_user_notification_settings_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+-- パーソナルアクセストークンテーブルの削除
+
+DROP TABLE IF EXISTS personal_access_tokens;
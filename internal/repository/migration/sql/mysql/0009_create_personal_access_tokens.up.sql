@@ -0,0 +1,20 @@
+-- パーソナルアクセストークンテーブルの作成
+-- トークンそのものは保存せず、SHA-256のハッシュ値（16進数）と表示用の先頭部分のみを保存する
+-- scopes は権限をスペース区切りで保存する（例: "read write"）
+
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+	id           INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	user_id      INT(10) UNSIGNED NOT NULL,
+	name         VARCHAR(100) NOT NULL,
+	token_prefix VARCHAR(16) NOT NULL,
+	token_hash   CHAR(64) NOT NULL,
+	scopes       VARCHAR(255) NOT NULL,
+	last_used    DATETIME NULL DEFAULT NULL,
+	expires      DATETIME NULL DEFAULT NULL,
+	revoked      DATETIME NULL DEFAULT NULL,
+	created      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE KEY token_hash_uq (token_hash),
+	KEY user_id_idx (user_id),
+	CONSTRAINT fk_personal_access_tokens_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
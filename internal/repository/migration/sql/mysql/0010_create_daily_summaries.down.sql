@@ -0,0 +1,3 @@
+-- 日別集計データテーブルの削除
+
+DROP TABLE IF EXISTS daily_summaries;
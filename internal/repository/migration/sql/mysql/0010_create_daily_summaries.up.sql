@@ -0,0 +1,20 @@
+-- 日別集計データテーブルの作成
+-- 睡眠記録から集計した睡眠日ごとの概要を保存する（項目は doc/cache.md の 1-1 の日別集計データと同じ）
+-- 記録のある日のみ保存し、睡眠記録の変更時に関連する日を集計し直す
+
+CREATE TABLE IF NOT EXISTS daily_summaries (
+	id                    INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	user_id               INT(10) UNSIGNED NOT NULL,
+	summary_date          DATE NOT NULL,
+	total_sleep_duration  DECIMAL(5,2) NOT NULL DEFAULT 0,
+	avg_sleep_quality     DECIMAL(5,2) NOT NULL DEFAULT 0,
+	sleep_start_time      VARCHAR(5) NOT NULL DEFAULT '',
+	sleep_end_time        VARCHAR(5) NOT NULL DEFAULT '',
+	sleep_segment_count   INT(10) UNSIGNED NOT NULL DEFAULT 0,
+	is_within_target_time BOOLEAN NOT NULL DEFAULT FALSE,
+	created               DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE KEY user_date_uq (user_id, summary_date),
+	CONSTRAINT fk_daily_summaries_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,10 @@
+-- 日別集計データの主睡眠と仮眠の項目を削除
+
+ALTER TABLE daily_summaries
+	DROP COLUMN bed_time,
+	DROP COLUMN main_sleep_minutes,
+	DROP COLUMN time_in_bed_minutes,
+	DROP COLUMN sleep_latency_minutes,
+	DROP COLUMN waso_minutes,
+	DROP COLUMN nap_count,
+	DROP COLUMN nap_minutes;
@@ -0,0 +1,12 @@
+-- 日別集計データに主睡眠と仮眠の項目を追加
+-- 期間の睡眠統計を睡眠記録から集計し直さずに、日別集計データから集計するため
+-- 追加前に保存した日は値が0のため、suiminnisshi rebuild-summaries で集計し直す
+
+ALTER TABLE daily_summaries
+	ADD COLUMN bed_time              VARCHAR(5) NOT NULL DEFAULT '' AFTER is_within_target_time,
+	ADD COLUMN main_sleep_minutes    INT(10) UNSIGNED NOT NULL DEFAULT 0 AFTER bed_time,
+	ADD COLUMN time_in_bed_minutes   INT(10) UNSIGNED NOT NULL DEFAULT 0 AFTER main_sleep_minutes,
+	ADD COLUMN sleep_latency_minutes INT(10) UNSIGNED NOT NULL DEFAULT 0 AFTER time_in_bed_minutes,
+	ADD COLUMN waso_minutes          INT(10) UNSIGNED NOT NULL DEFAULT 0 AFTER sleep_latency_minutes,
+	ADD COLUMN nap_count             INT(10) UNSIGNED NOT NULL DEFAULT 0 AFTER waso_minutes,
+	ADD COLUMN nap_minutes           INT(10) UNSIGNED NOT NULL DEFAULT 0 AFTER nap_count;
@@ -0,0 +1,4 @@
+-- 週間レポートの既定値を送信するに戻す（無効にした通知設定は戻さない）
+
+ALTER TABLE user_notification_settings
+	ALTER COLUMN weekly_report SET DEFAULT TRUE;
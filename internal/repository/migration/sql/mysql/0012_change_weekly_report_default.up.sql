@@ -0,0 +1,7 @@
+-- 週間レポートを既定で送信しないようにする（受け取る場合は通知設定で有効にする）
+-- 登録済みの通知設定も、週間レポートを無効にする
+
+ALTER TABLE user_notification_settings
+	ALTER COLUMN weekly_report SET DEFAULT FALSE;
+
+UPDATE user_notification_settings SET weekly_report = FALSE;
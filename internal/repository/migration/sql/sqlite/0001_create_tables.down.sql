@@ -0,0 +1,8 @@
+-- 基本テーブルの削除（外部キーの参照元から順に削除）
+
+DROP TABLE IF EXISTS users_sleep_preferences;
+DROP TABLE IF EXISTS sleep_records;
+DROP TABLE IF EXISTS meal_types;
+DROP TABLE IF EXISTS sleep_states;
+DROP TABLE IF EXISTS sleep_diaries;
+DROP TABLE IF EXISTS users;
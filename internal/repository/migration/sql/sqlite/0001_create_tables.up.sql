@@ -0,0 +1,82 @@
+-- 基本テーブルの作成（doc/table.md のMySQLスキーマに対応）
+-- 日付は"YYYY-MM-DD"、時刻は"HH:MM:SS"の文字列で保存する
+
+CREATE TABLE IF NOT EXISTS users (
+	id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+	email               VARCHAR(255) NOT NULL UNIQUE,
+	display_name        VARCHAR(100) NOT NULL,
+	password_hash       VARCHAR(255) NOT NULL,
+	last_login_datetime DATETIME NULL,
+	created             DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified            DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	deleted             DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS email_idx ON users (email);
+
+CREATE TABLE IF NOT EXISTS sleep_diaries (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id    INTEGER NOT NULL REFERENCES users (id),
+	start_date DATE NOT NULL,
+	end_date   DATE NOT NULL,
+	diary_name VARCHAR(100) NOT NULL,
+	note       TEXT NULL,
+	created    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	deleted    DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS sleep_diaries_user_id_idx ON sleep_diaries (user_id);
+CREATE INDEX IF NOT EXISTS sleep_diaries_start_date_idx ON sleep_diaries (start_date);
+
+CREATE TABLE IF NOT EXISTS sleep_states (
+	id                INTEGER PRIMARY KEY AUTOINCREMENT,
+	state_name        VARCHAR(50) NOT NULL,
+	state_code        VARCHAR(20) NOT NULL UNIQUE,
+	state_description VARCHAR(255) NULL,
+	display_symbol    VARCHAR(10) NOT NULL,
+	display_order     INTEGER NOT NULL DEFAULT 0,
+	created           DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified          DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	deleted           DATETIME NULL
+);
+
+CREATE TABLE IF NOT EXISTS meal_types (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	type_name      VARCHAR(50) NOT NULL,
+	type_code      VARCHAR(20) NOT NULL UNIQUE,
+	display_symbol VARCHAR(10) NOT NULL,
+	display_order  INTEGER NOT NULL DEFAULT 0,
+	created        DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	deleted        DATETIME NULL
+);
+
+CREATE TABLE IF NOT EXISTS sleep_records (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	sleep_diary_id INTEGER NOT NULL REFERENCES sleep_diaries (id),
+	sleep_state_id INTEGER NOT NULL REFERENCES sleep_states (id),
+	record_date    DATE NOT NULL,
+	time_slot      TEXT NOT NULL,
+	record_type    TEXT NOT NULL DEFAULT 'STATE' CHECK (record_type IN ('STATE', 'EVENT', 'MEAL')),
+	meal_type_id   INTEGER NULL REFERENCES meal_types (id),
+	note           TEXT NULL,
+	created        DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	deleted        DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS sleep_records_sleep_diary_id_idx ON sleep_records (sleep_diary_id);
+CREATE INDEX IF NOT EXISTS sleep_records_sleep_state_id_idx ON sleep_records (sleep_state_id);
+CREATE INDEX IF NOT EXISTS sleep_records_record_date_idx ON sleep_records (record_date);
+CREATE INDEX IF NOT EXISTS sleep_records_time_slot_idx ON sleep_records (record_date, time_slot);
+
+CREATE TABLE IF NOT EXISTS users_sleep_preferences (
+	id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id               INTEGER NOT NULL REFERENCES users (id),
+	preferred_bedtime     TEXT NOT NULL,
+	preferred_wakeup_time TEXT NOT NULL,
+	sleep_goal_hours      INTEGER NOT NULL DEFAULT 8,
+	is_reminder_enabled   BOOLEAN NOT NULL DEFAULT 1,
+	created               DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	deleted               DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS users_sleep_preferences_user_id_idx ON users_sleep_preferences (user_id);
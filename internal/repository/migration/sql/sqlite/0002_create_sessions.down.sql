@@ -0,0 +1,3 @@
+-- ログインセッションテーブルの削除
+
+DROP TABLE IF EXISTS sessions;
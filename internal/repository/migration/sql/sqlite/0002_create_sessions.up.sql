@@ -0,0 +1,11 @@
+-- ログインセッションテーブルの作成
+
+CREATE TABLE IF NOT EXISTS sessions (
+	id       VARCHAR(64) PRIMARY KEY,
+	user_id  INTEGER NOT NULL REFERENCES users (id),
+	expires  DATETIME NOT NULL,
+	created  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS sessions_expires_idx ON sessions (expires);
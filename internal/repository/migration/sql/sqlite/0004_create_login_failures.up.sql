@@ -0,0 +1,12 @@
+-- ログイン失敗の監査記録テーブルの作成
+-- 存在しないメールアドレスへの試行も記録するため、users への外部キーは設定しない
+
+CREATE TABLE IF NOT EXISTS login_failures (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	email      VARCHAR(255) NOT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	reason     TEXT NOT NULL CHECK (reason IN ('INVALID_CREDENTIALS', 'THROTTLED', 'LOCKED')),
+	created    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS login_failures_email_created_idx ON login_failures (email, created);
+CREATE INDEX IF NOT EXISTS login_failures_created_idx ON login_failures (created);
@@ -0,0 +1,12 @@
+-- パスワード再設定用トークンテーブルの作成
+-- トークンそのものは保存せず、SHA-256のハッシュ値（16進数）のみを保存する
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id    INTEGER NOT NULL REFERENCES users (id),
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	expires    DATETIME NOT NULL,
+	created    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS password_reset_tokens_user_id_idx ON password_reset_tokens (user_id);
+CREATE INDEX IF NOT EXISTS password_reset_tokens_expires_idx ON password_reset_tokens (expires);
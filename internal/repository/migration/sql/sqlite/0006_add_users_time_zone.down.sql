@@ -0,0 +1,3 @@
+-- ユーザーのタイムゾーンを削除
+
+ALTER TABLE users DROP COLUMN time_zone;
@@ -0,0 +1,3 @@
+-- ユーザーのタイムゾーン（IANAのタイムゾーン名）を追加
+
+ALTER TABLE users ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'Asia/Tokyo';
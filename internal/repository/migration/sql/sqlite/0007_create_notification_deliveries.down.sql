@@ -0,0 +1,3 @@
+-- 通知の送信履歴テーブルの削除
+
+DROP TABLE IF EXISTS notification_deliveries;
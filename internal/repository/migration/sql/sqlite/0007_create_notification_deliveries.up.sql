@@ -0,0 +1,12 @@
+-- 通知の送信履歴テーブルの作成
+-- 同じ通知を二重に送信しないよう、ユーザー・通知の種類・対象期間の組み合わせを一意にする
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id    INTEGER NOT NULL REFERENCES users (id),
+	kind       VARCHAR(32) NOT NULL,
+	period_key VARCHAR(32) NOT NULL,
+	created    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (user_id, kind, period_key)
+);
+CREATE INDEX IF NOT EXISTS notification_deliveries_created_idx ON notification_deliveries (created);
@@ -0,0 +1,3 @@
+-- ユーザーの通知設定テーブルの削除
+
+DROP TABLE IF EXISTS user_notification_settings;
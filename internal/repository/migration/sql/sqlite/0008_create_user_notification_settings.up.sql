@@ -0,0 +1,13 @@
+-- ユーザーの通知設定テーブルの作成
+-- 通知設定はユーザーごとに1件とし、未登録の場合は既定値を使う
+
+CREATE TABLE IF NOT EXISTS user_notification_settings (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id          INTEGER NOT NULL UNIQUE REFERENCES users (id),
+	email_enabled    BOOLEAN NOT NULL DEFAULT 1,
+	bedtime_reminder BOOLEAN NOT NULL DEFAULT 1,
+	reminder_time    VARCHAR(5) NOT NULL DEFAULT '',
+	weekly_report    BOOLEAN NOT NULL DEFAULT 1,
+	created          DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified         DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
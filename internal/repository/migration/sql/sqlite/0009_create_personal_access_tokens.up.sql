@@ -0,0 +1,17 @@
+-- パーソナルアクセストークンテーブルの作成
+-- トークンそのものは保存せず、SHA-256のハッシュ値（16進数）と表示用の先頭部分のみを保存する
+-- scopes は権限をスペース区切りで保存する（例: "read write"）
+
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id      INTEGER NOT NULL REFERENCES users (id),
+	name         VARCHAR(100) NOT NULL,
+	token_prefix VARCHAR(16) NOT NULL,
+	token_hash   CHAR(64) NOT NULL UNIQUE,
+	scopes       VARCHAR(255) NOT NULL,
+	last_used    DATETIME,
+	expires      DATETIME,
+	revoked      DATETIME,
+	created      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS personal_access_tokens_user_id_idx ON personal_access_tokens (user_id);
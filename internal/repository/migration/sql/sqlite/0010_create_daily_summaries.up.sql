@@ -0,0 +1,18 @@
+-- 日別集計データテーブルの作成
+-- 睡眠記録から集計した睡眠日ごとの概要を保存する（項目は doc/cache.md の 1-1 の日別集計データと同じ）
+-- 記録のある日のみ保存し、睡眠記録の変更時に関連する日を集計し直す
+
+CREATE TABLE IF NOT EXISTS daily_summaries (
+	id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id               INTEGER NOT NULL REFERENCES users (id),
+	summary_date          DATE NOT NULL,
+	total_sleep_duration  REAL NOT NULL DEFAULT 0,
+	avg_sleep_quality     REAL NOT NULL DEFAULT 0,
+	sleep_start_time      VARCHAR(5) NOT NULL DEFAULT '',
+	sleep_end_time        VARCHAR(5) NOT NULL DEFAULT '',
+	sleep_segment_count   INTEGER NOT NULL DEFAULT 0,
+	is_within_target_time BOOLEAN NOT NULL DEFAULT 0,
+	created               DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified              DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (user_id, summary_date)
+);
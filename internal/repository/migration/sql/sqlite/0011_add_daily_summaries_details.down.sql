@@ -0,0 +1,9 @@
+-- 日別集計データの主睡眠と仮眠の項目を削除
+
+ALTER TABLE daily_summaries DROP COLUMN bed_time;
+ALTER TABLE daily_summaries DROP COLUMN main_sleep_minutes;
+ALTER TABLE daily_summaries DROP COLUMN time_in_bed_minutes;
+ALTER TABLE daily_summaries DROP COLUMN sleep_latency_minutes;
+ALTER TABLE daily_summaries DROP COLUMN waso_minutes;
+ALTER TABLE daily_summaries DROP COLUMN nap_count;
+ALTER TABLE daily_summaries DROP COLUMN nap_minutes;
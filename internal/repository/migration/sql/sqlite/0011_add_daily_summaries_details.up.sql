@@ -0,0 +1,11 @@
+-- 日別集計データに主睡眠と仮眠の項目を追加
+-- 期間の睡眠統計を睡眠記録から集計し直さずに、日別集計データから集計するため
+-- 追加前に保存した日は値が0のため、suiminnisshi rebuild-summaries で集計し直す
+
+ALTER TABLE daily_summaries ADD COLUMN bed_time VARCHAR(5) NOT NULL DEFAULT '';
+ALTER TABLE daily_summaries ADD COLUMN main_sleep_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE daily_summaries ADD COLUMN time_in_bed_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE daily_summaries ADD COLUMN sleep_latency_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE daily_summaries ADD COLUMN waso_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE daily_summaries ADD COLUMN nap_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE daily_summaries ADD COLUMN nap_minutes INTEGER NOT NULL DEFAULT 0;
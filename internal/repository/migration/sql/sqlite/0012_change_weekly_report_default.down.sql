@@ -0,0 +1,21 @@
+-- 週間レポートの既定値を送信するに戻す（無効にした通知設定は戻さない）
+-- SQLiteは列の既定値を変更できないため、テーブルを作成し直す
+
+CREATE TABLE user_notification_settings_old (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id          INTEGER NOT NULL UNIQUE REFERENCES users (id),
+	email_enabled    BOOLEAN NOT NULL DEFAULT 1,
+	bedtime_reminder BOOLEAN NOT NULL DEFAULT 1,
+	reminder_time    VARCHAR(5) NOT NULL DEFAULT '',
+	weekly_report    BOOLEAN NOT NULL DEFAULT 1,
+	created          DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified         DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO user_notification_settings_old (id, user_id, email_enabled, bedtime_reminder, reminder_time, weekly_report, created, modified)
+	SELECT id, user_id, email_enabled, bedtime_reminder, reminder_time, weekly_report, created, modified
+	FROM user_notification_settings;
+
+DROP TABLE user_notification_settings;
+
+ALTER TABLE user_notification_settings_old RENAME TO user_notification_settings;
@@ -0,0 +1,22 @@
+-- 週間レポートを既定で送信しないようにする（受け取る場合は通知設定で有効にする）
+-- 登録済みの通知設定も、週間レポートを無効にする
+-- SQLiteは列の既定値を変更できないため、テーブルを作成し直す
+
+CREATE TABLE user_notification_settings_new (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id          INTEGER NOT NULL UNIQUE REFERENCES users (id),
+	email_enabled    BOOLEAN NOT NULL DEFAULT 1,
+	bedtime_reminder BOOLEAN NOT NULL DEFAULT 1,
+	reminder_time    VARCHAR(5) NOT NULL DEFAULT '',
+	weekly_report    BOOLEAN NOT NULL DEFAULT 0,
+	created          DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	modified         DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO user_notification_settings_new (id, user_id, email_enabled, bedtime_reminder, reminder_time, weekly_report, created, modified)
+	SELECT id, user_id, email_enabled, bedtime_reminder, reminder_time, 0, created, modified
+	FROM user_notification_settings;
+
+DROP TABLE user_notification_settings;
+
+ALTER TABLE user_notification_settings_new RENAME TO user_notification_settings;
@@ -0,0 +1,100 @@
+// internal/repository/mysql/login_failure_repository.go
+// login_failure_repositoryは、ログイン失敗の監査記録のリポジトリを提供します。
+
+// Package mysql provides MySQL repository implementations.
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// LoginFailureRepositoryのMySQL実装
+type LoginFailureRepository struct {
+	repo *MySQLRepository
+}
+
+// ログイン失敗を記録
+func (r *LoginFailureRepository) Create(ctx context.Context, failure *models.LoginFailure) error {
+	query := `
+		INSERT INTO login_failures (
+			email, ip_address, reason, created
+		) VALUES (?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.repo.getDB().ExecContext(ctx, query,
+		failure.Email,
+		failure.IPAddress,
+		failure.Reason,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	failure.ID = id
+	failure.Created = now
+
+	return nil
+}
+
+// メールアドレスで指定日時以降のログイン失敗を検索（新しい順）
+func (r *LoginFailureRepository) GetByEmail(ctx context.Context, email string, since time.Time) ([]*models.LoginFailure, error) {
+	query := `
+		SELECT id, email, ip_address, reason, created
+		FROM login_failures
+		WHERE email = ? AND created >= ?
+		ORDER BY created DESC, id DESC
+	`
+
+	rows, err := r.repo.getDB().QueryContext(ctx, query, email, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []*models.LoginFailure
+	for rows.Next() {
+		failure := &models.LoginFailure{}
+		err := rows.Scan(
+			&failure.ID,
+			&failure.Email,
+			&failure.IPAddress,
+			&failure.Reason,
+			&failure.Created,
+		)
+		if err != nil {
+			return nil, err
+		}
+		failures = append(failures, failure)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return failures, nil
+}
+
+// 指定日時より前のログイン失敗を削除し、削除件数を返す
+func (r *LoginFailureRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM login_failures
+		WHERE created < ?
+	`
+
+	result, err := r.repo.getDB().ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -26,7 +26,7 @@ func (r *MealTypeRepository) GetByID(ctx context.Context, id int64) (*models.Mea
 	`
 
 	mealType := &models.MealType{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, id).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, id).Scan(
 		&mealType.ID,
 		&mealType.TypeName,
 		&mealType.TypeCode,
@@ -56,7 +56,7 @@ func (r *MealTypeRepository) GetAll(ctx context.Context) ([]*models.MealType, er
 		ORDER BY display_order
 	`
 
-	rows, err := r.repo.getDB().(*sql.DB).QueryContext(ctx, query)
+	rows, err := r.repo.getDB().QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +97,7 @@ func (r *MealTypeRepository) GetByCode(ctx context.Context, code string) (*model
 	`
 
 	mealType := &models.MealType{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, code).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, code).Scan(
 		&mealType.ID,
 		&mealType.TypeName,
 		&mealType.TypeCode,
@@ -128,7 +128,7 @@ func (r *MealTypeRepository) Create(ctx context.Context, mealType *models.MealTy
 	`
 
 	now := time.Now()
-	result, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	result, err := r.repo.getDB().ExecContext(ctx, query,
 		mealType.TypeName,
 		mealType.TypeCode,
 		mealType.DisplaySymbol,
@@ -161,7 +161,7 @@ func (r *MealTypeRepository) Update(ctx context.Context, mealType *models.MealTy
 	`
 
 	now := time.Now()
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		mealType.TypeName,
 		mealType.TypeCode,
 		mealType.DisplaySymbol,
@@ -186,7 +186,7 @@ func (r *MealTypeRepository) Delete(ctx context.Context, id int64) error {
 		WHERE id = ? AND deleted IS NULL
 	`
 
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		time.Now(),
 		id,
 	)
@@ -7,14 +7,24 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
 )
 
 // MySQLリポジトリの実装
 type MySQLRepository struct {
-	db *sql.DB
-	tx *sql.Tx
+	db    *sql.DB
+	tx    *sql.Tx
+	depth int // トランザクションの入れ子の深さ（セーブポイント名に使用）
+}
+
+// クエリを実行できる接続（*sql.DB と *sql.Tx の共通部分）
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
 
 // 新しいMySQLリポジトリを作成
@@ -54,8 +64,49 @@ func (r *MySQLRepository) UserSleepPreference() repository.UserSleepPreferenceRe
 	return &UserSleepPreferenceRepository{repo: r}
 }
 
+// SessionStoreを取得
+func (r *MySQLRepository) Session() repository.SessionStore {
+	return &SessionStore{repo: r}
+}
+
+// LoginFailureRepositoryを取得
+func (r *MySQLRepository) LoginFailure() repository.LoginFailureRepository {
+	return &LoginFailureRepository{repo: r}
+}
+
+// PasswordResetTokenRepositoryを取得
+func (r *MySQLRepository) PasswordResetToken() repository.PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{repo: r}
+}
+
+// NotificationDeliveryRepositoryを取得
+func (r *MySQLRepository) NotificationDelivery() repository.NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{repo: r}
+}
+
+// NotificationSettingsRepositoryを取得
+func (r *MySQLRepository) NotificationSettings() repository.NotificationSettingsRepository {
+	return &NotificationSettingsRepository{repo: r}
+}
+
+// PersonalAccessTokenRepositoryを取得
+func (r *MySQLRepository) PersonalAccessToken() repository.PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{repo: r}
+}
+
+// DailySummaryRepositoryを取得
+func (r *MySQLRepository) DailySummary() repository.DailySummaryRepository {
+	return &DailySummaryRepository{repo: r}
+}
+
 // トランザクションを実行
+// すでにトランザクション中の場合は、セーブポイントを使って入れ子のトランザクションとして実行し、
+// エラーの場合はセーブポイントまでの変更のみを取り消す
 func (r *MySQLRepository) Transaction(ctx context.Context, fn func(repository.Repository) error) error {
+	if r.tx != nil {
+		return r.savepoint(ctx, fn)
+	}
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -66,6 +117,14 @@ func (r *MySQLRepository) Transaction(ctx context.Context, fn func(repository.Re
 		tx: tx,
 	}
 
+	// fnがパニックした場合もロールバックしてから、パニックを呼び出し元に伝える
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
 	if err := fn(repo); err != nil {
 		tx.Rollback()
 		return err
@@ -74,8 +133,39 @@ func (r *MySQLRepository) Transaction(ctx context.Context, fn func(repository.Re
 	return tx.Commit()
 }
 
+// セーブポイントを使って入れ子のトランザクションを実行
+func (r *MySQLRepository) savepoint(ctx context.Context, fn func(repository.Repository) error) error {
+	repo := &MySQLRepository{
+		db:    r.db,
+		tx:    r.tx,
+		depth: r.depth + 1,
+	}
+	name := fmt.Sprintf("sp_%d", repo.depth)
+
+	if _, err := r.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			r.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	if err := fn(repo); err != nil {
+		if _, rbErr := r.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	_, err := r.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
 // アクティブなデータベース接続を取得
-func (r *MySQLRepository) getDB() interface{} {
+func (r *MySQLRepository) getDB() executor {
 	if r.tx != nil {
 		return r.tx
 	}
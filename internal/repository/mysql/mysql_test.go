@@ -0,0 +1,82 @@
+//go:build mysql
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/migration"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/repotest"
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// テストに使うMySQLサーバーの接続先（データベース名はテストごとに作成するため無視する）
+//
+//	SUIMINNISSHI_TEST_MYSQL_DSN='root:password@tcp(127.0.0.1:3306)/' go test -tags mysql ./internal/repository/mysql/
+const testDSNEnv = "SUIMINNISSHI_TEST_MYSQL_DSN"
+
+// テスト用データベースの連番
+var testDBSeq atomic.Int64
+
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	cfg, err := driver.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", testDSNEnv, err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return newTestRepository(t, cfg)
+	})
+}
+
+// 空のデータベースを作成してマイグレーションを適用したリポジトリを返す（データベースはテストの終了時に削除する）
+func newTestRepository(t *testing.T, base *driver.Config) repository.Repository {
+	t.Helper()
+	ctx := context.Background()
+
+	server := base.Clone()
+	server.DBName = ""
+	admin, err := sql.Open("mysql", server.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	name := fmt.Sprintf("suiminnisshi_test_%d_%d", os.Getpid(), testDBSeq.Add(1))
+	if _, err := admin.ExecContext(ctx, "CREATE DATABASE "+name+" CHARACTER SET utf8mb4"); err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	t.Cleanup(func() { admin.ExecContext(ctx, "DROP DATABASE IF EXISTS "+name) })
+
+	config := base.Clone()
+	config.DBName = name
+	config.ParseTime = true
+	config.Loc = time.Local
+	config.Params = map[string]string{"charset": "utf8mb4"}
+	db, err := sql.Open("mysql", config.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migration.NewMigrator(db, migration.DialectMySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	return NewMySQLRepository(db)
+}
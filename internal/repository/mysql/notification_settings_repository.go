@@ -0,0 +1,119 @@
+// internal/repository/mysql/notification_settings_repository.go
+// notification_settings_repositoryは、ユーザーの通知設定のリポジトリを提供します。
+
+// Package mysql provides MySQL repository implementations.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// NotificationSettingsRepositoryのMySQL実装
+type NotificationSettingsRepository struct {
+	repo *MySQLRepository
+}
+
+// ユーザーIDで通知設定を検索
+func (r *NotificationSettingsRepository) GetByUserID(ctx context.Context, userID int64) (*models.NotificationSettings, error) {
+	query := `
+		SELECT id, user_id, email_enabled, bedtime_reminder, reminder_time, weekly_report, created, modified
+		FROM user_notification_settings
+		WHERE user_id = ?
+	`
+
+	settings := &models.NotificationSettings{}
+	err := r.repo.getDB().QueryRowContext(ctx, query, userID).Scan(
+		&settings.ID,
+		&settings.UserID,
+		&settings.EmailEnabled,
+		&settings.BedtimeReminder,
+		&settings.ReminderTime,
+		&settings.WeeklyReport,
+		&settings.Created,
+		&settings.Modified,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// 新規通知設定を作成
+func (r *NotificationSettingsRepository) Create(ctx context.Context, settings *models.NotificationSettings) error {
+	query := `
+		INSERT INTO user_notification_settings (
+			user_id, email_enabled, bedtime_reminder, reminder_time, weekly_report, created, modified
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.repo.getDB().ExecContext(ctx, query,
+		settings.UserID,
+		settings.EmailEnabled,
+		settings.BedtimeReminder,
+		settings.ReminderTime,
+		settings.WeeklyReport,
+		now,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	settings.ID = id
+	settings.Created = now
+	settings.Modified = now
+
+	return nil
+}
+
+// 通知設定を更新
+func (r *NotificationSettingsRepository) Update(ctx context.Context, settings *models.NotificationSettings) error {
+	query := `
+		UPDATE user_notification_settings
+		SET email_enabled = ?, bedtime_reminder = ?, reminder_time = ?, weekly_report = ?, modified = ?
+		WHERE user_id = ?
+	`
+
+	now := time.Now()
+	_, err := r.repo.getDB().ExecContext(ctx, query,
+		settings.EmailEnabled,
+		settings.BedtimeReminder,
+		settings.ReminderTime,
+		settings.WeeklyReport,
+		now,
+		settings.UserID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	settings.Modified = now
+	return nil
+}
+
+// 通知設定を削除
+func (r *NotificationSettingsRepository) Delete(ctx context.Context, userID int64) error {
+	query := `
+		DELETE FROM user_notification_settings
+		WHERE user_id = ?
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,174 @@
+// internal/repository/mysql/personal_access_token_repository.go
+// personal_access_token_repositoryは、パーソナルアクセストークンのリポジトリを提供します。
+
+// Package mysql provides MySQL repository implementations.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// PersonalAccessTokenRepositoryのMySQL実装
+type PersonalAccessTokenRepository struct {
+	repo *MySQLRepository
+}
+
+// 取得するカラム
+const personalAccessTokenColumns = `id, user_id, name, token_prefix, token_hash, scopes, last_used, expires, revoked, created`
+
+// IDでトークンを検索
+func (r *PersonalAccessTokenRepository) GetByID(ctx context.Context, id int64) (*models.PersonalAccessToken, error) {
+	query := `
+		SELECT ` + personalAccessTokenColumns + `
+		FROM personal_access_tokens
+		WHERE id = ?
+	`
+
+	return r.scanOne(r.repo.getDB().QueryRowContext(ctx, query, id))
+}
+
+// トークンのハッシュ値で検索
+func (r *PersonalAccessTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	query := `
+		SELECT ` + personalAccessTokenColumns + `
+		FROM personal_access_tokens
+		WHERE token_hash = ?
+	`
+
+	return r.scanOne(r.repo.getDB().QueryRowContext(ctx, query, tokenHash))
+}
+
+// ユーザーの全トークンを作成順に取得（無効化したトークンを含む）
+func (r *PersonalAccessTokenRepository) GetByUserID(ctx context.Context, userID int64) ([]*models.PersonalAccessToken, error) {
+	query := `
+		SELECT ` + personalAccessTokenColumns + `
+		FROM personal_access_tokens
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.repo.getDB().QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*models.PersonalAccessToken
+	for rows.Next() {
+		token, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// 新規トークンを作成
+func (r *PersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (
+			user_id, name, token_prefix, token_hash, scopes, expires, created
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.repo.getDB().ExecContext(ctx, query,
+		token.UserID,
+		token.Name,
+		token.TokenPrefix,
+		token.TokenHash,
+		token.Scopes,
+		token.Expires,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	token.ID = id
+	token.Created = now
+
+	return nil
+}
+
+// 最終利用日時を更新
+func (r *PersonalAccessTokenRepository) UpdateLastUsed(ctx context.Context, id int64, lastUsed time.Time) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used = ?
+		WHERE id = ?
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query, lastUsed, id)
+
+	return err
+}
+
+// トークンを無効化（無効化済みの場合は何もしない）
+func (r *PersonalAccessTokenRepository) Revoke(ctx context.Context, id int64, revoked time.Time) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked = ?
+		WHERE id = ? AND revoked IS NULL
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query, revoked, id)
+
+	return err
+}
+
+// ユーザーの全トークンを削除
+func (r *PersonalAccessTokenRepository) DeleteByUserID(ctx context.Context, userID int64) error {
+	query := `
+		DELETE FROM personal_access_tokens
+		WHERE user_id = ?
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query, userID)
+
+	return err
+}
+
+// 1件のトークンを読み込み（見つからない場合はnil）
+func (r *PersonalAccessTokenRepository) scanOne(row *sql.Row) (*models.PersonalAccessToken, error) {
+	token, err := r.scan(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// トークンを読み込み
+func (r *PersonalAccessTokenRepository) scan(row interface{ Scan(...interface{}) error }) (*models.PersonalAccessToken, error) {
+	token := &models.PersonalAccessToken{}
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenPrefix,
+		&token.TokenHash,
+		&token.Scopes,
+		&token.LastUsed,
+		&token.Expires,
+		&token.Revoked,
+		&token.Created,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
@@ -26,7 +26,7 @@ func (r *SleepDiaryRepository) GetByID(ctx context.Context, id int64) (*models.S
 	`
 
 	diary := &models.SleepDiary{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, id).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, id).Scan(
 		&diary.ID,
 		&diary.UserID,
 		&diary.StartDate,
@@ -57,7 +57,7 @@ func (r *SleepDiaryRepository) GetByUserID(ctx context.Context, userID int64) ([
 		ORDER BY start_date DESC
 	`
 
-	rows, err := r.repo.getDB().(*sql.DB).QueryContext(ctx, query, userID)
+	rows, err := r.repo.getDB().QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +105,7 @@ func (r *SleepDiaryRepository) GetByDateRange(ctx context.Context, userID int64,
 		ORDER BY start_date
 	`
 
-	rows, err := r.repo.getDB().(*sql.DB).QueryContext(ctx, query,
+	rows, err := r.repo.getDB().QueryContext(ctx, query,
 		userID, startDate, endDate, startDate, endDate, startDate, endDate)
 	if err != nil {
 		return nil, err
@@ -148,7 +148,7 @@ func (r *SleepDiaryRepository) Create(ctx context.Context, diary *models.SleepDi
 	`
 
 	now := time.Now()
-	result, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	result, err := r.repo.getDB().ExecContext(ctx, query,
 		diary.UserID,
 		diary.StartDate,
 		diary.EndDate,
@@ -182,7 +182,7 @@ func (r *SleepDiaryRepository) Update(ctx context.Context, diary *models.SleepDi
 	`
 
 	now := time.Now()
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		diary.StartDate,
 		diary.EndDate,
 		diary.DiaryName,
@@ -207,7 +207,7 @@ func (r *SleepDiaryRepository) Delete(ctx context.Context, id int64) error {
 		WHERE id = ? AND deleted IS NULL
 	`
 
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		time.Now(),
 		id,
 	)
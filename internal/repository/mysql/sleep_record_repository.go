@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
 )
 
 // SleepRecordRepositoryのMySQL実装
@@ -26,7 +27,7 @@ func (r *SleepRecordRepository) GetByID(ctx context.Context, id int64) (*models.
 	`
 
 	record := &models.SleepRecord{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, id).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, id).Scan(
 		&record.ID,
 		&record.SleepDiaryID,
 		&record.SleepStateID,
@@ -59,7 +60,7 @@ func (r *SleepRecordRepository) GetByDiaryID(ctx context.Context, diaryID int64)
 		ORDER BY record_date, time_slot
 	`
 
-	rows, err := r.repo.getDB().(*sql.DB).QueryContext(ctx, query, diaryID)
+	rows, err := r.repo.getDB().QueryContext(ctx, query, diaryID)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +106,7 @@ func (r *SleepRecordRepository) GetByDateRange(ctx context.Context, diaryID int6
 		ORDER BY record_date, time_slot
 	`
 
-	rows, err := r.repo.getDB().(*sql.DB).QueryContext(ctx, query, diaryID, startDate, endDate)
+	rows, err := r.repo.getDB().QueryContext(ctx, query, diaryID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +162,7 @@ func (r *SleepRecordRepository) GetWithRelations(ctx context.Context, id int64)
 
 	record := &models.SleepRecordWithRelations{}
 	var mealType models.MealType
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, id).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, id).Scan(
 		&record.ID, &record.SleepDiaryID, &record.SleepStateID, &record.RecordDate,
 		&record.TimeSlot, &record.RecordType, &record.MealTypeID, &record.Note,
 		&record.Created, &record.Modified, &record.Deleted,
@@ -190,7 +191,7 @@ func (r *SleepRecordRepository) GetWithRelations(ctx context.Context, id int64)
 	return record, nil
 }
 
-// 新規睡眠記録を作成
+// 新規睡眠記録を作成（作成日時・更新日時が設定されている場合はその値で保存する）
 func (r *SleepRecordRepository) Create(ctx context.Context, record *models.SleepRecord) error {
 	query := `
 		INSERT INTO sleep_records (
@@ -199,8 +200,8 @@ func (r *SleepRecordRepository) Create(ctx context.Context, record *models.Sleep
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	now := time.Now()
-	result, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	created, modified := record.CreateTimestamps(time.Now())
+	result, err := r.repo.getDB().ExecContext(ctx, query,
 		record.SleepDiaryID,
 		record.SleepStateID,
 		record.RecordDate,
@@ -208,8 +209,8 @@ func (r *SleepRecordRepository) Create(ctx context.Context, record *models.Sleep
 		record.RecordType,
 		record.MealTypeID,
 		record.Note,
-		now,
-		now,
+		created,
+		modified,
 	)
 	if err != nil {
 		return err
@@ -221,8 +222,8 @@ func (r *SleepRecordRepository) Create(ctx context.Context, record *models.Sleep
 	}
 
 	record.ID = id
-	record.Created = now
-	record.Modified = now
+	record.Created = created
+	record.Modified = modified
 
 	return nil
 }
@@ -231,12 +232,13 @@ func (r *SleepRecordRepository) Create(ctx context.Context, record *models.Sleep
 func (r *SleepRecordRepository) Update(ctx context.Context, record *models.SleepRecord) error {
 	query := `
 		UPDATE sleep_records
-		SET sleep_state_id = ?, record_date = ?, time_slot = ?, record_type = ?, meal_type_id = ?, note = ?, modified = ?
+		SET sleep_diary_id = ?, sleep_state_id = ?, record_date = ?, time_slot = ?, record_type = ?, meal_type_id = ?, note = ?, modified = ?
 		WHERE id = ? AND deleted IS NULL
 	`
 
 	now := time.Now()
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
+		record.SleepDiaryID,
 		record.SleepStateID,
 		record.RecordDate,
 		record.TimeSlot,
@@ -263,7 +265,7 @@ func (r *SleepRecordRepository) Delete(ctx context.Context, id int64) error {
 		WHERE id = ? AND deleted IS NULL
 	`
 
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		time.Now(),
 		id,
 	)
@@ -272,6 +274,8 @@ func (r *SleepRecordRepository) Delete(ctx context.Context, id int64) error {
 }
 
 // 複数の睡眠記録を一括作成
+// 実行中のトランザクションがあればセーブポイントを使って参加し、なければ新たにトランザクションを開始する
+// 途中で失敗した場合はすべての記録の作成を取り消す
 func (r *SleepRecordRepository) BulkCreate(ctx context.Context, records []*models.SleepRecord) error {
 	query := `
 		INSERT INTO sleep_records (
@@ -281,45 +285,48 @@ func (r *SleepRecordRepository) BulkCreate(ctx context.Context, records []*model
 	`
 
 	now := time.Now()
-	tx, err := r.repo.getDB().(*sql.DB).BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	stmt, err := tx.PrepareContext(ctx, query)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-	defer stmt.Close()
-
-	for _, record := range records {
-		result, err := stmt.ExecContext(ctx,
-			record.SleepDiaryID,
-			record.SleepStateID,
-			record.RecordDate,
-			record.TimeSlot,
-			record.RecordType,
-			record.MealTypeID,
-			record.Note,
-			now,
-			now,
-		)
+	ids := make([]int64, len(records))
+	timestamps := make([][2]time.Time, len(records))
+	err := r.repo.Transaction(ctx, func(repo repository.Repository) error {
+		stmt, err := repo.(*MySQLRepository).getDB().PrepareContext(ctx, query)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
-
-		id, err := result.LastInsertId()
-		if err != nil {
-			tx.Rollback()
-			return err
+		defer stmt.Close()
+
+		for i, record := range records {
+			created, modified := record.CreateTimestamps(now)
+			timestamps[i] = [2]time.Time{created, modified}
+			result, err := stmt.ExecContext(ctx,
+				record.SleepDiaryID,
+				record.SleepStateID,
+				record.RecordDate,
+				record.TimeSlot,
+				record.RecordType,
+				record.MealTypeID,
+				record.Note,
+				created,
+				modified,
+			)
+			if err != nil {
+				return err
+			}
+
+			if ids[i], err = result.LastInsertId(); err != nil {
+				return err
+			}
 		}
-
-		record.ID = id
-		record.Created = now
-		record.Modified = now
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return tx.Commit()
+	// コミット後に採番されたIDを設定
+	for i, record := range records {
+		record.ID = ids[i]
+		record.Created = timestamps[i][0]
+		record.Modified = timestamps[i][1]
+	}
+	return nil
 }
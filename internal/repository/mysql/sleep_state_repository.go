@@ -26,7 +26,7 @@ func (r *SleepStateRepository) GetByID(ctx context.Context, id int64) (*models.S
 	`
 
 	state := &models.SleepState{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, id).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, id).Scan(
 		&state.ID,
 		&state.StateName,
 		&state.StateCode,
@@ -57,7 +57,7 @@ func (r *SleepStateRepository) GetAll(ctx context.Context) ([]*models.SleepState
 		ORDER BY display_order
 	`
 
-	rows, err := r.repo.getDB().(*sql.DB).QueryContext(ctx, query)
+	rows, err := r.repo.getDB().QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +99,7 @@ func (r *SleepStateRepository) GetByCode(ctx context.Context, code string) (*mod
 	`
 
 	state := &models.SleepState{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, code).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, code).Scan(
 		&state.ID,
 		&state.StateName,
 		&state.StateCode,
@@ -131,7 +131,7 @@ func (r *SleepStateRepository) Create(ctx context.Context, state *models.SleepSt
 	`
 
 	now := time.Now()
-	result, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	result, err := r.repo.getDB().ExecContext(ctx, query,
 		state.StateName,
 		state.StateCode,
 		state.StateDescription,
@@ -166,7 +166,7 @@ func (r *SleepStateRepository) Update(ctx context.Context, state *models.SleepSt
 	`
 
 	now := time.Now()
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		state.StateName,
 		state.StateCode,
 		state.StateDescription,
@@ -192,7 +192,7 @@ func (r *SleepStateRepository) Delete(ctx context.Context, id int64) error {
 		WHERE id = ? AND deleted IS NULL
 	`
 
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		time.Now(),
 		id,
 	)
@@ -20,17 +20,18 @@ type UserRepository struct {
 // IDでユーザーを検索
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
 	query := `
-		SELECT id, email, display_name, password_hash, last_login_datetime, created, modified, deleted
+		SELECT id, email, display_name, password_hash, time_zone, last_login_datetime, created, modified, deleted
 		FROM users
 		WHERE id = ? AND deleted IS NULL
 	`
 
 	user := &models.User{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, id).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Email,
 		&user.DisplayName,
 		&user.PasswordHash,
+		&user.TimeZone,
 		&user.LastLoginDatetime,
 		&user.Created,
 		&user.Modified,
@@ -50,17 +51,18 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 // メールアドレスでユーザーを検索
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, display_name, password_hash, last_login_datetime, created, modified, deleted
+		SELECT id, email, display_name, password_hash, time_zone, last_login_datetime, created, modified, deleted
 		FROM users
 		WHERE email = ? AND deleted IS NULL
 	`
 
 	user := &models.User{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, email).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Email,
 		&user.DisplayName,
 		&user.PasswordHash,
+		&user.TimeZone,
 		&user.LastLoginDatetime,
 		&user.Created,
 		&user.Modified,
@@ -77,19 +79,62 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return user, nil
 }
 
+// 論理削除されていないすべてのユーザーを取得（ID順）
+func (r *UserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
+	query := `
+		SELECT id, email, display_name, password_hash, time_zone, last_login_datetime, created, modified, deleted
+		FROM users
+		WHERE deleted IS NULL
+		ORDER BY id
+	`
+
+	rows, err := r.repo.getDB().QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.DisplayName,
+			&user.PasswordHash,
+			&user.TimeZone,
+			&user.LastLoginDatetime,
+			&user.Created,
+			&user.Modified,
+			&user.Deleted,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 // 新規ユーザーを作成
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
 		INSERT INTO users (
-			email, display_name, password_hash, created, modified
-		) VALUES (?, ?, ?, ?, ?)
+			email, display_name, password_hash, time_zone, created, modified
+		) VALUES (?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
-	result, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	result, err := r.repo.getDB().ExecContext(ctx, query,
 		user.Email,
 		user.DisplayName,
 		user.PasswordHash,
+		user.TimeZoneOrDefault(),
 		now,
 		now,
 	)
@@ -103,6 +148,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	}
 
 	user.ID = id
+	user.TimeZone = user.TimeZoneOrDefault()
 	user.Created = now
 	user.Modified = now
 
@@ -113,15 +159,16 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
-		SET email = ?, display_name = ?, password_hash = ?, modified = ?
+		SET email = ?, display_name = ?, password_hash = ?, time_zone = ?, modified = ?
 		WHERE id = ? AND deleted IS NULL
 	`
 
 	now := time.Now()
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		user.Email,
 		user.DisplayName,
 		user.PasswordHash,
+		user.TimeZoneOrDefault(),
 		now,
 		user.ID,
 	)
@@ -142,7 +189,7 @@ func (r *UserRepository) Delete(ctx context.Context, id int64) error {
 		WHERE id = ? AND deleted IS NULL
 	`
 
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		time.Now(),
 		id,
 	)
@@ -159,7 +206,7 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, id int64) error {
 	`
 
 	now := time.Now()
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		now,
 		now,
 		id,
@@ -26,7 +26,7 @@ func (r *UserSleepPreferenceRepository) GetByUserID(ctx context.Context, userID
 	`
 
 	pref := &models.UserSleepPreference{}
-	err := r.repo.getDB().(*sql.DB).QueryRowContext(ctx, query, userID).Scan(
+	err := r.repo.getDB().QueryRowContext(ctx, query, userID).Scan(
 		&pref.ID,
 		&pref.UserID,
 		&pref.PreferredBedtime,
@@ -48,6 +48,49 @@ func (r *UserSleepPreferenceRepository) GetByUserID(ctx context.Context, userID
 	return pref, nil
 }
 
+// リマインダーが有効な睡眠設定をすべて取得（削除済みのユーザーは除く、ユーザーID順）
+func (r *UserSleepPreferenceRepository) GetReminderEnabled(ctx context.Context) ([]*models.UserSleepPreference, error) {
+	query := `
+		SELECT p.id, p.user_id, p.preferred_bedtime, p.preferred_wakeup_time, p.sleep_goal_hours, p.is_reminder_enabled, p.created, p.modified, p.deleted
+		FROM users_sleep_preferences p
+		INNER JOIN users u ON u.id = p.user_id AND u.deleted IS NULL
+		WHERE p.is_reminder_enabled = ? AND p.deleted IS NULL
+		ORDER BY p.user_id, p.id
+	`
+
+	rows, err := r.repo.getDB().QueryContext(ctx, query, true)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []*models.UserSleepPreference
+	for rows.Next() {
+		pref := &models.UserSleepPreference{}
+		err := rows.Scan(
+			&pref.ID,
+			&pref.UserID,
+			&pref.PreferredBedtime,
+			&pref.PreferredWakeupTime,
+			&pref.SleepGoalHours,
+			&pref.IsReminderEnabled,
+			&pref.Created,
+			&pref.Modified,
+			&pref.Deleted,
+		)
+		if err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, pref)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
 //新規睡眠設定を作成
 func (r *UserSleepPreferenceRepository) Create(ctx context.Context, pref *models.UserSleepPreference) error {
 	query := `
@@ -58,7 +101,7 @@ func (r *UserSleepPreferenceRepository) Create(ctx context.Context, pref *models
 	`
 
 	now := time.Now()
-	result, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	result, err := r.repo.getDB().ExecContext(ctx, query,
 		pref.UserID,
 		pref.PreferredBedtime,
 		pref.PreferredWakeupTime,
@@ -92,7 +135,7 @@ func (r *UserSleepPreferenceRepository) Update(ctx context.Context, pref *models
 	`
 
 	now := time.Now()
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		pref.PreferredBedtime,
 		pref.PreferredWakeupTime,
 		pref.SleepGoalHours,
@@ -117,7 +160,7 @@ func (r *UserSleepPreferenceRepository) Delete(ctx context.Context, userID int64
 		WHERE user_id = ? AND deleted IS NULL
 	`
 
-	_, err := r.repo.getDB().(*sql.DB).ExecContext(ctx, query,
+	_, err := r.repo.getDB().ExecContext(ctx, query,
 		time.Now(),
 		userID,
 	)
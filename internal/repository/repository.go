@@ -7,6 +7,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 )
@@ -20,6 +21,13 @@ type Repository interface {
 	SleepState() SleepStateRepository
 	MealType() MealTypeRepository
 	UserSleepPreference() UserSleepPreferenceRepository
+	Session() SessionStore
+	LoginFailure() LoginFailureRepository
+	PasswordResetToken() PasswordResetTokenRepository
+	NotificationDelivery() NotificationDeliveryRepository
+	NotificationSettings() NotificationSettingsRepository
+	PersonalAccessToken() PersonalAccessTokenRepository
+	DailySummary() DailySummaryRepository
 	// トランザクション
 	Transaction(ctx context.Context, fn func(Repository) error) error
 }
@@ -28,6 +36,7 @@ type Repository interface {
 type UserRepository interface {
 	GetByID(ctx context.Context, id int64) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetAll(ctx context.Context) ([]*models.User, error)
 	Create(ctx context.Context, user *models.User) error
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id int64) error
@@ -79,8 +88,67 @@ type MealTypeRepository interface {
 // ユーザー睡眠設定のリポジトリーインターフェイス
 type UserSleepPreferenceRepository interface {
 	GetByUserID(ctx context.Context, userID int64) (*models.UserSleepPreference, error)
+	GetReminderEnabled(ctx context.Context) ([]*models.UserSleepPreference, error)
 	Create(ctx context.Context, pref *models.UserSleepPreference) error
 	Update(ctx context.Context, pref *models.UserSleepPreference) error
 	Delete(ctx context.Context, userID int64) error
 	GetDefaultPreference(userID int64) *models.UserSleepPreference
 }
+
+// ログインセッションのストアインターフェイス
+type SessionStore interface {
+	GetByID(ctx context.Context, id string) (*models.Session, error)
+	Create(ctx context.Context, session *models.Session) error
+	UpdateExpires(ctx context.Context, id string, expires time.Time) error
+	Delete(ctx context.Context, id string) error
+	DeleteByUserID(ctx context.Context, userID int64) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// ログイン失敗の監査記録のリポジトリーインターフェイス
+type LoginFailureRepository interface {
+	Create(ctx context.Context, failure *models.LoginFailure) error
+	GetByEmail(ctx context.Context, email string, since time.Time) ([]*models.LoginFailure, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// パスワード再設定用トークンのリポジトリーインターフェイス
+type PasswordResetTokenRepository interface {
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	DeleteByUserID(ctx context.Context, userID int64) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// 通知の送信履歴のリポジトリーインターフェイス
+type NotificationDeliveryRepository interface {
+	Exists(ctx context.Context, userID int64, kind, periodKey string) (bool, error)
+	Create(ctx context.Context, delivery *models.NotificationDelivery) error
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ユーザーの通知設定のリポジトリーインターフェイス
+type NotificationSettingsRepository interface {
+	GetByUserID(ctx context.Context, userID int64) (*models.NotificationSettings, error)
+	Create(ctx context.Context, settings *models.NotificationSettings) error
+	Update(ctx context.Context, settings *models.NotificationSettings) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+// パーソナルアクセストークンのリポジトリーインターフェイス
+type PersonalAccessTokenRepository interface {
+	GetByID(ctx context.Context, id int64) (*models.PersonalAccessToken, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	GetByUserID(ctx context.Context, userID int64) ([]*models.PersonalAccessToken, error)
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+	UpdateLastUsed(ctx context.Context, id int64, lastUsed time.Time) error
+	Revoke(ctx context.Context, id int64, revoked time.Time) error
+	DeleteByUserID(ctx context.Context, userID int64) error
+}
+
+// 日別集計データのリポジトリーインターフェイス
+type DailySummaryRepository interface {
+	GetByDateRange(ctx context.Context, userID int64, startDate, endDate string) ([]*models.DailySummary, error)
+	Create(ctx context.Context, summary *models.DailySummary) error
+	DeleteByDateRange(ctx context.Context, userID int64, startDate, endDate string) (int64, error)
+}
@@ -0,0 +1,1107 @@
+// internal/repository/repotest/repotest.go
+// repotestは、repository.Repository の実装が同じ振る舞いをすることを確認する適合性テストを提供します。
+// 各実装のテストから Run を呼び出して利用します。
+//
+//	func TestRepository(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repository.Repository {
+//			return memory.NewMemoryRepository()
+//		})
+//	}
+//
+// newRepo は、睡眠状態・食事種別の初期データ（doc/table.md）が登録された空のリポジトリを返す必要があります。
+
+// Package repotest provides a conformance suite for repository.Repository implementations.
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+)
+
+// リポジトリを作成する関数
+type Factory func(t *testing.T) repository.Repository
+
+// 適合性テストを実行
+func Run(t *testing.T, newRepo Factory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo repository.Repository)
+	}{
+		{"User", testUser},
+		{"SleepDiary", testSleepDiary},
+		{"SleepRecord", testSleepRecord},
+		{"SleepState", testSleepState},
+		{"MealType", testMealType},
+		{"UserSleepPreference", testUserSleepPreference},
+		{"Session", testSession},
+		{"LoginFailure", testLoginFailure},
+		{"PasswordResetToken", testPasswordResetToken},
+		{"NotificationDelivery", testNotificationDelivery},
+		{"NotificationSettings", testNotificationSettings},
+		{"PersonalAccessToken", testPersonalAccessToken},
+		{"DailySummary", testDailySummary},
+		{"Transaction", testTransaction},
+		{"NestedTransaction", testNestedTransaction},
+		{"TransactionPanic", testTransactionPanic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// ユーザーのリポジトリ
+func testUser(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	users := repo.User()
+
+	user := createUser(t, repo, "user@example.com")
+	if user.ID == 0 || user.Created.IsZero() {
+		t.Fatalf("Create: ID and Created must be set, got %+v", user)
+	}
+
+	got, err := users.GetByID(ctx, user.ID)
+	mustNoError(t, "GetByID", err)
+	if got == nil || got.Email != user.Email || got.DisplayName != user.DisplayName {
+		t.Fatalf("GetByID: got %+v, want %+v", got, user)
+	}
+	// タイムゾーンを指定しない場合は既定値
+	if got.TimeZone != models.DefaultTimeZone {
+		t.Fatalf("GetByID: time zone = %q, want %q", got.TimeZone, models.DefaultTimeZone)
+	}
+
+	got, err = users.GetByEmail(ctx, "user@example.com")
+	mustNoError(t, "GetByEmail", err)
+	if got == nil || got.ID != user.ID {
+		t.Fatalf("GetByEmail: got %+v, want ID %d", got, user.ID)
+	}
+
+	other := createUser(t, repo, "other@example.com")
+	all, err := users.GetAll(ctx)
+	mustNoError(t, "GetAll", err)
+	if len(all) != 2 || all[0].ID != user.ID || all[1].ID != other.ID {
+		t.Fatalf("GetAll: got %d users, want [%d %d] in ID order", len(all), user.ID, other.ID)
+	}
+
+	got, err = users.GetByID(ctx, user.ID+1000)
+	mustNoError(t, "GetByID (missing)", err)
+	if got != nil {
+		t.Fatalf("GetByID (missing): got %+v, want nil", got)
+	}
+
+	// メールアドレスの一意制約
+	duplicate := &models.User{Email: user.Email, DisplayName: "dup", PasswordHash: "hash"}
+	if err := users.Create(ctx, duplicate); err == nil {
+		t.Fatalf("Create: duplicate email must fail")
+	}
+
+	user.DisplayName = "updated"
+	user.TimeZone = "America/New_York"
+	mustNoError(t, "Update", users.Update(ctx, user))
+	got, _ = users.GetByID(ctx, user.ID)
+	if got.DisplayName != "updated" || got.TimeZone != "America/New_York" {
+		t.Fatalf("Update: got display name %q and time zone %q", got.DisplayName, got.TimeZone)
+	}
+
+	mustNoError(t, "UpdateLastLogin", users.UpdateLastLogin(ctx, user.ID))
+	got, _ = users.GetByID(ctx, user.ID)
+	if !got.LastLoginDatetime.Valid {
+		t.Fatalf("UpdateLastLogin: last login must be set")
+	}
+
+	// 論理削除後は検索できない
+	mustNoError(t, "Delete", users.Delete(ctx, user.ID))
+	got, err = users.GetByID(ctx, user.ID)
+	mustNoError(t, "GetByID (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByID (deleted): got %+v, want nil", got)
+	}
+	got, err = users.GetByEmail(ctx, user.Email)
+	mustNoError(t, "GetByEmail (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByEmail (deleted): got %+v, want nil", got)
+	}
+	all, err = users.GetAll(ctx)
+	mustNoError(t, "GetAll (deleted)", err)
+	if len(all) != 1 || all[0].ID != other.ID {
+		t.Fatalf("GetAll (deleted): got %d users, want only %d", len(all), other.ID)
+	}
+}
+
+// 睡眠日誌のリポジトリ
+func testSleepDiary(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	diaries := repo.SleepDiary()
+	user := createUser(t, repo, "diary@example.com")
+	other := createUser(t, repo, "other@example.com")
+
+	jan := createDiary(t, repo, user.ID, "2025-01-01", "2025-01-31")
+	feb := createDiary(t, repo, user.ID, "2025-02-01", "2025-02-28")
+	mar := createDiary(t, repo, user.ID, "2025-03-01", "2025-03-31")
+	createDiary(t, repo, other.ID, "2025-01-01", "2025-12-31")
+
+	got, err := diaries.GetByID(ctx, feb.ID)
+	mustNoError(t, "GetByID", err)
+	if got == nil || got.DiaryName != feb.DiaryName || date(got.StartDate) != "2025-02-01" || date(got.EndDate) != "2025-02-28" {
+		t.Fatalf("GetByID: got %+v", got)
+	}
+
+	// 開始日の降順
+	list, err := diaries.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID", err)
+	assertDiaryIDs(t, "GetByUserID", list, mar.ID, feb.ID, jan.ID)
+
+	// 期間が重なる日誌を開始日の昇順で取得
+	tests := []struct {
+		start, end string
+		want       []int64
+	}{
+		{"2025-01-15", "2025-02-10", []int64{jan.ID, feb.ID}},
+		{"2025-02-10", "2025-02-12", []int64{feb.ID}},
+		{"2025-01-31", "2025-01-31", []int64{jan.ID}},
+		{"2024-12-01", "2025-04-30", []int64{jan.ID, feb.ID, mar.ID}},
+		{"2025-04-01", "2025-04-30", nil},
+	}
+	for _, tt := range tests {
+		list, err := diaries.GetByDateRange(ctx, user.ID, tt.start, tt.end)
+		mustNoError(t, "GetByDateRange", err)
+		assertDiaryIDs(t, fmt.Sprintf("GetByDateRange(%s, %s)", tt.start, tt.end), list, tt.want...)
+	}
+
+	feb.DiaryName = "renamed"
+	feb.Note = sql.NullString{String: "note", Valid: true}
+	mustNoError(t, "Update", diaries.Update(ctx, feb))
+	got, _ = diaries.GetByID(ctx, feb.ID)
+	if got.DiaryName != "renamed" || got.Note.String != "note" {
+		t.Fatalf("Update: got %+v", got)
+	}
+
+	mustNoError(t, "Delete", diaries.Delete(ctx, feb.ID))
+	got, err = diaries.GetByID(ctx, feb.ID)
+	mustNoError(t, "GetByID (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByID (deleted): got %+v, want nil", got)
+	}
+	list, _ = diaries.GetByUserID(ctx, user.ID)
+	assertDiaryIDs(t, "GetByUserID (after delete)", list, mar.ID, jan.ID)
+	list, _ = diaries.GetByDateRange(ctx, user.ID, "2025-02-01", "2025-02-28")
+	assertDiaryIDs(t, "GetByDateRange (after delete)", list)
+}
+
+// 睡眠記録のリポジトリ
+func testSleepRecord(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	records := repo.SleepRecord()
+	user := createUser(t, repo, "record@example.com")
+	diary := createDiary(t, repo, user.ID, "2025-01-01", "2025-01-31")
+	sleeping := stateByCode(t, repo, models.StateCodeSleeping)
+	awake := stateByCode(t, repo, models.StateCodeAwake)
+	breakfast, err := repo.MealType().GetByCode(ctx, models.MealCodeBreakfast)
+	mustNoError(t, "MealType.GetByCode", err)
+
+	// 登録順と異なる順序で作成し、記録日・時間枠の昇順で取得できることを確認
+	r3 := newRecord(diary.ID, awake.ID, "2025-01-02", "07:00")
+	r1 := newRecord(diary.ID, sleeping.ID, "2025-01-01", "23:30")
+	r2 := newRecord(diary.ID, sleeping.ID, "2025-01-02", "00:00")
+	mustNoError(t, "BulkCreate", records.BulkCreate(ctx, []*models.SleepRecord{r3, r1, r2}))
+	meal := newRecord(diary.ID, awake.ID, "2025-01-05", "08:00")
+	meal.RecordType = models.RecordTypeMeal
+	meal.MealTypeID = sql.NullInt64{Int64: breakfast.ID, Valid: true}
+	meal.Note = sql.NullString{String: "toast", Valid: true}
+	mustNoError(t, "Create", records.Create(ctx, meal))
+
+	// 作成日時・更新日時が設定されている場合は、その値で保存する（バックアップからの復元）
+	created := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	modified := time.Date(2024, 6, 2, 21, 30, 0, 0, time.UTC)
+	restored := newRecord(diary.ID, awake.ID, "2025-01-06", "12:00")
+	restored.Created, restored.Modified = created, modified
+	bulkRestored := newRecord(diary.ID, awake.ID, "2025-01-06", "12:30")
+	bulkRestored.Created, bulkRestored.Modified = created, modified
+	mustNoError(t, "Create (timestamps)", records.Create(ctx, restored))
+	mustNoError(t, "BulkCreate (timestamps)", records.BulkCreate(ctx, []*models.SleepRecord{bulkRestored}))
+	for _, record := range []*models.SleepRecord{restored, bulkRestored} {
+		got, err := records.GetByID(ctx, record.ID)
+		mustNoError(t, "GetByID (timestamps)", err)
+		if got == nil || !got.Created.Equal(created) || !got.Modified.Equal(modified) {
+			t.Fatalf("GetByID (timestamps): got %+v, want created %v, modified %v", got, created, modified)
+		}
+	}
+	mustNoError(t, "Delete (timestamps)", records.Delete(ctx, restored.ID))
+	mustNoError(t, "Delete (timestamps)", records.Delete(ctx, bulkRestored.ID))
+
+	got, err := records.GetByID(ctx, r1.ID)
+	mustNoError(t, "GetByID", err)
+	if got == nil || date(got.RecordDate) != "2025-01-01" || got.TimeSlot.Format("15:04") != "23:30" || got.SleepStateID != sleeping.ID {
+		t.Fatalf("GetByID: got %+v", got)
+	}
+
+	list, err := records.GetByDiaryID(ctx, diary.ID)
+	mustNoError(t, "GetByDiaryID", err)
+	assertRecordIDs(t, "GetByDiaryID", list, r1.ID, r2.ID, r3.ID, meal.ID)
+
+	list, err = records.GetByDateRange(ctx, diary.ID, "2025-01-02", "2025-01-04")
+	mustNoError(t, "GetByDateRange", err)
+	assertRecordIDs(t, "GetByDateRange", list, r2.ID, r3.ID)
+
+	withRelations, err := records.GetWithRelations(ctx, meal.ID)
+	mustNoError(t, "GetWithRelations", err)
+	if withRelations == nil || withRelations.State.StateCode != models.StateCodeAwake ||
+		withRelations.Diary.ID != diary.ID || withRelations.MealType == nil ||
+		withRelations.MealType.TypeCode != models.MealCodeBreakfast || withRelations.Note.String != "toast" {
+		t.Fatalf("GetWithRelations: got %+v", withRelations)
+	}
+
+	// 存在しない睡眠日誌への記録は外部キー制約で失敗する
+	if err := records.Create(ctx, newRecord(diary.ID+1000, sleeping.ID, "2025-01-01", "00:00")); err == nil {
+		t.Fatalf("Create: record for missing diary must fail")
+	}
+
+	r3.SleepStateID = sleeping.ID
+	r3.TimeSlot = timeOfDay("06:30")
+	mustNoError(t, "Update", records.Update(ctx, r3))
+	got, _ = records.GetByID(ctx, r3.ID)
+	if got.SleepStateID != sleeping.ID || got.TimeSlot.Format("15:04") != "06:30" {
+		t.Fatalf("Update: got %+v", got)
+	}
+
+	// 記録日を変更した記録は、変更後の記録日を含む睡眠日誌に移る
+	february := createDiary(t, repo, user.ID, "2025-02-01", "2025-02-28")
+	moved := newRecord(diary.ID, awake.ID, "2025-01-10", "12:00")
+	mustNoError(t, "Create (moved)", records.Create(ctx, moved))
+	moved.SleepDiaryID = february.ID
+	moved.RecordDate = day("2025-02-03")
+	mustNoError(t, "Update (diary)", records.Update(ctx, moved))
+	got, _ = records.GetByID(ctx, moved.ID)
+	if got.SleepDiaryID != february.ID || date(got.RecordDate) != "2025-02-03" {
+		t.Fatalf("Update (diary): got %+v", got)
+	}
+	list, _ = records.GetByDiaryID(ctx, february.ID)
+	assertRecordIDs(t, "GetByDiaryID (moved)", list, moved.ID)
+
+	mustNoError(t, "Delete", records.Delete(ctx, r2.ID))
+	got, err = records.GetByID(ctx, r2.ID)
+	mustNoError(t, "GetByID (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByID (deleted): got %+v, want nil", got)
+	}
+	list, _ = records.GetByDiaryID(ctx, diary.ID)
+	assertRecordIDs(t, "GetByDiaryID (after delete)", list, r1.ID, r3.ID, meal.ID)
+}
+
+// 睡眠状態のリポジトリ
+func testSleepState(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	states := repo.SleepState()
+
+	// 初期データが表示順で取得できる
+	list, err := states.GetAll(ctx)
+	mustNoError(t, "GetAll", err)
+	defaults := models.DefaultSleepStates()
+	if len(list) != len(defaults) {
+		t.Fatalf("GetAll: got %d states, want %d", len(list), len(defaults))
+	}
+	for i, state := range list {
+		if state.StateCode != defaults[i].StateCode || state.DisplaySymbol != defaults[i].DisplaySymbol {
+			t.Fatalf("GetAll[%d]: got %s, want %s", i, state.StateCode, defaults[i].StateCode)
+		}
+	}
+
+	custom := &models.SleepState{StateName: "昼寝", StateCode: "NAP", DisplaySymbol: "N", DisplayOrder: 0}
+	mustNoError(t, "Create", states.Create(ctx, custom))
+	if err := states.Create(ctx, &models.SleepState{StateName: "dup", StateCode: "NAP", DisplaySymbol: "D"}); err == nil {
+		t.Fatalf("Create: duplicate state code must fail")
+	}
+
+	list, _ = states.GetAll(ctx)
+	if len(list) == 0 || list[0].ID != custom.ID {
+		t.Fatalf("GetAll: state with display order 0 must come first")
+	}
+
+	custom.StateName = "仮眠"
+	mustNoError(t, "Update", states.Update(ctx, custom))
+	got, err := states.GetByID(ctx, custom.ID)
+	mustNoError(t, "GetByID", err)
+	if got == nil || got.StateName != "仮眠" {
+		t.Fatalf("Update: got %+v", got)
+	}
+
+	mustNoError(t, "Delete", states.Delete(ctx, custom.ID))
+	got, err = states.GetByCode(ctx, "NAP")
+	mustNoError(t, "GetByCode (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByCode (deleted): got %+v, want nil", got)
+	}
+}
+
+// 食事種別のリポジトリ
+func testMealType(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	mealTypes := repo.MealType()
+
+	list, err := mealTypes.GetAll(ctx)
+	mustNoError(t, "GetAll", err)
+	defaults := models.DefaultMealTypes()
+	if len(list) != len(defaults) {
+		t.Fatalf("GetAll: got %d meal types, want %d", len(list), len(defaults))
+	}
+	for i, mealType := range list {
+		if mealType.TypeCode != defaults[i].TypeCode {
+			t.Fatalf("GetAll[%d]: got %s, want %s", i, mealType.TypeCode, defaults[i].TypeCode)
+		}
+	}
+
+	snack, err := mealTypes.GetByCode(ctx, models.MealCodeSnack)
+	mustNoError(t, "GetByCode", err)
+	if snack == nil {
+		t.Fatalf("GetByCode: snack not found")
+	}
+
+	mustNoError(t, "Delete", mealTypes.Delete(ctx, snack.ID))
+	got, err := mealTypes.GetByID(ctx, snack.ID)
+	mustNoError(t, "GetByID (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByID (deleted): got %+v, want nil", got)
+	}
+	list, _ = mealTypes.GetAll(ctx)
+	if len(list) != len(defaults)-1 {
+		t.Fatalf("GetAll (after delete): got %d meal types, want %d", len(list), len(defaults)-1)
+	}
+}
+
+// ユーザー睡眠設定のリポジトリ
+func testUserSleepPreference(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	prefs := repo.UserSleepPreference()
+	user := createUser(t, repo, "pref@example.com")
+
+	got, err := prefs.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID (missing)", err)
+	if got != nil {
+		t.Fatalf("GetByUserID (missing): got %+v, want nil", got)
+	}
+
+	pref := prefs.GetDefaultPreference(user.ID)
+	mustNoError(t, "Create", prefs.Create(ctx, pref))
+
+	got, err = prefs.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID", err)
+	if got == nil || got.PreferredBedtime.Format("15:04") != "23:00" ||
+		got.PreferredWakeupTime.Format("15:04") != "07:00" || got.SleepGoalHours != 8 || !got.IsReminderEnabled {
+		t.Fatalf("GetByUserID: got %+v", got)
+	}
+
+	// リマインダーが有効な設定（削除済みのユーザーの設定は除く）
+	deletedUser := createUser(t, repo, "pref-deleted@example.com")
+	mustNoError(t, "Create", prefs.Create(ctx, prefs.GetDefaultPreference(deletedUser.ID)))
+	mustNoError(t, "User.Delete", repo.User().Delete(ctx, deletedUser.ID))
+	enabled, err := prefs.GetReminderEnabled(ctx)
+	mustNoError(t, "GetReminderEnabled", err)
+	if len(enabled) != 1 || enabled[0].UserID != user.ID || enabled[0].PreferredBedtime.Format("15:04") != "23:00" {
+		t.Fatalf("GetReminderEnabled: got %d preferences, want only user %d", len(enabled), user.ID)
+	}
+
+	pref.SleepGoalHours = 7
+	pref.IsReminderEnabled = false
+	pref.PreferredBedtime = timeOfDay("22:30")
+	mustNoError(t, "Update", prefs.Update(ctx, pref))
+	got, _ = prefs.GetByUserID(ctx, user.ID)
+	if got.SleepGoalHours != 7 || got.IsReminderEnabled || got.PreferredBedtime.Format("15:04") != "22:30" {
+		t.Fatalf("Update: got %+v", got)
+	}
+	enabled, err = prefs.GetReminderEnabled(ctx)
+	mustNoError(t, "GetReminderEnabled (disabled)", err)
+	if len(enabled) != 0 {
+		t.Fatalf("GetReminderEnabled (disabled): got %d preferences, want 0", len(enabled))
+	}
+
+	mustNoError(t, "Delete", prefs.Delete(ctx, user.ID))
+	got, err = prefs.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByUserID (deleted): got %+v, want nil", got)
+	}
+}
+
+// ログインセッションのストア
+func testSession(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	sessions := repo.Session()
+	user := createUser(t, repo, "session@example.com")
+	now := time.Now()
+
+	active := &models.Session{ID: "active", UserID: user.ID, Expires: now.Add(time.Hour)}
+	expired := &models.Session{ID: "expired", UserID: user.ID, Expires: now.Add(-time.Hour)}
+	mustNoError(t, "Create", sessions.Create(ctx, active))
+	mustNoError(t, "Create", sessions.Create(ctx, expired))
+
+	got, err := sessions.GetByID(ctx, "active")
+	mustNoError(t, "GetByID", err)
+	if got == nil || got.UserID != user.ID {
+		t.Fatalf("GetByID: got %+v", got)
+	}
+
+	extended := now.Add(2 * time.Hour).Truncate(time.Second)
+	mustNoError(t, "UpdateExpires", sessions.UpdateExpires(ctx, "active", extended))
+	got, _ = sessions.GetByID(ctx, "active")
+	if !got.Expires.Equal(extended) {
+		t.Fatalf("UpdateExpires: expires = %v, want %v", got.Expires, extended)
+	}
+
+	count, err := sessions.DeleteExpired(ctx, now)
+	mustNoError(t, "DeleteExpired", err)
+	if count != 1 {
+		t.Fatalf("DeleteExpired: deleted %d sessions, want 1", count)
+	}
+
+	mustNoError(t, "DeleteByUserID", sessions.DeleteByUserID(ctx, user.ID))
+	got, err = sessions.GetByID(ctx, "active")
+	mustNoError(t, "GetByID (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByID (deleted): got %+v, want nil", got)
+	}
+}
+
+// ログイン失敗の監査記録
+func testLoginFailure(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	failures := repo.LoginFailure()
+	since := time.Now().Add(-time.Minute)
+
+	// 存在しないユーザーのメールアドレスも記録できる
+	first := &models.LoginFailure{Email: "nobody@example.com", IPAddress: "192.0.2.1", Reason: models.LoginFailureInvalidCredentials}
+	mustNoError(t, "Create", failures.Create(ctx, first))
+	second := &models.LoginFailure{Email: "nobody@example.com", IPAddress: "192.0.2.2", Reason: models.LoginFailureLocked}
+	mustNoError(t, "Create", failures.Create(ctx, second))
+	other := &models.LoginFailure{Email: "other@example.com", IPAddress: "192.0.2.1", Reason: models.LoginFailureThrottled}
+	mustNoError(t, "Create", failures.Create(ctx, other))
+	if first.ID == 0 || first.Created.IsZero() {
+		t.Fatalf("Create: ID and Created must be set, got %+v", first)
+	}
+
+	list, err := failures.GetByEmail(ctx, "nobody@example.com", since)
+	mustNoError(t, "GetByEmail", err)
+	if len(list) != 2 || list[0].ID != second.ID || list[1].ID != first.ID {
+		t.Fatalf("GetByEmail: got %d failures, want [%d %d] in newest-first order", len(list), second.ID, first.ID)
+	}
+	if list[0].IPAddress != "192.0.2.2" || list[0].Reason != models.LoginFailureLocked {
+		t.Fatalf("GetByEmail: got %+v", list[0])
+	}
+
+	list, err = failures.GetByEmail(ctx, "nobody@example.com", time.Now().Add(time.Minute))
+	mustNoError(t, "GetByEmail (future)", err)
+	if len(list) != 0 {
+		t.Fatalf("GetByEmail (future): got %d failures, want 0", len(list))
+	}
+
+	count, err := failures.DeleteBefore(ctx, time.Now().Add(time.Minute))
+	mustNoError(t, "DeleteBefore", err)
+	if count != 3 {
+		t.Fatalf("DeleteBefore: deleted %d failures, want 3", count)
+	}
+}
+
+// パスワード再設定用トークン
+func testPasswordResetToken(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	tokens := repo.PasswordResetToken()
+	user := createUser(t, repo, "reset@example.com")
+	now := time.Now()
+
+	active := &models.PasswordResetToken{UserID: user.ID, TokenHash: "active-hash", Expires: now.Add(time.Hour)}
+	expired := &models.PasswordResetToken{UserID: user.ID, TokenHash: "expired-hash", Expires: now.Add(-time.Hour)}
+	mustNoError(t, "Create", tokens.Create(ctx, active))
+	mustNoError(t, "Create", tokens.Create(ctx, expired))
+	if active.ID == 0 || active.Created.IsZero() {
+		t.Fatalf("Create: ID and Created must be set, got %+v", active)
+	}
+
+	// ハッシュ値は一意
+	if err := tokens.Create(ctx, &models.PasswordResetToken{UserID: user.ID, TokenHash: "active-hash", Expires: now}); err == nil {
+		t.Fatal("Create (duplicate hash): expected error")
+	}
+	// ユーザーへの外部キー
+	if err := tokens.Create(ctx, &models.PasswordResetToken{UserID: 99999, TokenHash: "orphan-hash", Expires: now}); err == nil {
+		t.Fatal("Create (unknown user): expected error")
+	}
+
+	got, err := tokens.GetByTokenHash(ctx, "active-hash")
+	mustNoError(t, "GetByTokenHash", err)
+	if got == nil || got.ID != active.ID || got.UserID != user.ID || got.IsExpired(now) {
+		t.Fatalf("GetByTokenHash: got %+v", got)
+	}
+	got, err = tokens.GetByTokenHash(ctx, "unknown-hash")
+	mustNoError(t, "GetByTokenHash (unknown)", err)
+	if got != nil {
+		t.Fatalf("GetByTokenHash (unknown): got %+v, want nil", got)
+	}
+
+	count, err := tokens.DeleteExpired(ctx, now)
+	mustNoError(t, "DeleteExpired", err)
+	if count != 1 {
+		t.Fatalf("DeleteExpired: deleted %d tokens, want 1", count)
+	}
+
+	mustNoError(t, "DeleteByUserID", tokens.DeleteByUserID(ctx, user.ID))
+	got, err = tokens.GetByTokenHash(ctx, "active-hash")
+	mustNoError(t, "GetByTokenHash (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByTokenHash (deleted): got %+v, want nil", got)
+	}
+}
+
+// 通知の送信履歴
+func testNotificationDelivery(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	deliveries := repo.NotificationDelivery()
+	user := createUser(t, repo, "notify@example.com")
+	kind := models.NotificationKindBedtimeReminder
+
+	exists, err := deliveries.Exists(ctx, user.ID, kind, "2025-01-15")
+	mustNoError(t, "Exists (missing)", err)
+	if exists {
+		t.Fatal("Exists (missing): got true, want false")
+	}
+
+	delivery := &models.NotificationDelivery{UserID: user.ID, Kind: kind, PeriodKey: "2025-01-15"}
+	mustNoError(t, "Create", deliveries.Create(ctx, delivery))
+	if delivery.ID == 0 || delivery.Created.IsZero() {
+		t.Fatalf("Create: ID and Created must be set, got %+v", delivery)
+	}
+
+	exists, err = deliveries.Exists(ctx, user.ID, kind, "2025-01-15")
+	mustNoError(t, "Exists", err)
+	if !exists {
+		t.Fatal("Exists: got false, want true")
+	}
+	exists, _ = deliveries.Exists(ctx, user.ID, kind, "2025-01-16")
+	if exists {
+		t.Fatal("Exists (other period): got true, want false")
+	}
+
+	// ユーザー・種類・対象期間の組み合わせは一意
+	if err := deliveries.Create(ctx, &models.NotificationDelivery{UserID: user.ID, Kind: kind, PeriodKey: "2025-01-15"}); err == nil {
+		t.Fatal("Create (duplicate): expected error")
+	}
+
+	count, err := deliveries.DeleteBefore(ctx, time.Now().Add(time.Minute))
+	mustNoError(t, "DeleteBefore", err)
+	if count != 1 {
+		t.Fatalf("DeleteBefore: deleted %d deliveries, want 1", count)
+	}
+}
+
+// ユーザーの通知設定のリポジトリ
+func testNotificationSettings(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	notifications := repo.NotificationSettings()
+	user := createUser(t, repo, "settings@example.com")
+
+	got, err := notifications.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID (missing)", err)
+	if got != nil {
+		t.Fatalf("GetByUserID (missing): got %+v, want nil", got)
+	}
+
+	settings := models.DefaultNotificationSettings(user.ID)
+	settings.ReminderTime = "22:30"
+	settings.WeeklyReport = true
+	mustNoError(t, "Create", notifications.Create(ctx, settings))
+	if settings.ID == 0 || settings.Created.IsZero() {
+		t.Fatalf("Create: ID and Created must be set, got %+v", settings)
+	}
+
+	got, err = notifications.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID", err)
+	if got == nil || got.ID != settings.ID || !got.EmailEnabled || !got.BedtimeReminder || got.ReminderTime != "22:30" || !got.WeeklyReport {
+		t.Fatalf("GetByUserID: got %+v, want %+v", got, settings)
+	}
+
+	// ユーザーごとに1件
+	if err := notifications.Create(ctx, models.DefaultNotificationSettings(user.ID)); err == nil {
+		t.Fatal("Create (duplicate): expected error")
+	}
+
+	settings.EmailEnabled = false
+	settings.ReminderTime = ""
+	settings.WeeklyReport = false
+	mustNoError(t, "Update", notifications.Update(ctx, settings))
+	got, _ = notifications.GetByUserID(ctx, user.ID)
+	if got.EmailEnabled || !got.BedtimeReminder || got.ReminderTime != "" || got.WeeklyReport {
+		t.Fatalf("Update: got %+v", got)
+	}
+
+	mustNoError(t, "Delete", notifications.Delete(ctx, user.ID))
+	got, err = notifications.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID (deleted)", err)
+	if got != nil {
+		t.Fatalf("GetByUserID (deleted): got %+v, want nil", got)
+	}
+}
+
+// パーソナルアクセストークンのリポジトリ
+func testPersonalAccessToken(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	tokens := repo.PersonalAccessToken()
+	user := createUser(t, repo, "token@example.com")
+	now := time.Now()
+
+	script := &models.PersonalAccessToken{UserID: user.ID, Name: "script", TokenPrefix: "sn_aaaa", TokenHash: "script-hash", Scopes: "read"}
+	mobile := &models.PersonalAccessToken{
+		UserID:      user.ID,
+		Name:        "mobile",
+		TokenPrefix: "sn_bbbb",
+		TokenHash:   "mobile-hash",
+		Scopes:      "read write",
+		Expires:     sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+	}
+	mustNoError(t, "Create", tokens.Create(ctx, script))
+	mustNoError(t, "Create", tokens.Create(ctx, mobile))
+	if script.ID == 0 || script.Created.IsZero() {
+		t.Fatalf("Create: ID and Created must be set, got %+v", script)
+	}
+
+	// ハッシュ値は一意
+	if err := tokens.Create(ctx, &models.PersonalAccessToken{UserID: user.ID, Name: "dup", TokenHash: "script-hash", Scopes: "read"}); err == nil {
+		t.Fatal("Create (duplicate hash): expected error")
+	}
+	// ユーザーへの外部キー
+	if err := tokens.Create(ctx, &models.PersonalAccessToken{UserID: 99999, Name: "orphan", TokenHash: "orphan-hash", Scopes: "read"}); err == nil {
+		t.Fatal("Create (unknown user): expected error")
+	}
+
+	got, err := tokens.GetByTokenHash(ctx, "mobile-hash")
+	mustNoError(t, "GetByTokenHash", err)
+	if got == nil || got.ID != mobile.ID || got.Name != "mobile" || got.TokenPrefix != "sn_bbbb" || !got.HasScope(models.AccessTokenScopeWrite) {
+		t.Fatalf("GetByTokenHash: got %+v", got)
+	}
+	if !got.Expires.Valid || got.Expires.Time.Unix() != mobile.Expires.Time.Unix() || got.LastUsed.Valid || got.Revoked.Valid {
+		t.Fatalf("GetByTokenHash: unexpected dates %+v", got)
+	}
+	got, err = tokens.GetByTokenHash(ctx, "unknown-hash")
+	mustNoError(t, "GetByTokenHash (unknown)", err)
+	if got != nil {
+		t.Fatalf("GetByTokenHash (unknown): got %+v, want nil", got)
+	}
+
+	got, err = tokens.GetByID(ctx, script.ID)
+	mustNoError(t, "GetByID", err)
+	if got == nil || got.TokenHash != "script-hash" || got.Expires.Valid || !got.IsActive(now) {
+		t.Fatalf("GetByID: got %+v", got)
+	}
+
+	mustNoError(t, "UpdateLastUsed", tokens.UpdateLastUsed(ctx, script.ID, now))
+	mustNoError(t, "Revoke", tokens.Revoke(ctx, script.ID, now))
+	got, _ = tokens.GetByID(ctx, script.ID)
+	if !got.LastUsed.Valid || got.LastUsed.Time.Unix() != now.Unix() || !got.IsRevoked() {
+		t.Fatalf("Revoke: got %+v", got)
+	}
+	// 無効化済みのトークンの無効化日時は変えない
+	mustNoError(t, "Revoke (again)", tokens.Revoke(ctx, script.ID, now.Add(time.Hour)))
+	got, _ = tokens.GetByID(ctx, script.ID)
+	if got.Revoked.Time.Unix() != now.Unix() {
+		t.Fatalf("Revoke (again): revoked = %v, want %v", got.Revoked.Time, now)
+	}
+
+	list, err := tokens.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID", err)
+	if len(list) != 2 || list[0].ID != script.ID || list[1].ID != mobile.ID {
+		t.Fatalf("GetByUserID: got %d tokens", len(list))
+	}
+
+	mustNoError(t, "DeleteByUserID", tokens.DeleteByUserID(ctx, user.ID))
+	list, err = tokens.GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID (deleted)", err)
+	if len(list) != 0 {
+		t.Fatalf("GetByUserID (deleted): got %d tokens, want 0", len(list))
+	}
+}
+
+// 日別集計データのリポジトリ
+func testDailySummary(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	summaries := repo.DailySummary()
+	user := createUser(t, repo, "summary@example.com")
+	other := createUser(t, repo, "summary-other@example.com")
+
+	first := &models.DailySummary{
+		UserID:              user.ID,
+		SummaryDate:         "2024-01-02",
+		TotalSleepDuration:  7.5,
+		AvgSleepQuality:     93.75,
+		SleepStartTime:      "23:30",
+		SleepEndTime:        "07:00",
+		SleepSegmentCount:   1,
+		IsWithinTargetTime:  true,
+		BedTime:             "23:00",
+		MainSleepMinutes:    450,
+		TimeInBedMinutes:    480,
+		SleepLatencyMinutes: 30,
+		WASOMinutes:         0,
+		NapCount:            1,
+		NapMinutes:          60,
+	}
+	second := &models.DailySummary{UserID: user.ID, SummaryDate: "2024-01-01", TotalSleepDuration: 5.25, SleepSegmentCount: 2}
+	mustNoError(t, "Create", summaries.Create(ctx, first))
+	mustNoError(t, "Create", summaries.Create(ctx, second))
+	mustNoError(t, "Create", summaries.Create(ctx, &models.DailySummary{UserID: other.ID, SummaryDate: "2024-01-01", SleepSegmentCount: 1}))
+	if first.ID == 0 || first.Created.IsZero() {
+		t.Fatalf("Create: ID and Created must be set, got %+v", first)
+	}
+
+	// ユーザーと睡眠日の組み合わせは一意
+	if err := summaries.Create(ctx, &models.DailySummary{UserID: user.ID, SummaryDate: "2024-01-02"}); err == nil {
+		t.Fatal("Create (duplicate date): expected error")
+	}
+	// ユーザーへの外部キー
+	if err := summaries.Create(ctx, &models.DailySummary{UserID: 99999, SummaryDate: "2024-01-02"}); err == nil {
+		t.Fatal("Create (unknown user): expected error")
+	}
+
+	list, err := summaries.GetByDateRange(ctx, user.ID, "2024-01-01", "2024-01-31")
+	mustNoError(t, "GetByDateRange", err)
+	if len(list) != 2 || list[0].ID != second.ID || list[1].ID != first.ID {
+		t.Fatalf("GetByDateRange: got %d summaries", len(list))
+	}
+	got := list[1]
+	if got.UserID != user.ID || got.SummaryDate != "2024-01-02" || got.TotalSleepDuration != 7.5 || got.AvgSleepQuality != 93.75 ||
+		got.SleepStartTime != "23:30" || got.SleepEndTime != "07:00" || got.SleepSegmentCount != 1 || !got.IsWithinTargetTime ||
+		got.BedTime != "23:00" || got.MainSleepMinutes != 450 || got.TimeInBedMinutes != 480 || got.SleepLatencyMinutes != 30 ||
+		got.WASOMinutes != 0 || got.NapCount != 1 || got.NapMinutes != 60 {
+		t.Fatalf("GetByDateRange: got %+v, want %+v", got, first)
+	}
+	list, err = summaries.GetByDateRange(ctx, user.ID, "2024-01-02", "2024-01-02")
+	mustNoError(t, "GetByDateRange (single day)", err)
+	if len(list) != 1 || list[0].ID != first.ID {
+		t.Fatalf("GetByDateRange (single day): got %d summaries", len(list))
+	}
+
+	count, err := summaries.DeleteByDateRange(ctx, user.ID, "2023-12-31", "2024-01-01")
+	mustNoError(t, "DeleteByDateRange", err)
+	if count != 1 {
+		t.Fatalf("DeleteByDateRange: deleted %d, want 1", count)
+	}
+	list, _ = summaries.GetByDateRange(ctx, user.ID, "2024-01-01", "2024-01-31")
+	if len(list) != 1 || list[0].ID != first.ID {
+		t.Fatalf("DeleteByDateRange: got %d summaries, want 1", len(list))
+	}
+	// 他のユーザーの集計データは削除しない
+	list, _ = summaries.GetByDateRange(ctx, other.ID, "2024-01-01", "2024-01-31")
+	if len(list) != 1 {
+		t.Fatalf("DeleteByDateRange: other user has %d summaries, want 1", len(list))
+	}
+}
+
+// トランザクション
+func testTransaction(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	// エラーを返した場合はすべての変更が取り消される
+	var created *models.User
+	err := repo.Transaction(ctx, func(tx repository.Repository) error {
+		created = &models.User{Email: "rollback@example.com", DisplayName: "rollback", PasswordHash: "hash"}
+		if err := tx.User().Create(ctx, created); err != nil {
+			return err
+		}
+		diary := &models.SleepDiary{UserID: created.ID, StartDate: day("2025-01-01"), EndDate: day("2025-01-07"), DiaryName: "rollback"}
+		if err := tx.SleepDiary().Create(ctx, diary); err != nil {
+			return err
+		}
+
+		// トランザクション内では変更が見える
+		got, err := tx.User().GetByID(ctx, created.ID)
+		if err != nil {
+			return err
+		}
+		if got == nil {
+			t.Errorf("Transaction: created user must be visible inside the transaction")
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Transaction: error = %v, want %v", err, errRollback)
+	}
+	got, err := repo.User().GetByEmail(ctx, "rollback@example.com")
+	mustNoError(t, "GetByEmail (rolled back)", err)
+	if got != nil {
+		t.Fatalf("Transaction: rolled back user must not exist, got %+v", got)
+	}
+
+	// 成功した場合は変更が確定する
+	user := createUser(t, repo, "commit@example.com")
+	err = repo.Transaction(ctx, func(tx repository.Repository) error {
+		diary := &models.SleepDiary{UserID: user.ID, StartDate: day("2025-01-01"), EndDate: day("2025-01-07"), DiaryName: "commit"}
+		if err := tx.SleepDiary().Create(ctx, diary); err != nil {
+			return err
+		}
+		return tx.User().UpdateLastLogin(ctx, user.ID)
+	})
+	mustNoError(t, "Transaction (commit)", err)
+	list, _ := repo.SleepDiary().GetByUserID(ctx, user.ID)
+	if len(list) != 1 {
+		t.Fatalf("Transaction: committed diary not found")
+	}
+
+	// 一括作成は途中で失敗した場合にすべて取り消される
+	state := stateByCode(t, repo, models.StateCodeSleeping)
+	records := []*models.SleepRecord{
+		newRecord(list[0].ID, state.ID, "2025-01-01", "23:00"),
+		newRecord(list[0].ID+1000, state.ID, "2025-01-01", "23:30"),
+	}
+	if err := repo.SleepRecord().BulkCreate(ctx, records); err == nil {
+		t.Fatalf("BulkCreate: must fail for missing diary")
+	}
+	stored, _ := repo.SleepRecord().GetByDiaryID(ctx, list[0].ID)
+	if len(stored) != 0 {
+		t.Fatalf("BulkCreate: failed bulk insert must not leave %d records", len(stored))
+	}
+}
+
+// 入れ子のトランザクション（セーブポイント）
+func testNestedTransaction(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+	state := stateByCode(t, repo, models.StateCodeSleeping)
+
+	var user *models.User
+	var diary *models.SleepDiary
+	err := repo.Transaction(ctx, func(tx repository.Repository) error {
+		user = &models.User{Email: "nested@example.com", DisplayName: "nested", PasswordHash: "hash"}
+		if err := tx.User().Create(ctx, user); err != nil {
+			return err
+		}
+		diary = &models.SleepDiary{UserID: user.ID, StartDate: day("2025-01-01"), EndDate: day("2025-01-07"), DiaryName: "nested"}
+		if err := tx.SleepDiary().Create(ctx, diary); err != nil {
+			return err
+		}
+
+		// 内側のトランザクションの失敗は、内側の変更のみを取り消す
+		err := tx.Transaction(ctx, func(inner repository.Repository) error {
+			other := &models.SleepDiary{UserID: user.ID, StartDate: day("2025-02-01"), EndDate: day("2025-02-07"), DiaryName: "inner"}
+			if err := inner.SleepDiary().Create(ctx, other); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Errorf("nested Transaction: error = %v, want %v", err, errRollback)
+		}
+
+		// トランザクション中の一括作成の失敗も、一括作成分のみを取り消す
+		failed := []*models.SleepRecord{
+			newRecord(diary.ID, state.ID, "2025-01-01", "23:00"),
+			newRecord(diary.ID+1000, state.ID, "2025-01-01", "23:30"),
+		}
+		if err := tx.SleepRecord().BulkCreate(ctx, failed); err == nil {
+			t.Errorf("BulkCreate: must fail for missing diary")
+		}
+
+		return tx.SleepRecord().BulkCreate(ctx, []*models.SleepRecord{
+			newRecord(diary.ID, state.ID, "2025-01-02", "00:00"),
+		})
+	})
+	mustNoError(t, "Transaction (nested)", err)
+
+	list, err := repo.SleepDiary().GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID", err)
+	assertDiaryIDs(t, "GetByUserID (after nested rollback)", list, diary.ID)
+
+	records, err := repo.SleepRecord().GetByDiaryID(ctx, diary.ID)
+	mustNoError(t, "GetByDiaryID", err)
+	if len(records) != 1 || records[0].TimeSlot.Format("15:04") != "00:00" {
+		t.Fatalf("GetByDiaryID (after nested rollback): got %d records, want only the committed one", len(records))
+	}
+
+	// 外側のトランザクションの失敗は、内側で確定した変更も取り消す
+	err = repo.Transaction(ctx, func(tx repository.Repository) error {
+		err := tx.Transaction(ctx, func(inner repository.Repository) error {
+			return inner.SleepDiary().Delete(ctx, diary.ID)
+		})
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Transaction: error = %v, want %v", err, errRollback)
+	}
+	got, err := repo.SleepDiary().GetByID(ctx, diary.ID)
+	mustNoError(t, "GetByID (outer rolled back)", err)
+	if got == nil {
+		t.Fatalf("Transaction: inner change must be rolled back with the outer transaction")
+	}
+}
+
+// トランザクション中のパニック
+func testTransactionPanic(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	// 内側のトランザクションでパニックした場合は、内側の変更のみを取り消してパニックを伝える
+	var user *models.User
+	err := repo.Transaction(ctx, func(tx repository.Repository) error {
+		user = &models.User{Email: "panic@example.com", DisplayName: "panic", PasswordHash: "hash"}
+		if err := tx.User().Create(ctx, user); err != nil {
+			return err
+		}
+
+		recovered := catchPanic(func() {
+			tx.Transaction(ctx, func(inner repository.Repository) error {
+				diary := &models.SleepDiary{UserID: user.ID, StartDate: day("2025-01-01"), EndDate: day("2025-01-07"), DiaryName: "inner"}
+				if err := inner.SleepDiary().Create(ctx, diary); err != nil {
+					return err
+				}
+				panic("inner")
+			})
+		})
+		if recovered != "inner" {
+			t.Errorf("nested Transaction: recovered %v, want the panic value", recovered)
+		}
+		return nil
+	})
+	mustNoError(t, "Transaction (inner panic)", err)
+	list, err := repo.SleepDiary().GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID (inner panic)", err)
+	assertDiaryIDs(t, "GetByUserID (inner panic)", list)
+
+	// 外側のトランザクションでパニックした場合は、すべての変更を取り消してパニックを伝える
+	recovered := catchPanic(func() {
+		repo.Transaction(ctx, func(tx repository.Repository) error {
+			diary := &models.SleepDiary{UserID: user.ID, StartDate: day("2025-02-01"), EndDate: day("2025-02-07"), DiaryName: "outer"}
+			if err := tx.SleepDiary().Create(ctx, diary); err != nil {
+				return err
+			}
+			panic("outer")
+		})
+	})
+	if recovered != "outer" {
+		t.Fatalf("Transaction: recovered %v, want the panic value", recovered)
+	}
+
+	// ロールバック後も接続を使える
+	list, err = repo.SleepDiary().GetByUserID(ctx, user.ID)
+	mustNoError(t, "GetByUserID (outer panic)", err)
+	assertDiaryIDs(t, "GetByUserID (outer panic)", list)
+	createDiary(t, repo, user.ID, "2025-03-01", "2025-03-07")
+}
+
+// 関数を実行し、パニックした場合はその値を返す
+func catchPanic(fn func()) (recovered any) {
+	defer func() {
+		recovered = recover()
+	}()
+	fn()
+	return nil
+}
+
+// ユーザーを作成
+func createUser(t *testing.T, repo repository.Repository, email string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email, DisplayName: "テストユーザー", PasswordHash: "hash"}
+	mustNoError(t, "User.Create", repo.User().Create(context.Background(), user))
+	return user
+}
+
+// 睡眠日誌を作成
+func createDiary(t *testing.T, repo repository.Repository, userID int64, start, end string) *models.SleepDiary {
+	t.Helper()
+	diary := &models.SleepDiary{UserID: userID, StartDate: day(start), EndDate: day(end), DiaryName: start + "〜" + end}
+	mustNoError(t, "SleepDiary.Create", repo.SleepDiary().Create(context.Background(), diary))
+	return diary
+}
+
+// 睡眠状態をコードで取得
+func stateByCode(t *testing.T, repo repository.Repository, code string) *models.SleepState {
+	t.Helper()
+	state, err := repo.SleepState().GetByCode(context.Background(), code)
+	mustNoError(t, "SleepState.GetByCode", err)
+	if state == nil {
+		t.Fatalf("SleepState.GetByCode: %s not found (initial data must be registered)", code)
+	}
+	return state
+}
+
+// 睡眠記録を生成
+func newRecord(diaryID, stateID int64, recordDate, slot string) *models.SleepRecord {
+	return &models.SleepRecord{
+		SleepDiaryID: diaryID,
+		SleepStateID: stateID,
+		RecordDate:   day(recordDate),
+		TimeSlot:     timeOfDay(slot),
+		RecordType:   models.RecordTypeState,
+	}
+}
+
+// 日誌IDの一覧を検証
+func assertDiaryIDs(t *testing.T, name string, diaries []*models.SleepDiary, want ...int64) {
+	t.Helper()
+	got := make([]int64, len(diaries))
+	for i, diary := range diaries {
+		got[i] = diary.ID
+	}
+	assertIDs(t, name, got, want)
+}
+
+// 記録IDの一覧を検証
+func assertRecordIDs(t *testing.T, name string, records []*models.SleepRecord, want ...int64) {
+	t.Helper()
+	got := make([]int64, len(records))
+	for i, record := range records {
+		got[i] = record.ID
+	}
+	assertIDs(t, name, got, want)
+}
+
+// IDの一覧を順序を含めて検証
+func assertIDs(t *testing.T, name string, got, want []int64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got IDs %v, want %v", name, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got IDs %v, want %v", name, got, want)
+		}
+	}
+}
+
+// エラーがないことを検証
+func mustNoError(t *testing.T, name string, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", name, err)
+	}
+}
+
+// "2006-01-02" 形式の日付を time.Time に変換
+func day(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// "15:04" 形式の時刻を time.Time に変換
+func timeOfDay(s string) time.Time {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// 日付を比較用の文字列に変換
+func date(t time.Time) string {
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,132 @@
+// internal/repository/sqlite/daily_summary_repository.go
+// daily_summary_repositoryは、日別集計データのリポジトリを提供します。
+
+// Package sqlite provides SQLite repository implementations.
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// DailySummaryRepositoryのSQLite実装
+type DailySummaryRepository struct {
+	repo *SQLiteRepository
+}
+
+// 日付範囲でユーザーの日別集計データを検索（睡眠日の昇順）
+func (r *DailySummaryRepository) GetByDateRange(ctx context.Context, userID int64, startDate, endDate string) ([]*models.DailySummary, error) {
+	query := `
+		SELECT id, user_id, summary_date, total_sleep_duration, avg_sleep_quality, sleep_start_time, sleep_end_time,
+			sleep_segment_count, is_within_target_time, bed_time, main_sleep_minutes, time_in_bed_minutes,
+			sleep_latency_minutes, waso_minutes, nap_count, nap_minutes, created, modified
+		FROM daily_summaries
+		WHERE user_id = ? AND summary_date BETWEEN ? AND ?
+		ORDER BY summary_date
+	`
+
+	rows, err := r.repo.getDB().QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*models.DailySummary
+	for rows.Next() {
+		summary := &models.DailySummary{}
+		var summaryDate time.Time
+		err := rows.Scan(
+			&summary.ID,
+			&summary.UserID,
+			&summaryDate,
+			&summary.TotalSleepDuration,
+			&summary.AvgSleepQuality,
+			&summary.SleepStartTime,
+			&summary.SleepEndTime,
+			&summary.SleepSegmentCount,
+			&summary.IsWithinTargetTime,
+			&summary.BedTime,
+			&summary.MainSleepMinutes,
+			&summary.TimeInBedMinutes,
+			&summary.SleepLatencyMinutes,
+			&summary.WASOMinutes,
+			&summary.NapCount,
+			&summary.NapMinutes,
+			&summary.Created,
+			&summary.Modified,
+		)
+		if err != nil {
+			return nil, err
+		}
+		summary.SummaryDate = formatDate(summaryDate)
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// 新規日別集計データを作成
+func (r *DailySummaryRepository) Create(ctx context.Context, summary *models.DailySummary) error {
+	query := `
+		INSERT INTO daily_summaries (
+			user_id, summary_date, total_sleep_duration, avg_sleep_quality, sleep_start_time, sleep_end_time,
+			sleep_segment_count, is_within_target_time, bed_time, main_sleep_minutes, time_in_bed_minutes,
+			sleep_latency_minutes, waso_minutes, nap_count, nap_minutes, created, modified
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.repo.getDB().ExecContext(ctx, query,
+		summary.UserID,
+		summary.SummaryDate,
+		summary.TotalSleepDuration,
+		summary.AvgSleepQuality,
+		summary.SleepStartTime,
+		summary.SleepEndTime,
+		summary.SleepSegmentCount,
+		summary.IsWithinTargetTime,
+		summary.BedTime,
+		summary.MainSleepMinutes,
+		summary.TimeInBedMinutes,
+		summary.SleepLatencyMinutes,
+		summary.WASOMinutes,
+		summary.NapCount,
+		summary.NapMinutes,
+		now,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	summary.ID = id
+	summary.Created = now
+	summary.Modified = now
+
+	return nil
+}
+
+// 日付範囲のユーザーの日別集計データを削除（削除した件数を返す）
+func (r *DailySummaryRepository) DeleteByDateRange(ctx context.Context, userID int64, startDate, endDate string) (int64, error) {
+	query := `
+		DELETE FROM daily_summaries
+		WHERE user_id = ? AND summary_date BETWEEN ? AND ?
+	`
+
+	result, err := r.repo.getDB().ExecContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,84 @@
+// internal/repository/sqlite/db.go
+// dbは、SQLiteデータベース接続を提供します。
+
+// Package sqlite provides SQLite repository implementations.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/migration"
+
+	// SQLiteドライバを使用するために必要
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// インメモリデータベースを表すパス
+const MemoryPath = ":memory:"
+
+// データベース接続設定
+type DBConfig struct {
+	Path string // データベースファイルのパス（":memory:" の場合はインメモリ）
+}
+
+// データベース接続を初期化
+// 未適用のマイグレーションを適用し、テーブルと睡眠状態・食事種別の初期データを作成する
+func NewDB(config DBConfig) (*sql.DB, error) {
+	db, err := Open(config)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := migration.NewMigrator(db, migration.DialectSQLite)
+	if err == nil {
+		_, err = migrator.Up(context.Background())
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %v", err)
+	}
+
+	return db, nil
+}
+
+// データベースに接続（マイグレーションは行わない）
+func Open(config DBConfig) (*sql.DB, error) {
+	dsn := "file::memory:?_loc=auto&_foreign_keys=on"
+	if config.Path != MemoryPath {
+		if dir := filepath.Dir(config.Path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create database directory: %v", err)
+			}
+		}
+		dsn = fmt.Sprintf("file:%s?_loc=auto&_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL", config.Path)
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	// 接続テスト
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	// コネクションプールの設定
+	// インメモリの場合は接続ごとに別のデータベースになるため、接続を1つに限定する
+	if config.Path == MemoryPath {
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxLifetime(0)
+	} else {
+		db.SetMaxOpenConns(4)
+		db.SetMaxIdleConns(4)
+		db.SetConnMaxLifetime(5 * time.Minute)
+	}
+
+	return db, nil
+}
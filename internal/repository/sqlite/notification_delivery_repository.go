@@ -0,0 +1,80 @@
+// internal/repository/sqlite/notification_delivery_repository.go
+// notification_delivery_repositoryは、通知の送信履歴のリポジトリを提供します。
+
+// Package sqlite provides SQLite repository implementations.
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// NotificationDeliveryRepositoryのSQLite実装
+type NotificationDeliveryRepository struct {
+	repo *SQLiteRepository
+}
+
+// 送信済みの通知かチェック
+func (r *NotificationDeliveryRepository) Exists(ctx context.Context, userID int64, kind, periodKey string) (bool, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM notification_deliveries
+		WHERE user_id = ? AND kind = ? AND period_key = ?
+	`
+
+	var count int
+	err := r.repo.getDB().QueryRowContext(ctx, query, userID, kind, periodKey).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// 通知の送信を記録
+// 日時の比較を文字列で行うため、UTCで保存する
+func (r *NotificationDeliveryRepository) Create(ctx context.Context, delivery *models.NotificationDelivery) error {
+	query := `
+		INSERT INTO notification_deliveries (
+			user_id, kind, period_key, created
+		) VALUES (?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.repo.getDB().ExecContext(ctx, query,
+		delivery.UserID,
+		delivery.Kind,
+		delivery.PeriodKey,
+		now.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	delivery.ID = id
+	delivery.Created = now
+
+	return nil
+}
+
+// 指定日時より前の送信履歴を削除し、削除件数を返す
+func (r *NotificationDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM notification_deliveries
+		WHERE created < ?
+	`
+
+	result, err := r.repo.getDB().ExecContext(ctx, query, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,103 @@
+// internal/repository/sqlite/password_reset_token_repository.go
+// password_reset_token_repositoryは、パスワード再設定用トークンのリポジトリを提供します。
+
+// Package sqlite provides SQLite repository implementations.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// PasswordResetTokenRepositoryのSQLite実装
+type PasswordResetTokenRepository struct {
+	repo *SQLiteRepository
+}
+
+// トークンのハッシュ値で検索
+func (r *PasswordResetTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires, created
+		FROM password_reset_tokens
+		WHERE token_hash = ?
+	`
+
+	token := &models.PasswordResetToken{}
+	err := r.repo.getDB().QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.Expires,
+		&token.Created,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// 新規トークンを作成
+// 日時の比較を文字列で行うため、UTCで保存する
+func (r *PasswordResetTokenRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (
+			user_id, token_hash, expires, created
+		) VALUES (?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.repo.getDB().ExecContext(ctx, query,
+		token.UserID,
+		token.TokenHash,
+		token.Expires.UTC(),
+		now.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	token.ID = id
+	token.Created = now
+
+	return nil
+}
+
+// ユーザーの全トークンを削除
+func (r *PasswordResetTokenRepository) DeleteByUserID(ctx context.Context, userID int64) error {
+	query := `
+		DELETE FROM password_reset_tokens
+		WHERE user_id = ?
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query, userID)
+
+	return err
+}
+
+// 有効期限切れのトークンを削除
+func (r *PasswordResetTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		DELETE FROM password_reset_tokens
+		WHERE expires <= ?
+	`
+
+	result, err := r.repo.getDB().ExecContext(ctx, query, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,127 @@
+// internal/repository/sqlite/session_store.go
+// session_storeは、ログインセッションのストアを提供します。
+
+// Package sqlite provides SQLite repository implementations.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// SessionStoreのSQLite実装
+type SessionStore struct {
+	repo *SQLiteRepository
+}
+
+// IDでセッションを検索
+func (r *SessionStore) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	query := `
+		SELECT id, user_id, expires, created, modified
+		FROM sessions
+		WHERE id = ?
+	`
+
+	session := &models.Session{}
+	err := r.repo.getDB().QueryRowContext(ctx, query, id).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Expires,
+		&session.Created,
+		&session.Modified,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// 新規セッションを作成
+func (r *SessionStore) Create(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (
+			id, user_id, expires, created, modified
+		) VALUES (?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	_, err := r.repo.getDB().ExecContext(ctx, query,
+		session.ID,
+		session.UserID,
+		session.Expires.UTC(),
+		now,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	session.Created = now
+	session.Modified = now
+
+	return nil
+}
+
+// セッションの有効期限を更新
+func (r *SessionStore) UpdateExpires(ctx context.Context, id string, expires time.Time) error {
+	query := `
+		UPDATE sessions
+		SET expires = ?, modified = ?
+		WHERE id = ?
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query,
+		expires.UTC(),
+		time.Now(),
+		id,
+	)
+
+	return err
+}
+
+// セッションを削除
+func (r *SessionStore) Delete(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM sessions
+		WHERE id = ?
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query, id)
+
+	return err
+}
+
+// ユーザーの全セッションを削除
+func (r *SessionStore) DeleteByUserID(ctx context.Context, userID int64) error {
+	query := `
+		DELETE FROM sessions
+		WHERE user_id = ?
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query, userID)
+
+	return err
+}
+
+// 有効期限切れのセッションを削除
+func (r *SessionStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		DELETE FROM sessions
+		WHERE expires <= ?
+	`
+
+	result, err := r.repo.getDB().ExecContext(ctx, query, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
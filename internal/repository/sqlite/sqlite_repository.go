@@ -0,0 +1,172 @@
+// internal/repository/sqlite/sqlite_repository.go
+// sqlite_repositoryは、SQLiteリポジトリの実装を提供します。
+
+// Package sqlite provides SQLite repository implementations.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+)
+
+// SQLiteリポジトリの実装
+type SQLiteRepository struct {
+	db    *sql.DB
+	tx    *sql.Tx
+	depth int // トランザクションの入れ子の深さ（セーブポイント名に使用）
+}
+
+// クエリを実行できる接続（*sql.DB と *sql.Tx の共通部分）
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// 新しいSQLiteリポジトリを作成
+func NewSQLiteRepository(db *sql.DB) repository.Repository {
+	return &SQLiteRepository{
+		db: db,
+	}
+}
+
+// UserRepositoryを取得
+func (r *SQLiteRepository) User() repository.UserRepository {
+	return &UserRepository{repo: r}
+}
+
+// SleepDiaryRepositoryを取得
+func (r *SQLiteRepository) SleepDiary() repository.SleepDiaryRepository {
+	return &SleepDiaryRepository{repo: r}
+}
+
+// SleepRecordRepositoryを取得
+func (r *SQLiteRepository) SleepRecord() repository.SleepRecordRepository {
+	return &SleepRecordRepository{repo: r}
+}
+
+// SleepStateRepositoryを取得
+func (r *SQLiteRepository) SleepState() repository.SleepStateRepository {
+	return &SleepStateRepository{repo: r}
+}
+
+// MealTypeRepositoryを取得
+func (r *SQLiteRepository) MealType() repository.MealTypeRepository {
+	return &MealTypeRepository{repo: r}
+}
+
+// UserSleepPreferenceRepositoryを取得
+func (r *SQLiteRepository) UserSleepPreference() repository.UserSleepPreferenceRepository {
+	return &UserSleepPreferenceRepository{repo: r}
+}
+
+// SessionStoreを取得
+func (r *SQLiteRepository) Session() repository.SessionStore {
+	return &SessionStore{repo: r}
+}
+
+// LoginFailureRepositoryを取得
+func (r *SQLiteRepository) LoginFailure() repository.LoginFailureRepository {
+	return &LoginFailureRepository{repo: r}
+}
+
+// PasswordResetTokenRepositoryを取得
+func (r *SQLiteRepository) PasswordResetToken() repository.PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{repo: r}
+}
+
+// NotificationDeliveryRepositoryを取得
+func (r *SQLiteRepository) NotificationDelivery() repository.NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{repo: r}
+}
+
+// NotificationSettingsRepositoryを取得
+func (r *SQLiteRepository) NotificationSettings() repository.NotificationSettingsRepository {
+	return &NotificationSettingsRepository{repo: r}
+}
+
+// PersonalAccessTokenRepositoryを取得
+func (r *SQLiteRepository) PersonalAccessToken() repository.PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{repo: r}
+}
+
+// DailySummaryRepositoryを取得
+func (r *SQLiteRepository) DailySummary() repository.DailySummaryRepository {
+	return &DailySummaryRepository{repo: r}
+}
+
+// トランザクションを実行
+// すでにトランザクション中の場合は、セーブポイントを使って入れ子のトランザクションとして実行し、
+// エラーの場合はセーブポイントまでの変更のみを取り消す
+func (r *SQLiteRepository) Transaction(ctx context.Context, fn func(repository.Repository) error) error {
+	if r.tx != nil {
+		return r.savepoint(ctx, fn)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	repo := &SQLiteRepository{
+		db: r.db,
+		tx: tx,
+	}
+
+	// fnがパニックした場合もロールバックしてから、パニックを呼び出し元に伝える
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(repo); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// セーブポイントを使って入れ子のトランザクションを実行
+func (r *SQLiteRepository) savepoint(ctx context.Context, fn func(repository.Repository) error) error {
+	repo := &SQLiteRepository{
+		db:    r.db,
+		tx:    r.tx,
+		depth: r.depth + 1,
+	}
+	name := fmt.Sprintf("sp_%d", repo.depth)
+
+	if _, err := r.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			r.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	if err := fn(repo); err != nil {
+		if _, rbErr := r.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	_, err := r.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// アクティブなデータベース接続を取得
+func (r *SQLiteRepository) getDB() executor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
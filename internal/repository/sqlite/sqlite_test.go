@@ -0,0 +1,19 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		db, err := NewDB(DBConfig{Path: ":memory:"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return NewSQLiteRepository(db)
+	})
+}
@@ -0,0 +1,63 @@
+// internal/repository/sqlite/time_value.go
+// time_valueは、SQLiteに保存する日付・時刻の変換を提供します。
+
+// Package sqlite provides SQLite repository implementations.
+package sqlite
+
+import (
+	"fmt"
+	"time"
+)
+
+// 日付の保存形式（MySQLのDATE型に対応）
+const dateFormat = "2006-01-02"
+
+// 時刻の保存形式（MySQLのTIME型に対応）
+const timeOfDayFormat = "15:04:05"
+
+// 日付を保存形式の文字列に変換
+func formatDate(t time.Time) string {
+	return t.Format(dateFormat)
+}
+
+// 時刻を保存形式の文字列に変換
+func formatTimeOfDay(t time.Time) string {
+	return t.Format(timeOfDayFormat)
+}
+
+// 時刻の文字列を time.Time として読み込むためのスキャナー
+type timeOfDayScanner struct {
+	dest *time.Time
+}
+
+// 時刻カラムの読み込み先を作成
+func timeOfDay(dest *time.Time) *timeOfDayScanner {
+	return &timeOfDayScanner{dest: dest}
+}
+
+// sql.Scanner の実装
+func (s *timeOfDayScanner) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+		*s.dest = time.Time{}
+		return nil
+	case time.Time:
+		*s.dest = time.Date(0, 1, 1, v.Hour(), v.Minute(), v.Second(), 0, time.UTC)
+		return nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported time of day value: %T", src)
+	}
+
+	for _, layout := range []string{timeOfDayFormat, "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			*s.dest = t
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time of day: %q", value)
+}
@@ -0,0 +1,216 @@
+// internal/repository/sqlite/user_repository.go
+// user_repositoryは、ユーザーのリポジトリを提供します。
+
+// Package sqlite provides SQLite repository implementations.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// UserRepositoryのSQLite実装
+type UserRepository struct {
+	repo *SQLiteRepository
+}
+
+// IDでユーザーを検索
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	query := `
+		SELECT id, email, display_name, password_hash, time_zone, last_login_datetime, created, modified, deleted
+		FROM users
+		WHERE id = ? AND deleted IS NULL
+	`
+
+	user := &models.User{}
+	err := r.repo.getDB().QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Email,
+		&user.DisplayName,
+		&user.PasswordHash,
+		&user.TimeZone,
+		&user.LastLoginDatetime,
+		&user.Created,
+		&user.Modified,
+		&user.Deleted,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// メールアドレスでユーザーを検索
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `
+		SELECT id, email, display_name, password_hash, time_zone, last_login_datetime, created, modified, deleted
+		FROM users
+		WHERE email = ? AND deleted IS NULL
+	`
+
+	user := &models.User{}
+	err := r.repo.getDB().QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Email,
+		&user.DisplayName,
+		&user.PasswordHash,
+		&user.TimeZone,
+		&user.LastLoginDatetime,
+		&user.Created,
+		&user.Modified,
+		&user.Deleted,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// 論理削除されていないすべてのユーザーを取得（ID順）
+func (r *UserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
+	query := `
+		SELECT id, email, display_name, password_hash, time_zone, last_login_datetime, created, modified, deleted
+		FROM users
+		WHERE deleted IS NULL
+		ORDER BY id
+	`
+
+	rows, err := r.repo.getDB().QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.DisplayName,
+			&user.PasswordHash,
+			&user.TimeZone,
+			&user.LastLoginDatetime,
+			&user.Created,
+			&user.Modified,
+			&user.Deleted,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// 新規ユーザーを作成
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	query := `
+		INSERT INTO users (
+			email, display_name, password_hash, time_zone, created, modified
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.repo.getDB().ExecContext(ctx, query,
+		user.Email,
+		user.DisplayName,
+		user.PasswordHash,
+		user.TimeZoneOrDefault(),
+		now,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	user.ID = id
+	user.TimeZone = user.TimeZoneOrDefault()
+	user.Created = now
+	user.Modified = now
+
+	return nil
+}
+
+// ユーザー情報を更新
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	query := `
+		UPDATE users
+		SET email = ?, display_name = ?, password_hash = ?, time_zone = ?, modified = ?
+		WHERE id = ? AND deleted IS NULL
+	`
+
+	now := time.Now()
+	_, err := r.repo.getDB().ExecContext(ctx, query,
+		user.Email,
+		user.DisplayName,
+		user.PasswordHash,
+		user.TimeZoneOrDefault(),
+		now,
+		user.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	user.Modified = now
+	return nil
+}
+
+// ユーザーを論理削除
+func (r *UserRepository) Delete(ctx context.Context, id int64) error {
+	query := `
+		UPDATE users
+		SET deleted = ?
+		WHERE id = ? AND deleted IS NULL
+	`
+
+	_, err := r.repo.getDB().ExecContext(ctx, query,
+		time.Now(),
+		id,
+	)
+
+	return err
+}
+
+// 最終ログイン日時を更新
+func (r *UserRepository) UpdateLastLogin(ctx context.Context, id int64) error {
+	query := `
+		UPDATE users
+		SET last_login_datetime = ?, modified = ?
+		WHERE id = ? AND deleted IS NULL
+	`
+
+	now := time.Now()
+	_, err := r.repo.getDB().ExecContext(ctx, query,
+		now,
+		now,
+		id,
+	)
+
+	return err
+}
@@ -0,0 +1,212 @@
+// internal/service/access_token_service.go
+// access_token_serviceは、APIで利用するパーソナルアクセストークン関連のサービスを提供します。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+var (
+	// ErrInvalidAccessToken アクセストークンが無効か期限切れです
+	ErrInvalidAccessToken = errors.New("invalid or expired access token / アクセストークンが無効か期限切れです")
+	// ErrInsufficientScope アクセストークンに必要な権限がありません
+	ErrInsufficientScope = errors.New("insufficient token scope / アクセストークンに必要な権限がありません")
+	// ErrInvalidTokenName トークンの名前は1〜100文字で指定してください
+	ErrInvalidTokenName = errors.New("token name must be 1-100 characters / トークンの名前は1〜100文字で指定してください")
+	// ErrInvalidTokenScope トークンの権限が正しくありません
+	ErrInvalidTokenScope = errors.New("invalid token scope / トークンの権限が正しくありません")
+	// ErrInvalidTokenLifetime トークンの有効期間が正しくありません
+	ErrInvalidTokenLifetime = errors.New("invalid token lifetime / トークンの有効期間が正しくありません")
+	// ErrAccessTokenNotFound アクセストークンが見つかりません
+	ErrAccessTokenNotFound = errors.New("access token not found / アクセストークンが見つかりません")
+)
+
+// AccessTokenPrefix パーソナルアクセストークンの先頭に付ける文字列です
+// ログなどに紛れ込んだトークンを見分けやすくするために付ける
+const AccessTokenPrefix = "sn_"
+
+const (
+	// アクセストークンのランダム部分のバイト長
+	accessTokenBytes = 32
+	// 一覧での識別用に保存するトークンの先頭部分の長さ（AccessTokenPrefix を含む）
+	accessTokenDisplayLength = len(AccessTokenPrefix) + 8
+	// トークンの名前の最大文字数
+	maxAccessTokenNameLength = 100
+	// 最終利用日時を更新する間隔（リクエストごとの書き込みを避ける）
+	accessTokenTouchInterval = time.Minute
+)
+
+// パーソナルアクセストークン関連のサービス
+type AccessTokenService struct {
+	s   *Service
+	now func() time.Time
+}
+
+// 新しいAccessTokenServiceを作成
+func NewAccessTokenService(s *Service) *AccessTokenService {
+	return &AccessTokenService{
+		s:   s,
+		now: time.Now,
+	}
+}
+
+// 現在時刻の取得方法を設定（テスト用）
+func (s *AccessTokenService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// トークンを発行
+// 発行したトークンは保存しないため、戻り値のトークンを表示できるのはこのときだけ
+// lifetime が0の場合は無期限とする
+func (s *AccessTokenService) CreateToken(ctx context.Context, userID int64, name string, scopes []string, lifetime time.Duration) (string, *models.PersonalAccessToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxAccessTokenNameLength {
+		return "", nil, ErrInvalidTokenName
+	}
+
+	scope, err := normalizeTokenScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if lifetime < 0 {
+		return "", nil, ErrInvalidTokenLifetime
+	}
+
+	token, err := generateAccessToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	accessToken := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: token[:accessTokenDisplayLength],
+		TokenHash:   hashAccessToken(token),
+		Scopes:      scope,
+	}
+	if lifetime > 0 {
+		accessToken.Expires = sql.NullTime{Time: s.now().Add(lifetime), Valid: true}
+	}
+
+	if err := s.s.repoFor(ctx).PersonalAccessToken().Create(ctx, accessToken); err != nil {
+		return "", nil, err
+	}
+
+	s.s.Logger().Info("アクセストークンを発行: userID=%d, tokenID=%d, scopes=%s", userID, accessToken.ID, scope)
+	return token, accessToken, nil
+}
+
+// ユーザーのトークンの一覧を取得（無効化したトークンを含む）
+func (s *AccessTokenService) ListTokens(ctx context.Context, userID int64) ([]*models.PersonalAccessToken, error) {
+	return s.s.repoFor(ctx).PersonalAccessToken().GetByUserID(ctx, userID)
+}
+
+// トークンを無効化
+// 他のユーザーのトークンの場合は、存在を知られないよう ErrAccessTokenNotFound を返す
+func (s *AccessTokenService) RevokeToken(ctx context.Context, userID, tokenID int64) error {
+	token, err := s.s.repoFor(ctx).PersonalAccessToken().GetByID(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if token == nil || token.UserID != userID {
+		return ErrAccessTokenNotFound
+	}
+
+	if err := s.s.repoFor(ctx).PersonalAccessToken().Revoke(ctx, tokenID, s.now()); err != nil {
+		return err
+	}
+
+	s.s.Logger().Info("アクセストークンを無効化: userID=%d, tokenID=%d", userID, tokenID)
+	return nil
+}
+
+// トークンを検証し、トークンの所有者とトークンを返す
+// 無効化・期限切れのトークンや、所有者が削除されている場合は ErrInvalidAccessToken
+func (s *AccessTokenService) Authenticate(ctx context.Context, token string) (*models.User, *models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(token, AccessTokenPrefix) {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	accessToken, err := s.s.repoFor(ctx).PersonalAccessToken().GetByTokenHash(ctx, hashAccessToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := s.now()
+	if accessToken == nil || !accessToken.IsActive(now) {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, accessToken.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, ErrInvalidAccessToken
+	}
+
+	// 最終利用日時の記録に失敗しても認証は続ける
+	if !accessToken.LastUsed.Valid || now.Sub(accessToken.LastUsed.Time) >= accessTokenTouchInterval {
+		if err := s.s.repoFor(ctx).PersonalAccessToken().UpdateLastUsed(ctx, accessToken.ID, now); err != nil {
+			s.s.Logger().Error("アクセストークンの最終利用日時の更新に失敗: error=%v, tokenID=%d", err, accessToken.ID)
+		} else {
+			accessToken.LastUsed = sql.NullTime{Time: now, Valid: true}
+		}
+	}
+
+	return user, accessToken, nil
+}
+
+// 権限の指定を検証し、保存用のスペース区切りの文字列に変換（重複は除き、AccessTokenScopes の順に並べる）
+func normalizeTokenScopes(scopes []string) (string, error) {
+	requested := make(map[string]bool)
+	for _, scope := range scopes {
+		for _, field := range strings.Fields(scope) {
+			requested[field] = true
+		}
+	}
+	if len(requested) == 0 {
+		return "", ErrInvalidTokenScope
+	}
+
+	var normalized []string
+	for _, scope := range models.AccessTokenScopes() {
+		if requested[scope] {
+			normalized = append(normalized, scope)
+			delete(requested, scope)
+		}
+	}
+	if len(requested) > 0 {
+		return "", ErrInvalidTokenScope
+	}
+
+	return strings.Join(normalized, " "), nil
+}
+
+// アクセストークンを生成（AccessTokenPrefix とBase64URL形式のランダムな文字列）
+func generateAccessToken() (string, error) {
+	b := make([]byte, accessTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// 保存用のトークンのハッシュ値（SHA-256の16進数）
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,398 @@
+// internal/service/backup_service.go
+// backup_serviceは、ユーザーのデータのバックアップ（JSON）の作成と、バックアップからの復元を提供します。
+// 復元は睡眠日誌のない新しいアカウントに対して行い、睡眠日誌・睡眠記録のIDは作成し直し、睡眠状態・食事種別はコードから復元先のIDに読み替えます。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// 復元できるバックアップの最大サイズ（バイト）
+const BackupMaxBytes = 32 << 20
+
+var (
+	// ErrBackupFormat バックアップの形式が正しくありません
+	ErrBackupFormat = errors.New("invalid backup format / バックアップの形式が正しくありません")
+	// ErrBackupVersion このバックアップの版には対応していません
+	ErrBackupVersion = errors.New("unsupported backup version / このバックアップの版には対応していません")
+	// ErrBackupTooLarge バックアップが大きすぎます
+	ErrBackupTooLarge = errors.New("backup is too large / バックアップが大きすぎます")
+	// ErrBackupAccountNotEmpty 復元先のアカウントに睡眠日誌があります
+	ErrBackupAccountNotEmpty = errors.New("account already has diaries / 復元先のアカウントに睡眠日誌があります")
+	// ErrBackupUnknownCode バックアップの睡眠状態・食事種別のコードがこのサーバーにありません
+	ErrBackupUnknownCode = errors.New("unknown state or meal type code / バックアップの睡眠状態・食事種別のコードがこのサーバーにありません")
+	// ErrBackupInvalidRecord バックアップに正しくない睡眠記録があります
+	ErrBackupInvalidRecord = errors.New("invalid record in backup / バックアップに正しくない睡眠記録があります")
+)
+
+// バックアップ関連のサービス
+type BackupService struct {
+	s *Service
+}
+
+// バックアップからの復元結果
+type BackupRestore struct {
+	Diaries int // 作成した睡眠日誌の数
+	Records int // 作成した睡眠記録の数
+}
+
+// 新しいBackupServiceを作成
+func NewBackupService(s *Service) *BackupService {
+	return &BackupService{s: s}
+}
+
+// ユーザーのデータのバックアップを作成
+func (s *BackupService) Export(ctx context.Context, userID int64) (*models.Backup, error) {
+	repo := s.s.repoFor(ctx)
+	user, err := repo.User().GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	backup := &models.Backup{
+		Format:     models.BackupFormat,
+		Version:    models.BackupVersion,
+		ExportedAt: time.Now().UTC(),
+		Profile: models.BackupProfile{
+			Email:       user.Email,
+			DisplayName: user.DisplayName,
+			TimeZone:    user.TimeZone,
+		},
+		SleepStates: []models.BackupSleepState{},
+		MealTypes:   []models.BackupMealType{},
+		Diaries:     []models.BackupDiary{},
+	}
+
+	// 睡眠設定は保存されている場合のみ含める（既定値を作成しない）
+	pref, err := repo.UserSleepPreference().GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pref != nil {
+		backup.SleepPreference = &models.BackupSleepPreference{
+			PreferredBedtime:    pref.PreferredBedtime.Format("15:04"),
+			PreferredWakeupTime: pref.PreferredWakeupTime.Format("15:04"),
+			SleepGoalHours:      pref.SleepGoalHours,
+			IsReminderEnabled:   pref.IsReminderEnabled,
+		}
+	}
+
+	// 通知設定も保存されている場合のみ含める
+	settings, err := repo.NotificationSettings().GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		backup.NotificationSettings = &models.BackupNotificationSettings{
+			EmailEnabled:    settings.EmailEnabled,
+			BedtimeReminder: settings.BedtimeReminder,
+			ReminderTime:    settings.ReminderTime,
+			WeeklyReport:    settings.WeeklyReport,
+		}
+	}
+
+	states, err := repo.SleepState().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stateByID := make(map[int64]*models.SleepState, len(states))
+	for _, state := range states {
+		stateByID[state.ID] = state
+	}
+	mealTypes, err := repo.MealType().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mealTypeByID := make(map[int64]*models.MealType, len(mealTypes))
+	for _, mealType := range mealTypes {
+		mealTypeByID[mealType.ID] = mealType
+	}
+
+	diaries, err := repo.SleepDiary().GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(diaries, func(i, j int) bool {
+		return diaries[i].StartDate.Before(diaries[j].StartDate)
+	})
+
+	usedStates := make(map[int64]bool)
+	usedMealTypes := make(map[int64]bool)
+	for _, diary := range diaries {
+		records, err := repo.SleepRecord().GetByDiaryID(ctx, diary.ID)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(records, func(i, j int) bool {
+			if !records[i].RecordDate.Equal(records[j].RecordDate) {
+				return records[i].RecordDate.Before(records[j].RecordDate)
+			}
+			return records[i].TimeSlot.Before(records[j].TimeSlot)
+		})
+
+		backupDiary := models.BackupDiary{
+			StartDate: diary.StartDate.Format(diaryDateFormat),
+			EndDate:   diary.EndDate.Format(diaryDateFormat),
+			Name:      diary.DiaryName,
+			Note:      diary.Note.String,
+			Records:   make([]models.BackupRecord, 0, len(records)),
+		}
+		for _, record := range records {
+			state, ok := stateByID[record.SleepStateID]
+			if !ok {
+				return nil, fmt.Errorf("sleep state not found: %d", record.SleepStateID)
+			}
+			usedStates[state.ID] = true
+
+			backupRecord := models.BackupRecord{
+				RecordDate: record.RecordDate.Format(diaryDateFormat),
+				TimeSlot:   record.TimeSlot.Format("15:04"),
+				RecordType: record.RecordType,
+				StateCode:  state.StateCode,
+				Note:       record.Note.String,
+				Created:    record.Created.UTC(),
+				Modified:   record.Modified.UTC(),
+			}
+			if record.MealTypeID.Valid {
+				mealType, ok := mealTypeByID[record.MealTypeID.Int64]
+				if !ok {
+					return nil, fmt.Errorf("meal type not found: %d", record.MealTypeID.Int64)
+				}
+				usedMealTypes[mealType.ID] = true
+				backupRecord.MealTypeCode = mealType.TypeCode
+			}
+			backupDiary.Records = append(backupDiary.Records, backupRecord)
+		}
+		backup.Diaries = append(backup.Diaries, backupDiary)
+	}
+
+	for _, state := range states {
+		if usedStates[state.ID] {
+			backup.SleepStates = append(backup.SleepStates, models.BackupSleepState{Code: state.StateCode, Name: state.StateName, Symbol: state.DisplaySymbol})
+		}
+	}
+	for _, mealType := range mealTypes {
+		if usedMealTypes[mealType.ID] {
+			backup.MealTypes = append(backup.MealTypes, models.BackupMealType{Code: mealType.TypeCode, Name: mealType.TypeName, Symbol: mealType.DisplaySymbol})
+		}
+	}
+
+	return backup, nil
+}
+
+// バックアップのJSONを読み込み、形式と版を確認
+func (s *BackupService) Decode(r io.Reader) (*models.Backup, error) {
+	data, err := io.ReadAll(io.LimitReader(r, BackupMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > BackupMaxBytes {
+		return nil, ErrBackupTooLarge
+	}
+
+	var backup models.Backup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, ErrBackupFormat
+	}
+	if backup.Format != models.BackupFormat || backup.Version < 1 {
+		return nil, ErrBackupFormat
+	}
+	if backup.Version > models.BackupVersion {
+		return nil, ErrBackupVersion
+	}
+	return &backup, nil
+}
+
+// バックアップからユーザーのデータを復元（すべてを1つのトランザクションで行う）
+// 復元先のアカウントに睡眠日誌がある場合は ErrBackupAccountNotEmpty を返す
+// メールアドレスは復元先のアカウントのものを使い、表示名・タイムゾーン・睡眠設定・通知設定・睡眠日誌・睡眠記録を復元する
+// 睡眠記録の作成日時・更新日時はバックアップの値を使う
+func (s *BackupService) Restore(ctx context.Context, userID int64, backup *models.Backup) (*BackupRestore, error) {
+	result := &BackupRestore{}
+	err := s.s.Transaction(ctx, func(ctx context.Context) error {
+		repo := s.s.repoFor(ctx)
+		existing, err := repo.SleepDiary().GetByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return ErrBackupAccountNotEmpty
+		}
+
+		stateIDs, mealTypeIDs, err := s.resolveCodes(ctx, backup)
+		if err != nil {
+			return err
+		}
+
+		profile := &models.User{ID: userID, DisplayName: backup.Profile.DisplayName, TimeZone: backup.Profile.TimeZone}
+		if err := s.s.User().UpdateProfile(ctx, profile); err != nil {
+			return err
+		}
+		if backup.SleepPreference != nil {
+			if err := s.restoreSleepPreference(ctx, userID, backup.SleepPreference); err != nil {
+				return err
+			}
+		}
+		// 通知設定は睡眠設定の後に復元する（就寝時刻のリマインダーの有効・無効は睡眠設定にも反映される）
+		if backup.NotificationSettings != nil {
+			if err := s.restoreNotificationSettings(ctx, userID, backup.NotificationSettings); err != nil {
+				return err
+			}
+		}
+
+		// 睡眠記録は全睡眠日誌の分をまとめて作成し、日別集計データの再計算を1回にする
+		var records []*models.SleepRecord
+		for _, backupDiary := range backup.Diaries {
+			startDate, err := time.Parse(diaryDateFormat, backupDiary.StartDate)
+			if err != nil {
+				return ErrBackupFormat
+			}
+			endDate, err := time.Parse(diaryDateFormat, backupDiary.EndDate)
+			if err != nil {
+				return ErrBackupFormat
+			}
+			diary, err := s.s.Diary().CreateDiary(ctx, userID, startDate, endDate, backupDiary.Name, backupDiary.Note)
+			if err != nil {
+				return err
+			}
+			result.Diaries++
+
+			for _, backupRecord := range backupDiary.Records {
+				record, err := newBackupRecord(diary, backupRecord, stateIDs, mealTypeIDs)
+				if err != nil {
+					return err
+				}
+				records = append(records, record)
+			}
+		}
+
+		if len(records) > 0 {
+			if err := s.s.Record().BulkCreateRecords(ctx, records); err != nil {
+				return err
+			}
+		}
+		result.Records = len(records)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// バックアップの睡眠状態・食事種別のコードを復元先のIDに読み替える
+// 睡眠記録で使っているコードのうち、このサーバーにないものがある場合は ErrBackupUnknownCode を返す
+func (s *BackupService) resolveCodes(ctx context.Context, backup *models.Backup) (map[string]int64, map[string]int64, error) {
+	repo := s.s.repoFor(ctx)
+	stateIDs := make(map[string]int64)
+	mealTypeIDs := make(map[string]int64)
+	for _, diary := range backup.Diaries {
+		for _, record := range diary.Records {
+			if _, ok := stateIDs[record.StateCode]; !ok {
+				state, err := repo.SleepState().GetByCode(ctx, record.StateCode)
+				if err != nil {
+					return nil, nil, err
+				}
+				if state == nil {
+					return nil, nil, fmt.Errorf("%w: %s", ErrBackupUnknownCode, record.StateCode)
+				}
+				stateIDs[record.StateCode] = state.ID
+			}
+
+			if _, ok := mealTypeIDs[record.MealTypeCode]; record.MealTypeCode != "" && !ok {
+				mealType, err := repo.MealType().GetByCode(ctx, record.MealTypeCode)
+				if err != nil {
+					return nil, nil, err
+				}
+				if mealType == nil {
+					return nil, nil, fmt.Errorf("%w: %s", ErrBackupUnknownCode, record.MealTypeCode)
+				}
+				mealTypeIDs[record.MealTypeCode] = mealType.ID
+			}
+		}
+	}
+	return stateIDs, mealTypeIDs, nil
+}
+
+// バックアップの睡眠設定を復元
+func (s *BackupService) restoreSleepPreference(ctx context.Context, userID int64, backup *models.BackupSleepPreference) error {
+	bedtime, err := time.Parse("15:04", backup.PreferredBedtime)
+	if err != nil {
+		return ErrBackupFormat
+	}
+	wakeupTime, err := time.Parse("15:04", backup.PreferredWakeupTime)
+	if err != nil {
+		return ErrBackupFormat
+	}
+
+	_, err = s.s.User().PatchSleepPreference(ctx, userID, SleepPreferenceUpdate{
+		PreferredBedtime:    &bedtime,
+		PreferredWakeupTime: &wakeupTime,
+		SleepGoalHours:      &backup.SleepGoalHours,
+		ReminderEnabled:     &backup.IsReminderEnabled,
+	})
+	return err
+}
+
+// バックアップの通知設定を復元
+func (s *BackupService) restoreNotificationSettings(ctx context.Context, userID int64, backup *models.BackupNotificationSettings) error {
+	_, err := s.s.User().UpdateNotificationSettings(ctx, userID, NotificationSettingsUpdate{
+		EmailEnabled:    &backup.EmailEnabled,
+		BedtimeReminder: &backup.BedtimeReminder,
+		ReminderTime:    &backup.ReminderTime,
+		WeeklyReport:    &backup.WeeklyReport,
+	})
+	return err
+}
+
+// バックアップの睡眠記録から、作成し直した睡眠日誌の睡眠記録を作成
+// 記録日が睡眠日誌の期間外の場合や、種別・時間枠が正しくない場合は ErrBackupInvalidRecord を返す
+func newBackupRecord(diary *models.SleepDiary, backup models.BackupRecord, stateIDs, mealTypeIDs map[string]int64) (*models.SleepRecord, error) {
+	invalid := fmt.Errorf("%w: %s %s", ErrBackupInvalidRecord, backup.RecordDate, backup.TimeSlot)
+	recordDate, err := time.Parse(diaryDateFormat, backup.RecordDate)
+	if err != nil {
+		return nil, invalid
+	}
+	slot, err := time.Parse("15:04", backup.TimeSlot)
+	if err != nil || recordDate.Before(diary.StartDate) || recordDate.After(diary.EndDate) {
+		return nil, invalid
+	}
+	switch backup.RecordType {
+	case models.RecordTypeState, models.RecordTypeEvent, models.RecordTypeMeal:
+	default:
+		return nil, invalid
+	}
+
+	record := &models.SleepRecord{
+		SleepDiaryID: diary.ID,
+		SleepStateID: stateIDs[backup.StateCode],
+		RecordDate:   recordDate,
+		TimeSlot:     time.Date(0, 1, 1, slot.Hour(), slot.Minute(), 0, 0, time.UTC),
+		RecordType:   backup.RecordType,
+		Note:         sql.NullString{String: backup.Note, Valid: backup.Note != ""},
+		Created:      backup.Created,
+		Modified:     backup.Modified,
+	}
+	if backup.MealTypeCode != "" {
+		record.MealTypeID = sql.NullInt64{Int64: mealTypeIDs[backup.MealTypeCode], Valid: true}
+	}
+	if !record.IsValidTimeSlot() {
+		return nil, invalid
+	}
+	return record, nil
+}
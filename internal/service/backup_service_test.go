@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// バックアップを比較できる形にする（作成日時とメールアドレスは除く）
+func backupJSON(t *testing.T, backup *models.Backup) string {
+	t.Helper()
+	copied := *backup
+	copied.ExportedAt = time.Time{}
+	copied.Profile.Email = ""
+	data, err := json.Marshal(&copied)
+	if err != nil {
+		t.Fatalf("failed to encode backup: %v", err)
+	}
+	return string(data)
+}
+
+// バックアップのJSONを書き出して読み込み直す
+func reloadBackup(t *testing.T, s *Service, backup *models.Backup) *models.Backup {
+	t.Helper()
+	data, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatalf("failed to encode backup: %v", err)
+	}
+	decoded, err := s.Backup().Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return decoded
+}
+
+// バックアップ元のユーザーのデータを作成
+func createBackupSource(t *testing.T, s *Service) *models.User {
+	t.Helper()
+	ctx := context.Background()
+	user := createTestUser(t, s, "source@example.com")
+	if err := s.User().UpdateProfile(ctx, &models.User{ID: user.ID, DisplayName: "復元元", TimeZone: "America/New_York"}); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+
+	bedtime, wakeup, goal, reminder := testClock(t, "23:30"), testClock(t, "07:00"), 7, true
+	if _, err := s.User().PatchSleepPreference(ctx, user.ID, SleepPreferenceUpdate{
+		PreferredBedtime: &bedtime, PreferredWakeupTime: &wakeup, SleepGoalHours: &goal, ReminderEnabled: &reminder,
+	}); err != nil {
+		t.Fatalf("PatchSleepPreference: %v", err)
+	}
+	emailEnabled, reminderTime, weeklyReport := true, "22:45", true
+	if _, err := s.User().UpdateNotificationSettings(ctx, user.ID, NotificationSettingsUpdate{
+		EmailEnabled: &emailEnabled, ReminderTime: &reminderTime, WeeklyReport: &weeklyReport,
+	}); err != nil {
+		t.Fatalf("UpdateNotificationSettings: %v", err)
+	}
+
+	createTestDiary(t, s, user.ID, "2024-04-01", "2024-04-14")
+	createTestDiary(t, s, user.ID, "2024-05-01", "2024-05-14")
+	breakfast := models.NightMeal{MealTypeCode: models.MealCodeBreakfast, At: models.NightEntryTime(testDate(t, "2024-04-01"), testClock(t, "07:30"))}
+	saveTestNight(t, s, user.ID, "2024-04-01", "23:00", "07:00", breakfast)
+	saveTestNight(t, s, user.ID, "2024-05-02", "00:30", "06:30")
+	return user
+}
+
+// バックアップから別のアカウントに復元し、同じ内容のバックアップを作成できることを確認
+func TestBackupRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	source := createBackupSource(t, s)
+	target := createTestUser(t, s, "target@example.com")
+
+	backup, err := s.Backup().Export(ctx, source.ID)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if backup.NotificationSettings == nil || !backup.NotificationSettings.WeeklyReport || backup.NotificationSettings.ReminderTime != "22:45" {
+		t.Fatalf("notification settings = %+v", backup.NotificationSettings)
+	}
+	// 作成日時・更新日時は、復元した睡眠記録にそのまま使う
+	created := time.Date(2024, 4, 2, 8, 0, 0, 0, time.UTC)
+	modified := time.Date(2024, 4, 3, 12, 15, 0, 0, time.UTC)
+	records := 0
+	for i := range backup.Diaries {
+		for j := range backup.Diaries[i].Records {
+			backup.Diaries[i].Records[j].Created = created
+			backup.Diaries[i].Records[j].Modified = modified
+			records++
+		}
+	}
+	if len(backup.Diaries) != 2 || records == 0 {
+		t.Fatalf("exported %d diaries, %d records", len(backup.Diaries), records)
+	}
+
+	result, err := s.Backup().Restore(ctx, target.ID, reloadBackup(t, s, backup))
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if result.Diaries != 2 || result.Records != records {
+		t.Errorf("restored %d diaries, %d records, want 2, %d", result.Diaries, result.Records, records)
+	}
+
+	restored, err := s.Backup().Export(ctx, target.ID)
+	if err != nil {
+		t.Fatalf("Export restored: %v", err)
+	}
+	if got, want := backupJSON(t, restored), backupJSON(t, backup); got != want {
+		t.Errorf("restored backup differs:\ngot:  %s\nwant: %s", got, want)
+	}
+	if restored.Profile.Email != "target@example.com" {
+		t.Errorf("email = %s, want the target account's", restored.Profile.Email)
+	}
+
+	// 睡眠日誌・睡眠記録は新しいIDで作成し、復元元のデータは変わらない
+	sourceDiaries, _ := s.repo.SleepDiary().GetByUserID(ctx, source.ID)
+	targetDiaries, _ := s.repo.SleepDiary().GetByUserID(ctx, target.ID)
+	sourceIDs := make(map[int64]bool)
+	sourceRecordIDs := make(map[int64]bool)
+	for _, diary := range sourceDiaries {
+		sourceIDs[diary.ID] = true
+		list, _ := s.repo.SleepRecord().GetByDiaryID(ctx, diary.ID)
+		for _, record := range list {
+			sourceRecordIDs[record.ID] = true
+		}
+	}
+	if len(sourceDiaries) != 2 || len(targetDiaries) != 2 {
+		t.Fatalf("diaries: source %d, target %d, want 2, 2", len(sourceDiaries), len(targetDiaries))
+	}
+	for _, diary := range targetDiaries {
+		if sourceIDs[diary.ID] {
+			t.Errorf("diary %d reuses the source ID", diary.ID)
+		}
+		list, _ := s.repo.SleepRecord().GetByDiaryID(ctx, diary.ID)
+		for _, record := range list {
+			if sourceRecordIDs[record.ID] {
+				t.Errorf("record %d reuses the source ID", record.ID)
+			}
+		}
+	}
+	again, err := s.Backup().Export(ctx, source.ID)
+	if err != nil {
+		t.Fatalf("Export source: %v", err)
+	}
+	if again.Diaries[0].Records[0].Created.Equal(created) {
+		t.Error("restore changed the source records")
+	}
+}
+
+// 復元できないバックアップは、何も変更せずにエラーを返すことを確認
+func TestBackupRestoreRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(backup *models.Backup)
+		source  bool
+		wantErr error
+	}{
+		{
+			name:    "不明な睡眠状態のコード",
+			modify:  func(backup *models.Backup) { backup.Diaries[1].Records[0].StateCode = "NAPPING" },
+			wantErr: ErrBackupUnknownCode,
+		},
+		{
+			name: "不明な食事種別のコード",
+			modify: func(backup *models.Backup) {
+				for i, record := range backup.Diaries[0].Records {
+					if record.MealTypeCode != "" {
+						backup.Diaries[0].Records[i].MealTypeCode = "BRUNCH"
+					}
+				}
+			},
+			wantErr: ErrBackupUnknownCode,
+		},
+		{
+			name:    "睡眠日誌の期間外の睡眠記録",
+			modify:  func(backup *models.Backup) { backup.Diaries[1].Records[0].RecordDate = "2024-06-01" },
+			wantErr: ErrBackupInvalidRecord,
+		},
+		{
+			name:    "睡眠日誌がある",
+			source:  true,
+			wantErr: ErrBackupAccountNotEmpty,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t)
+			source := createBackupSource(t, s)
+			target := createTestUser(t, s, "target@example.com")
+			if tt.source {
+				target = source
+			}
+
+			backup, err := s.Backup().Export(ctx, source.ID)
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			before, err := s.Backup().Export(ctx, target.ID)
+			if err != nil {
+				t.Fatalf("Export target: %v", err)
+			}
+			if tt.modify != nil {
+				tt.modify(backup)
+			}
+
+			if _, err := s.Backup().Restore(ctx, target.ID, reloadBackup(t, s, backup)); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Restore: error = %v, want %v", err, tt.wantErr)
+			}
+
+			// プロフィール・設定・睡眠日誌のいずれも変更しない
+			after, err := s.Backup().Export(ctx, target.ID)
+			if err != nil {
+				t.Fatalf("Export target: %v", err)
+			}
+			if got, want := backupJSON(t, after), backupJSON(t, before); got != want {
+				t.Errorf("target changed after failed restore:\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+// バックアップの形式と版の確認
+func TestBackupDecode(t *testing.T) {
+	s := newTestService(t)
+	tests := []struct {
+		name    string
+		json    string
+		wantErr error
+	}{
+		{name: "版1", json: `{"format":"` + models.BackupFormat + `","version":1,"diaries":[]}`},
+		{name: "現在の版", json: `{"format":"` + models.BackupFormat + `","version":2,"diaries":[]}`},
+		{name: "JSONでない", json: `not json`, wantErr: ErrBackupFormat},
+		{name: "形式が違う", json: `{"format":"other","version":1}`, wantErr: ErrBackupFormat},
+		{name: "版がない", json: `{"format":"` + models.BackupFormat + `"}`, wantErr: ErrBackupFormat},
+		{name: "新しい版", json: `{"format":"` + models.BackupFormat + `","version":3}`, wantErr: ErrBackupVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Backup().Decode(bytes.NewReader([]byte(tt.json)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode: error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
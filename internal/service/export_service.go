@@ -0,0 +1,266 @@
+// internal/service/export_service.go
+// export_serviceは、睡眠記録をCSVファイルとして出力するサービスを提供します。
+// 1記録1行の縦長の形式（long）と、紙の睡眠日誌と同じく1日1行・30分ごとの48列に記号を並べる横長の形式（wide）に対応します。
+// 日本語版のExcelでそのまま開けるよう、BOM付きのUTF-8とShift_JISでも出力できます。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// CSVの形式
+const (
+	CSVLayoutLong = "long" // 1記録1行（既定）
+	CSVLayoutWide = "wide" // 1日1行・48列（紙の睡眠日誌と同じ並び）
+)
+
+// CSVの文字コード
+const (
+	CSVEncodingUTF8     = "utf8"    // UTF-8（既定）
+	CSVEncodingUTF8BOM  = "utf8bom" // BOM付きのUTF-8
+	CSVEncodingShiftJIS = "sjis"    // Shift_JIS
+)
+
+// 横長の形式の1日の時間枠の数と、最初の時間枠の時刻（夜間の睡眠が1行に収まるよう正午始まり）
+const (
+	csvWideSlots     = 48
+	csvWideStartHour = 12
+)
+
+var (
+	// ErrCSVExportLayout CSVの形式は long または wide を指定してください
+	ErrCSVExportLayout = errors.New("csv layout must be long or wide / CSVの形式は long または wide を指定してください")
+	// ErrCSVExportEncoding CSVの文字コードは utf8・utf8bom・sjis のいずれかを指定してください
+	ErrCSVExportEncoding = errors.New("csv encoding must be utf8, utf8bom or sjis / CSVの文字コードは utf8・utf8bom・sjis のいずれかを指定してください")
+)
+
+// 縦長の形式の見出し
+// 設定画面のCSV取り込みでそのまま取り込めるよう、取り込みと同じ見出しを使う（睡眠日誌の列は取り込み時には使わない）
+var csvLongHeader = []string{"日付", "時間枠", "状態", "状態コード", "種別", "食事", "メモ", "睡眠日誌"}
+
+// 縦長の形式の種別の表記
+var csvRecordTypeLabels = map[string]string{
+	models.RecordTypeState: "状態",
+	models.RecordTypeEvent: "イベント",
+	models.RecordTypeMeal:  "食事",
+}
+
+// CSV出力関連のサービス
+type ExportService struct {
+	s *Service
+}
+
+// CSV出力の設定
+type CSVExportOptions struct {
+	Layout    string    // CSVLayoutLong・CSVLayoutWide（空の場合は CSVLayoutLong）
+	Encoding  string    // CSVEncodingUTF8・CSVEncodingUTF8BOM・CSVEncodingShiftJIS（空の場合は CSVEncodingUTF8）
+	StartDate time.Time // 出力する最初の日（ゼロ値の場合は制限しない）
+	EndDate   time.Time // 出力する最後の日（ゼロ値の場合は制限しない）
+}
+
+// 新しいExportServiceを作成
+func NewExportService(s *Service) *ExportService {
+	return &ExportService{s: s}
+}
+
+// CSV出力の設定を検証し、空の項目に既定値を設定
+func (o *CSVExportOptions) Validate() error {
+	switch o.Layout {
+	case "":
+		o.Layout = CSVLayoutLong
+	case CSVLayoutLong, CSVLayoutWide:
+	default:
+		return ErrCSVExportLayout
+	}
+
+	switch o.Encoding {
+	case "":
+		o.Encoding = CSVEncodingUTF8
+	case CSVEncodingUTF8, CSVEncodingUTF8BOM, CSVEncodingShiftJIS:
+	default:
+		return ErrCSVExportEncoding
+	}
+
+	if !o.StartDate.IsZero() && !o.EndDate.IsZero() && o.StartDate.After(o.EndDate) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// ユーザーの睡眠記録をCSVの行（見出し行を含む）にする
+func (s *ExportService) CSVRows(ctx context.Context, userID int64, opts CSVExportOptions) ([][]string, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	diaries, err := s.s.Diary().GetUserDiaries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(diaries, func(i, j int) bool {
+		return diaries[i].StartDate.Before(diaries[j].StartDate)
+	})
+
+	// 横長の形式では、最後の日の行に翌日の午前の記録も含めるため1日多く取得する
+	var records []*models.SleepRecord
+	if opts.StartDate.IsZero() && opts.EndDate.IsZero() {
+		records, err = s.s.Record().GetAllRecords(ctx, userID)
+	} else {
+		start, end := opts.StartDate, opts.EndDate
+		if start.IsZero() {
+			start = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+		}
+		if end.IsZero() {
+			end = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+		} else if opts.Layout == CSVLayoutWide {
+			end = end.AddDate(0, 0, 1)
+		}
+		records, err = s.s.Record().GetUserRecordsByDateRange(ctx, userID, start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	states, mealTypes, err := s.s.PDF().masterData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Layout == CSVLayoutWide {
+		return csvWideRows(diaries, records, states, mealTypes, opts), nil
+	}
+	return csvLongRows(diaries, records, states, mealTypes, opts), nil
+}
+
+// CSVの行を指定した文字コードで書き込む
+// Shift_JISで表せない文字（絵文字など）は代替文字に置き換える
+func (s *ExportService) WriteCSV(w io.Writer, rows [][]string, encodingName string) error {
+	switch encodingName {
+	case "", CSVEncodingUTF8:
+	case CSVEncodingUTF8BOM:
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return err
+		}
+	case CSVEncodingShiftJIS:
+		writer := transform.NewWriter(w, encoding.ReplaceUnsupported(japanese.ShiftJIS.NewEncoder()))
+		if err := writeCSVRows(writer, rows); err != nil {
+			return err
+		}
+		return writer.Close()
+	default:
+		return ErrCSVExportEncoding
+	}
+	return writeCSVRows(w, rows)
+}
+
+// CSVの行を書き込む（Excelで開けるよう改行はCRLFにする）
+func writeCSVRows(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// 縦長の形式の行を作成（記録日・時間枠の順）
+func csvLongRows(diaries []*models.SleepDiary, records []*models.SleepRecord, states map[int64]models.SleepState, mealTypes map[int64]models.MealType, opts CSVExportOptions) [][]string {
+	diaryNames := make(map[int64]string, len(diaries))
+	for _, diary := range diaries {
+		diaryNames[diary.ID] = diary.DiaryName
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].SlotStart().Before(records[j].SlotStart())
+	})
+
+	rows := [][]string{csvLongHeader}
+	for _, record := range records {
+		if !csvDateInRange(record.RecordDate, opts) {
+			continue
+		}
+		state := states[record.SleepStateID]
+		var mealTypeName string
+		if mealType, ok := mealTypes[record.MealTypeID.Int64]; record.MealTypeID.Valid && ok {
+			mealTypeName = mealType.TypeName
+		}
+		recordType, ok := csvRecordTypeLabels[record.RecordType]
+		if !ok {
+			recordType = record.RecordType
+		}
+
+		rows = append(rows, []string{
+			record.RecordDate.Format("2006-01-02"),
+			record.TimeSlot.Format("15:04"),
+			state.StateName,
+			state.StateCode,
+			recordType,
+			mealTypeName,
+			record.Note.String,
+			diaryNames[record.SleepDiaryID],
+		})
+	}
+	return rows
+}
+
+// 横長の形式の行を作成
+// 睡眠日誌の期間の日ごとに、正午から翌日の正午までの48枠へ睡眠状態と食事の記号を並べる（記号の決め方はPDFの睡眠日誌と同じ）
+func csvWideRows(diaries []*models.SleepDiary, records []*models.SleepRecord, states map[int64]models.SleepState, mealTypes map[int64]models.MealType, opts CSVExportOptions) [][]string {
+	header := make([]string, 0, csvWideSlots+1)
+	header = append(header, "日付")
+	start := time.Date(0, 1, 1, csvWideStartHour, 0, 0, 0, time.UTC)
+	for i := 0; i < csvWideSlots; i++ {
+		header = append(header, start.Add(time.Duration(i)*models.SlotDuration).Format("15:04"))
+	}
+
+	// 1日の行にはその日と翌日の記録のみを渡す
+	recordsByDate := make(map[string][]*models.SleepRecord)
+	for _, record := range records {
+		key := record.RecordDate.Format("2006-01-02")
+		recordsByDate[key] = append(recordsByDate[key], record)
+	}
+
+	grid := &models.PDFExportData{States: states, MealTypes: mealTypes}
+	rows := [][]string{header}
+	for _, diary := range diaries {
+		for date := diary.StartDate; !date.After(diary.EndDate); date = date.AddDate(0, 0, 1) {
+			if !csvDateInRange(date, opts) {
+				continue
+			}
+			grid.Records = slices.Concat(recordsByDate[date.Format("2006-01-02")], recordsByDate[date.AddDate(0, 0, 1).Format("2006-01-02")])
+
+			row := make([]string, 0, csvWideSlots+1)
+			row = append(row, date.Format("2006-01-02"))
+			for _, slot := range grid.FormatTimeSlots(date) {
+				row = append(row, slot.Symbol+slot.MealSymbol)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// 日付が出力する期間に含まれるか
+func csvDateInRange(date time.Time, opts CSVExportOptions) bool {
+	day := date.Format("2006-01-02")
+	if !opts.StartDate.IsZero() && day < opts.StartDate.Format("2006-01-02") {
+		return false
+	}
+	if !opts.EndDate.IsZero() && day > opts.EndDate.Format("2006-01-02") {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// 睡眠状態・食事種別のマスター（IDは初期データの並び順）
+func testExportMasters() (map[int64]models.SleepState, map[int64]models.MealType, map[string]int64, map[string]int64) {
+	states := make(map[int64]models.SleepState)
+	stateIDs := make(map[string]int64)
+	for i, state := range models.DefaultSleepStates() {
+		state.ID = int64(i + 1)
+		states[state.ID] = state
+		stateIDs[state.StateCode] = state.ID
+	}
+	mealTypes := make(map[int64]models.MealType)
+	mealTypeIDs := make(map[string]int64)
+	for i, mealType := range models.DefaultMealTypes() {
+		mealType.ID = int64(i + 1)
+		mealTypes[mealType.ID] = mealType
+		mealTypeIDs[mealType.TypeCode] = mealType.ID
+	}
+	return states, mealTypes, stateIDs, mealTypeIDs
+}
+
+// 横長の形式の列数と並び（正午から翌日の正午まで）を確認
+func TestCSVWideRows(t *testing.T) {
+	states, mealTypes, stateIDs, mealTypeIDs := testExportMasters()
+	diaries := []*models.SleepDiary{
+		{ID: 1, DiaryName: "4月", StartDate: testDate(t, "2024-04-01"), EndDate: testDate(t, "2024-04-02")},
+	}
+	record := func(date, slot, code string) *models.SleepRecord {
+		return &models.SleepRecord{
+			SleepDiaryID: 1,
+			SleepStateID: stateIDs[code],
+			RecordDate:   testDate(t, date),
+			TimeSlot:     testClock(t, slot),
+			RecordType:   models.RecordTypeState,
+		}
+	}
+	breakfast := record("2024-04-02", "07:30", models.StateCodeAwake)
+	breakfast.RecordType = models.RecordTypeMeal
+	breakfast.MealTypeID = sql.NullInt64{Int64: mealTypeIDs[models.MealCodeBreakfast], Valid: true}
+	records := []*models.SleepRecord{
+		record("2024-04-01", "12:00", models.StateCodeDrowsiness),
+		record("2024-04-01", "23:30", models.StateCodeSleeping),
+		record("2024-04-02", "00:00", models.StateCodeSleeping),
+		record("2024-04-02", "07:30", models.StateCodeAwake),
+		breakfast,
+		record("2024-04-02", "11:30", models.StateCodeAwakeInBed),
+		record("2024-04-02", "12:00", models.StateCodeAwake),
+	}
+
+	rows := csvWideRows(diaries, records, states, mealTypes, CSVExportOptions{})
+	if len(rows) != 3 {
+		t.Fatalf("rows = %d, want header and 2 days", len(rows))
+	}
+	header := rows[0]
+	if len(header) != 49 {
+		t.Fatalf("header has %d columns, want 49 (date and 48 slots)", len(header))
+	}
+	for i, want := range map[int]string{0: "日付", 1: "12:00", 2: "12:30", 24: "23:30", 25: "00:00", 48: "11:30"} {
+		if header[i] != want {
+			t.Errorf("header[%d] = %q, want %q", i, header[i], want)
+		}
+	}
+
+	// 1日の行には、その日の正午から翌日の正午までの記録を並べる
+	want := make([]string, 49)
+	want[0] = "2024-04-01"
+	want[1] = "Z"
+	want[24] = "■"
+	want[25] = "■"
+	want[40] = "□▲"
+	want[48] = "╱"
+	if !reflect.DeepEqual(rows[1], want) {
+		t.Errorf("row 2024-04-01 =\n%q\nwant\n%q", rows[1], want)
+	}
+	want = make([]string, 49)
+	want[0] = "2024-04-02"
+	want[1] = "□"
+	if !reflect.DeepEqual(rows[2], want) {
+		t.Errorf("row 2024-04-02 =\n%q\nwant\n%q", rows[2], want)
+	}
+
+	// 期間を指定した場合は、期間内の日の行のみ
+	rows = csvWideRows(diaries, records, states, mealTypes, CSVExportOptions{StartDate: testDate(t, "2024-04-02")})
+	if len(rows) != 2 || rows[1][0] != "2024-04-02" || len(rows[1]) != 49 {
+		t.Errorf("rows from 2024-04-02 = %q", rows)
+	}
+}
+
+// 文字コードごとのCSVのバイト列を確認
+func TestWriteCSVEncoding(t *testing.T) {
+	s := newTestService(t)
+	rows := [][]string{{"日付", "状態"}, {"2024-04-01", "睡眠中■"}, {"メモ", "😴"}}
+
+	tests := []struct {
+		encoding string
+		want     []byte
+	}{
+		{
+			encoding: CSVEncodingUTF8,
+			want:     []byte("日付,状態\r\n2024-04-01,睡眠中■\r\nメモ,😴\r\n"),
+		},
+		{
+			encoding: CSVEncodingUTF8BOM,
+			want:     []byte("\xef\xbb\xbf日付,状態\r\n2024-04-01,睡眠中■\r\nメモ,😴\r\n"),
+		},
+		{
+			// 日付 = 93FA 9574、状態 = 8FF3 91D4、睡眠中 = 9087 96B0 9286、■ = 81A1、メモ = 8381 8382
+			// Shift_JISで表せない文字は代替文字（0x1A）に置き換える
+			encoding: CSVEncodingShiftJIS,
+			want: []byte("\x93\xfa\x95\x74,\x8f\xf3\x91\xd4\r\n" +
+				"2024-04-01,\x90\x87\x96\xb0\x92\x86\x81\xa1\r\n" +
+				"\x83\x81\x83\x82,\x1a\r\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := s.Export().WriteCSV(&buf, rows, tt.encoding); err != nil {
+				t.Fatalf("WriteCSV: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("WriteCSV =\n% x\nwant\n% x", buf.Bytes(), tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := s.Export().WriteCSV(&buf, rows, "latin1"); err != ErrCSVExportEncoding {
+		t.Errorf("WriteCSV latin1: error = %v, want %v", err, ErrCSVExportEncoding)
+	}
+}
+
+// CSV出力の設定の既定値と検証を確認
+func TestCSVExportOptionsValidate(t *testing.T) {
+	opts := CSVExportOptions{}
+	if err := opts.Validate(); err != nil || opts.Layout != CSVLayoutLong || opts.Encoding != CSVEncodingUTF8 {
+		t.Errorf("Validate defaults = %+v, %v", opts, err)
+	}
+
+	tests := []struct {
+		name string
+		opts CSVExportOptions
+		want error
+	}{
+		{name: "横長・Shift_JIS", opts: CSVExportOptions{Layout: CSVLayoutWide, Encoding: CSVEncodingShiftJIS}},
+		{name: "形式が正しくない", opts: CSVExportOptions{Layout: "grid"}, want: ErrCSVExportLayout},
+		{name: "文字コードが正しくない", opts: CSVExportOptions{Encoding: "euc-jp"}, want: ErrCSVExportEncoding},
+		{
+			name: "期間が逆",
+			opts: CSVExportOptions{StartDate: time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+			want: ErrInvalidTimeRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); err != tt.want {
+				t.Errorf("Validate: error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
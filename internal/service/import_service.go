@@ -0,0 +1,484 @@
+// internal/service/import_service.go
+// import_serviceは、CSVファイルから睡眠記録を取り込むサービスを提供します。
+// 設定画面のCSV出力と同じ形式（日付,時間枠,状態,種別,メモ）のほか、状態を睡眠状態のコードで指定する形式に対応します。
+// 文字コードはUTF-8（BOMの有無を問わない）とShift_JISに対応します。
+// 取り込む前に全行を検証し、取り込めない行がある場合は1件も作成しません。
+
+// Package service provides application services.
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+const (
+	// 取り込めるCSVファイルの最大サイズ（バイト）
+	CSVImportMaxBytes = 5 << 20
+	// 取り込めるCSVファイルの最大行数（見出し行を除く）
+	CSVImportMaxRows = 20000
+)
+
+var (
+	// ErrCSVImportTooLarge CSVファイルが大きすぎます
+	ErrCSVImportTooLarge = errors.New("csv is too large / CSVファイルが大きすぎます（5MB・20,000行まで）")
+	// ErrCSVImportHeader CSVの見出し行に日付・時間枠・状態の列が必要です
+	ErrCSVImportHeader = errors.New("csv header must include date, time slot and state columns / CSVの見出し行に日付・時間枠・状態の列が必要です")
+	// ErrCSVImportEmpty CSVに取り込む行がありません
+	ErrCSVImportEmpty = errors.New("csv has no records to import / CSVに取り込む行がありません")
+	// ErrCSVImportInvalid CSVに取り込めない行があります
+	ErrCSVImportInvalid = errors.New("csv has invalid lines / CSVに取り込めない行があります")
+)
+
+// CSVの見出しと列の対応（見出しは小文字にして比較する）
+var csvImportColumns = map[string]string{
+	"日付":          "date",
+	"記録日":         "date",
+	"date":        "date",
+	"record_date": "date",
+	"時間枠":         "slot",
+	"時刻":          "slot",
+	"time_slot":   "slot",
+	"状態":          "state",
+	"状態コード":       "state",
+	"state":       "state",
+	"state_code":  "state",
+	"種別":          "type",
+	"記録種別":        "type",
+	"record_type": "type",
+	"食事":          "meal",
+	"食事種別":        "meal",
+	"meal_type":   "meal",
+	"メモ":          "note",
+	"備考":          "note",
+	"note":        "note",
+}
+
+// 種別の表記と記録種別の対応（大文字にして比較する）
+var csvImportRecordTypes = map[string]string{
+	"":                     models.RecordTypeState,
+	models.RecordTypeState: models.RecordTypeState,
+	models.RecordTypeEvent: models.RecordTypeEvent,
+	models.RecordTypeMeal:  models.RecordTypeMeal,
+	"状態":                   models.RecordTypeState,
+	"イベント":                 models.RecordTypeEvent,
+	"食事":                   models.RecordTypeMeal,
+}
+
+// CSVの日付の形式
+var csvImportDateLayouts = []string{"2006-01-02", "2006/01/02", "2006/1/2"}
+
+// CSVの時間枠の形式
+var csvImportSlotLayouts = []string{"15:04", "15:04:05"}
+
+// CSV取り込み関連のサービス
+type ImportService struct {
+	s *Service
+}
+
+// CSVの取り込み結果
+type CSVImport struct {
+	Rows       []*CSVImportRow   // 取り込む行（重複する行を除く）
+	Errors     []*CSVImportError // 取り込めない行
+	Duplicates int               // 既存の記録やCSVの前の行と重複するため取り込まない行の数
+	Imported   int               // 作成した記録の数（確認のみの場合は0）
+}
+
+// CSVの取り込む行
+type CSVImportRow struct {
+	Line     int
+	Record   *models.SleepRecord
+	State    *models.SleepState
+	MealType *models.MealType // 食事種別を指定しない場合はnil
+}
+
+// CSVの取り込めない行
+type CSVImportError struct {
+	Line    int
+	Message string
+}
+
+// CSVの1行分の値
+type csvImportLine struct {
+	line   int
+	values map[string]string
+}
+
+// CSVの取り込み中に参照するマスターデータと睡眠日誌
+type csvImportLookup struct {
+	states    map[string]*models.SleepState
+	mealTypes map[string]*models.MealType
+	diaries   map[string]*models.SleepDiary
+}
+
+// 新しいImportServiceを作成
+func NewImportService(s *Service) *ImportService {
+	return &ImportService{s: s}
+}
+
+// 取り込めない行がなく、取り込む行があるか
+func (r *CSVImport) Valid() bool {
+	return len(r.Errors) == 0 && len(r.Rows) > 0
+}
+
+// CSVを検証して取り込む内容を確認（記録は作成しない）
+func (s *ImportService) PreviewCSV(ctx context.Context, userID int64, r io.Reader) (*CSVImport, error) {
+	return s.parseCSV(ctx, userID, r)
+}
+
+// CSVを検証して睡眠記録を取り込む（検証と作成を1つのトランザクションで行う）
+// 取り込めない行がある場合は1件も作成せず、確認結果とあわせて ErrCSVImportInvalid を返す
+func (s *ImportService) ImportCSV(ctx context.Context, userID int64, r io.Reader) (*CSVImport, error) {
+	var result *CSVImport
+	err := s.s.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if result, err = s.parseCSV(ctx, userID, r); err != nil {
+			return err
+		}
+		if len(result.Errors) > 0 {
+			return ErrCSVImportInvalid
+		}
+
+		records := make([]*models.SleepRecord, 0, len(result.Rows))
+		for _, row := range result.Rows {
+			records = append(records, row.Record)
+		}
+		if err := s.s.Record().BulkCreateRecords(ctx, records); err != nil {
+			return err
+		}
+		result.Imported = len(records)
+		return nil
+	})
+	if errors.Is(err, ErrCSVImportInvalid) {
+		return result, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CSVを解析して各行を検証
+// ファイル全体を取り込めない場合（サイズ・見出し行・行がない）はエラーを返し、行ごとの誤りは結果の Errors に含める
+func (s *ImportService) parseCSV(ctx context.Context, userID int64, r io.Reader) (*CSVImport, error) {
+	lines, err := readCSVImportLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CSVImport{}
+	lookup := &csvImportLookup{
+		states:    make(map[string]*models.SleepState),
+		mealTypes: make(map[string]*models.MealType),
+		diaries:   make(map[string]*models.SleepDiary),
+	}
+	existing, err := s.existingRecordKeys(ctx, userID, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		if line.values == nil {
+			result.Errors = append(result.Errors, &CSVImportError{Line: line.line, Message: "CSVの形式が正しくありません（引用符の対応などを確認してください）"})
+			continue
+		}
+
+		row, message, err := s.parseCSVImportLine(ctx, userID, line, lookup)
+		if err != nil {
+			return nil, err
+		}
+		if message != "" {
+			result.Errors = append(result.Errors, &CSVImportError{Line: line.line, Message: message})
+			continue
+		}
+
+		key := csvImportRecordKey(row.Record)
+		if existing[key] {
+			result.Duplicates++
+			continue
+		}
+		existing[key] = true
+		result.Rows = append(result.Rows, row)
+	}
+
+	if len(result.Rows) == 0 && len(result.Errors) == 0 && result.Duplicates == 0 {
+		return nil, ErrCSVImportEmpty
+	}
+	return result, nil
+}
+
+// 1行分の値を検証して睡眠記録を作成
+// 行の誤りはメッセージとして返し、データベースのエラーのみをエラーとして返す
+func (s *ImportService) parseCSVImportLine(ctx context.Context, userID int64, line csvImportLine, lookup *csvImportLookup) (*CSVImportRow, string, error) {
+	values := line.values
+
+	date, ok := parseCSVImportTime(values["date"], csvImportDateLayouts)
+	if !ok {
+		return nil, fmt.Sprintf("日付「%s」を読み取れません（YYYY-MM-DD の形式で指定してください）", values["date"]), nil
+	}
+	slot, ok := parseCSVImportTime(values["slot"], csvImportSlotLayouts)
+	if !ok {
+		return nil, fmt.Sprintf("時間枠「%s」を読み取れません（HH:MM の形式で指定してください）", values["slot"]), nil
+	}
+	record := &models.SleepRecord{
+		RecordDate: date,
+		TimeSlot:   time.Date(0, 1, 1, slot.Hour(), slot.Minute(), slot.Second(), 0, time.UTC),
+		Note:       sql.NullString{String: values["note"], Valid: values["note"] != ""},
+	}
+	if !record.IsValidTimeSlot() || slot.Second() != 0 {
+		return nil, fmt.Sprintf("時間枠「%s」は30分単位（00分または30分）で指定してください", values["slot"]), nil
+	}
+
+	recordType, ok := csvImportRecordTypes[strings.ToUpper(values["type"])]
+	if !ok {
+		return nil, fmt.Sprintf("種別「%s」は STATE・EVENT・MEAL のいずれかで指定してください", values["type"]), nil
+	}
+	record.RecordType = recordType
+
+	state, err := s.lookupState(ctx, values["state"], lookup)
+	if err != nil {
+		return nil, "", err
+	}
+	if state == nil {
+		return nil, fmt.Sprintf("睡眠状態「%s」が見つかりません（睡眠状態のコードまたは名前で指定してください）", values["state"]), nil
+	}
+	record.SleepStateID = state.ID
+
+	var mealType *models.MealType
+	if values["meal"] != "" {
+		if recordType != models.RecordTypeMeal {
+			return nil, "食事種別は、種別が MEAL の行のみ指定できます", nil
+		}
+		if mealType, err = s.lookupMealType(ctx, values["meal"], lookup); err != nil {
+			return nil, "", err
+		}
+		if mealType == nil {
+			return nil, fmt.Sprintf("食事種別「%s」が見つかりません（食事種別のコードまたは名前で指定してください）", values["meal"]), nil
+		}
+		record.MealTypeID = sql.NullInt64{Int64: mealType.ID, Valid: true}
+	}
+
+	dateKey := date.Format("2006-01-02")
+	diary, ok := lookup.diaries[dateKey]
+	if !ok {
+		diary, err = s.s.Diary().GetDiaryForDate(ctx, userID, date)
+		if err != nil && !errors.Is(err, ErrNoDiaryForDate) {
+			return nil, "", err
+		}
+		lookup.diaries[dateKey] = diary
+	}
+	if diary == nil {
+		return nil, fmt.Sprintf("%s を含む睡眠日誌がありません（先に睡眠日誌を作成してください）", dateKey), nil
+	}
+	record.SleepDiaryID = diary.ID
+
+	return &CSVImportRow{Line: line.line, Record: record, State: state, MealType: mealType}, "", nil
+}
+
+// 睡眠状態をコード・名前・ID（設定画面のCSV出力の形式）のいずれかで検索（見つからない場合はnil）
+func (s *ImportService) lookupState(ctx context.Context, value string, lookup *csvImportLookup) (*models.SleepState, error) {
+	if state, ok := lookup.states[value]; ok {
+		return state, nil
+	}
+
+	repo := s.s.repoFor(ctx).SleepState()
+	state, err := repo.GetByCode(ctx, strings.ToUpper(value))
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		states, err := repo.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		id, idErr := strconv.ParseInt(value, 10, 64)
+		for _, candidate := range states {
+			if candidate.StateName == value || (idErr == nil && candidate.ID == id) {
+				state = candidate
+				break
+			}
+		}
+	}
+
+	lookup.states[value] = state
+	return state, nil
+}
+
+// 食事種別をコード・名前のいずれかで検索（見つからない場合はnil）
+func (s *ImportService) lookupMealType(ctx context.Context, value string, lookup *csvImportLookup) (*models.MealType, error) {
+	if mealType, ok := lookup.mealTypes[value]; ok {
+		return mealType, nil
+	}
+
+	repo := s.s.repoFor(ctx).MealType()
+	mealType, err := repo.GetByCode(ctx, strings.ToUpper(value))
+	if err != nil {
+		return nil, err
+	}
+	if mealType == nil {
+		mealTypes, err := repo.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range mealTypes {
+			if candidate.TypeName == value {
+				mealType = candidate
+				break
+			}
+		}
+	}
+
+	lookup.mealTypes[value] = mealType
+	return mealType, nil
+}
+
+// CSVの日付の範囲にあるユーザーの既存の記録を、重複の判定に使うキーの集合として取得
+func (s *ImportService) existingRecordKeys(ctx context.Context, userID int64, lines []csvImportLine) (map[string]bool, error) {
+	var start, end time.Time
+	for _, line := range lines {
+		date, ok := parseCSVImportTime(line.values["date"], csvImportDateLayouts)
+		if !ok {
+			continue
+		}
+		if start.IsZero() || date.Before(start) {
+			start = date
+		}
+		if end.IsZero() || date.After(end) {
+			end = date
+		}
+	}
+
+	keys := make(map[string]bool)
+	if start.IsZero() {
+		return keys, nil
+	}
+	records, err := s.s.Record().GetUserRecordsByDateRange(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		keys[csvImportRecordKey(record)] = true
+	}
+	return keys, nil
+}
+
+// CSVを読み込んで見出し行に従って行ごとの値にする（空行は除く）
+// 形式が正しくない行は values をnilとし、以降の行は読み込まない
+func readCSVImportLines(r io.Reader) ([]csvImportLine, error) {
+	data, err := io.ReadAll(io.LimitReader(r, CSVImportMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > CSVImportMaxBytes {
+		return nil, ErrCSVImportTooLarge
+	}
+	data = DecodeCSVImport(data)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrCSVImportEmpty
+	}
+	if err != nil {
+		return nil, ErrCSVImportHeader
+	}
+	columns := make([]string, len(header))
+	found := make(map[string]bool)
+	for i, name := range header {
+		columns[i] = csvImportColumns[strings.ToLower(strings.TrimSpace(name))]
+		found[columns[i]] = true
+	}
+	if !found["date"] || !found["slot"] || !found["state"] {
+		return nil, ErrCSVImportHeader
+	}
+
+	var lines []csvImportLine
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			lines = append(lines, csvImportLine{line: parseErr.StartLine})
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string)
+		empty := true
+		for i, value := range fields {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				empty = false
+			}
+			if i < len(columns) && columns[i] != "" {
+				values[columns[i]] = value
+			}
+		}
+		if empty {
+			continue
+		}
+		if len(lines) >= CSVImportMaxRows {
+			return nil, ErrCSVImportTooLarge
+		}
+		lines = append(lines, csvImportLine{line: line, values: values})
+	}
+
+	return lines, nil
+}
+
+// 取り込むCSVをUTF-8にする
+// Excelなどで保存したUTF-8のBOMを除き、UTF-8として正しくない場合はShift_JISとして読み込む
+func DecodeCSVImport(data []byte) []byte {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if utf8.Valid(data) {
+		return data
+	}
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+	if err != nil {
+		return data
+	}
+	return decoded
+}
+
+// 日付・時刻を指定した形式のいずれかで読み込む
+func parseCSVImportTime(value string, layouts []string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// 重複の判定に使う記録のキー（記録日・時間枠・種別・睡眠状態・食事種別）
+func csvImportRecordKey(record *models.SleepRecord) string {
+	return fmt.Sprintf("%s|%s|%s|%d|%d",
+		record.RecordDate.Format("2006-01-02"),
+		record.TimeSlot.Format("15:04"),
+		record.RecordType,
+		record.SleepStateID,
+		record.MealTypeID.Int64,
+	)
+}
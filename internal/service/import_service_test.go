@@ -0,0 +1,348 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// CSV取り込みのテスト用に、睡眠日誌と登録済みの記録（2024-04-05 12:00 通常覚醒）を持つユーザーを作成
+func createImportTestUser(t *testing.T, s *Service) *models.User {
+	t.Helper()
+	user := createTestUser(t, s, "import@example.com")
+	createTestDiary(t, s, user.ID, "2024-04-01", "2024-04-07")
+
+	stateIDs, _, err := s.Record().masterIDs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := &models.SleepRecord{
+		SleepStateID: stateIDs[models.StateCodeAwake],
+		RecordDate:   testDate(t, "2024-04-05"),
+		TimeSlot:     testClock(t, "12:00"),
+		RecordType:   models.RecordTypeState,
+	}
+	if err := s.Record().CreateUserRecord(context.Background(), user.ID, record); err != nil {
+		t.Fatalf("failed to create record: %v", err)
+	}
+	return user
+}
+
+// 取り込む行を「日付|時間枠|種別|状態コード|食事種別コード|メモ」の形にする
+func importRowKeys(result *CSVImport) []string {
+	keys := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		var mealType string
+		if row.MealType != nil {
+			mealType = row.MealType.TypeCode
+		}
+		keys = append(keys, fmt.Sprintf("%s|%s|%s|%s|%s|%s",
+			row.Record.RecordDate.Format("2006-01-02"),
+			row.Record.TimeSlot.Format("15:04"),
+			row.Record.RecordType,
+			row.State.StateCode,
+			mealType,
+			row.Record.Note.String,
+		))
+	}
+	return keys
+}
+
+// 保存されているユーザーの記録を「日付|時間枠|種別|状態コード|食事種別コード|メモ」の形で取得
+func storedRecordKeys(t *testing.T, s *Service, userID int64) []string {
+	t.Helper()
+	ctx := context.Background()
+	records, err := s.Record().GetAllRecords(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetAllRecords: %v", err)
+	}
+	states, mealTypes, err := s.PDF().masterData(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(records))
+	for _, record := range records {
+		var mealType string
+		if record.MealTypeID.Valid {
+			mealType = mealTypes[record.MealTypeID.Int64].TypeCode
+		}
+		keys = append(keys, fmt.Sprintf("%s|%s|%s|%s|%s|%s",
+			record.RecordDate.Format("2006-01-02"),
+			record.TimeSlot.Format("15:04"),
+			record.RecordType,
+			states[record.SleepStateID].StateCode,
+			mealType,
+			record.Note.String,
+		))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Shift_JISにする
+func shiftJIS(t *testing.T, s string) string {
+	t.Helper()
+	encoded, err := japanese.ShiftJIS.NewEncoder().String(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+// CSVの見出し・値の読み取りと、行ごとの検証を確認
+func TestImportPreviewCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		csv        string
+		wantRows   []string
+		wantErrors []CSVImportError // Message はメッセージに含まれる文字列
+		duplicates int
+		wantErr    error
+	}{
+		{
+			name:     "日本語の見出し",
+			csv:      "日付,時間枠,状態,種別,メモ\n2024-04-01,23:00,SLEEPING,STATE,\n2024-04-02,07:00,AWAKE,,すっきり\n",
+			wantRows: []string{"2024-04-01|23:00|STATE|SLEEPING||", "2024-04-02|07:00|STATE|AWAKE||すっきり"},
+		},
+		{
+			name:     "英語の見出し（大文字・小文字を区別しない）",
+			csv:      "Record_Date, TIME_SLOT ,State_Code,record_type,meal_type,note\n2024/04/02,07:30,awake,meal,breakfast,パン\n",
+			wantRows: []string{"2024-04-02|07:30|MEAL|AWAKE|BREAKFAST|パン"},
+		},
+		{
+			name:     "別名の見出しと列の順序・不明な列",
+			csv:      "備考,状態コード,記録日,時刻,記録種別,食事種別,睡眠日誌\nメモ,DROWSINESS,2024/4/3,14:00:00,イベント,,日誌\n,AWAKE,2024-04-03,12:30,食事,昼食,日誌\n",
+			wantRows: []string{"2024-04-03|14:00|EVENT|DROWSINESS||メモ", "2024-04-03|12:30|MEAL|AWAKE|LUNCH|"},
+		},
+		{
+			name:     "状態をコード・名前・IDで指定",
+			csv:      "日付,時間枠,状態\n2024-04-01,22:00,床で覚醒\n2024-04-01,22:30,{awake_id}\n2024-04-01,23:00,Sleeping\n",
+			wantRows: []string{"2024-04-01|22:00|STATE|AWAKE_IN_BED||", "2024-04-01|22:30|STATE|AWAKE||", "2024-04-01|23:00|STATE|SLEEPING||"},
+		},
+		{
+			name: "30分単位でない時間枠",
+			csv:  "日付,時間枠,状態\n2024-04-01,07:15,AWAKE\n2024-04-01,07:30:30,AWAKE\n2024-04-01,25:00,AWAKE\n2024-04-01,7時,AWAKE\n2024-04-01,00:00,SLEEPING\n",
+			wantErrors: []CSVImportError{
+				{Line: 2, Message: "30分単位"},
+				{Line: 3, Message: "30分単位"},
+				{Line: 4, Message: "読み取れません"},
+				{Line: 5, Message: "読み取れません"},
+			},
+			wantRows: []string{"2024-04-01|00:00|STATE|SLEEPING||"},
+		},
+		{
+			name: "行ごとの誤り（空行は飛ばし、行番号はファイルの行）",
+			csv: "日付,時間枠,状態,種別,食事\n" +
+				"2024-13-01,07:00,AWAKE,,\n" +
+				"\n" +
+				"2024-04-01,07:00,NAPPING,,\n" +
+				"2024-04-01,07:00,AWAKE,SLEEP,\n" +
+				",,,,\n" +
+				"2024-04-01,07:00,AWAKE,STATE,BREAKFAST\n" +
+				"2024-04-01,07:00,AWAKE,MEAL,BRUNCH\n" +
+				"2024-04-10,07:00,AWAKE,,\n" +
+				"2024-04-01,07:00,AWAKE,MEAL,夕食\n",
+			wantErrors: []CSVImportError{
+				{Line: 2, Message: "日付「2024-13-01」"},
+				{Line: 4, Message: "睡眠状態「NAPPING」"},
+				{Line: 5, Message: "種別「SLEEP」"},
+				{Line: 7, Message: "MEAL の行のみ"},
+				{Line: 8, Message: "食事種別「BRUNCH」"},
+				{Line: 9, Message: "2024-04-10 を含む睡眠日誌がありません"},
+			},
+			wantRows: []string{"2024-04-01|07:00|MEAL|AWAKE|DINNER|"},
+		},
+		{
+			name:       "形式の誤りは以降の行を読まない",
+			csv:        "日付,時間枠,状態\n2024-04-01,23:00,SLEEPING\n2024-04-01,\"23:30,SLEEPING\n2024-04-02,00:00,SLEEPING\n",
+			wantRows:   []string{"2024-04-01|23:00|STATE|SLEEPING||"},
+			wantErrors: []CSVImportError{{Line: 3, Message: "CSVの形式"}},
+		},
+		{
+			name:       "登録済みの記録と前の行に重複する行は取り込まない",
+			csv:        "日付,時間枠,状態\n2024-04-05,12:00,AWAKE\n2024-04-05,12:30,AWAKE\n2024-04-05,12:30,通常覚醒\n2024-04-05,12:00,SLEEPING\n",
+			wantRows:   []string{"2024-04-05|12:30|STATE|AWAKE||", "2024-04-05|12:00|STATE|SLEEPING||"},
+			duplicates: 2,
+		},
+		{
+			name:       "重複する行のみ",
+			csv:        "日付,時間枠,状態\n2024-04-05,12:00,AWAKE\n",
+			duplicates: 1,
+		},
+		{
+			name:     "UTF-8のBOM",
+			csv:      "\ufeff日付,時間枠,状態,メモ\n2024-04-01,23:00,睡眠中,よく眠れた\n",
+			wantRows: []string{"2024-04-01|23:00|STATE|SLEEPING||よく眠れた"},
+		},
+		{
+			name:     "Shift_JIS",
+			csv:      "{sjis}日付,時間枠,状態,種別,食事,メモ\r\n2024/04/02,07:30,通常覚醒,食事,朝食,ご飯と味噌汁\r\n",
+			wantRows: []string{"2024-04-02|07:30|MEAL|AWAKE|BREAKFAST|ご飯と味噌汁"},
+		},
+		{name: "状態の列がない", csv: "日付,時間枠,メモ\n2024-04-01,23:00,x\n", wantErr: ErrCSVImportHeader},
+		{name: "空のファイル", csv: "", wantErr: ErrCSVImportEmpty},
+		{name: "見出し行のみ", csv: "日付,時間枠,状態\n\n", wantErr: ErrCSVImportEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t)
+			user := createImportTestUser(t, s)
+			stateIDs, _, err := s.Record().masterIDs(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			csv := strings.ReplaceAll(tt.csv, "{awake_id}", fmt.Sprint(stateIDs[models.StateCodeAwake]))
+			if rest, ok := strings.CutPrefix(csv, "{sjis}"); ok {
+				csv = shiftJIS(t, rest)
+			}
+
+			result, err := s.Import().PreviewCSV(ctx, user.ID, strings.NewReader(csv))
+			if tt.wantErr != nil || err != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("PreviewCSV: error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if got := importRowKeys(result); !slices.Equal(got, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", got, tt.wantRows)
+			}
+			if len(result.Errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %d, want %d: %+v", len(result.Errors), len(tt.wantErrors), result.Errors)
+			}
+			for i, want := range tt.wantErrors {
+				got := result.Errors[i]
+				if got.Line != want.Line || !strings.Contains(got.Message, want.Message) {
+					t.Errorf("error %d = line %d %q, want line %d containing %q", i, got.Line, got.Message, want.Line, want.Message)
+				}
+			}
+			if result.Duplicates != tt.duplicates {
+				t.Errorf("duplicates = %d, want %d", result.Duplicates, tt.duplicates)
+			}
+			if result.Imported != 0 {
+				t.Errorf("imported = %d, want 0 for preview", result.Imported)
+			}
+			if got := storedRecordKeys(t, s, user.ID); len(got) != 1 {
+				t.Errorf("preview created records: %q", got)
+			}
+		})
+	}
+}
+
+// 取り込めない行がある場合は1件も作成しないことを確認
+func TestImportCSVAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	user := createImportTestUser(t, s)
+	before := storedRecordKeys(t, s, user.ID)
+
+	invalid := "日付,時間枠,状態\n2024-04-01,23:00,SLEEPING\n2024-04-01,23:30,SLEEPING\n2024-04-02,00:15,SLEEPING\n"
+	result, err := s.Import().ImportCSV(ctx, user.ID, strings.NewReader(invalid))
+	if !errors.Is(err, ErrCSVImportInvalid) {
+		t.Fatalf("ImportCSV: error = %v, want %v", err, ErrCSVImportInvalid)
+	}
+	// 確認画面に表示するため、取り込めない行とあわせて結果を返す
+	if result == nil || len(result.Rows) != 2 || len(result.Errors) != 1 || result.Errors[0].Line != 4 || result.Imported != 0 {
+		t.Fatalf("result = %+v, want 2 rows and an error on line 4", result)
+	}
+	if got := storedRecordKeys(t, s, user.ID); !slices.Equal(got, before) {
+		t.Fatalf("records after invalid import = %q, want %q", got, before)
+	}
+
+	valid := "日付,時間枠,状態\n2024-04-01,23:00,SLEEPING\n2024-04-01,23:30,SLEEPING\n2024-04-05,12:00,AWAKE\n"
+	result, err = s.Import().ImportCSV(ctx, user.ID, strings.NewReader(valid))
+	if err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	if result.Imported != 2 || result.Duplicates != 1 {
+		t.Errorf("imported = %d, duplicates = %d, want 2, 1", result.Imported, result.Duplicates)
+	}
+	want := []string{"2024-04-01|23:00|STATE|SLEEPING||", "2024-04-01|23:30|STATE|SLEEPING||", "2024-04-05|12:00|STATE|AWAKE||"}
+	if got := storedRecordKeys(t, s, user.ID); !slices.Equal(got, want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+
+	// 同じCSVをもう一度取り込んでも、記録は増えない
+	result, err = s.Import().ImportCSV(ctx, user.ID, strings.NewReader(valid))
+	if err != nil {
+		t.Fatalf("ImportCSV again: %v", err)
+	}
+	if result.Imported != 0 || result.Duplicates != 3 {
+		t.Errorf("imported = %d, duplicates = %d, want 0, 3", result.Imported, result.Duplicates)
+	}
+}
+
+// 設定画面のCSV出力（縦長の形式）を、別のユーザーにそのまま取り込めることを確認
+func TestImportCSVExportRoundTrip(t *testing.T) {
+	for _, encoding := range []string{CSVEncodingUTF8, CSVEncodingUTF8BOM, CSVEncodingShiftJIS} {
+		t.Run(encoding, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t)
+			source := createTestUser(t, s, "source@example.com")
+			createTestDiary(t, s, source.ID, "2024-04-01", "2024-04-07")
+			breakfast := models.NightMeal{MealTypeCode: models.MealCodeBreakfast, At: models.NightEntryTime(testDate(t, "2024-04-01"), testClock(t, "07:30"))}
+			saveTestNight(t, s, source.ID, "2024-04-01", "23:00", "07:00", breakfast)
+			saveTestNight(t, s, source.ID, "2024-04-02", "00:30", "06:30")
+
+			target := createTestUser(t, s, "target@example.com")
+			createTestDiary(t, s, target.ID, "2024-04-01", "2024-04-07")
+
+			rows, err := s.Export().CSVRows(ctx, source.ID, CSVExportOptions{Encoding: encoding})
+			if err != nil {
+				t.Fatalf("CSVRows: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := s.Export().WriteCSV(&buf, rows, encoding); err != nil {
+				t.Fatalf("WriteCSV: %v", err)
+			}
+
+			result, err := s.Import().ImportCSV(ctx, target.ID, &buf)
+			if err != nil {
+				t.Fatalf("ImportCSV: %v %+v", err, result)
+			}
+			want := storedRecordKeys(t, s, source.ID)
+			if result.Imported != len(want) || result.Duplicates != 0 {
+				t.Errorf("imported = %d, duplicates = %d, want %d, 0", result.Imported, result.Duplicates, len(want))
+			}
+			if got := storedRecordKeys(t, s, target.ID); !slices.Equal(got, want) {
+				t.Errorf("imported records differ:\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+// 取り込むCSVをUTF-8にすることを確認
+func TestDecodeCSVImport(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "UTF-8", data: "日付,状態\n", want: "日付,状態\n"},
+		{name: "BOM付きのUTF-8", data: "\ufeff日付,状態\n", want: "日付,状態\n"},
+		{name: "Shift_JIS", data: "{sjis}日付,状態,メモ（全角）\n", want: "日付,状態,メモ（全角）\n"},
+		{name: "ASCIIのみ", data: "date,state\n", want: "date,state\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data
+			if rest, ok := strings.CutPrefix(data, "{sjis}"); ok {
+				data = shiftJIS(t, rest)
+			}
+			if got := string(DecodeCSVImport([]byte(data))); got != tt.want {
+				t.Errorf("DecodeCSVImport = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+// internal/service/login_throttle_service.go
+// login_throttle_serviceは、ログインの総当たり攻撃対策（試行回数の制限とアカウントの一時ロック）のサービスを提供します。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+var (
+	// ErrInvalidCredentials メールアドレスまたはパスワードが正しくありません
+	ErrInvalidCredentials = errors.New("invalid credentials / メールアドレスまたはパスワードが正しくありません")
+	// ErrLoginThrottled ログインの試行が多すぎます
+	ErrLoginThrottled = errors.New("too many login attempts / ログインの試行が多すぎます")
+	// ErrAccountLocked アカウントが一時的にロックされています
+	ErrAccountLocked = errors.New("account temporarily locked / アカウントが一時的にロックされています")
+	// ErrIPLocked 接続元のIPアドレスからのログインが一時的に制限されています
+	ErrIPLocked = errors.New("login from this IP address temporarily locked / 接続元のIPアドレスからのログインが一時的に制限されています")
+)
+
+// ログイン失敗の監査記録の保存期間
+const loginFailureRetention = 90 * 24 * time.Hour
+
+// ログイン制限のエラー（再試行できるまでの時間を含む）
+type LoginThrottleError struct {
+	Err        error // ErrLoginThrottled、ErrAccountLocked（メールアドレスのロック）または ErrIPLocked（IPアドレスのロック）
+	RetryAfter time.Duration
+}
+
+// エラーメッセージを取得
+func (e *LoginThrottleError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.RetryAfter)
+}
+
+// 元のエラーを取得
+func (e *LoginThrottleError) Unwrap() error {
+	return e.Err
+}
+
+// ログイン試行の制限ルール
+type LoginThrottlePolicy struct {
+	Window          time.Duration // 失敗回数を数える期間（最後の失敗からこの期間が過ぎるとリセット）
+	MaxFailures     int           // メールアドレスごとの、ロックするまでの失敗回数
+	MaxIPFailures   int           // IPアドレスごとの、ロックするまでの失敗回数
+	LockoutDuration time.Duration // ロックする期間
+	BaseDelay       time.Duration // 1回目の失敗後に次の試行まで待つ時間（失敗のたびに倍増）
+	MaxDelay        time.Duration // 次の試行まで待つ時間の上限
+}
+
+// 既定のログイン試行の制限ルール
+func DefaultLoginThrottlePolicy() LoginThrottlePolicy {
+	return LoginThrottlePolicy{
+		Window:          15 * time.Minute,
+		MaxFailures:     5,
+		MaxIPFailures:   20,
+		LockoutDuration: 15 * time.Minute,
+		BaseDelay:       time.Second,
+		MaxDelay:        30 * time.Second,
+	}
+}
+
+// ログイン試行の状況
+type LoginAttempt struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// ログイン試行の状況のストアインターフェイス
+// 既定はインメモリで、複数のサーバーで状況を共有する場合は外部のストアに差し替える
+type LoginAttemptStore interface {
+	Get(ctx context.Context, key string) (*LoginAttempt, error)
+	Put(ctx context.Context, key string, attempt *LoginAttempt, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// ログインの総当たり攻撃対策のサービス
+type LoginThrottleService struct {
+	s      *Service
+	store  LoginAttemptStore
+	policy LoginThrottlePolicy
+	mutex  sync.Mutex
+	now    func() time.Time
+}
+
+// 新しいLoginThrottleServiceを作成
+func NewLoginThrottleService(s *Service) *LoginThrottleService {
+	return &LoginThrottleService{
+		s:      s,
+		store:  NewMemoryLoginAttemptStore(),
+		policy: DefaultLoginThrottlePolicy(),
+		now:    time.Now,
+	}
+}
+
+// ストアを差し替え
+func (s *LoginThrottleService) SetStore(store LoginAttemptStore) {
+	s.store = store
+}
+
+// 制限ルールを設定（0以下の項目は変更しない）
+func (s *LoginThrottleService) SetPolicy(policy LoginThrottlePolicy) {
+	defaults := s.policy
+	if policy.Window <= 0 {
+		policy.Window = defaults.Window
+	}
+	if policy.MaxFailures <= 0 {
+		policy.MaxFailures = defaults.MaxFailures
+	}
+	if policy.MaxIPFailures <= 0 {
+		policy.MaxIPFailures = defaults.MaxIPFailures
+	}
+	if policy.LockoutDuration <= 0 {
+		policy.LockoutDuration = defaults.LockoutDuration
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaults.BaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaults.MaxDelay
+	}
+	s.policy = policy
+}
+
+// ログインを試行できるかチェック
+// ロック中または試行間隔の制限中の場合は、監査記録を残して *LoginThrottleError を返す
+// IPアドレスのロックはパスワードの再設定では解除されないため、メールアドレスのロックより先に判定する
+func (s *LoginThrottleService) Check(ctx context.Context, email, ipAddress string) error {
+	now := s.now()
+
+	limits := []struct {
+		key     string
+		lockErr error
+	}{
+		{ipThrottleKey(ipAddress), ErrIPLocked},
+		{emailThrottleKey(email), ErrAccountLocked},
+	}
+	for _, limit := range limits {
+		attempt, err := s.store.Get(ctx, limit.key)
+		if err != nil {
+			return err
+		}
+		if attempt == nil {
+			continue
+		}
+
+		if now.Before(attempt.LockedUntil) {
+			s.audit(ctx, email, ipAddress, models.LoginFailureLocked)
+			return &LoginThrottleError{Err: limit.lockErr, RetryAfter: attempt.LockedUntil.Sub(now)}
+		}
+		if next := attempt.LastFailure.Add(s.delay(attempt.Failures)); now.Before(next) {
+			s.audit(ctx, email, ipAddress, models.LoginFailureThrottled)
+			return &LoginThrottleError{Err: ErrLoginThrottled, RetryAfter: next.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// ログインの失敗を記録
+// 失敗回数が上限に達した場合はロックし、*LoginThrottleError を返す（両方がロックされた場合はIPアドレスのロックを返す）
+func (s *LoginThrottleService) RecordFailure(ctx context.Context, email, ipAddress string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.audit(ctx, email, ipAddress, models.LoginFailureInvalidCredentials)
+
+	now := s.now()
+	var locked *LoginThrottleError
+	limits := []struct {
+		key         string
+		maxFailures int
+		lockErr     error
+	}{
+		{emailThrottleKey(email), s.policy.MaxFailures, ErrAccountLocked},
+		{ipThrottleKey(ipAddress), s.policy.MaxIPFailures, ErrIPLocked},
+	}
+	for _, limit := range limits {
+		attempt, err := s.store.Get(ctx, limit.key)
+		if err != nil {
+			return err
+		}
+		if attempt == nil || now.Sub(attempt.LastFailure) > s.policy.Window || !attempt.LockedUntil.IsZero() && !now.Before(attempt.LockedUntil) {
+			attempt = &LoginAttempt{}
+		}
+
+		attempt.Failures++
+		attempt.LastFailure = now
+		ttl := s.policy.Window
+		if attempt.Failures >= limit.maxFailures {
+			attempt.LockedUntil = now.Add(s.policy.LockoutDuration)
+			ttl = s.policy.LockoutDuration
+			locked = &LoginThrottleError{Err: limit.lockErr, RetryAfter: s.policy.LockoutDuration}
+			s.s.Logger().Info("ログインの失敗が上限に達したためロック: key=%s, failures=%d, until=%s",
+				limit.key, attempt.Failures, attempt.LockedUntil.Format(time.RFC3339))
+		}
+
+		if err := s.store.Put(ctx, limit.key, attempt, ttl); err != nil {
+			return err
+		}
+	}
+
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+// ログインの成功を記録（メールアドレスの失敗回数をリセット）
+// IPアドレスの失敗回数は、他のアカウントへの試行を続けられないようリセットしない
+func (s *LoginThrottleService) RecordSuccess(ctx context.Context, email string) error {
+	return s.store.Delete(ctx, emailThrottleKey(email))
+}
+
+// アカウントのロックを解除（パスワードの再設定時）
+// IPアドレスのロックは、攻撃者が自分のアカウントのパスワードを再設定して解除できないよう、期限まで解除しない
+func (s *LoginThrottleService) Unlock(ctx context.Context, email string) error {
+	return s.store.Delete(ctx, emailThrottleKey(email))
+}
+
+// メールアドレスのログイン失敗の監査記録を取得
+func (s *LoginThrottleService) GetFailures(ctx context.Context, email string, since time.Time) ([]*models.LoginFailure, error) {
+	return s.s.repoFor(ctx).LoginFailure().GetByEmail(ctx, normalizeLoginEmail(email), since)
+}
+
+// 保存期間を過ぎたログイン失敗の監査記録を削除
+func (s *LoginThrottleService) PurgeAudit(ctx context.Context) (int64, error) {
+	return s.s.repoFor(ctx).LoginFailure().DeleteBefore(ctx, s.now().Add(-loginFailureRetention))
+}
+
+// 失敗回数に応じた次の試行までの待ち時間
+func (s *LoginThrottleService) delay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay := s.policy.BaseDelay
+	for i := 1; i < failures && delay < s.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.policy.MaxDelay {
+		delay = s.policy.MaxDelay
+	}
+	return delay
+}
+
+// ログイン失敗の監査記録を保存（保存に失敗してもログインの処理は続ける）
+func (s *LoginThrottleService) audit(ctx context.Context, email, ipAddress, reason string) {
+	failure := &models.LoginFailure{
+		Email:     normalizeLoginEmail(email),
+		IPAddress: ipAddress,
+		Reason:    reason,
+	}
+	if err := s.s.repoFor(ctx).LoginFailure().Create(ctx, failure); err != nil {
+		s.s.Logger().Error("ログイン失敗の記録に失敗: error=%v, email=%s, ip=%s", err, failure.Email, ipAddress)
+	}
+}
+
+// メールアドレスの表記を統一
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// メールアドレスごとの試行状況のキー
+func emailThrottleKey(email string) string {
+	return "email:" + normalizeLoginEmail(email)
+}
+
+// IPアドレスごとの試行状況のキー
+func ipThrottleKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+// ログイン試行の状況のインメモリストア
+type MemoryLoginAttemptStore struct {
+	mutex    sync.Mutex
+	attempts map[string]memoryLoginAttempt
+	now      func() time.Time
+}
+
+// 有効期限付きのログイン試行の状況
+type memoryLoginAttempt struct {
+	attempt LoginAttempt
+	expires time.Time
+}
+
+// 新しいMemoryLoginAttemptStoreを作成
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{
+		attempts: make(map[string]memoryLoginAttempt),
+		now:      time.Now,
+	}
+}
+
+// キーで試行状況を取得（ない場合や期限切れの場合はnil）
+func (m *MemoryLoginAttemptStore) Get(_ context.Context, key string) (*LoginAttempt, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, ok := m.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	if !m.now().Before(entry.expires) {
+		delete(m.attempts, key)
+		return nil, nil
+	}
+	attempt := entry.attempt
+	return &attempt, nil
+}
+
+// 試行状況を保存
+func (m *MemoryLoginAttemptStore) Put(_ context.Context, key string, attempt *LoginAttempt, ttl time.Duration) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	// 期限切れのデータを掃除
+	for k, entry := range m.attempts {
+		if !now.Before(entry.expires) {
+			delete(m.attempts, k)
+		}
+	}
+
+	m.attempts[key] = memoryLoginAttempt{
+		attempt: *attempt,
+		expires: now.Add(ttl),
+	}
+	return nil
+}
+
+// 試行状況を削除
+func (m *MemoryLoginAttemptStore) Delete(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.attempts, key)
+	return nil
+}
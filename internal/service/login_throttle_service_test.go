@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// 時計を差し替えたログイン試行の制限サービスを作成
+func newTestLoginThrottle(t *testing.T, policy LoginThrottlePolicy) (*LoginThrottleService, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)}
+	throttle := newTestService(t).LoginThrottle()
+	store := NewMemoryLoginAttemptStore()
+	store.now = clock.Now
+	throttle.SetStore(store)
+	throttle.SetPolicy(policy)
+	throttle.now = clock.Now
+	return throttle, clock
+}
+
+// エラーが期待するログイン制限のエラーか確認
+func assertThrottleError(t *testing.T, err, want error, retryAfter time.Duration) {
+	t.Helper()
+	var throttleErr *LoginThrottleError
+	if !errors.As(err, &throttleErr) || !errors.Is(err, want) {
+		t.Fatalf("error = %v, want %v", err, want)
+	}
+	if throttleErr.RetryAfter != retryAfter {
+		t.Fatalf("RetryAfter = %s, want %s", throttleErr.RetryAfter, retryAfter)
+	}
+}
+
+var testThrottlePolicy = LoginThrottlePolicy{
+	Window:          15 * time.Minute,
+	MaxFailures:     3,
+	MaxIPFailures:   5,
+	LockoutDuration: 10 * time.Minute,
+	BaseDelay:       time.Second,
+	MaxDelay:        4 * time.Second,
+}
+
+// 失敗のたびに次の試行までの待ち時間が倍増し、上限で止まることを確認
+func TestLoginThrottleDelay(t *testing.T) {
+	ctx := context.Background()
+	policy := testThrottlePolicy
+	policy.MaxFailures = 10
+	policy.MaxIPFailures = 10
+	throttle, clock := newTestLoginThrottle(t, policy)
+
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if err := throttle.RecordFailure(ctx, "user@example.com", "192.0.2.1"); err != nil {
+			t.Fatalf("failure %d: RecordFailure: %v", i+1, err)
+		}
+		assertThrottleError(t, throttle.Check(ctx, "user@example.com", "192.0.2.1"), ErrLoginThrottled, want)
+
+		clock.Advance(want - time.Millisecond)
+		assertThrottleError(t, throttle.Check(ctx, "user@example.com", "192.0.2.1"), ErrLoginThrottled, time.Millisecond)
+		clock.Advance(time.Millisecond)
+		if err := throttle.Check(ctx, "user@example.com", "192.0.2.1"); err != nil {
+			t.Fatalf("failure %d: Check after delay: %v", i+1, err)
+		}
+	}
+
+	// 期間が過ぎると失敗回数はリセットされる
+	clock.Advance(policy.Window + time.Second)
+	if err := throttle.RecordFailure(ctx, "user@example.com", "192.0.2.1"); err != nil {
+		t.Fatalf("RecordFailure after window: %v", err)
+	}
+	assertThrottleError(t, throttle.Check(ctx, "user@example.com", "192.0.2.1"), ErrLoginThrottled, time.Second)
+}
+
+// メールアドレスごとのロックと、パスワードの再設定による解除を確認
+func TestLoginThrottleEmailLockout(t *testing.T) {
+	ctx := context.Background()
+	throttle, clock := newTestLoginThrottle(t, testThrottlePolicy)
+
+	for i := 1; i < testThrottlePolicy.MaxFailures; i++ {
+		if err := throttle.RecordFailure(ctx, "user@example.com", "192.0.2.1"); err != nil {
+			t.Fatalf("failure %d: RecordFailure: %v", i, err)
+		}
+		clock.Advance(testThrottlePolicy.MaxDelay)
+	}
+	err := throttle.RecordFailure(ctx, " User@Example.com ", "192.0.2.2")
+	assertThrottleError(t, err, ErrAccountLocked, testThrottlePolicy.LockoutDuration)
+
+	// 別のIPアドレスからもログインできない
+	clock.Advance(time.Minute)
+	assertThrottleError(t, throttle.Check(ctx, "user@example.com", "198.51.100.1"), ErrAccountLocked, testThrottlePolicy.LockoutDuration-time.Minute)
+	// 別のメールアドレスはロックされない
+	if err := throttle.Check(ctx, "other@example.com", "198.51.100.1"); err != nil {
+		t.Fatalf("Check other email: %v", err)
+	}
+
+	// パスワードの再設定でロックを解除できる
+	if err := throttle.Unlock(ctx, "user@example.com"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := throttle.Check(ctx, "user@example.com", "198.51.100.1"); err != nil {
+		t.Fatalf("Check after unlock: %v", err)
+	}
+
+	// ロックの期間が過ぎると解除される
+	for i := 0; i < testThrottlePolicy.MaxFailures; i++ {
+		clock.Advance(testThrottlePolicy.MaxDelay)
+		throttle.RecordFailure(ctx, "user@example.com", "198.51.100.1")
+	}
+	clock.Advance(testThrottlePolicy.MaxDelay)
+	assertThrottleError(t, throttle.Check(ctx, "user@example.com", "198.51.100.1"), ErrAccountLocked, testThrottlePolicy.LockoutDuration-testThrottlePolicy.MaxDelay)
+	clock.Advance(testThrottlePolicy.LockoutDuration - testThrottlePolicy.MaxDelay)
+	if err := throttle.Check(ctx, "user@example.com", "198.51.100.1"); err != nil {
+		t.Fatalf("Check after lockout: %v", err)
+	}
+}
+
+// IPアドレスごとのロックが、パスワードの再設定では解除されないことを確認
+func TestLoginThrottleIPLockout(t *testing.T) {
+	ctx := context.Background()
+	throttle, clock := newTestLoginThrottle(t, testThrottlePolicy)
+
+	// 複数のアカウントへの試行で、IPアドレスの失敗回数が上限に達する
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+	for i, email := range emails {
+		err := throttle.RecordFailure(ctx, email, "192.0.2.1")
+		if i < len(emails)-1 {
+			if err != nil {
+				t.Fatalf("failure %d: RecordFailure: %v", i+1, err)
+			}
+			continue
+		}
+		assertThrottleError(t, err, ErrIPLocked, testThrottlePolicy.LockoutDuration)
+	}
+
+	// 同じIPアドレスからは、失敗していないアカウントにもログインできない
+	clock.Advance(time.Minute)
+	assertThrottleError(t, throttle.Check(ctx, "new@example.com", "192.0.2.1"), ErrIPLocked, testThrottlePolicy.LockoutDuration-time.Minute)
+	// 別のIPアドレスからはログインできる
+	if err := throttle.Check(ctx, "a@example.com", "198.51.100.1"); err != nil {
+		t.Fatalf("Check other IP: %v", err)
+	}
+
+	// パスワードを再設定しても、IPアドレスのロックは解除されない
+	if err := throttle.Unlock(ctx, "a@example.com"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	assertThrottleError(t, throttle.Check(ctx, "a@example.com", "192.0.2.1"), ErrIPLocked, testThrottlePolicy.LockoutDuration-time.Minute)
+
+	// ロックの期間が過ぎると解除される
+	clock.Advance(testThrottlePolicy.LockoutDuration - time.Minute)
+	if err := throttle.Check(ctx, "a@example.com", "192.0.2.1"); err != nil {
+		t.Fatalf("Check after lockout: %v", err)
+	}
+}
+
+// メールアドレスとIPアドレスの両方がロックされた場合は、IPアドレスのロックを返すことを確認
+func TestLoginThrottleBothLocked(t *testing.T) {
+	ctx := context.Background()
+	policy := testThrottlePolicy
+	policy.MaxIPFailures = policy.MaxFailures
+	throttle, clock := newTestLoginThrottle(t, policy)
+
+	var err error
+	for i := 0; i < policy.MaxFailures; i++ {
+		clock.Advance(policy.MaxDelay)
+		err = throttle.RecordFailure(ctx, "user@example.com", "192.0.2.1")
+	}
+	assertThrottleError(t, err, ErrIPLocked, policy.LockoutDuration)
+	assertThrottleError(t, throttle.Check(ctx, "user@example.com", "192.0.2.1"), ErrIPLocked, policy.LockoutDuration)
+
+	// パスワードの再設定後は、別のIPアドレスからログインできる
+	if err := throttle.Unlock(ctx, "user@example.com"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	assertThrottleError(t, throttle.Check(ctx, "user@example.com", "192.0.2.1"), ErrIPLocked, policy.LockoutDuration)
+	if err := throttle.Check(ctx, "user@example.com", "198.51.100.1"); err != nil {
+		t.Fatalf("Check other IP after unlock: %v", err)
+	}
+}
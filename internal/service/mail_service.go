@@ -1,17 +1,153 @@
 // internal/service/mail_service.go
+// mail_serviceは、メール送信関連のサービスを提供します。
 
-// package logger implements package that extends logging capabilities
+// Package service provides application services.
 package service
 
-import "context"
+import (
+	"context"
+	"fmt"
+	netmail "net/mail"
+	"time"
 
-//  新しいメールサービスを作成
+	"github.com/223n-tech/SuiminNisshi-Go/internal/mail"
+)
+
+// 送信元の既定のメールアドレス
+const DefaultMailFrom = "no-reply@localhost"
+
+// メール送信サービス
+type EmailService struct {
+	s        *Service
+	mailer   mail.Mailer
+	renderer *mail.Renderer
+	from     string
+}
+
+// 新しいメールサービスを作成
 func NewEmailService(s *Service) *EmailService {
-    return &EmailService{s: s}
+	renderer, err := mail.NewRenderer()
+	if err != nil {
+		// テンプレートは埋め込みのため、読み込めないのはビルドの不備
+		panic(err)
+	}
+	return &EmailService{
+		s:        s,
+		renderer: renderer,
+		from:     DefaultMailFrom,
+	}
+}
+
+// メールの送信方法を設定
+// 設定しない場合はメールを送信せず、ログに件名と宛先のみを出力する
+func (s *EmailService) SetMailer(mailer mail.Mailer) {
+	s.mailer = mailer
+}
+
+// 送信元を設定
+func (s *EmailService) SetFrom(address, name string) {
+	if address == "" {
+		return
+	}
+	s.from = (&netmail.Address{Name: name, Address: address}).String()
 }
 
 // ウェルカムメールを送信
 func (s *EmailService) SendWelcomeEmail(ctx context.Context, email, name string) error {
-    // 実装
-    return nil
+	return s.send(ctx, email, mail.TemplateWelcome, map[string]interface{}{
+		"Name": name,
+	})
+}
+
+// パスワード再設定用のメールを送信
+func (s *EmailService) SendPasswordResetEmail(ctx context.Context, email, name, resetURL string, expiresIn time.Duration) error {
+	return s.send(ctx, email, mail.TemplatePasswordReset, map[string]interface{}{
+		"Name":      name,
+		"ResetURL":  resetURL,
+		"ExpiresIn": formatExpiresIn(expiresIn),
+	})
+}
+
+// 週間レポートのメールを送信（PDFなどの添付ファイルは任意）
+func (s *EmailService) SendWeeklyReportEmail(ctx context.Context, email, name string, report *WeeklyReport, reportURL string, attachments ...mail.Attachment) error {
+	return s.send(ctx, email, mail.TemplateWeeklyReport, weeklyReportMailData(name, report, reportURL), attachments...)
+}
+
+// 週間レポートのメールの内容を作成（ブラウザでのプレビュー用）
+func (s *EmailService) RenderWeeklyReportEmail(name string, report *WeeklyReport, reportURL string) (*mail.Content, error) {
+	return s.render(mail.TemplateWeeklyReport, weeklyReportMailData(name, report, reportURL))
+}
+
+// アカウント削除の完了メールを送信
+func (s *EmailService) SendAccountDeletionEmail(ctx context.Context, email, name string) error {
+	return s.send(ctx, email, mail.TemplateAccountDeletion, map[string]interface{}{
+		"Name": name,
+	})
+}
+
+// 就寝時刻のリマインダーのメールを送信
+func (s *EmailService) SendBedtimeReminderEmail(ctx context.Context, email, name string, bedtime time.Time, leadTime time.Duration) error {
+	return s.send(ctx, email, mail.TemplateBedtimeReminder, map[string]interface{}{
+		"Name":     name,
+		"Bedtime":  bedtime.Format("15:04"),
+		"LeadTime": leadTime,
+	})
+}
+
+// テンプレートからメールを作成して送信
+func (s *EmailService) send(ctx context.Context, to, template string, data map[string]interface{}, attachments ...mail.Attachment) error {
+	content, err := s.render(template, data)
+	if err != nil {
+		return err
+	}
+
+	if s.mailer == nil {
+		s.s.Logger().Info("メールの送信方法が未設定のため送信しません: to=%s, subject=%s", to, content.Subject)
+		return nil
+	}
+
+	msg := &mail.Message{
+		From:        s.from,
+		To:          []string{to},
+		Subject:     content.Subject,
+		Text:        content.Text,
+		HTML:        content.HTML,
+		Attachments: attachments,
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send mail %s: %w", template, err)
+	}
+
+	s.s.Logger().Debug("メールを送信: to=%s, subject=%s", to, content.Subject)
+	return nil
+}
+
+// テンプレートからメールの内容を作成
+func (s *EmailService) render(template string, data map[string]interface{}) (*mail.Content, error) {
+	data["BaseURL"] = s.s.BaseURL()
+
+	content, err := s.renderer.Render(template, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render mail template %s: %w", template, err)
+	}
+	return content, nil
+}
+
+// 週間レポートのメールのテンプレートに渡すデータ
+func weeklyReportMailData(name string, report *WeeklyReport, reportURL string) map[string]interface{} {
+	return map[string]interface{}{
+		"Name":      name,
+		"Report":    report,
+		"ReportURL": reportURL,
+	}
+}
+
+// 有効期間を表示用に整形（例: 1時間, 30分）
+func formatExpiresIn(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%d時間", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%d分", int(d.Round(time.Minute)/time.Minute))
+	}
 }
@@ -0,0 +1,41 @@
+// internal/service/notifier.go
+// notifierは、ユーザーへの通知の送信方法を提供します。
+// 既定はメールで、Webhookなどの送信方法は Notifier インターフェイスを実装して差し替えます。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// ユーザーへの通知の送信インターフェイス
+type Notifier interface {
+	// 就寝時刻のリマインダーを送信（bedtime はユーザーのタイムゾーンの就寝予定時刻、leadTime は就寝時刻までの時間）
+	NotifyBedtime(ctx context.Context, user *models.User, bedtime time.Time, leadTime time.Duration) error
+}
+
+// メールで通知を送信するNotifier
+type EmailNotifier struct {
+	s *Service
+}
+
+// 新しいEmailNotifierを作成
+func NewEmailNotifier(s *Service) *EmailNotifier {
+	return &EmailNotifier{s: s}
+}
+
+// 就寝時刻のリマインダーをメールで送信（メールでの通知を停止している場合は送信しない）
+func (n *EmailNotifier) NotifyBedtime(ctx context.Context, user *models.User, bedtime time.Time, leadTime time.Duration) error {
+	settings, err := n.s.User().GetNotificationSettings(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !settings.EmailEnabled {
+		return nil
+	}
+	return n.s.Email().SendBedtimeReminderEmail(ctx, user.Email, user.DisplayName, bedtime, leadTime)
+}
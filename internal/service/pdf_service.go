@@ -9,9 +9,17 @@ import (
 	"errors"
 	"time"
 
+	"github.com/223n-tech/SuiminNisshi-Go/internal/assets"
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 )
 
+var (
+	// ErrDiaryNotFound 睡眠日誌が見つかりません
+	ErrDiaryNotFound = errors.New("diary not found / 睡眠日誌が見つかりません")
+	// ErrDiaryAccessDenied 他のユーザーの睡眠日誌にはアクセスできません
+	ErrDiaryAccessDenied = errors.New("unauthorized access / 他のユーザーの睡眠日誌にはアクセスできません")
+)
+
 // PDF出力関連のサービス
 type PDFService struct {
 	s *Service
@@ -23,139 +31,134 @@ func NewPDFService(s *Service) *PDFService {
 }
 
 // 睡眠日誌のPDFを生成
-func (s *PDFService) GenerateSleepDiaryPDF(ctx context.Context, userID, diaryID int64) ([]byte, error) {
+// optsに期間が指定されている場合は、日誌の期間と重なる範囲のみを出力する
+func (s *PDFService) GenerateSleepDiaryPDF(ctx context.Context, userID, diaryID int64, opts *models.PDFExportOptions) ([]byte, error) {
+	opts = normalizePDFExportOptions(opts)
+
 	// 日誌の存在確認
-	diary, err := s.s.repo.SleepDiary().GetByID(ctx, diaryID)
+	diary, err := s.s.repoFor(ctx).SleepDiary().GetByID(ctx, diaryID)
 	if err != nil {
 		return nil, err
 	}
 	if diary == nil {
-		return nil, errors.New("diary not found")
+		return nil, ErrDiaryNotFound
 	}
 
 	// ユーザーの確認
 	if diary.UserID != userID {
-		return nil, errors.New("unauthorized access")
+		return nil, ErrDiaryAccessDenied
+	}
+
+	// 出力期間の決定
+	period := *diary
+	if !opts.StartDate.IsZero() && opts.StartDate.After(period.StartDate) {
+		period.StartDate = opts.StartDate
+	}
+	if !opts.EndDate.IsZero() && opts.EndDate.Before(period.EndDate) {
+		period.EndDate = opts.EndDate
+	}
+	if period.StartDate.After(period.EndDate) {
+		return nil, ErrInvalidTimeRange
 	}
 
 	// ユーザー情報の取得
-	user, err := s.s.repo.User().GetByID(ctx, userID)
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
 
 	// 睡眠記録の取得
-	records, err := s.s.repo.SleepRecord().GetByDiaryID(ctx, diaryID)
+	records, err := s.s.repoFor(ctx).SleepRecord().GetByDiaryID(ctx, diaryID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 睡眠状態の取得
-	states, err := s.s.repo.SleepState().GetAll(ctx)
+	// マスターデータの取得
+	statesMap, mealTypesMap, err := s.masterData(ctx)
 	if err != nil {
 		return nil, err
 	}
-	statesMap := make(map[int64]models.SleepState)
-	for _, state := range states {
-		statesMap[state.ID] = *state
-	}
 
-	// 食事種別の取得
-	mealTypes, err := s.s.repo.MealType().GetAll(ctx)
+	// ユーザーの睡眠設定を取得
+	pref, err := s.sleepPreference(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	mealTypesMap := make(map[int64]models.MealType)
-	for _, mealType := range mealTypes {
-		mealTypesMap[mealType.ID] = *mealType
-	}
 
-	// ユーザーの睡眠設定を取得
-	pref, err := s.s.repo.UserSleepPreference().GetByUserID(ctx, userID)
+	// 日別集計データの取得（合計睡眠時間の列に使用）
+	summaries, err := s.s.Summary().GetDailySummaries(ctx, userID, period.StartDate, period.EndDate)
 	if err != nil {
 		return nil, err
 	}
 
 	// PDF出力用データの作成
 	data := &models.PDFExportData{
-		User:        *user,
-		SleepDiary:  *diary,
-		Records:     records,
-		States:      statesMap,
-		MealTypes:   mealTypesMap,
-		Preferences: *pref,
-	}
-
-	// PDF出力用テンプレートの設定
-	template := models.PDFTemplate{
-		FontPath:   "internal/assets/fonts/ipaexg.ttf",
-		PageWidth:  595.28,
-		PageHeight: 841.89,
-		Margin:     20,
+		User:           *user,
+		SleepDiary:     period,
+		Records:        records,
+		States:         statesMap,
+		MealTypes:      mealTypesMap,
+		Preferences:    *pref,
+		DailySummaries: summaries,
+		Options:        *opts,
 	}
 
 	// PDFの生成
-	return data.GeneratePDF(template)
+	return data.GeneratePDF(pdfTemplate())
 }
 
 // 統計情報のPDFを生成
-func (s *PDFService) GenerateStatisticsPDF(ctx context.Context, userID int64, startDate, endDate time.Time) ([]byte, error) {
+// optsの期間内にあるすべての日誌の記録を1つのグリッドにまとめ、期間の統計を併記する
+func (s *PDFService) GenerateStatisticsPDF(ctx context.Context, userID int64, opts *models.PDFExportOptions) ([]byte, error) {
+	opts = normalizePDFExportOptions(opts)
+	startDate, endDate := opts.StartDate, opts.EndDate
+
 	// 期間の妥当性チェック
-	if startDate.After(endDate) {
-		return nil, errors.New("invalid date range")
+	if startDate.IsZero() || endDate.IsZero() || startDate.After(endDate) {
+		return nil, ErrInvalidTimeRange
 	}
 
-	// 日誌の取得
-	diaries, err := s.s.repo.SleepDiary().GetByDateRange(ctx, userID,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"))
+	// ユーザー情報の取得
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
 
-	// ユーザー情報の取得
-	user, err := s.s.repo.User().GetByID(ctx, userID)
+	// 睡眠記録の取得（期間の前後1日を含め、日をまたぐ睡眠を欠けさせない）
+	records, err := s.s.Record().GetUserRecordsByDateRange(ctx, userID, startDate.AddDate(0, 0, -1), endDate.AddDate(0, 0, 1))
 	if err != nil {
 		return nil, err
 	}
 
-	// 睡眠記録の取得とデータの集計
-	var allRecords []*models.SleepRecord
-	for _, diary := range diaries {
-		records, err := s.s.repo.SleepRecord().GetByDiaryID(ctx, diary.ID)
-		if err != nil {
-			return nil, err
-		}
-		allRecords = append(allRecords, records...)
-	}
-
 	// マスターデータの取得
-	states, err := s.s.repo.SleepState().GetAll(ctx)
+	statesMap, mealTypesMap, err := s.masterData(ctx)
 	if err != nil {
 		return nil, err
 	}
-	statesMap := make(map[int64]models.SleepState)
-	for _, state := range states {
-		statesMap[state.ID] = *state
-	}
 
-	mealTypes, err := s.s.repo.MealType().GetAll(ctx)
+	// ユーザーの睡眠設定を取得
+	pref, err := s.sleepPreference(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	mealTypesMap := make(map[int64]models.MealType)
-	for _, mealType := range mealTypes {
-		mealTypesMap[mealType.ID] = *mealType
-	}
 
-	// ユーザーの睡眠設定を取得
-	pref, err := s.s.repo.UserSleepPreference().GetByUserID(ctx, userID)
+	// 統計データの計算
+	stats, err := s.calculateStatistics(ctx, userID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	// 統計データの計算
-	// stats := s.calculateStatistics(allRecords, startDate, endDate)
+	// 日別集計データの取得（合計睡眠時間の列に使用）
+	summaries, err := s.s.Summary().GetDailySummaries(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
 
 	// PDF出力用データの作成
 	data := &models.PDFExportData{
@@ -164,38 +167,92 @@ func (s *PDFService) GenerateStatisticsPDF(ctx context.Context, userID int64, st
 			StartDate: startDate,
 			EndDate:   endDate,
 		},
-		Records:     allRecords,
-		States:      statesMap,
-		MealTypes:   mealTypesMap,
-		Preferences: *pref,
+		Records:        records,
+		States:         statesMap,
+		MealTypes:      mealTypesMap,
+		Preferences:    *pref,
+		Statistics:     stats,
+		DailySummaries: summaries,
+		Options:        *opts,
 	}
 
-	// PDF出力用テンプレートの設定
-	template := models.PDFTemplate{
-		FontPath:   "internal/assets/fonts/ipaexg.ttf",
-		PageWidth:  595.28,
-		PageHeight: 841.89,
-		Margin:     20,
+	// PDFの生成
+	return data.GeneratePDF(pdfTemplate())
+}
+
+// 期間の睡眠記録から統計データを計算
+func (s *PDFService) calculateStatistics(ctx context.Context, userID int64, startDate, endDate time.Time) (*models.PDFStatistics, error) {
+	summary, err := s.s.Record().GetStatistics(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
 	}
 
-	// PDFの生成
-	return data.GeneratePDF(template)
+	return &models.PDFStatistics{
+		AverageDuration: summary.AverageTotalSleepHours,
+		AverageBedTime:  summary.AverageBedTime,
+		AverageWakeTime: summary.AverageWakeTime,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		TotalDays:       summary.TotalDays,
+	}, nil
 }
 
-// 睡眠記録から統計データを計算
-func (s *PDFService) calculateStatistics(_ []*models.SleepRecord, startDate, endDate time.Time) *models.PDFStatistics {
-	stats := &models.PDFStatistics{
-		StartDate: startDate,
-		EndDate:   endDate,
-		TotalDays: int(endDate.Sub(startDate).Hours() / 24),
+// 睡眠状態と食事種別のマスターデータを取得
+func (s *PDFService) masterData(ctx context.Context) (map[int64]models.SleepState, map[int64]models.MealType, error) {
+	states, err := s.s.repoFor(ctx).SleepState().GetAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	statesMap := make(map[int64]models.SleepState)
+	for _, state := range states {
+		statesMap[state.ID] = *state
 	}
 
-	// TODO: 睡眠記録から各種統計値を計算
-	// - 平均睡眠時間
-	// - 平均就寝時刻
-	// - 平均起床時刻
-	// - 平均睡眠スコア
-	// など
+	mealTypes, err := s.s.repoFor(ctx).MealType().GetAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	mealTypesMap := make(map[int64]models.MealType)
+	for _, mealType := range mealTypes {
+		mealTypesMap[mealType.ID] = *mealType
+	}
+
+	return statesMap, mealTypesMap, nil
+}
+
+// ユーザーの睡眠設定を取得（未設定の場合はデフォルト値）
+func (s *PDFService) sleepPreference(ctx context.Context, userID int64) (*models.UserSleepPreference, error) {
+	pref, err := s.s.repoFor(ctx).UserSleepPreference().GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pref == nil {
+		pref = s.s.repoFor(ctx).UserSleepPreference().GetDefaultPreference(userID)
+	}
+	return pref, nil
+}
 
-	return stats
+// PDF出力設定の既定値を補完
+func normalizePDFExportOptions(opts *models.PDFExportOptions) *models.PDFExportOptions {
+	normalized := models.PDFExportOptions{}
+	if opts != nil {
+		normalized = *opts
+	}
+	if normalized.Language != "en" {
+		normalized.Language = "ja"
+	}
+	if normalized.Quality == "" {
+		normalized.Quality = "high"
+	}
+	return &normalized
+}
+
+// PDF出力用テンプレートの設定（48枠のグリッドを収めるためA4横向き）
+func pdfTemplate() models.PDFTemplate {
+	return models.PDFTemplate{
+		FontData:   assets.GothicFont,
+		PageWidth:  841.89,
+		PageHeight: 595.28,
+		Margin:     20,
+	}
 }
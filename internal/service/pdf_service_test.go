@@ -0,0 +1,88 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+func TestGenerateSleepDiaryPDF(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	user := createTestUser(t, s, "pdf@example.com")
+	other := createTestUser(t, s, "other@example.com")
+	diary := createTestDiary(t, s, user.ID, "2024-04-01", "2024-04-28")
+	saveTestNight(t, s, user.ID, "2024-04-01", "23:00", "07:00",
+		models.NightMeal{MealTypeCode: models.MealCodeDinner, At: models.NightEntryTime(testDate(t, "2024-04-01"), testClock(t, "19:00"))},
+		models.NightMeal{MealTypeCode: models.MealCodeBreakfast, At: models.NightEntryTime(testDate(t, "2024-04-01"), testClock(t, "07:30"))},
+	)
+	saveTestNight(t, s, user.ID, "2024-04-02", "00:30", "06:30")
+
+	tests := []struct {
+		name    string
+		userID  int64
+		diaryID int64
+		opts    *models.PDFExportOptions
+		wantErr error
+	}{
+		{name: "日本語", userID: user.ID, diaryID: diary.ID},
+		{name: "英語・メモあり", userID: user.ID, diaryID: diary.ID, opts: &models.PDFExportOptions{Language: "en", IncludeNote: true}},
+		{name: "期間を指定", userID: user.ID, diaryID: diary.ID, opts: &models.PDFExportOptions{StartDate: testDate(t, "2024-04-02"), EndDate: testDate(t, "2024-04-03")}},
+		{name: "他のユーザーの睡眠日誌", userID: other.ID, diaryID: diary.ID, wantErr: ErrDiaryAccessDenied},
+		{name: "存在しない睡眠日誌", userID: user.ID, diaryID: diary.ID + 100, wantErr: ErrDiaryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := s.PDF().GenerateSleepDiaryPDF(ctx, tt.userID, tt.diaryID, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateSleepDiaryPDF: %v", err)
+			}
+			assertPDF(t, b)
+		})
+	}
+}
+
+func TestGenerateStatisticsPDF(t *testing.T) {
+	s := newTestService(t)
+	user := createTestUser(t, s, "pdf@example.com")
+	createTestDiary(t, s, user.ID, "2024-04-01", "2024-04-28")
+	saveTestNight(t, s, user.ID, "2024-04-01", "23:00", "07:00")
+
+	b, err := s.PDF().GenerateStatisticsPDF(context.Background(), user.ID, &models.PDFExportOptions{
+		StartDate: testDate(t, "2024-04-01"),
+		EndDate:   testDate(t, "2024-04-07"),
+	})
+	if err != nil {
+		t.Fatalf("GenerateStatisticsPDF: %v", err)
+	}
+	assertPDF(t, b)
+
+	_, err = s.PDF().GenerateStatisticsPDF(context.Background(), user.ID, &models.PDFExportOptions{
+		StartDate: testDate(t, "2024-04-07"),
+		EndDate:   testDate(t, "2024-04-01"),
+	})
+	if !errors.Is(err, ErrInvalidTimeRange) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidTimeRange)
+	}
+}
+
+// PDFのデータであることを確認
+func assertPDF(t *testing.T, b []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(b, []byte("%PDF-")) {
+		t.Fatalf("output is not a PDF (%d bytes)", len(b))
+	}
+	if !bytes.Contains(b, []byte("%%EOF")) {
+		t.Fatalf("PDF is truncated (%d bytes)", len(b))
+	}
+}
@@ -0,0 +1,192 @@
+// internal/service/reminder_service.go
+// reminder_serviceは、就寝時刻のリマインダーを送信するサービスを提供します。
+// ユーザーの睡眠設定の就寝時刻を、ユーザーのタイムゾーンで解釈して送信します。
+// 送信済みの記録はデータベースに保存するため、再起動しても同じ夜に二重に送信しません。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+const (
+	// 既定の、就寝時刻の何分前にリマインダーを送信するか
+	DefaultReminderLeadTime = 30 * time.Minute
+	// 既定の、リマインダーの送信チェックの間隔
+	DefaultReminderInterval = time.Minute
+	// 通知の送信記録の保存期間
+	notificationDeliveryRetention = 30 * 24 * time.Hour
+)
+
+// 就寝時刻のリマインダーのサービス
+type ReminderService struct {
+	s        *Service
+	notifier Notifier
+	leadTime time.Duration
+	now      func() time.Time
+}
+
+// 新しいReminderServiceを作成
+func NewReminderService(s *Service) *ReminderService {
+	return &ReminderService{
+		s:        s,
+		notifier: NewEmailNotifier(s),
+		leadTime: DefaultReminderLeadTime,
+		now:      time.Now,
+	}
+}
+
+// 通知の送信方法を差し替え
+func (s *ReminderService) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// 就寝時刻の何分前に送信するかを設定（0以下の場合は変更しない）
+func (s *ReminderService) SetLeadTime(leadTime time.Duration) {
+	if leadTime > 0 {
+		s.leadTime = leadTime
+	}
+}
+
+// 現在時刻の取得方法を差し替え（テスト用）
+func (s *ReminderService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// 送信時刻になったリマインダーを送信し、送信した件数を返す
+// ユーザーごとのエラーはログに記録して、他のユーザーの処理を続ける
+func (s *ReminderService) Tick(ctx context.Context) (int, error) {
+	prefs, err := s.s.repoFor(ctx).UserSleepPreference().GetReminderEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	sent := 0
+	for _, pref := range prefs {
+		ok, err := s.remind(ctx, pref, now)
+		if err != nil {
+			s.s.Logger().Error("就寝時刻のリマインダーの送信に失敗: error=%v, user_id=%d", err, pref.UserID)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// 指定した間隔でリマインダーの送信をチェック（ctxが終了するまで続ける）
+func (s *ReminderService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReminderInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Tick(ctx); err != nil {
+			s.s.Logger().Error("就寝時刻のリマインダーのチェックに失敗: error=%v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 保存期間を過ぎた通知の送信記録を削除
+func (s *ReminderService) PurgeDeliveries(ctx context.Context) (int64, error) {
+	return s.s.repoFor(ctx).NotificationDelivery().DeleteBefore(ctx, s.now().Add(-notificationDeliveryRetention))
+}
+
+// ユーザーのリマインダーが送信時刻になっていれば送信
+func (s *ReminderService) remind(ctx context.Context, pref *models.UserSleepPreference, now time.Time) (bool, error) {
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, pref.UserID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, nil
+	}
+
+	settings, err := s.s.User().GetNotificationSettings(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	if !settings.BedtimeReminder {
+		return false, nil
+	}
+
+	bedtime, ok := s.dueBedtime(pref, s.leadTimeFor(pref, settings), now.In(user.Location()))
+	if !ok {
+		return false, nil
+	}
+
+	// 就寝予定日で送信済みかを判定（日付をまたぐ就寝時刻でも1晩に1回）
+	deliveries := s.s.repoFor(ctx).NotificationDelivery()
+	periodKey := bedtime.Format("2006-01-02")
+	exists, err := deliveries.Exists(ctx, user.ID, models.NotificationKindBedtimeReminder, periodKey)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	if err := s.notifier.NotifyBedtime(ctx, user, bedtime, bedtime.Sub(now)); err != nil {
+		return false, err
+	}
+
+	delivery := &models.NotificationDelivery{
+		UserID:    user.ID,
+		Kind:      models.NotificationKindBedtimeReminder,
+		PeriodKey: periodKey,
+	}
+	if err := deliveries.Create(ctx, delivery); err != nil {
+		return false, err
+	}
+
+	s.s.Logger().Info("就寝時刻のリマインダーを送信: user_id=%d, bedtime=%s", user.ID, bedtime.Format(time.RFC3339))
+	return true, nil
+}
+
+// ユーザーのリマインダーを就寝時刻のどれだけ前に送信するかを取得
+// 通知設定で送信時刻が指定されている場合は、その時刻から就寝時刻までの時間とする
+func (s *ReminderService) leadTimeFor(pref *models.UserSleepPreference, settings *models.NotificationSettings) time.Duration {
+	if settings.ReminderTime == "" {
+		return s.leadTime
+	}
+	reminderAt, err := time.Parse(models.ReminderTimeFormat, settings.ReminderTime)
+	if err != nil {
+		return s.leadTime
+	}
+
+	bedtime := pref.PreferredBedtime.Hour()*60 + pref.PreferredBedtime.Minute()
+	reminder := reminderAt.Hour()*60 + reminderAt.Minute()
+	minutes := (bedtime - reminder + 24*60) % (24 * 60)
+	if minutes == 0 {
+		// 就寝時刻と同じ時刻の場合は、既定の時間前に送信する
+		return s.leadTime
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// 送信時刻（就寝時刻の leadTime 前から就寝時刻まで）になっている就寝時刻を取得
+// now はユーザーのタイムゾーンの現在時刻で、今日と翌日の就寝時刻を対象にする（深夜0時をまたぐ場合のため）
+func (s *ReminderService) dueBedtime(pref *models.UserSleepPreference, leadTime time.Duration, now time.Time) (time.Time, bool) {
+	hour, minute := pref.PreferredBedtime.Hour(), pref.PreferredBedtime.Minute()
+	for _, day := range []int{0, 1} {
+		bedtime := time.Date(now.Year(), now.Month(), now.Day()+day, hour, minute, 0, 0, now.Location())
+		if !now.Before(bedtime.Add(-leadTime)) && now.Before(bedtime) {
+			return bedtime, true
+		}
+	}
+	return time.Time{}, false
+}
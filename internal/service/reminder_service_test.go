@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// 送信したリマインダーを記録するNotifier
+type recordingNotifier struct {
+	sent []sentReminder
+}
+
+// 送信したリマインダー
+type sentReminder struct {
+	userID   int64
+	bedtime  time.Time
+	leadTime time.Duration
+}
+
+// 送信したリマインダーを記録
+func (n *recordingNotifier) NotifyBedtime(_ context.Context, user *models.User, bedtime time.Time, leadTime time.Duration) error {
+	n.sent = append(n.sent, sentReminder{userID: user.ID, bedtime: bedtime, leadTime: leadTime})
+	return nil
+}
+
+// 時計とNotifierを差し替えたリマインダーのサービスを作成
+func newTestReminder(t *testing.T) (*Service, *recordingNotifier, *fakeClock) {
+	t.Helper()
+	s := newTestService(t)
+	notifier := &recordingNotifier{}
+	clock := &fakeClock{}
+	s.Reminder().SetNotifier(notifier)
+	s.Reminder().SetClock(clock.Now)
+	return s, notifier, clock
+}
+
+// タイムゾーンと就寝時刻（HH:MM）を設定した、リマインダーを有効にしたユーザーを作成
+func createReminderUser(t *testing.T, s *Service, email, timeZone, bedtime string) *models.User {
+	t.Helper()
+	ctx := context.Background()
+	user := &models.User{Email: email, DisplayName: "テスト", PasswordHash: "hash", TimeZone: timeZone}
+	if err := s.repo.User().Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	pref := &models.UserSleepPreference{
+		UserID:              user.ID,
+		PreferredBedtime:    testClock(t, bedtime),
+		PreferredWakeupTime: testClock(t, "07:00"),
+		SleepGoalHours:      8,
+		IsReminderEnabled:   true,
+	}
+	if err := s.repo.UserSleepPreference().Create(ctx, pref); err != nil {
+		t.Fatalf("failed to create sleep preference: %v", err)
+	}
+	return user
+}
+
+// タイムゾーンの日時（YYYY-MM-DD HH:MM）
+func testTime(t *testing.T, s, timeZone string) time.Time {
+	t.Helper()
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+// 送信時刻の範囲（就寝時刻の前から就寝時刻まで）の判定を、深夜0時をまたぐ場合を含めて確認
+func TestReminderDueWindow(t *testing.T) {
+	const tz = "Asia/Tokyo"
+
+	tests := []struct {
+		name         string
+		bedtime      string
+		reminderTime string // 通知設定の送信時刻（空の場合は就寝時刻の30分前）
+		now          string
+		wantBedtime  string // 空の場合は送信しない
+		wantLeadTime time.Duration
+	}{
+		{name: "before window", bedtime: "23:00", now: "2024-04-01 22:29"},
+		{name: "window start", bedtime: "23:00", now: "2024-04-01 22:30", wantBedtime: "2024-04-01 23:00", wantLeadTime: 30 * time.Minute},
+		{name: "window end", bedtime: "23:00", now: "2024-04-01 22:59", wantBedtime: "2024-04-01 23:00", wantLeadTime: time.Minute},
+		{name: "at bedtime", bedtime: "23:00", now: "2024-04-01 23:00"},
+		{name: "after midnight bedtime, window before midnight", bedtime: "00:15", now: "2024-04-01 23:45", wantBedtime: "2024-04-02 00:15", wantLeadTime: 30 * time.Minute},
+		{name: "after midnight bedtime, window after midnight", bedtime: "00:15", now: "2024-04-02 00:05", wantBedtime: "2024-04-02 00:15", wantLeadTime: 10 * time.Minute},
+		{name: "after midnight bedtime, before window", bedtime: "00:15", now: "2024-04-01 23:44"},
+		{name: "after midnight bedtime, at bedtime", bedtime: "00:15", now: "2024-04-02 00:15"},
+		{name: "reminder time before midnight", bedtime: "00:30", reminderTime: "23:00", now: "2024-04-01 23:00", wantBedtime: "2024-04-02 00:30", wantLeadTime: 90 * time.Minute},
+		{name: "reminder time, before window", bedtime: "00:30", reminderTime: "23:00", now: "2024-04-01 22:59"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, notifier, clock := newTestReminder(t)
+			user := createReminderUser(t, s, "user@example.com", tz, tt.bedtime)
+			if tt.reminderTime != "" {
+				settings := models.DefaultNotificationSettings(user.ID)
+				settings.ReminderTime = tt.reminderTime
+				if err := s.repo.NotificationSettings().Create(context.Background(), settings); err != nil {
+					t.Fatalf("failed to create notification settings: %v", err)
+				}
+			}
+			clock.now = testTime(t, tt.now, tz)
+
+			sent, err := s.Reminder().Tick(context.Background())
+			if err != nil {
+				t.Fatalf("Tick: %v", err)
+			}
+			if tt.wantBedtime == "" {
+				if sent != 0 || len(notifier.sent) != 0 {
+					t.Fatalf("sent %d reminders %+v, want none", sent, notifier.sent)
+				}
+				return
+			}
+			if sent != 1 || len(notifier.sent) != 1 {
+				t.Fatalf("sent %d reminders %+v, want 1", sent, notifier.sent)
+			}
+			got := notifier.sent[0]
+			if want := testTime(t, tt.wantBedtime, tz); !got.bedtime.Equal(want) {
+				t.Errorf("bedtime = %s, want %s", got.bedtime, want)
+			}
+			if got.leadTime != tt.wantLeadTime {
+				t.Errorf("leadTime = %s, want %s", got.leadTime, tt.wantLeadTime)
+			}
+		})
+	}
+}
+
+// 就寝時刻をユーザーごとのタイムゾーンで判定することを確認
+func TestReminderTimeZones(t *testing.T) {
+	ctx := context.Background()
+	s, notifier, clock := newTestReminder(t)
+	tokyo := createReminderUser(t, s, "tokyo@example.com", "Asia/Tokyo", "23:00")
+	newYork := createReminderUser(t, s, "newyork@example.com", "America/New_York", "23:00")
+	utc := createReminderUser(t, s, "utc@example.com", "UTC", "23:00")
+
+	tests := []struct {
+		now      time.Time
+		wantUser *models.User
+		wantBed  time.Time
+	}{
+		// 東京の22:40（ニューヨークは09:40、UTCは13:40）
+		{testTime(t, "2024-04-01 13:40", "UTC"), tokyo, testTime(t, "2024-04-01 23:00", "Asia/Tokyo")},
+		// UTCの22:40（東京は翌日07:40、ニューヨークは18:40）
+		{testTime(t, "2024-04-01 22:40", "UTC"), utc, testTime(t, "2024-04-01 23:00", "UTC")},
+		// ニューヨークの22:40（夏時間、UTCは翌日02:40）
+		{testTime(t, "2024-04-01 22:40", "America/New_York"), newYork, testTime(t, "2024-04-01 23:00", "America/New_York")},
+	}
+
+	for _, tt := range tests {
+		notifier.sent = nil
+		clock.now = tt.now
+		sent, err := s.Reminder().Tick(ctx)
+		if err != nil {
+			t.Fatalf("%s: Tick: %v", tt.now, err)
+		}
+		if sent != 1 || len(notifier.sent) != 1 {
+			t.Fatalf("%s: sent %d reminders %+v, want 1", tt.now, sent, notifier.sent)
+		}
+		got := notifier.sent[0]
+		if got.userID != tt.wantUser.ID {
+			t.Errorf("%s: sent to user %d, want %s (%d)", tt.now, got.userID, tt.wantUser.TimeZone, tt.wantUser.ID)
+		}
+		if !got.bedtime.Equal(tt.wantBed) || got.bedtime.Location().String() != tt.wantUser.TimeZone {
+			t.Errorf("%s: bedtime = %s, want %s", tt.now, got.bedtime, tt.wantBed)
+		}
+		if got.leadTime != 20*time.Minute {
+			t.Errorf("%s: leadTime = %s, want 20m", tt.now, got.leadTime)
+		}
+	}
+}
+
+// 同じ夜のリマインダーは、送信記録により1回だけ送信することを確認
+func TestReminderDedupe(t *testing.T) {
+	const tz = "Asia/Tokyo"
+	ctx := context.Background()
+	s, notifier, clock := newTestReminder(t)
+	user := createReminderUser(t, s, "user@example.com", tz, "00:15")
+
+	tick := func(now string) int {
+		t.Helper()
+		clock.now = testTime(t, now, tz)
+		sent, err := s.Reminder().Tick(ctx)
+		if err != nil {
+			t.Fatalf("%s: Tick: %v", now, err)
+		}
+		return sent
+	}
+
+	if sent := tick("2024-04-01 23:45"); sent != 1 {
+		t.Fatalf("first run: sent %d, want 1", sent)
+	}
+	// 同じ送信時刻の範囲で再度実行しても送信しない（深夜0時をまたいでも同じ夜）
+	for _, now := range []string{"2024-04-01 23:46", "2024-04-01 23:59", "2024-04-02 00:10"} {
+		if sent := tick(now); sent != 0 {
+			t.Fatalf("%s: sent %d, want 0", now, sent)
+		}
+	}
+	exists, err := s.repo.NotificationDelivery().Exists(ctx, user.ID, models.NotificationKindBedtimeReminder, "2024-04-02")
+	if err != nil || !exists {
+		t.Fatalf("delivery for 2024-04-02: exists = %v, err = %v", exists, err)
+	}
+
+	// 再起動してサービスを作り直しても、送信記録があるため送信しない
+	restarted := NewReminderService(s)
+	restarted.SetNotifier(notifier)
+	restarted.SetClock(clock.Now)
+	clock.now = testTime(t, "2024-04-02 00:12", tz)
+	if sent, err := restarted.Tick(ctx); err != nil || sent != 0 {
+		t.Fatalf("after restart: sent %d, err %v, want 0", sent, err)
+	}
+
+	// 翌日の夜は送信する
+	if sent := tick("2024-04-02 23:50"); sent != 1 {
+		t.Fatalf("next night: sent %d, want 1", sent)
+	}
+	if len(notifier.sent) != 2 {
+		t.Fatalf("sent %d reminders in total, want 2", len(notifier.sent))
+	}
+}
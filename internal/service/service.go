@@ -7,6 +7,7 @@ package service
 import (
 	"context"
 	"log"
+	"strings"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
 )
@@ -18,13 +19,19 @@ type Service struct {
     user   *UserService
     diary  *SleepDiaryService
     record *SleepRecordService
+    episode *SleepEpisodeService
     pdf    *PDFService
     email  *EmailService
-}
-
-// メール送信サービス
-type EmailService struct {
-    s *Service
+    session *SessionService
+    loginThrottle *LoginThrottleService
+    reminder *ReminderService
+    weeklyReport *WeeklyReportService
+    accessToken *AccessTokenService
+    summary *SummaryService
+    importer *ImportService
+    backup *BackupService
+    export *ExportService
+    baseURL string
 }
 
 // 新しいサービスインスタンスを作成
@@ -35,17 +42,82 @@ func NewService(repo repository.Repository, level LogLevel, logger *log.Logger)
     s.user = NewUserService(s)
     s.diary = NewSleepDiaryService(s)
     s.record = NewSleepRecordService(s)
+    s.episode = NewSleepEpisodeService(s)
     s.pdf = NewPDFService(s)
     s.email = NewEmailService(s)
+    s.session = NewSessionService(s)
+    s.loginThrottle = NewLoginThrottleService(s)
+    s.reminder = NewReminderService(s)
+    s.weeklyReport = NewWeeklyReportService(s)
+    s.accessToken = NewAccessTokenService(s)
+    s.summary = NewSummaryService(s)
+    s.importer = NewImportService(s)
+    s.backup = NewBackupService(s)
+    s.export = NewExportService(s)
     s.logger = NewLoggerService(level, logger)
     return s
 }
 
+// メールなどに記載するリンクのベースURLを設定
+func (s *Service) SetBaseURL(baseURL string) {
+    s.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// メールなどに記載するリンクのベースURLを取得
+func (s *Service) BaseURL() string {
+    return s.baseURL
+}
+
 // メール関連のサービスを取得
 func (s *Service) Email() *EmailService {
     return s.email
 }
 
+// セッション関連のサービスを取得
+func (s *Service) Session() *SessionService {
+    return s.session
+}
+
+// ログイン試行の制限関連のサービスを取得
+func (s *Service) LoginThrottle() *LoginThrottleService {
+    return s.loginThrottle
+}
+
+// 就寝時刻のリマインダー関連のサービスを取得
+func (s *Service) Reminder() *ReminderService {
+    return s.reminder
+}
+
+// 週間レポート関連のサービスを取得
+func (s *Service) WeeklyReport() *WeeklyReportService {
+    return s.weeklyReport
+}
+
+// パーソナルアクセストークン関連のサービスを取得
+func (s *Service) AccessToken() *AccessTokenService {
+    return s.accessToken
+}
+
+// 日別集計・期間統計（キャッシュ）関連のサービスを取得
+func (s *Service) Summary() *SummaryService {
+    return s.summary
+}
+
+// CSV取り込み関連のサービスを取得
+func (s *Service) Import() *ImportService {
+    return s.importer
+}
+
+// バックアップ・復元関連のサービスを取得
+func (s *Service) Backup() *BackupService {
+    return s.backup
+}
+
+// CSV出力関連のサービスを取得
+func (s *Service) Export() *ExportService {
+    return s.export
+}
+
 // ログ関連のサービスを取得
 func (s *Service) Logger() *LoggerService {
     return s.logger
@@ -66,14 +138,69 @@ func (s *Service) Record() *SleepRecordService {
 	return s.record
 }
 
+// 睡眠エピソード関連のサービスを取得
+func (s *Service) Episode() *SleepEpisodeService {
+	return s.episode
+}
+
 // PDF出力関連のサービスを取得
 func (s *Service) PDF() *PDFService {
 	return s.pdf
 }
 
+// トランザクション中のリポジトリをコンテキストに格納するキー
+type txRepositoryKey struct{}
+
+// トランザクションのコミット後に実行する処理をコンテキストに格納するキー
+type txHooksKey struct{}
+
+// トランザクションのコミット後に実行する処理（入れ子のトランザクションごとに持つ）
+type txHooks struct {
+	fns []func()
+}
+
 // トランザクションを実行
+// fnに渡すコンテキストにはトランザクション用のリポジトリが格納され、
+// fnの中で呼び出したサービスの処理はすべて同じトランザクションで実行される
+// すでにトランザクション中の場合は、入れ子のトランザクション（セーブポイント）として実行する
+// afterCommit で登録した処理は、最も外側のトランザクションをコミットした後に実行する
+// 入れ子のトランザクションで登録した処理は、そのトランザクションが成功した場合のみ外側に引き継ぐ
 func (s *Service) Transaction(ctx context.Context, fn func(context.Context) error) error {
-	return s.repo.Transaction(ctx, func(_ repository.Repository) error {
-		return fn(ctx)
+	parent, nested := ctx.Value(txHooksKey{}).(*txHooks)
+	hooks := &txHooks{}
+	ctx = context.WithValue(ctx, txHooksKey{}, hooks)
+
+	err := s.repoFor(ctx).Transaction(ctx, func(tx repository.Repository) error {
+		return fn(context.WithValue(ctx, txRepositoryKey{}, tx))
 	})
+	if err != nil {
+		return err
+	}
+	if nested {
+		parent.fns = append(parent.fns, hooks.fns...)
+		return nil
+	}
+
+	for _, hook := range hooks.fns {
+		hook()
+	}
+	return nil
+}
+
+// トランザクションのコミット後に処理を実行（トランザクション中でなければすぐに実行）
+// トランザクションがロールバックされた場合は実行しない
+func (s *Service) afterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(txHooksKey{}).(*txHooks); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn()
+}
+
+// コンテキストに応じたリポジトリを取得（トランザクション中の場合はトランザクション用のリポジトリ）
+func (s *Service) repoFor(ctx context.Context) repository.Repository {
+	if tx, ok := ctx.Value(txRepositoryKey{}).(repository.Repository); ok {
+		return tx
+	}
+	return s.repo
 }
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository/memory"
+)
+
+// インメモリのリポジトリを使うサービスを作成
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	return NewService(memory.NewMemoryRepository(), ErrorLevel, log.New(io.Discard, "", 0))
+}
+
+// ユーザーを作成
+func createTestUser(t *testing.T, s *Service, email string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email, DisplayName: "テスト", PasswordHash: "hash", TimeZone: models.DefaultTimeZone}
+	if err := s.repo.User().Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+// 睡眠日誌を作成（日付は YYYY-MM-DD）
+func createTestDiary(t *testing.T, s *Service, userID int64, start, end string) *models.SleepDiary {
+	t.Helper()
+	diary, err := s.Diary().CreateDiary(context.Background(), userID, testDate(t, start), testDate(t, end), "テスト", "")
+	if err != nil {
+		t.Fatalf("failed to create diary: %v", err)
+	}
+	return diary
+}
+
+// 一晩分の睡眠記録を作成（日付は YYYY-MM-DD、時刻は HH:MM）
+func saveTestNight(t *testing.T, s *Service, userID int64, date, bedTime, finalWake string, meals ...models.NightMeal) []*models.SleepRecord {
+	t.Helper()
+	day := testDate(t, date)
+	entry := &models.NightEntry{
+		Date:      day,
+		BedTime:   models.NightEntryTime(day, testClock(t, bedTime)),
+		LightsOut: models.NightEntryTime(day, testClock(t, bedTime)),
+		FinalWake: models.NightEntryTime(day, testClock(t, finalWake)),
+		Meals:     meals,
+	}
+	records, err := s.Record().ReplaceNightRecords(context.Background(), userID, entry)
+	if err != nil {
+		t.Fatalf("failed to save night %s: %v", date, err)
+	}
+	return records
+}
+
+// 日付（YYYY-MM-DD）をUTCの日時にする
+func testDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// 時刻（HH:MM）を時間枠の形式の日時にする
+func testClock(t *testing.T, s string) time.Time {
+	t.Helper()
+	c, err := time.Parse("15:04", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return time.Date(0, 1, 1, c.Hour(), c.Minute(), 0, 0, time.UTC)
+}
+
+// テスト用の時計
+type fakeClock struct {
+	now time.Time
+}
+
+// 現在時刻を取得
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// 時計を進める
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestTransactionAfterCommit(t *testing.T) {
+	errRollback := errors.New("rollback")
+
+	tests := []struct {
+		name string
+		fn   func(s *Service, ctx context.Context, record func(string)) error
+		want []string
+	}{
+		{
+			name: "トランザクション外ではすぐに実行する",
+			fn: func(s *Service, ctx context.Context, record func(string)) error {
+				s.afterCommit(ctx, func() { record("now") })
+				return nil
+			},
+			want: []string{"now"},
+		},
+		{
+			name: "コミット後に実行する",
+			fn: func(s *Service, ctx context.Context, record func(string)) error {
+				return s.Transaction(ctx, func(ctx context.Context) error {
+					s.afterCommit(ctx, func() { record("outer") })
+					return nil
+				})
+			},
+			want: []string{"outer"},
+		},
+		{
+			name: "ロールバックした場合は実行しない",
+			fn: func(s *Service, ctx context.Context, record func(string)) error {
+				return s.Transaction(ctx, func(ctx context.Context) error {
+					s.afterCommit(ctx, func() { record("outer") })
+					return errRollback
+				})
+			},
+		},
+		{
+			name: "成功した入れ子の処理は外側のコミット後に実行する",
+			fn: func(s *Service, ctx context.Context, record func(string)) error {
+				return s.Transaction(ctx, func(ctx context.Context) error {
+					s.afterCommit(ctx, func() { record("outer") })
+					return s.Transaction(ctx, func(ctx context.Context) error {
+						s.afterCommit(ctx, func() { record("inner") })
+						return nil
+					})
+				})
+			},
+			want: []string{"outer", "inner"},
+		},
+		{
+			name: "ロールバックした入れ子の処理は実行しない",
+			fn: func(s *Service, ctx context.Context, record func(string)) error {
+				return s.Transaction(ctx, func(ctx context.Context) error {
+					s.afterCommit(ctx, func() { record("outer") })
+					if err := s.Transaction(ctx, func(ctx context.Context) error {
+						s.afterCommit(ctx, func() { record("inner") })
+						return s.Transaction(ctx, func(ctx context.Context) error {
+							s.afterCommit(ctx, func() { record("innermost") })
+							return nil
+						})
+					}); err != nil {
+						return err
+					}
+					err := s.Transaction(ctx, func(ctx context.Context) error {
+						s.afterCommit(ctx, func() { record("rolled back") })
+						return errRollback
+					})
+					if !errors.Is(err, errRollback) {
+						return errors.New("nested transaction must fail")
+					}
+					return nil
+				})
+			},
+			want: []string{"outer", "inner", "innermost"},
+		},
+		{
+			name: "外側をロールバックした場合は入れ子の処理も実行しない",
+			fn: func(s *Service, ctx context.Context, record func(string)) error {
+				return s.Transaction(ctx, func(ctx context.Context) error {
+					if err := s.Transaction(ctx, func(ctx context.Context) error {
+						s.afterCommit(ctx, func() { record("inner") })
+						return nil
+					}); err != nil {
+						return err
+					}
+					return errRollback
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			var got []string
+			err := tt.fn(s, context.Background(), func(name string) { got = append(got, name) })
+			if err != nil && !errors.Is(err, errRollback) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("hooks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// internal/service/session_service.go
+// session_serviceは、ログインセッション関連のサービスを提供します。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/repository"
+)
+
+// DefaultSessionLifetime セッションの既定の有効期間です
+const DefaultSessionLifetime = 7 * 24 * time.Hour
+
+// セッションIDのバイト長
+const sessionIDBytes = 32
+
+var (
+	// ErrSessionNotFound セッションが見つかりません
+	ErrSessionNotFound = errors.New("session not found / セッションが見つかりません")
+	// ErrSessionExpired セッションの有効期限が切れています
+	ErrSessionExpired = errors.New("session expired / セッションの有効期限が切れています")
+)
+
+// ログインセッション関連のサービス
+type SessionService struct {
+	s        *Service
+	store    repository.SessionStore
+	lifetime time.Duration
+	now      func() time.Time
+}
+
+// 新しいSessionServiceを作成
+func NewSessionService(s *Service) *SessionService {
+	return &SessionService{
+		s:        s,
+		lifetime: DefaultSessionLifetime,
+		now:      time.Now,
+	}
+}
+
+// セッションストアを差し替え（テストやインメモリ運用向け）
+func (s *SessionService) SetStore(store repository.SessionStore) {
+	s.store = store
+}
+
+// セッションの有効期間を設定
+func (s *SessionService) SetLifetime(lifetime time.Duration) {
+	if lifetime > 0 {
+		s.lifetime = lifetime
+	}
+}
+
+// セッションの有効期間を取得
+func (s *SessionService) Lifetime() time.Duration {
+	return s.lifetime
+}
+
+// 利用するセッションストアを取得
+func (s *SessionService) sessionStore(ctx context.Context) repository.SessionStore {
+	if s.store != nil {
+		return s.store
+	}
+	return s.s.repoFor(ctx).Session()
+}
+
+// 新規セッションを作成
+func (s *SessionService) CreateSession(ctx context.Context, userID int64) (*models.Session, error) {
+	id, err := generateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.Session{
+		ID:      id,
+		UserID:  userID,
+		Expires: s.now().Add(s.lifetime),
+	}
+
+	if err := s.sessionStore(ctx).Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// セッションを検証し、ログイン中のユーザーを取得
+func (s *SessionService) ValidateSession(ctx context.Context, sessionID string) (*models.Session, *models.User, error) {
+	if sessionID == "" {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	session, err := s.sessionStore(ctx).GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session == nil {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	if session.IsExpired(s.now()) {
+		if err := s.sessionStore(ctx).Delete(ctx, session.ID); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrSessionExpired
+	}
+
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		// 削除済みユーザーのセッションは破棄する
+		if err := s.sessionStore(ctx).Delete(ctx, session.ID); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrSessionNotFound
+	}
+
+	return session, user, nil
+}
+
+// 有効期間の半分を過ぎたセッションを延長（スライディング方式）
+func (s *SessionService) RenewSession(ctx context.Context, session *models.Session) (bool, error) {
+	now := s.now()
+	if session.Expires.Sub(now) > s.lifetime/2 {
+		return false, nil
+	}
+
+	expires := now.Add(s.lifetime)
+	if err := s.sessionStore(ctx).UpdateExpires(ctx, session.ID, expires); err != nil {
+		return false, err
+	}
+	session.Expires = expires
+
+	return true, nil
+}
+
+// セッションを破棄
+func (s *SessionService) DestroySession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return s.sessionStore(ctx).Delete(ctx, sessionID)
+}
+
+// ユーザーの全セッションを破棄
+func (s *SessionService) DestroyUserSessions(ctx context.Context, userID int64) error {
+	return s.sessionStore(ctx).DeleteByUserID(ctx, userID)
+}
+
+// 有効期限切れのセッションを削除
+func (s *SessionService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.sessionStore(ctx).DeleteExpired(ctx, s.now())
+}
+
+// ランダムなセッションIDを生成
+func generateSessionID() (string, error) {
+	b := make([]byte, sessionIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
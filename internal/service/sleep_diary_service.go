@@ -6,11 +6,32 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 )
 
+var (
+	// ErrEmptyDiaryName 睡眠日誌の名前を入力してください
+	ErrEmptyDiaryName = errors.New("diary name cannot be empty / 睡眠日誌の名前を入力してください")
+	// ErrInvalidDiaryPeriod 睡眠日誌の終了日は開始日以降の日付を指定してください
+	ErrInvalidDiaryPeriod = errors.New("diary end date must not be before start date / 睡眠日誌の終了日は開始日以降の日付を指定してください")
+	// ErrDiaryPeriodOverlap 期間が他の睡眠日誌と重なっています
+	ErrDiaryPeriodOverlap = errors.New("diary period overlaps another diary / 期間が他の睡眠日誌と重なっています")
+	// ErrDiaryRecordsOutOfPeriod 期間外になる睡眠記録があります
+	ErrDiaryRecordsOutOfPeriod = errors.New("diary has records outside the new period / 期間外になる睡眠記録があります")
+	// ErrDiaryAlreadyClosed 睡眠日誌はすでに終了しています
+	ErrDiaryAlreadyClosed = errors.New("diary is already closed / 睡眠日誌はすでに終了しています")
+	// ErrNoDiaryForDate 日付を含む睡眠日誌がありません
+	ErrNoDiaryForDate = errors.New("no diary covers the date / 日付を含む睡眠日誌がありません")
+)
+
+// 睡眠日誌の日付の形式（期間の比較に使う）
+const diaryDateFormat = "2006-01-02"
+
 // 睡眠日誌関連のサービス
 type SleepDiaryService struct {
 	s *Service
@@ -21,16 +42,28 @@ func NewSleepDiaryService(s *Service) *SleepDiaryService {
 	return &SleepDiaryService{s: s}
 }
 
-// 新規睡眠日誌を作成
-func (s *SleepDiaryService) CreateDiary(ctx context.Context, userID int64, startDate, endDate time.Time, name string) (*models.SleepDiary, error) {
+// 新規睡眠日誌を作成（noteが空の場合はメモなし）
+func (s *SleepDiaryService) CreateDiary(ctx context.Context, userID int64, startDate, endDate time.Time, name, note string) (*models.SleepDiary, error) {
 	diary := &models.SleepDiary{
 		UserID:    userID,
 		StartDate: startDate,
 		EndDate:   endDate,
-		DiaryName: name,
+		DiaryName: strings.TrimSpace(name),
+		Note:      sql.NullString{String: note, Valid: note != ""},
 	}
 
-	if err := s.s.repo.SleepDiary().Create(ctx, diary); err != nil {
+	if err := validateDiary(diary); err != nil {
+		return nil, err
+	}
+
+	// 期間の重複の確認と作成は同じトランザクションで行う
+	err := s.s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.checkOverlap(ctx, diary); err != nil {
+			return err
+		}
+		return s.s.repoFor(ctx).SleepDiary().Create(ctx, diary)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -39,46 +72,133 @@ func (s *SleepDiaryService) CreateDiary(ctx context.Context, userID int64, start
 
 // ユーザーの全睡眠日誌を取得
 func (s *SleepDiaryService) GetUserDiaries(ctx context.Context, userID int64) ([]*models.SleepDiary, error) {
-	return s.s.repo.SleepDiary().GetByUserID(ctx, userID)
+	return s.s.repoFor(ctx).SleepDiary().GetByUserID(ctx, userID)
+}
+
+// ユーザーの睡眠日誌を取得
+// 見つからない場合は ErrDiaryNotFound、他のユーザーの睡眠日誌の場合は ErrDiaryAccessDenied
+func (s *SleepDiaryService) GetUserDiary(ctx context.Context, userID, diaryID int64) (*models.SleepDiary, error) {
+	diary, err := s.s.repoFor(ctx).SleepDiary().GetByID(ctx, diaryID)
+	if err != nil {
+		return nil, err
+	}
+	if diary == nil {
+		return nil, ErrDiaryNotFound
+	}
+	if diary.UserID != userID {
+		return nil, ErrDiaryAccessDenied
+	}
+	return diary, nil
+}
+
+// 日付を含む睡眠日誌を取得（見つからない場合は ErrNoDiaryForDate）
+// 睡眠日誌の期間は重ならないため、該当するのは1件のみ
+func (s *SleepDiaryService) GetDiaryForDate(ctx context.Context, userID int64, date time.Time) (*models.SleepDiary, error) {
+	diaries, err := s.GetDiaryByDateRange(ctx, userID, date, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(diaries) == 0 {
+		return nil, ErrNoDiaryForDate
+	}
+	return diaries[0], nil
+}
+
+// 現在の睡眠日誌（ユーザーのタイムゾーンで今日を含む睡眠日誌）を取得
+// 該当する睡眠日誌がない場合はnilを返す
+func (s *SleepDiaryService) GetCurrentDiary(ctx context.Context, user *models.User) (*models.SleepDiary, error) {
+	diary, err := s.GetDiaryForDate(ctx, user.ID, time.Now().In(user.Location()))
+	if errors.Is(err, ErrNoDiaryForDate) {
+		return nil, nil
+	}
+	return diary, err
 }
 
 // 日付範囲で睡眠日誌を取得
 func (s *SleepDiaryService) GetDiaryByDateRange(ctx context.Context, userID int64, startDate, endDate time.Time) ([]*models.SleepDiary, error) {
-	return s.s.repo.SleepDiary().GetByDateRange(ctx, userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	return s.s.repoFor(ctx).SleepDiary().GetByDateRange(ctx, userID, startDate.Format(diaryDateFormat), endDate.Format(diaryDateFormat))
 }
 
 // 睡眠日誌を更新
+// 期間が他の睡眠日誌と重なる場合や、期間外になる睡眠記録がある場合はエラー
 func (s *SleepDiaryService) UpdateDiary(ctx context.Context, diary *models.SleepDiary) error {
-	return s.s.repo.SleepDiary().Update(ctx, diary)
+	diary.DiaryName = strings.TrimSpace(diary.DiaryName)
+	if err := validateDiary(diary); err != nil {
+		return err
+	}
+
+	return s.s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.checkOverlap(ctx, diary); err != nil {
+			return err
+		}
+
+		records, err := s.s.repoFor(ctx).SleepRecord().GetByDiaryID(ctx, diary.ID)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if !diary.Covers(record.RecordDate) {
+				return ErrDiaryRecordsOutOfPeriod
+			}
+		}
+
+		return s.s.repoFor(ctx).SleepDiary().Update(ctx, diary)
+	})
+}
+
+// 睡眠日誌を終了（終了日を today にする）
+// 終了日が today 以前の場合は ErrDiaryAlreadyClosed、開始日が today より後の場合は ErrInvalidDiaryPeriod
+func (s *SleepDiaryService) CloseDiary(ctx context.Context, userID, diaryID int64, today time.Time) (*models.SleepDiary, error) {
+	diary, err := s.GetUserDiary(ctx, userID, diaryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if diary.EndDate.Format(diaryDateFormat) <= today.Format(diaryDateFormat) {
+		return nil, ErrDiaryAlreadyClosed
+	}
+	if diary.Status(today) == models.DiaryStatusUpcoming {
+		return nil, ErrInvalidDiaryPeriod
+	}
+
+	diary.EndDate = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if err := s.UpdateDiary(ctx, diary); err != nil {
+		return nil, err
+	}
+	return diary, nil
 }
 
 // 睡眠日誌を削除
 func (s *SleepDiaryService) DeleteDiary(ctx context.Context, diaryID int64) error {
 	// 関連する睡眠記録も含めてトランザクションで削除
 	return s.s.Transaction(ctx, func(ctx context.Context) error {
-		records, err := s.s.repo.SleepRecord().GetByDiaryID(ctx, diaryID)
+		records, err := s.s.repoFor(ctx).SleepRecord().GetByDiaryID(ctx, diaryID)
 		if err != nil {
 			return err
 		}
 
 		for _, record := range records {
-			if err := s.s.repo.SleepRecord().Delete(ctx, record.ID); err != nil {
+			if err := s.s.repoFor(ctx).SleepRecord().Delete(ctx, record.ID); err != nil {
 				return err
 			}
 		}
+		// 睡眠日誌からユーザーを特定するため、睡眠日誌の削除前に集計し直す
+		if err := s.s.Summary().recordsChanged(ctx, records...); err != nil {
+			return err
+		}
 
-		return s.s.repo.SleepDiary().Delete(ctx, diaryID)
+		return s.s.repoFor(ctx).SleepDiary().Delete(ctx, diaryID)
 	})
 }
 
 // 睡眠日誌のサマリー情報を取得
 func (s *SleepDiaryService) GetDiarySummary(ctx context.Context, diaryID int64) (*models.PDFStatistics, error) {
-	_, err := s.s.repo.SleepRecord().GetByDiaryID(ctx, diaryID)
+	_, err := s.s.repoFor(ctx).SleepRecord().GetByDiaryID(ctx, diaryID)
 	if err != nil {
 		return nil, err
 	}
 
-	diary, err := s.s.repo.SleepDiary().GetByID(ctx, diaryID)
+	diary, err := s.s.repoFor(ctx).SleepDiary().GetByID(ctx, diaryID)
 	if err != nil {
 		return nil, err
 	}
@@ -98,3 +218,31 @@ func (s *SleepDiaryService) GetDiarySummary(ctx context.Context, diaryID int64)
 
 	return stats, nil
 }
+
+// 期間が同じユーザーの他の睡眠日誌と重なっていないかを確認
+func (s *SleepDiaryService) checkOverlap(ctx context.Context, diary *models.SleepDiary) error {
+	diaries, err := s.GetDiaryByDateRange(ctx, diary.UserID, diary.StartDate, diary.EndDate)
+	if err != nil {
+		return err
+	}
+	for _, other := range diaries {
+		if other.ID != diary.ID {
+			return ErrDiaryPeriodOverlap
+		}
+	}
+	return nil
+}
+
+// 睡眠日誌の名前と期間を検証
+func validateDiary(diary *models.SleepDiary) error {
+	if diary.DiaryName == "" {
+		return ErrEmptyDiaryName
+	}
+	if diary.StartDate.IsZero() || diary.EndDate.IsZero() {
+		return ErrInvalidDate
+	}
+	if diary.EndDate.Before(diary.StartDate) {
+		return ErrInvalidDiaryPeriod
+	}
+	return nil
+}
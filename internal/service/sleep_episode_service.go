@@ -0,0 +1,84 @@
+// internal/service/sleep_episode_service.go
+// sleep_episode_serviceは、睡眠記録から睡眠エピソード（一晩分の睡眠）を再構成するサービスを提供します。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// 睡眠エピソード関連のサービス
+type SleepEpisodeService struct {
+	s *Service
+}
+
+// 新しいSleepEpisodeServiceを作成
+func NewSleepEpisodeService(s *Service) *SleepEpisodeService {
+	return &SleepEpisodeService{s: s}
+}
+
+// 日誌の睡眠エピソードを取得
+func (s *SleepEpisodeService) GetDiaryEpisodes(ctx context.Context, diaryID int64) ([]*models.SleepEpisode, error) {
+	records, err := s.s.repoFor(ctx).SleepRecord().GetByDiaryID(ctx, diaryID)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := s.statesMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildSleepEpisodes(records, states), nil
+}
+
+// ユーザーの全日誌から、睡眠日が期間内の睡眠エピソードを取得
+func (s *SleepEpisodeService) GetUserEpisodes(ctx context.Context, userID int64, startDate, endDate time.Time) ([]*models.SleepEpisode, error) {
+	// 日付をまたぐ睡眠を再構成できるよう、前後1日分を含めて記録を取得
+	records, err := s.s.Record().GetUserRecordsByDateRange(ctx, userID, startDate.AddDate(0, 0, -1), endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := s.statesMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := truncateDate(startDate)
+	end := truncateDate(endDate)
+
+	var episodes []*models.SleepEpisode
+	for _, episode := range models.BuildSleepEpisodes(records, states) {
+		date := truncateDate(episode.Date)
+		if date.Before(start) || date.After(end) {
+			continue
+		}
+		episodes = append(episodes, episode)
+	}
+
+	return episodes, nil
+}
+
+// 睡眠状態のマスターデータをIDで引けるマップとして取得
+func (s *SleepEpisodeService) statesMap(ctx context.Context) (map[int64]models.SleepState, error) {
+	states, err := s.s.repoFor(ctx).SleepState().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statesMap := make(map[int64]models.SleepState)
+	for _, state := range states {
+		statesMap[state.ID] = *state
+	}
+	return statesMap, nil
+}
+
+// 日付部分のみを比較できるよう、時刻とタイムゾーンを切り捨てる
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -6,8 +6,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
@@ -26,6 +28,16 @@ var (
     ErrInvalidSleepState = errors.New("invalid sleep state / 無効な睡眠状態です")
 	// invalid record type 無効なレコード種別です
     ErrInvalidRecordType = errors.New("invalid record type / 無効なレコード種別です")
+	// ErrInvalidMealType 無効な食事種別です
+    ErrInvalidMealType = errors.New("invalid meal type / 無効な食事種別です")
+	// ErrRecordNotFound 睡眠記録が見つかりません
+    ErrRecordNotFound = errors.New("record not found / 睡眠記録が見つかりません")
+	// ErrNightEntryOrder 就床・消灯・最終覚醒の時刻の順序が正しくありません
+	ErrNightEntryOrder = errors.New("bedtime, lights-out and final wake must be in order / 就床・消灯・最終覚醒の時刻の順序が正しくありません")
+	// ErrNightEntryOutOfRange 時刻が睡眠日の正午から翌日の正午までの範囲外です
+	ErrNightEntryOutOfRange = errors.New("times must be between noon of the night and noon of the next day / 時刻は睡眠日の正午から翌日の正午までの範囲で指定してください")
+	// ErrInvalidWakePeriod 中途覚醒の時間帯が正しくありません
+	ErrInvalidWakePeriod = errors.New("wake periods must be between lights-out and final wake without overlapping / 中途覚醒は消灯から最終覚醒までの間で、重ならないように指定してください")
 )
 
 // 睡眠記録関連のサービス
@@ -57,98 +69,354 @@ func NewSleepRecordService(s *Service) *SleepRecordService {
 
 // 新規睡眠記録を作成
 func (s *SleepRecordService) CreateRecord(ctx context.Context, record *models.SleepRecord) error {
-	// 時間枠の妥当性チェック
-	if !record.IsValidTimeSlot() {
-		return errors.New("invalid time slot")
+	if err := s.validateRecord(ctx, record); err != nil {
+		return err
 	}
 
-	// 睡眠状態の存在チェック
-	state, err := s.s.repo.SleepState().GetByID(ctx, record.SleepStateID)
+	// 日別集計データもあわせて集計し直す
+	return s.s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.s.repoFor(ctx).SleepRecord().Create(ctx, record); err != nil {
+			return err
+		}
+		return s.s.Summary().recordsChanged(ctx, record)
+	})
+}
+
+// ユーザーの睡眠記録を取得
+// 見つからない場合と他のユーザーの睡眠記録の場合は、いずれも ErrRecordNotFound
+func (s *SleepRecordService) GetUserRecord(ctx context.Context, userID, recordID int64) (*models.SleepRecord, error) {
+	record, err := s.s.repoFor(ctx).SleepRecord().GetByID(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrRecordNotFound
+	}
+
+	diary, err := s.s.repoFor(ctx).SleepDiary().GetByID(ctx, record.SleepDiaryID)
+	if err != nil {
+		return nil, err
+	}
+	if diary == nil || diary.UserID != userID {
+		return nil, ErrRecordNotFound
+	}
+
+	return record, nil
+}
+
+// ユーザーの睡眠記録を作成
+// 睡眠日誌には、記録日を含むユーザーの睡眠日誌を割り当てる（該当する睡眠日誌がない場合は ErrNoDiaryForDate）
+func (s *SleepRecordService) CreateUserRecord(ctx context.Context, userID int64, record *models.SleepRecord) error {
+	diary, err := s.s.Diary().GetDiaryForDate(ctx, userID, record.RecordDate)
 	if err != nil {
 		return err
 	}
-	if state == nil {
-		return errors.New("invalid sleep state")
+
+	record.SleepDiaryID = diary.ID
+	return s.CreateRecord(ctx, record)
+}
+
+// ユーザーの睡眠記録を更新
+// 記録日を変更した場合は、変更後の記録日を含む睡眠日誌に移す
+func (s *SleepRecordService) UpdateUserRecord(ctx context.Context, userID int64, record *models.SleepRecord) error {
+	if _, err := s.GetUserRecord(ctx, userID, record.ID); err != nil {
+		return err
 	}
 
-	// 食事種別の存在チェック（設定されている場合）
-	if record.MealTypeID.Valid {
-		mealType, err := s.s.repo.MealType().GetByID(ctx, record.MealTypeID.Int64)
-		if err != nil {
-			return err
-		}
-		if mealType == nil {
-			return errors.New("invalid meal type")
-		}
+	diary, err := s.s.Diary().GetDiaryForDate(ctx, userID, record.RecordDate)
+	if err != nil {
+		return err
 	}
 
-	return s.s.repo.SleepRecord().Create(ctx, record)
+	record.SleepDiaryID = diary.ID
+	return s.UpdateRecord(ctx, record)
 }
 
 // 日誌の全睡眠記録を取得
 func (s *SleepRecordService) GetDiaryRecords(ctx context.Context, diaryID int64) ([]*models.SleepRecord, error) {
-	return s.s.repo.SleepRecord().GetByDiaryID(ctx, diaryID)
+	return s.s.repoFor(ctx).SleepRecord().GetByDiaryID(ctx, diaryID)
 }
 
 // 関連データを含む睡眠記録を取得
 func (s *SleepRecordService) GetRecordWithRelations(ctx context.Context, recordID int64) (*models.SleepRecordWithRelations, error) {
-	return s.s.repo.SleepRecord().GetWithRelations(ctx, recordID)
+	return s.s.repoFor(ctx).SleepRecord().GetWithRelations(ctx, recordID)
 }
 
 // 日付範囲で睡眠記録を取得
 func (s *SleepRecordService) GetRecordsByDateRange(ctx context.Context, diaryID int64, startDate, endDate time.Time) ([]*models.SleepRecord, error) {
-	return s.s.repo.SleepRecord().GetByDateRange(ctx, diaryID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	return s.s.repoFor(ctx).SleepRecord().GetByDateRange(ctx, diaryID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 }
 
-// 睡眠記録を更新
-func (s *SleepRecordService) UpdateRecord(ctx context.Context, record *models.SleepRecord) error {
-	if !record.IsValidTimeSlot() {
-		return errors.New("invalid time slot")
-	}
+// ユーザーの全日誌から日付範囲で睡眠記録を取得
+func (s *SleepRecordService) GetUserRecordsByDateRange(ctx context.Context, userID int64, startDate, endDate time.Time) ([]*models.SleepRecord, error) {
+	start := startDate.Format("2006-01-02")
+	end := endDate.Format("2006-01-02")
 
-	existing, err := s.s.repo.SleepRecord().GetByID(ctx, record.ID)
+	diaries, err := s.s.repoFor(ctx).SleepDiary().GetByDateRange(ctx, userID, start, end)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if existing == nil {
-		return errors.New("record not found")
+
+	var records []*models.SleepRecord
+	for _, diary := range diaries {
+		diaryRecords, err := s.s.repoFor(ctx).SleepRecord().GetByDateRange(ctx, diary.ID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, diaryRecords...)
 	}
 
-	return s.s.repo.SleepRecord().Update(ctx, record)
+	return records, nil
 }
 
-// 睡眠記録を削除
-func (s *SleepRecordService) DeleteRecord(ctx context.Context, recordID int64) error {
-	existing, err := s.s.repo.SleepRecord().GetByID(ctx, recordID)
-	if err != nil {
+// 睡眠記録を更新
+func (s *SleepRecordService) UpdateRecord(ctx context.Context, record *models.SleepRecord) error {
+	if err := s.validateRecord(ctx, record); err != nil {
 		return err
 	}
-	if existing == nil {
-		return errors.New("record not found")
-	}
 
-	return s.s.repo.SleepRecord().Delete(ctx, recordID)
+	return s.s.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := s.s.repoFor(ctx).SleepRecord().GetByID(ctx, record.ID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return ErrRecordNotFound
+		}
+
+		if err := s.s.repoFor(ctx).SleepRecord().Update(ctx, record); err != nil {
+			return err
+		}
+
+		// 記録日を変更した場合に備えて、変更前後の両方の日を集計し直す
+		return s.s.Summary().recordsChanged(ctx, existing, record)
+	})
+}
+
+// 睡眠記録を削除
+func (s *SleepRecordService) DeleteRecord(ctx context.Context, recordID int64) error {
+	return s.s.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := s.s.repoFor(ctx).SleepRecord().GetByID(ctx, recordID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return ErrRecordNotFound
+		}
+
+		if err := s.s.repoFor(ctx).SleepRecord().Delete(ctx, recordID); err != nil {
+			return err
+		}
+		return s.s.Summary().recordsChanged(ctx, existing)
+	})
 }
 
 // 複数の睡眠記録を一括作成
 func (s *SleepRecordService) BulkCreateRecords(ctx context.Context, records []*models.SleepRecord) error {
 	for _, record := range records {
 		if !record.IsValidTimeSlot() {
-			return errors.New("invalid time slot")
+			return ErrInvalidTimeSlot
+		}
+	}
+
+	return s.s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.s.repoFor(ctx).SleepRecord().BulkCreate(ctx, records); err != nil {
+			return err
+		}
+		return s.s.Summary().recordsChanged(ctx, records...)
+	})
+}
+
+// 一晩分の睡眠記録を、夜の入力から展開した記録に置き換える
+// 睡眠日の正午から翌日の正午までの既存の記録をすべて削除し、展開した記録を一括作成する（すべてを1つのトランザクションで行う）
+// 睡眠日誌は、記録日ごとにその日を含むユーザーの睡眠日誌を割り当てる（該当する睡眠日誌がない場合は ErrNoDiaryForDate）
+func (s *SleepRecordService) ReplaceNightRecords(ctx context.Context, userID int64, entry *models.NightEntry) ([]*models.SleepRecord, error) {
+	if err := validateNightEntry(entry); err != nil {
+		return nil, err
+	}
+
+	slots := entry.Slots()
+	start, end := entry.Window()
+	for _, slot := range slots {
+		if slot.At.Before(start) || !slot.At.Before(end) {
+			return nil, ErrNightEntryOutOfRange
 		}
 	}
 
-	return s.s.repo.SleepRecord().BulkCreate(ctx, records)
+	var records []*models.SleepRecord
+	err := s.s.Transaction(ctx, func(ctx context.Context) error {
+		stateIDs, mealTypeIDs, err := s.masterIDs(ctx)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.GetNightRecords(ctx, userID, entry.Date)
+		if err != nil {
+			return err
+		}
+		// 削除する記録の日は、作成する記録の日とともに一括作成時に集計し直す（同じ睡眠日の範囲に含まれる）
+		for _, record := range existing {
+			if err := s.s.repoFor(ctx).SleepRecord().Delete(ctx, record.ID); err != nil {
+				return err
+			}
+		}
+
+		diaries := make(map[string]*models.SleepDiary)
+		records = make([]*models.SleepRecord, 0, len(slots))
+		for _, slot := range slots {
+			date := slot.At.Format("2006-01-02")
+			diary, ok := diaries[date]
+			if !ok {
+				if diary, err = s.s.Diary().GetDiaryForDate(ctx, userID, slot.At); err != nil {
+					return err
+				}
+				diaries[date] = diary
+			}
+
+			stateID, ok := stateIDs[slot.StateCode]
+			if !ok {
+				return ErrInvalidSleepState
+			}
+			record := &models.SleepRecord{
+				SleepDiaryID: diary.ID,
+				SleepStateID: stateID,
+				RecordDate:   time.Date(slot.At.Year(), slot.At.Month(), slot.At.Day(), 0, 0, 0, 0, time.UTC),
+				TimeSlot:     time.Date(0, 1, 1, slot.At.Hour(), slot.At.Minute(), 0, 0, time.UTC),
+				RecordType:   slot.RecordType,
+				Note:         sql.NullString{String: entry.Note, Valid: entry.Note != ""},
+			}
+			if slot.MealTypeCode != "" {
+				mealTypeID, ok := mealTypeIDs[slot.MealTypeCode]
+				if !ok {
+					return ErrInvalidMealType
+				}
+				record.MealTypeID = sql.NullInt64{Int64: mealTypeID, Valid: true}
+			}
+			records = append(records, record)
+		}
+
+		return s.BulkCreateRecords(ctx, records)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// ユーザーの一晩分（睡眠日の正午から翌日の正午まで）の睡眠記録を取得
+func (s *SleepRecordService) GetNightRecords(ctx context.Context, userID int64, date time.Time) ([]*models.SleepRecord, error) {
+	start, end := (&models.NightEntry{Date: date}).Window()
+
+	records, err := s.GetUserRecordsByDateRange(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var night []*models.SleepRecord
+	for _, record := range records {
+		at := record.SlotStart()
+		if at.Before(start) || !at.Before(end) {
+			continue
+		}
+		night = append(night, record)
+	}
+
+	return night, nil
+}
+
+// 夜の入力の時刻の順序を検証
+func validateNightEntry(entry *models.NightEntry) error {
+	if entry.Date.IsZero() {
+		return ErrInvalidDate
+	}
+	if entry.LightsOut.Before(entry.BedTime) || !entry.FinalWake.After(entry.LightsOut) {
+		return ErrNightEntryOrder
+	}
+
+	start, end := entry.Window()
+	if entry.BedTime.Before(start) || entry.FinalWake.After(end) {
+		return ErrNightEntryOutOfRange
+	}
+
+	periods := make([]models.NightWakePeriod, len(entry.WakePeriods))
+	copy(periods, entry.WakePeriods)
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Start.Before(periods[j].Start)
+	})
+	for i, period := range periods {
+		if !period.End.After(period.Start) || period.Start.Before(entry.LightsOut) || period.End.After(entry.FinalWake) {
+			return ErrInvalidWakePeriod
+		}
+		if i > 0 && period.Start.Before(periods[i-1].End) {
+			return ErrInvalidWakePeriod
+		}
+	}
+
+	return nil
+}
+
+// 睡眠状態・食事種別のコードからIDを引けるマップを取得
+func (s *SleepRecordService) masterIDs(ctx context.Context) (map[string]int64, map[string]int64, error) {
+	states, err := s.GetStatesList(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	mealTypes, err := s.GetMealTypesList(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stateIDs := make(map[string]int64, len(states))
+	for _, state := range states {
+		stateIDs[state.StateCode] = state.ID
+	}
+	mealTypeIDs := make(map[string]int64, len(mealTypes))
+	for _, mealType := range mealTypes {
+		mealTypeIDs[mealType.TypeCode] = mealType.ID
+	}
+
+	return stateIDs, mealTypeIDs, nil
+}
+
+// 睡眠記録の時間枠・睡眠状態・食事種別を検証
+func (s *SleepRecordService) validateRecord(ctx context.Context, record *models.SleepRecord) error {
+	// 時間枠の妥当性チェック
+	if !record.IsValidTimeSlot() {
+		return ErrInvalidTimeSlot
+	}
+
+	// 睡眠状態の存在チェック
+	state, err := s.s.repoFor(ctx).SleepState().GetByID(ctx, record.SleepStateID)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return ErrInvalidSleepState
+	}
+
+	// 食事種別の存在チェック（設定されている場合）
+	if record.MealTypeID.Valid {
+		mealType, err := s.s.repoFor(ctx).MealType().GetByID(ctx, record.MealTypeID.Int64)
+		if err != nil {
+			return err
+		}
+		if mealType == nil {
+			return ErrInvalidMealType
+		}
+	}
+
+	return nil
 }
 
 // すべての睡眠状態を取得
 func (s *SleepRecordService) GetStatesList(ctx context.Context) ([]*models.SleepState, error) {
-	return s.s.repo.SleepState().GetAll(ctx)
+	return s.s.repoFor(ctx).SleepState().GetAll(ctx)
 }
 
 // すべての食事種別を取得
 func (s *SleepRecordService) GetMealTypesList(ctx context.Context) ([]*models.MealType, error) {
-	return s.s.repo.MealType().GetAll(ctx)
+	return s.s.repoFor(ctx).MealType().GetAll(ctx)
 }
 
 // 時間範囲の妥当性をチェック
@@ -183,28 +451,98 @@ func (d *DashboardStats) ToJSON() []byte {
     return jsonData
 }
 
-// 統計データを取得
-func (s *SleepRecordService) GetStatistics(ctx context.Context, userID int64, startDate, endDate time.Time) (interface{}, error) {
-    // 実装
-    return nil, nil
+// 統計データを取得（日別集計データから集計する）
+func (s *SleepRecordService) GetStatistics(ctx context.Context, userID int64, startDate, endDate time.Time) (*SleepStatistics, error) {
+	if startDate.After(endDate) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	summaries, err := s.s.Summary().GetDailySummaries(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	goalHours, err := s.sleepGoalHours(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return CalculateSummaryStatistics(summaries, startDate, endDate, goalHours), nil
 }
 
 // 週間データを取得
-func (s *SleepRecordService) GetWeeklyStats(ctx context.Context, userID int64, startDate, endDate time.Time) (interface{}, error) {
-    // 実装
-    return nil, nil
+func (s *SleepRecordService) GetWeeklyStats(ctx context.Context, userID int64, startDate, endDate time.Time) (*PeriodStatistics, error) {
+	return s.getPeriodStats(ctx, userID, "week", splitWeeks(startDate, endDate))
 }
 
 // 月間データを取得
-func (s *SleepRecordService) GetMonthlyStats(ctx context.Context, userID int64, startDate, endDate time.Time) (interface{}, error) {
-    // 実装
-    return nil, nil
+func (s *SleepRecordService) GetMonthlyStats(ctx context.Context, userID int64, startDate, endDate time.Time) (*PeriodStatistics, error) {
+	return s.getPeriodStats(ctx, userID, "month", splitMonths(startDate, endDate))
+}
+
+// 区切った期間ごとの統計データを取得
+func (s *SleepRecordService) getPeriodStats(ctx context.Context, userID int64, unit string, periods [][2]time.Time) (*PeriodStatistics, error) {
+	result := &PeriodStatistics{
+		Unit:    unit,
+		Periods: []*SleepStatistics{},
+	}
+	if len(periods) == 0 {
+		return nil, ErrInvalidTimeRange
+	}
+
+	// 日別集計データは全期間分をまとめて取得し、期間ごとに集計する
+	summaries, err := s.s.Summary().GetDailySummaries(ctx, userID, periods[0][0], periods[len(periods)-1][1])
+	if err != nil {
+		return nil, err
+	}
+
+	goalHours, err := s.sleepGoalHours(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, period := range periods {
+		result.Periods = append(result.Periods, CalculateSummaryStatistics(summaries, period[0], period[1], goalHours))
+	}
+
+	return result, nil
 }
 
-// すべてのデータを取得
+// ユーザーの目標睡眠時間を取得（未設定の場合はデフォルト値）
+func (s *SleepRecordService) sleepGoalHours(ctx context.Context, userID int64) (int, error) {
+	pref, err := s.s.repoFor(ctx).UserSleepPreference().GetByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if pref == nil {
+		pref = s.s.repoFor(ctx).UserSleepPreference().GetDefaultPreference(userID)
+	}
+	return pref.SleepGoalHours, nil
+}
+
+// ユーザーの全睡眠日誌の睡眠記録を、記録日・時間枠の順に取得
 func (s *SleepRecordService) GetAllRecords(ctx context.Context, userID int64) ([]*models.SleepRecord, error) {
-    // 実装
-    return nil, nil
+	diaries, err := s.s.repoFor(ctx).SleepDiary().GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*models.SleepRecord
+	for _, diary := range diaries {
+		diaryRecords, err := s.s.repoFor(ctx).SleepRecord().GetByDiaryID(ctx, diary.ID)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, diaryRecords...)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].RecordDate.Equal(records[j].RecordDate) {
+			return records[i].RecordDate.Before(records[j].RecordDate)
+		}
+		return records[i].TimeSlot.Before(records[j].TimeSlot)
+	})
+	return records, nil
 }
 
 // 絞り込み条件で検索したデータを取得
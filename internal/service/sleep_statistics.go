@@ -0,0 +1,289 @@
+// internal/service/sleep_statistics.go
+// sleep_statisticsは、日別集計データ（または睡眠エピソード）から期間の睡眠統計を計算する機能を提供します。
+
+// Package service provides application services.
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// 1日の分数
+const minutesPerDay = 24 * 60
+
+// 曜日の表示名
+var weekdayLabels = [...]string{"日", "月", "火", "水", "木", "金", "土"}
+
+// 期間の睡眠統計
+type SleepStatistics struct {
+	StartDate                  string               `json:"start_date"`
+	EndDate                    string               `json:"end_date"`
+	TotalDays                  int                  `json:"total_days"`
+	RecordedNights             int                  `json:"recorded_nights"`
+	NapCount                   int                  `json:"nap_count"`
+	AverageTotalSleepHours     float64              `json:"average_total_sleep_hours"`
+	AverageTimeInBedHours      float64              `json:"average_time_in_bed_hours"`
+	AverageSleepEfficiency     float64              `json:"average_sleep_efficiency"`
+	AverageSleepLatencyMinutes float64              `json:"average_sleep_latency_minutes"`
+	AverageWASOMinutes         float64              `json:"average_waso_minutes"`
+	AverageBedTime             string               `json:"average_bed_time"`
+	AverageWakeTime            string               `json:"average_wake_time"`
+	BedTimeDeviationMinutes    float64              `json:"bed_time_deviation_minutes"`
+	WakeTimeDeviationMinutes   float64              `json:"wake_time_deviation_minutes"`
+	SleepGoalHours             int                  `json:"sleep_goal_hours"`
+	TargetAchievedNights       int                  `json:"target_achieved_nights"`
+	TargetAchievementRate      float64              `json:"target_achievement_rate"`
+	Weekdays                   []*WeekdayStatistics `json:"weekdays"`
+	Nights                     []*NightSummary      `json:"nights"`
+}
+
+// 曜日別の睡眠統計
+type WeekdayStatistics struct {
+	Weekday                int     `json:"weekday"`
+	Label                  string  `json:"label"`
+	Nights                 int     `json:"nights"`
+	AverageTotalSleepHours float64 `json:"average_total_sleep_hours"`
+	AverageSleepEfficiency float64 `json:"average_sleep_efficiency"`
+	AverageBedTime         string  `json:"average_bed_time"`
+	AverageWakeTime        string  `json:"average_wake_time"`
+}
+
+// 一晩分の睡眠の概要
+type NightSummary struct {
+	Date            string  `json:"date"`
+	BedTime         string  `json:"bed_time"`
+	WakeTime        string  `json:"wake_time"`
+	TotalSleepHours float64 `json:"total_sleep_hours"`
+	TimeInBedHours  float64 `json:"time_in_bed_hours"`
+	SleepEfficiency float64 `json:"sleep_efficiency"`
+	NapMinutes      float64 `json:"nap_minutes"`
+	TargetAchieved  bool    `json:"target_achieved"`
+}
+
+// 週単位・月単位に区切った睡眠統計
+type PeriodStatistics struct {
+	Unit    string             `json:"unit"` // "week", "month"
+	Periods []*SleepStatistics `json:"periods"`
+}
+
+// 睡眠エピソードから期間の睡眠統計を計算
+// 睡眠日ごとに日別集計データにまとめてから CalculateSummaryStatistics で集計する
+func CalculateSleepStatistics(episodes []*models.SleepEpisode, startDate, endDate time.Time, goalHours int) *SleepStatistics {
+	byDate := make(map[time.Time][]*models.SleepEpisode)
+	for _, episode := range episodes {
+		date := truncateDate(episode.Date)
+		byDate[date] = append(byDate[date], episode)
+	}
+
+	var summaries []*models.DailySummary
+	for date := truncateDate(startDate); !date.After(truncateDate(endDate)); date = date.AddDate(0, 0, 1) {
+		summaries = append(summaries, models.NewDailySummary(date, byDate[date], goalHours))
+	}
+	return CalculateSummaryStatistics(summaries, startDate, endDate, goalHours)
+}
+
+// 日別集計データから期間の睡眠統計を計算
+// 主睡眠のある日を1晩として集計し、仮眠は回数と各晩の仮眠時間にのみ反映する
+// 目標睡眠時間の達成は、集計した時点ではなく現在の目標睡眠時間で判定する
+func CalculateSummaryStatistics(summaries []*models.DailySummary, startDate, endDate time.Time, goalHours int) *SleepStatistics {
+	start := truncateDate(startDate)
+	end := truncateDate(endDate)
+
+	stats := &SleepStatistics{
+		StartDate:      start.Format("2006-01-02"),
+		EndDate:        end.Format("2006-01-02"),
+		TotalDays:      int(end.Sub(start).Hours()/24) + 1,
+		SleepGoalHours: goalHours,
+		Weekdays:       make([]*WeekdayStatistics, 0, len(weekdayLabels)),
+		Nights:         []*NightSummary{},
+	}
+
+	goalMinutes := goalHours * 60
+	var totalSleep, timeInBed, latency, waso int
+	var efficiency float64
+	var bedTimes, wakeTimes []float64
+	byWeekday := make([][]*models.DailySummary, len(weekdayLabels))
+	for _, summary := range summaries {
+		if summary == nil {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", summary.SummaryDate)
+		if err != nil || date.Before(start) || date.After(end) {
+			continue
+		}
+		stats.NapCount += summary.NapCount
+		if !summary.HasMainSleep() {
+			continue
+		}
+
+		achieved := goalHours > 0 && summary.MainSleepMinutes >= goalMinutes
+		if achieved {
+			stats.TargetAchievedNights++
+		}
+
+		stats.RecordedNights++
+		totalSleep += summary.MainSleepMinutes
+		timeInBed += summary.TimeInBedMinutes
+		latency += summary.SleepLatencyMinutes
+		waso += summary.WASOMinutes
+		efficiency += summary.MainSleepEfficiency()
+		bedTimes = append(bedTimes, clockMinutes(summary.BedTime))
+		wakeTimes = append(wakeTimes, clockMinutes(summary.SleepEndTime))
+
+		weekday := date.Weekday()
+		byWeekday[weekday] = append(byWeekday[weekday], summary)
+
+		stats.Nights = append(stats.Nights, &NightSummary{
+			Date:            summary.SummaryDate,
+			BedTime:         summary.BedTime,
+			WakeTime:        summary.SleepEndTime,
+			TotalSleepHours: round2(float64(summary.MainSleepMinutes) / 60),
+			TimeInBedHours:  round2(float64(summary.TimeInBedMinutes) / 60),
+			SleepEfficiency: round2(summary.MainSleepEfficiency()),
+			NapMinutes:      float64(summary.NapMinutes),
+			TargetAchieved:  achieved,
+		})
+	}
+
+	if n := float64(stats.RecordedNights); n > 0 {
+		stats.AverageTotalSleepHours = round2(float64(totalSleep) / 60 / n)
+		stats.AverageTimeInBedHours = round2(float64(timeInBed) / 60 / n)
+		stats.AverageSleepEfficiency = round2(efficiency / n)
+		stats.AverageSleepLatencyMinutes = round2(float64(latency) / n)
+		stats.AverageWASOMinutes = round2(float64(waso) / n)
+		stats.TargetAchievementRate = round2(float64(stats.TargetAchievedNights) / n * 100)
+
+		bedMean, bedDeviation := circularMean(bedTimes)
+		wakeMean, wakeDeviation := circularMean(wakeTimes)
+		stats.AverageBedTime = formatMinuteOfDay(bedMean)
+		stats.AverageWakeTime = formatMinuteOfDay(wakeMean)
+		stats.BedTimeDeviationMinutes = round2(bedDeviation)
+		stats.WakeTimeDeviationMinutes = round2(wakeDeviation)
+	}
+
+	for weekday, list := range byWeekday {
+		stats.Weekdays = append(stats.Weekdays, calculateWeekdayStatistics(time.Weekday(weekday), list))
+	}
+
+	return stats
+}
+
+// 曜日別の睡眠統計を計算
+func calculateWeekdayStatistics(weekday time.Weekday, summaries []*models.DailySummary) *WeekdayStatistics {
+	stats := &WeekdayStatistics{
+		Weekday: int(weekday),
+		Label:   weekdayLabels[weekday],
+		Nights:  len(summaries),
+	}
+	if len(summaries) == 0 {
+		return stats
+	}
+
+	var totalSleep int
+	var efficiency float64
+	var bedTimes, wakeTimes []float64
+	for _, summary := range summaries {
+		totalSleep += summary.MainSleepMinutes
+		efficiency += summary.MainSleepEfficiency()
+		bedTimes = append(bedTimes, clockMinutes(summary.BedTime))
+		wakeTimes = append(wakeTimes, clockMinutes(summary.SleepEndTime))
+	}
+
+	n := float64(len(summaries))
+	stats.AverageTotalSleepHours = round2(float64(totalSleep) / 60 / n)
+	stats.AverageSleepEfficiency = round2(efficiency / n)
+	bedMean, _ := circularMean(bedTimes)
+	wakeMean, _ := circularMean(wakeTimes)
+	stats.AverageBedTime = formatMinuteOfDay(bedMean)
+	stats.AverageWakeTime = formatMinuteOfDay(wakeMean)
+
+	return stats
+}
+
+// 期間を週単位（月曜始まり）に区切る
+func splitWeeks(startDate, endDate time.Time) [][2]time.Time {
+	start := truncateDate(startDate)
+	end := truncateDate(endDate)
+
+	var periods [][2]time.Time
+	for from := start; !from.After(end); {
+		offset := (int(from.Weekday()) + 6) % 7
+		to := from.AddDate(0, 0, 6-offset)
+		if to.After(end) {
+			to = end
+		}
+		periods = append(periods, [2]time.Time{from, to})
+		from = to.AddDate(0, 0, 1)
+	}
+	return periods
+}
+
+// 期間を暦月単位に区切る
+func splitMonths(startDate, endDate time.Time) [][2]time.Time {
+	start := truncateDate(startDate)
+	end := truncateDate(endDate)
+
+	var periods [][2]time.Time
+	for from := start; !from.After(end); {
+		to := time.Date(from.Year(), from.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		if to.After(end) {
+			to = end
+		}
+		periods = append(periods, [2]time.Time{from, to})
+		from = to.AddDate(0, 0, 1)
+	}
+	return periods
+}
+
+// "15:04"形式の時刻を0時からの経過分に変換
+func clockMinutes(clock string) float64 {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0
+	}
+	return float64(t.Hour()*60 + t.Minute())
+}
+
+// 時刻（0時からの経過分）の円周平均と円周標準偏差（分）を計算
+// 23:30と00:30の平均が00:00になるよう、時刻を24時間周期の角度として扱う
+func circularMean(minutes []float64) (float64, float64) {
+	if len(minutes) == 0 {
+		return 0, 0
+	}
+
+	var sumSin, sumCos float64
+	for _, m := range minutes {
+		angle := m / minutesPerDay * 2 * math.Pi
+		sumSin += math.Sin(angle)
+		sumCos += math.Cos(angle)
+	}
+
+	n := float64(len(minutes))
+	mean := math.Atan2(sumSin/n, sumCos/n) / (2 * math.Pi) * minutesPerDay
+	if mean < 0 {
+		mean += minutesPerDay
+	}
+
+	r := math.Hypot(sumSin/n, sumCos/n)
+	var deviation float64
+	if r > 0 && r < 1 {
+		deviation = math.Sqrt(-2*math.Log(r)) / (2 * math.Pi) * minutesPerDay
+	}
+
+	return mean, deviation
+}
+
+// 0時からの経過分を"15:04"形式に整形
+func formatMinuteOfDay(minutes float64) string {
+	total := int(math.Round(minutes)) % minutesPerDay
+	return fmt.Sprintf("%02d:%02d", total/60, total%60)
+}
+
+// 小数点以下2桁に丸める
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// 睡眠エピソードを作成（date の bedTime に就床し、睡眠日の正午より前の時刻は翌日として扱う）
+func testEpisode(t *testing.T, date, bedTime, wakeTime string, sleep time.Duration, nap bool) *models.SleepEpisode {
+	t.Helper()
+	day := testDate(t, date)
+	bed := models.NightEntryTime(day, testClock(t, bedTime))
+	wake := models.NightEntryTime(day, testClock(t, wakeTime))
+	if nap {
+		// 仮眠は睡眠日の日中の時刻として扱う
+		bedClock, wakeClock := testClock(t, bedTime), testClock(t, wakeTime)
+		bed = day.Add(time.Duration(bedClock.Hour())*time.Hour + time.Duration(bedClock.Minute())*time.Minute)
+		wake = day.Add(time.Duration(wakeClock.Hour())*time.Hour + time.Duration(wakeClock.Minute())*time.Minute)
+	}
+	return &models.SleepEpisode{
+		Date:           day,
+		BedTime:        bed,
+		SleepOnset:     bed,
+		FinalWake:      wake,
+		OutOfBed:       wake,
+		TimeInBed:      wake.Sub(bed),
+		TotalSleepTime: sleep,
+		IsNap:          nap,
+	}
+}
+
+func TestCalculateSleepStatistics(t *testing.T) {
+	tests := []struct {
+		name     string
+		episodes func(t *testing.T) []*models.SleepEpisode
+		start    string
+		end      string
+		goal     int
+		check    func(t *testing.T, stats *SleepStatistics)
+	}{
+		{
+			name: "日付をまたぐ就床時刻の平均（23:30と00:30で00:00）",
+			episodes: func(t *testing.T) []*models.SleepEpisode {
+				return []*models.SleepEpisode{
+					testEpisode(t, "2024-04-01", "23:30", "07:00", 7*time.Hour, false),
+					testEpisode(t, "2024-04-02", "00:30", "07:00", 6*time.Hour, false),
+				}
+			},
+			start: "2024-04-01", end: "2024-04-07", goal: 7,
+			check: func(t *testing.T, stats *SleepStatistics) {
+				if stats.AverageBedTime != "00:00" {
+					t.Errorf("AverageBedTime = %q, want 00:00", stats.AverageBedTime)
+				}
+				if stats.AverageWakeTime != "07:00" {
+					t.Errorf("AverageWakeTime = %q, want 07:00", stats.AverageWakeTime)
+				}
+				if stats.BedTimeDeviationMinutes < 29 || stats.BedTimeDeviationMinutes > 31 {
+					t.Errorf("BedTimeDeviationMinutes = %v, want about 30", stats.BedTimeDeviationMinutes)
+				}
+				if stats.WakeTimeDeviationMinutes != 0 {
+					t.Errorf("WakeTimeDeviationMinutes = %v, want 0", stats.WakeTimeDeviationMinutes)
+				}
+			},
+		},
+		{
+			name: "仮眠は夜間の集計に含めない",
+			episodes: func(t *testing.T) []*models.SleepEpisode {
+				return []*models.SleepEpisode{
+					testEpisode(t, "2024-04-01", "23:00", "06:00", 7*time.Hour, false),
+					testEpisode(t, "2024-04-01", "13:00", "14:00", time.Hour, true),
+					testEpisode(t, "2024-04-02", "13:00", "13:30", 30*time.Minute, true),
+				}
+			},
+			start: "2024-04-01", end: "2024-04-07", goal: 8,
+			check: func(t *testing.T, stats *SleepStatistics) {
+				if stats.RecordedNights != 1 || stats.NapCount != 2 {
+					t.Errorf("RecordedNights = %d, NapCount = %d, want 1 and 2", stats.RecordedNights, stats.NapCount)
+				}
+				if stats.AverageTotalSleepHours != 7 || stats.AverageTimeInBedHours != 7 {
+					t.Errorf("average sleep = %vh, in bed = %vh, want 7h and 7h", stats.AverageTotalSleepHours, stats.AverageTimeInBedHours)
+				}
+				if stats.AverageBedTime != "23:00" {
+					t.Errorf("AverageBedTime = %q, want 23:00 (naps excluded)", stats.AverageBedTime)
+				}
+				// 7時間の睡眠と1時間の仮眠を合計しても目標の達成には数えない
+				if stats.TargetAchievedNights != 0 {
+					t.Errorf("TargetAchievedNights = %d, want 0", stats.TargetAchievedNights)
+				}
+				if len(stats.Nights) != 1 || stats.Nights[0].NapMinutes != 60 {
+					t.Errorf("Nights = %+v, want 1 night with 60 nap minutes", stats.Nights)
+				}
+			},
+		},
+		{
+			name: "目標睡眠時間の達成率",
+			episodes: func(t *testing.T) []*models.SleepEpisode {
+				return []*models.SleepEpisode{
+					testEpisode(t, "2024-04-01", "23:00", "06:00", 7*time.Hour, false),
+					testEpisode(t, "2024-04-02", "23:00", "06:00", 7*time.Hour-30*time.Minute, false),
+					testEpisode(t, "2024-04-03", "22:00", "06:00", 8*time.Hour, false),
+					testEpisode(t, "2024-04-04", "23:00", "06:00", 7*time.Hour, false),
+				}
+			},
+			start: "2024-04-01", end: "2024-04-07", goal: 7,
+			check: func(t *testing.T, stats *SleepStatistics) {
+				if stats.TargetAchievedNights != 3 || stats.TargetAchievementRate != 75 {
+					t.Errorf("achieved = %d (%v%%), want 3 (75%%)", stats.TargetAchievedNights, stats.TargetAchievementRate)
+				}
+				want := []bool{true, false, true, true}
+				for i, night := range stats.Nights {
+					if night.TargetAchieved != want[i] {
+						t.Errorf("Nights[%d].TargetAchieved = %v, want %v", i, night.TargetAchieved, want[i])
+					}
+				}
+			},
+		},
+		{
+			name: "目標睡眠時間が未設定の場合は達成なし",
+			episodes: func(t *testing.T) []*models.SleepEpisode {
+				return []*models.SleepEpisode{
+					testEpisode(t, "2024-04-01", "23:00", "06:00", 7*time.Hour, false),
+				}
+			},
+			start: "2024-04-01", end: "2024-04-07", goal: 0,
+			check: func(t *testing.T, stats *SleepStatistics) {
+				if stats.TargetAchievedNights != 0 || stats.TargetAchievementRate != 0 {
+					t.Errorf("achieved = %d (%v%%), want 0", stats.TargetAchievedNights, stats.TargetAchievementRate)
+				}
+			},
+		},
+		{
+			name: "曜日別の集計",
+			episodes: func(t *testing.T) []*models.SleepEpisode {
+				return []*models.SleepEpisode{
+					testEpisode(t, "2024-04-01", "23:00", "05:00", 6*time.Hour, false), // 月
+					testEpisode(t, "2024-04-08", "23:30", "07:30", 8*time.Hour, false), // 月
+					testEpisode(t, "2024-04-02", "22:00", "05:00", 7*time.Hour, false), // 火
+				}
+			},
+			start: "2024-04-01", end: "2024-04-14", goal: 7,
+			check: func(t *testing.T, stats *SleepStatistics) {
+				if len(stats.Weekdays) != 7 {
+					t.Fatalf("len(Weekdays) = %d, want 7", len(stats.Weekdays))
+				}
+				monday := stats.Weekdays[time.Monday]
+				if monday.Label != "月" || monday.Nights != 2 || monday.AverageTotalSleepHours != 7 {
+					t.Errorf("Monday = %+v, want 2 nights averaging 7h", monday)
+				}
+				if monday.AverageBedTime != "23:15" || monday.AverageWakeTime != "06:15" {
+					t.Errorf("Monday bed/wake = %s/%s, want 23:15/06:15", monday.AverageBedTime, monday.AverageWakeTime)
+				}
+				tuesday := stats.Weekdays[time.Tuesday]
+				if tuesday.Nights != 1 || tuesday.AverageTotalSleepHours != 7 || tuesday.AverageSleepEfficiency != 100 {
+					t.Errorf("Tuesday = %+v, want 1 night of 7h", tuesday)
+				}
+				if sunday := stats.Weekdays[time.Sunday]; sunday.Label != "日" || sunday.Nights != 0 || sunday.AverageBedTime != "" {
+					t.Errorf("Sunday = %+v, want no nights", sunday)
+				}
+			},
+		},
+		{
+			name: "期間外のエピソードは含めない",
+			episodes: func(t *testing.T) []*models.SleepEpisode {
+				return []*models.SleepEpisode{
+					testEpisode(t, "2024-03-31", "23:00", "07:00", 8*time.Hour, false),
+					testEpisode(t, "2024-04-01", "23:00", "05:00", 6*time.Hour, false),
+					testEpisode(t, "2024-04-08", "23:00", "07:00", 8*time.Hour, false),
+				}
+			},
+			start: "2024-04-01", end: "2024-04-07", goal: 7,
+			check: func(t *testing.T, stats *SleepStatistics) {
+				if stats.TotalDays != 7 || stats.RecordedNights != 1 || stats.AverageTotalSleepHours != 6 {
+					t.Errorf("days = %d, nights = %d, average = %vh, want 7, 1, 6h", stats.TotalDays, stats.RecordedNights, stats.AverageTotalSleepHours)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := CalculateSleepStatistics(tt.episodes(t), testDate(t, tt.start), testDate(t, tt.end), tt.goal)
+			tt.check(t, stats)
+		})
+	}
+}
+
+func TestCircularMean(t *testing.T) {
+	tests := []struct {
+		name          string
+		minutes       []float64
+		wantMean      float64
+		wantDeviation float64
+	}{
+		{name: "なし", minutes: nil, wantMean: 0, wantDeviation: 0},
+		{name: "同じ時刻", minutes: []float64{23 * 60, 23 * 60}, wantMean: 23 * 60, wantDeviation: 0},
+		{name: "日付をまたぐ", minutes: []float64{23*60 + 30, 30}, wantMean: 0, wantDeviation: 30},
+		{name: "日付をまたぐ（3件）", minutes: []float64{23 * 60, 0, 60}, wantMean: 0, wantDeviation: 49},
+		{name: "日中", minutes: []float64{60, 120}, wantMean: 90, wantDeviation: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, deviation := circularMean(tt.minutes)
+			// 0時ちょうどは 0 と 1440 のどちらにもなりうる
+			if diff := math.Mod(math.Abs(mean-tt.wantMean), minutesPerDay); diff > 0.5 && diff < minutesPerDay-0.5 {
+				t.Errorf("mean = %v, want %v", mean, tt.wantMean)
+			}
+			if math.Abs(deviation-tt.wantDeviation) > 1 {
+				t.Errorf("deviation = %v, want about %v", deviation, tt.wantDeviation)
+			}
+		})
+	}
+}
+
+func TestSplitPeriods(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data is not available: %v", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data is not available: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		split func(startDate, endDate time.Time) [][2]time.Time
+		start time.Time
+		end   time.Time
+		want  []string
+	}{
+		{
+			// 日本時間の日曜日の0時はUTCでは土曜日だが、その地域の日付で区切る
+			name:  "週（日本時間・日曜日始まり）",
+			split: splitWeeks,
+			start: time.Date(2024, 3, 31, 0, 0, 0, 0, tokyo),
+			end:   time.Date(2024, 4, 14, 0, 0, 0, 0, tokyo),
+			want:  []string{"2024-03-31/2024-03-31", "2024-04-01/2024-04-07", "2024-04-08/2024-04-14"},
+		},
+		{
+			name:  "週（ニューヨーク・夏時間の開始をまたぐ）",
+			split: splitWeeks,
+			start: time.Date(2024, 3, 6, 23, 0, 0, 0, newYork),
+			end:   time.Date(2024, 3, 18, 23, 0, 0, 0, newYork),
+			want:  []string{"2024-03-06/2024-03-10", "2024-03-11/2024-03-17", "2024-03-18/2024-03-18"},
+		},
+		{
+			name:  "月（日本時間・うるう年）",
+			split: splitMonths,
+			start: time.Date(2024, 1, 15, 0, 0, 0, 0, tokyo),
+			end:   time.Date(2024, 3, 1, 0, 0, 0, 0, tokyo),
+			want:  []string{"2024-01-15/2024-01-31", "2024-02-01/2024-02-29", "2024-03-01/2024-03-01"},
+		},
+		{
+			name:  "月（ニューヨーク・年をまたぐ夜）",
+			split: splitMonths,
+			start: time.Date(2023, 12, 31, 23, 30, 0, 0, newYork),
+			end:   time.Date(2024, 1, 31, 23, 30, 0, 0, newYork),
+			want:  []string{"2023-12-31/2023-12-31", "2024-01-01/2024-01-31"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods := tt.split(tt.start, tt.end)
+			got := make([]string, 0, len(periods))
+			for _, period := range periods {
+				got = append(got, period[0].Format("2006-01-02")+"/"+period[1].Format("2006-01-02"))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("periods = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("periods = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
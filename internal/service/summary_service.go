@@ -0,0 +1,519 @@
+// internal/service/summary_service.go
+// summary_serviceは、日別集計データと期間統計データをキャッシュを介して提供するサービスを提供します。
+// 日別集計データは daily_summaries テーブルに保存し、睡眠記録を変更すると関連する日を集計し直してキャッシュを無効化します。
+// 毎日0時台に過去90日分を集計し直し、キャッシュを作成し直します。
+// キャッシュの設計は doc/cache.md を参照してください。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/cache"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+const (
+	// キャッシュを作成し直す日別集計データの日数
+	SummaryRebuildDays = 90
+	// 既定の、キャッシュの再作成と使用状況のチェックの間隔
+	DefaultSummaryInterval = time.Hour
+	// ヒット率のアラートを判定する、チェックの間隔あたりの最小の取得回数
+	summaryAlertMinLookups = 100
+)
+
+// ErrInvalidStatsPeriod 期間統計データの期間が正しくありません
+var ErrInvalidStatsPeriod = errors.New("invalid stats period / 期間統計データの期間が正しくありません")
+
+// 期間統計データ（直近の期間の睡眠の傾向）
+// キャッシュのデータ構造は doc/cache.md の 1-2 を参照
+type PeriodStats struct {
+	Period                string  `json:"period"`                  // "30days"、"60days"、"90days"
+	AvgDuration           float64 `json:"avg_duration"`            // 平均総睡眠時間（時間）
+	AvgQuality            float64 `json:"avg_quality"`             // 睡眠効率の平均（%）
+	TargetAchievementRate float64 `json:"target_achievement_rate"` // 目標睡眠時間の達成率（%）
+	MostCommonBedtime     string  `json:"most_common_bedtime"`     // 最も多い入眠時刻（HH:MM）
+	MostCommonWaketime    string  `json:"most_common_waketime"`    // 最も多い最終覚醒時刻（HH:MM）
+}
+
+// 日別集計・期間統計関連のサービス
+type SummaryService struct {
+	s           *Service
+	cache       *cache.Metered
+	now         func() time.Time
+	mutex       sync.Mutex
+	lastStats   cache.Stats // 前回チェックした時点のキャッシュの使用状況
+	lastRebuild string      // 最後にキャッシュを作成し直した日（YYYY-MM-DD）
+}
+
+// 新しいSummaryServiceを作成（キャッシュは既定の最大サイズのLRU）
+func NewSummaryService(s *Service) *SummaryService {
+	return &SummaryService{
+		s:     s,
+		cache: cache.NewMetered(cache.NewLRU(0)),
+		now:   time.Now,
+	}
+}
+
+// キャッシュを差し替え
+func (s *SummaryService) SetCache(c cache.Cache) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cache = cache.NewMetered(c)
+	s.lastStats = cache.Stats{}
+}
+
+// 現在時刻の取得方法を差し替え（テスト用）
+func (s *SummaryService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// キャッシュの使用状況を取得
+func (s *SummaryService) CacheStats() cache.Stats {
+	return s.metered().Stats()
+}
+
+// 期間の日別集計データを日付順に取得
+// キャッシュにない日のみ daily_summaries テーブルから取得し、キャッシュに保存する
+func (s *SummaryService) GetDailySummaries(ctx context.Context, userID int64, startDate, endDate time.Time) ([]*models.DailySummary, error) {
+	start := truncateDate(startDate)
+	end := truncateDate(endDate)
+	if start.After(end) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	var dates []time.Time
+	keys := make([]string, 0, int(end.Sub(start).Hours()/24)+1)
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+		keys = append(keys, cache.DailySummaryKey(userID, date))
+	}
+
+	// キャッシュを取得できない場合は、すべての日をテーブルから取得する
+	c := s.metered()
+	cached, err := c.GetMulti(ctx, keys)
+	if err != nil {
+		s.s.Logger().Error("日別集計データのキャッシュの取得に失敗: error=%v, user_id=%d", err, userID)
+		cached = nil
+	}
+
+	summaries := make([]*models.DailySummary, len(dates))
+	var missing []time.Time
+	for i, date := range dates {
+		if value, ok := cached[keys[i]]; ok {
+			var summary models.DailySummary
+			if err := json.Unmarshal(value, &summary); err == nil {
+				summaries[i] = &summary
+				continue
+			}
+		}
+		missing = append(missing, date)
+	}
+	if len(missing) == 0 {
+		return summaries, nil
+	}
+
+	stored, err := s.loadDailySummaries(ctx, userID, missing[0], missing[len(missing)-1])
+	if err != nil {
+		return nil, err
+	}
+	for i, date := range dates {
+		if summaries[i] != nil {
+			continue
+		}
+		summaries[i] = stored[date]
+		s.set(ctx, c, keys[i], summaries[i], cache.DailySummaryTTL)
+	}
+
+	return summaries, nil
+}
+
+// 日別集計データを取得
+func (s *SummaryService) GetDailySummary(ctx context.Context, userID int64, date time.Time) (*models.DailySummary, error) {
+	summaries, err := s.GetDailySummaries(ctx, userID, date, date)
+	if err != nil {
+		return nil, err
+	}
+	return summaries[0], nil
+}
+
+// 直近の期間（ユーザーのタイムゾーンで今日まで）の期間統計データを取得
+func (s *SummaryService) GetPeriodStats(ctx context.Context, userID int64, period string) (*PeriodStats, error) {
+	days, ok := statsPeriodDays(period)
+	if !ok {
+		return nil, ErrInvalidStatsPeriod
+	}
+
+	c := s.metered()
+	key := cache.PeriodStatsKey(userID, period)
+	value, ok, err := c.Get(ctx, key)
+	if err != nil {
+		s.s.Logger().Error("期間統計データのキャッシュの取得に失敗: error=%v, user_id=%d", err, userID)
+	}
+	if ok {
+		var stats PeriodStats
+		if err := json.Unmarshal(value, &stats); err == nil {
+			return &stats, nil
+		}
+	}
+
+	stats, err := s.computePeriodStats(ctx, userID, period, days)
+	if err != nil {
+		return nil, err
+	}
+	s.set(ctx, c, key, stats, cache.PeriodStatsTTL)
+
+	return stats, nil
+}
+
+// 睡眠日の日別集計データと、ユーザーの期間統計データのキャッシュを無効化
+func (s *SummaryService) InvalidateDates(ctx context.Context, userID int64, dates ...time.Time) {
+	keys := make([]string, 0, len(dates)+len(cache.StatsPeriods))
+	for _, date := range dates {
+		keys = append(keys, cache.DailySummaryKey(userID, date))
+	}
+	for _, period := range cache.StatsPeriods {
+		keys = append(keys, cache.PeriodStatsKey(userID, period))
+	}
+
+	if err := s.metered().Delete(ctx, keys...); err != nil {
+		s.s.Logger().Error("集計データのキャッシュの無効化に失敗: error=%v, user_id=%d", err, userID)
+	}
+}
+
+// 睡眠日の範囲の日別集計データを睡眠記録から集計し直して保存し、キャッシュを無効化（トランザクション中の場合はコミット後）
+// 記録のない日の集計データは削除する
+func (s *SummaryService) RecomputeDays(ctx context.Context, userID int64, startDate, endDate time.Time) (map[time.Time]*models.DailySummary, error) {
+	start := truncateDate(startDate)
+	end := truncateDate(endDate)
+	if start.After(end) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	var summaries map[time.Time]*models.DailySummary
+	err := s.s.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		summaries, err = s.computeDailySummaries(ctx, userID, start, end)
+		if err != nil {
+			return err
+		}
+
+		repo := s.s.repoFor(ctx).DailySummary()
+		if _, err := repo.DeleteByDateRange(ctx, userID, start.Format("2006-01-02"), end.Format("2006-01-02")); err != nil {
+			return err
+		}
+		dates := make([]time.Time, 0, len(summaries))
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			dates = append(dates, date)
+			if summary := summaries[date]; summary.HasRecords() {
+				summary.UserID = userID
+				if err := repo.Create(ctx, summary); err != nil {
+					return err
+				}
+			}
+		}
+
+		s.s.afterCommit(ctx, func() {
+			s.InvalidateDates(context.WithoutCancel(ctx), userID, dates...)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// 睡眠記録の変更に合わせて、関連する日の日別集計データを集計し直す
+// 正午より前の時間枠は前日の夜に含まれ、分割睡眠は前日の夜にまとめられることがあるため、記録日とその前日を集計し直す
+// 睡眠記録の変更と同じトランザクションの中で呼び出す
+func (s *SummaryService) recordsChanged(ctx context.Context, records ...*models.SleepRecord) error {
+	type dateRange struct{ start, end time.Time }
+	ranges := make(map[int64]*dateRange)
+	users := make(map[int64]int64)
+	for _, record := range records {
+		userID, ok := users[record.SleepDiaryID]
+		if !ok {
+			diary, err := s.s.repoFor(ctx).SleepDiary().GetByID(ctx, record.SleepDiaryID)
+			if err != nil {
+				return err
+			}
+			if diary != nil {
+				userID = diary.UserID
+			}
+			users[record.SleepDiaryID] = userID
+		}
+		if userID == 0 {
+			continue
+		}
+
+		date := truncateDate(record.RecordDate)
+		r, ok := ranges[userID]
+		if !ok {
+			ranges[userID] = &dateRange{start: date.AddDate(0, 0, -1), end: date}
+			continue
+		}
+		if date.AddDate(0, 0, -1).Before(r.start) {
+			r.start = date.AddDate(0, 0, -1)
+		}
+		if date.After(r.end) {
+			r.end = date
+		}
+	}
+
+	for userID, r := range ranges {
+		if _, err := s.RecomputeDays(ctx, userID, r.start, r.end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ユーザーの過去90日分の日別集計データを集計し直して保存し、期間統計データとあわせてキャッシュを作成し直す
+func (s *SummaryService) RebuildUser(ctx context.Context, user *models.User) error {
+	today := s.userToday(user)
+	start := today.AddDate(0, 0, -(SummaryRebuildDays - 1))
+
+	summaries, err := s.RecomputeDays(ctx, user.ID, start, today)
+	if err != nil {
+		return err
+	}
+	c := s.metered()
+	for date, summary := range summaries {
+		s.set(ctx, c, cache.DailySummaryKey(user.ID, date), summary, cache.DailySummaryTTL)
+	}
+
+	// 期間統計データは日別集計データの作成後に集計する
+	for _, period := range cache.StatsPeriods {
+		days, _ := statsPeriodDays(period)
+		stats, err := s.computePeriodStats(ctx, user.ID, period, days)
+		if err != nil {
+			return err
+		}
+		s.set(ctx, c, cache.PeriodStatsKey(user.ID, period), stats, cache.PeriodStatsTTL)
+	}
+
+	return nil
+}
+
+// 全ユーザーのキャッシュを作成し直す（作成し直したユーザー数を返す）
+func (s *SummaryService) RebuildAll(ctx context.Context) (int, error) {
+	users, err := s.s.repoFor(ctx).User().GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rebuilt := 0
+	for _, user := range users {
+		if err := s.RebuildUser(ctx, user); err != nil {
+			s.s.Logger().Error("集計データのキャッシュの再作成に失敗: error=%v, user_id=%d", err, user.ID)
+			continue
+		}
+		rebuilt++
+	}
+
+	return rebuilt, nil
+}
+
+// キャッシュの使用状況をログに出力し、アラート条件に該当する項目を返す
+// ヒット率・更新エラーは前回のチェックからの差分で判定する
+func (s *SummaryService) CheckCache() []string {
+	stats := s.CacheStats()
+
+	s.mutex.Lock()
+	interval := stats.Since(s.lastStats)
+	s.lastStats = stats
+	s.mutex.Unlock()
+
+	s.s.Logger().Info("キャッシュの使用状況: hit_rate=%.1f%%, update_success_rate=%.1f%%, memory_usage=%.1f%%, entries=%d",
+		interval.HitRate(), interval.UpdateSuccessRate(), stats.MemoryUsage(), stats.Entries)
+
+	alerts := interval.Alerts(summaryAlertMinLookups)
+	for _, alert := range alerts {
+		s.s.Logger().Error("キャッシュのアラート: %s", alert)
+	}
+	return alerts
+}
+
+// 指定した間隔でキャッシュの使用状況をチェックし、日付が変わっていればキャッシュを作成し直す（ctxが終了するまで続ける）
+func (s *SummaryService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSummaryInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if today := s.now().Format("2006-01-02"); today != s.lastRebuild {
+			count, err := s.RebuildAll(ctx)
+			if err != nil {
+				s.s.Logger().Error("集計データのキャッシュの再作成に失敗: error=%v", err)
+			} else {
+				s.lastRebuild = today
+				s.s.Logger().Info("集計データのキャッシュを作成し直しました: users=%d", count)
+			}
+		}
+		s.CheckCache()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 期間の日別集計データを睡眠記録から集計（記録のない日を含む）
+func (s *SummaryService) computeDailySummaries(ctx context.Context, userID int64, startDate, endDate time.Time) (map[time.Time]*models.DailySummary, error) {
+	episodes, err := s.s.Episode().GetUserEpisodes(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	goalHours, err := s.s.Record().sleepGoalHours(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[time.Time][]*models.SleepEpisode)
+	for _, episode := range episodes {
+		date := truncateDate(episode.Date)
+		byDate[date] = append(byDate[date], episode)
+	}
+
+	summaries := make(map[time.Time]*models.DailySummary)
+	for date := truncateDate(startDate); !date.After(truncateDate(endDate)); date = date.AddDate(0, 0, 1) {
+		summaries[date] = models.NewDailySummary(date, byDate[date], goalHours)
+	}
+	return summaries, nil
+}
+
+// 期間の日別集計データを daily_summaries テーブルから取得（保存されていない日は記録のない日の集計データとする）
+func (s *SummaryService) loadDailySummaries(ctx context.Context, userID int64, startDate, endDate time.Time) (map[time.Time]*models.DailySummary, error) {
+	start := truncateDate(startDate)
+	end := truncateDate(endDate)
+
+	stored, err := s.s.repoFor(ctx).DailySummary().GetByDateRange(ctx, userID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]*models.DailySummary, len(stored))
+	for _, summary := range stored {
+		byDate[summary.SummaryDate] = summary
+	}
+
+	summaries := make(map[time.Time]*models.DailySummary)
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		if summary, ok := byDate[date.Format("2006-01-02")]; ok {
+			summaries[date] = summary
+			continue
+		}
+		summaries[date] = models.NewDailySummary(date, nil, 0)
+	}
+	return summaries, nil
+}
+
+// 直近の日数分の日別集計データから期間統計データを集計（記録のある日のみを対象とする）
+func (s *SummaryService) computePeriodStats(ctx context.Context, userID int64, period string, days int) (*PeriodStats, error) {
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	today := s.userToday(user)
+	summaries, err := s.GetDailySummaries(ctx, userID, today.AddDate(0, 0, -(days-1)), today)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &PeriodStats{Period: period}
+	var recorded, achieved int
+	bedtimes := make(map[string]int)
+	waketimes := make(map[string]int)
+	for _, summary := range summaries {
+		if summary.SleepStartTime == "" {
+			continue
+		}
+		recorded++
+		stats.AvgDuration += summary.TotalSleepDuration
+		stats.AvgQuality += summary.AvgSleepQuality
+		if summary.IsWithinTargetTime {
+			achieved++
+		}
+		bedtimes[summary.SleepStartTime]++
+		waketimes[summary.SleepEndTime]++
+	}
+	if recorded > 0 {
+		n := float64(recorded)
+		stats.AvgDuration = round2(stats.AvgDuration / n)
+		stats.AvgQuality = round2(stats.AvgQuality / n)
+		stats.TargetAchievementRate = round2(float64(achieved) / n * 100)
+		stats.MostCommonBedtime = mostCommon(bedtimes)
+		stats.MostCommonWaketime = mostCommon(waketimes)
+	}
+
+	return stats, nil
+}
+
+// 値をJSONにしてキャッシュに保存（失敗した場合はログに記録し、古いキャッシュは次の再作成まで残る）
+func (s *SummaryService) set(ctx context.Context, c *cache.Metered, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		s.s.Logger().Error("集計データのキャッシュの作成に失敗: error=%v, key=%s", err, key)
+		return
+	}
+	if err := c.Set(ctx, key, data, ttl); err != nil {
+		s.s.Logger().Error("集計データのキャッシュの保存に失敗: error=%v, key=%s", err, key)
+	}
+}
+
+// 現在のキャッシュ
+func (s *SummaryService) metered() *cache.Metered {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cache
+}
+
+// ユーザーのタイムゾーンでの今日（UTCの0時）
+func (s *SummaryService) userToday(user *models.User) time.Time {
+	return truncateDate(s.now().In(user.Location()))
+}
+
+// 期間統計データの期間の日数
+func statsPeriodDays(period string) (int, bool) {
+	switch period {
+	case "30days":
+		return 30, true
+	case "60days":
+		return 60, true
+	case "90days":
+		return 90, true
+	}
+	return 0, false
+}
+
+// 出現回数が最も多い値（同数の場合は小さい値）
+func mostCommon(counts map[string]int) string {
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	var result string
+	for _, value := range values {
+		if result == "" || counts[value] > counts[result] {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// 保存されている日別集計データを取得（ID・作成日時・更新日時は比較しない）
+func storedDailySummaries(t *testing.T, s *Service, userID int64, start, end string) []models.DailySummary {
+	t.Helper()
+	stored, err := s.repo.DailySummary().GetByDateRange(context.Background(), userID, start, end)
+	if err != nil {
+		t.Fatalf("failed to get daily summaries: %v", err)
+	}
+	summaries := make([]models.DailySummary, 0, len(stored))
+	for _, summary := range stored {
+		copied := *summary
+		copied.ID = 0
+		copied.Created = time.Time{}
+		copied.Modified = time.Time{}
+		summaries = append(summaries, copied)
+	}
+	return summaries
+}
+
+// 睡眠記録の変更のたびに関連する日だけを集計し直した結果が、期間全体を集計し直した結果と一致することを確認
+func TestRecomputeDaysIncremental(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	user := createTestUser(t, s, "summary@example.com")
+	createTestDiary(t, s, user.ID, "2024-04-01", "2024-04-14")
+	const start, end = "2024-04-01", "2024-04-14"
+
+	// 睡眠記録を保存するたびに、関連する日が集計し直される
+	saveTestNight(t, s, user.ID, "2024-04-01", "23:00", "07:00")
+	saveTestNight(t, s, user.ID, "2024-04-02", "00:30", "06:30")
+	saveTestNight(t, s, user.ID, "2024-04-03", "22:30", "05:00")
+	deleted := saveTestNight(t, s, user.ID, "2024-04-04", "23:30", "07:30")
+	saveTestNight(t, s, user.ID, "2024-04-05", "01:00", "08:00")
+	// 同じ夜を入力し直す
+	saveTestNight(t, s, user.ID, "2024-04-03", "23:00", "06:00")
+	// 夜の記録を削除する
+	for _, record := range deleted {
+		if err := s.Record().DeleteRecord(ctx, record.ID); err != nil {
+			t.Fatalf("failed to delete record: %v", err)
+		}
+	}
+	// 日中の仮眠を追加する
+	stateIDs, _, err := s.Record().masterIDs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, slot := range []string{"14:00", "14:30"} {
+		record := &models.SleepRecord{
+			SleepStateID: stateIDs[models.StateCodeSleeping],
+			RecordDate:   testDate(t, "2024-04-05"),
+			TimeSlot:     testClock(t, slot),
+			RecordType:   models.RecordTypeState,
+		}
+		if err := s.Record().CreateUserRecord(ctx, user.ID, record); err != nil {
+			t.Fatalf("failed to create nap record: %v", err)
+		}
+	}
+
+	incremental := storedDailySummaries(t, s, user.ID, start, end)
+	if len(incremental) != 4 {
+		t.Fatalf("stored %d daily summaries, want 4: %+v", len(incremental), incremental)
+	}
+	// 統計データは日別集計データから集計する
+	incrementalStats, err := s.Record().GetStatistics(ctx, user.ID, testDate(t, start), testDate(t, end))
+	if err != nil {
+		t.Fatalf("GetStatistics: %v", err)
+	}
+
+	// 期間全体を集計し直す（rebuild-summaries と同じ）
+	if _, err := s.repo.DailySummary().DeleteByDateRange(ctx, user.ID, start, end); err != nil {
+		t.Fatalf("failed to delete daily summaries: %v", err)
+	}
+	if _, err := s.Summary().RecomputeDays(ctx, user.ID, testDate(t, start), testDate(t, end)); err != nil {
+		t.Fatalf("RecomputeDays: %v", err)
+	}
+
+	full := storedDailySummaries(t, s, user.ID, start, end)
+	if !reflect.DeepEqual(incremental, full) {
+		t.Errorf("incremental daily summaries differ from full rebuild:\nincremental: %+v\nfull:        %+v", incremental, full)
+	}
+	for _, summary := range full {
+		if summary.SummaryDate == "2024-04-05" && (summary.NapCount != 1 || summary.NapMinutes != 60) {
+			t.Errorf("2024-04-05: naps = %d (%d min), want 1 (60 min)", summary.NapCount, summary.NapMinutes)
+		}
+	}
+
+	fullStats, err := s.Record().GetStatistics(ctx, user.ID, testDate(t, start), testDate(t, end))
+	if err != nil {
+		t.Fatalf("GetStatistics: %v", err)
+	}
+	if !reflect.DeepEqual(incrementalStats, fullStats) {
+		t.Errorf("statistics differ after full rebuild:\nincremental: %+v\nfull:        %+v", incrementalStats, fullStats)
+	}
+
+	// 日別集計データからの統計データは、睡眠記録から集計した統計データと一致する
+	episodes, err := s.Episode().GetUserEpisodes(ctx, user.ID, testDate(t, start), testDate(t, end))
+	if err != nil {
+		t.Fatalf("GetUserEpisodes: %v", err)
+	}
+	goalHours, err := s.Record().sleepGoalHours(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := CalculateSleepStatistics(episodes, testDate(t, start), testDate(t, end), goalHours); !reflect.DeepEqual(fullStats, want) {
+		t.Errorf("statistics from daily summaries differ from records:\nsummaries: %+v\nrecords:   %+v", fullStats, want)
+	}
+}
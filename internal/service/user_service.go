@@ -6,7 +6,13 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
 	"golang.org/x/crypto/bcrypt"
@@ -21,23 +27,50 @@ var (
     ErrEmptyEmail = errors.New("email cannot be empty")
     ErrInvalidEmail = errors.New("email format is invalid")
     ErrEmptyTimezone = errors.New("timezone cannot be empty")
+    // ErrInvalidTimezone タイムゾーンが正しくありません
+    ErrInvalidTimezone = errors.New("timezone is invalid / タイムゾーンが正しくありません")
+    // ErrInvalidReminderTime リマインダーの時刻は HH:MM の形式で指定してください
+    ErrInvalidReminderTime = errors.New("reminder time must be in HH:MM format / リマインダーの時刻は HH:MM の形式で指定してください")
+    // ErrInvalidSleepGoal 目標睡眠時間は1〜24時間で指定してください
+    ErrInvalidSleepGoal = errors.New("sleep goal must be between 1 and 24 hours / 目標睡眠時間は1〜24時間で指定してください")
     ErrEmailAlreadyExists = errors.New("email already exists")
+    // ErrInvalidResetToken パスワード再設定用のトークンが無効か期限切れです
+    ErrInvalidResetToken = errors.New("invalid or expired reset token / パスワード再設定用のトークンが無効か期限切れです")
 )
 
+// DefaultResetTokenLifetime パスワード再設定用トークンの既定の有効期間です
+const DefaultResetTokenLifetime = time.Hour
+
+// パスワード再設定用トークンのバイト長
+const resetTokenBytes = 32
+
 // ユーザー関連のサービス
 type UserService struct {
-	s *Service
+	s                  *Service
+	resetTokenLifetime time.Duration
+	now                func() time.Time
 }
 
 // 新しいUserServiceを作成
 func NewUserService(s *Service) *UserService {
-	return &UserService{s: s}
+	return &UserService{
+		s:                  s,
+		resetTokenLifetime: DefaultResetTokenLifetime,
+		now:                time.Now,
+	}
+}
+
+// パスワード再設定用トークンの有効期間を設定
+func (s *UserService) SetResetTokenLifetime(lifetime time.Duration) {
+	if lifetime > 0 {
+		s.resetTokenLifetime = lifetime
+	}
 }
 
 // ユーザー登録
 func (s *UserService) Register(ctx context.Context, email, displayName, password string) (*models.User, error) {
 	// メールアドレスの重複チェック
-	existing, err := s.s.repo.User().GetByEmail(ctx, email)
+	existing, err := s.s.repoFor(ctx).User().GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
@@ -55,16 +88,19 @@ func (s *UserService) Register(ctx context.Context, email, displayName, password
 		Email:        email,
 		DisplayName:  displayName,
 		PasswordHash: string(hash),
+		TimeZone:     models.DefaultTimeZone,
 	}
 
-	// ユーザーの作成
-	if err := s.s.repo.User().Create(ctx, user); err != nil {
-		return nil, err
-	}
+	// ユーザーとデフォルトの睡眠設定をトランザクションで作成
+	err = s.s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.s.repoFor(ctx).User().Create(ctx, user); err != nil {
+			return err
+		}
 
-	// デフォルトの睡眠設定を作成
-	pref := s.s.repo.UserSleepPreference().GetDefaultPreference(user.ID)
-	if err := s.s.repo.UserSleepPreference().Create(ctx, pref); err != nil {
+		pref := s.s.repoFor(ctx).UserSleepPreference().GetDefaultPreference(user.ID)
+		return s.s.repoFor(ctx).UserSleepPreference().Create(ctx, pref)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -73,40 +109,66 @@ func (s *UserService) Register(ctx context.Context, email, displayName, password
 
 // ユーザーIDからユーザーを取得
 func (s *UserService) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
-	return s.s.repo.User().GetByID(ctx, userID)
+	return s.s.repoFor(ctx).User().GetByID(ctx, userID)
 }
 
 // ユーザー認証
-func (s *UserService) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
-	user, err := s.s.repo.User().GetByEmail(ctx, email)
+// 失敗が続いた場合は、ログイン試行の制限により *LoginThrottleError を返す
+func (s *UserService) Authenticate(ctx context.Context, email, password, ipAddress string) (*models.User, error) {
+	throttle := s.s.LoginThrottle()
+	if err := throttle.Check(ctx, email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	user, err := s.s.repoFor(ctx).User().GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
-	if user == nil {
-		return nil, errors.New("invalid credentials")
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		if err := throttle.RecordFailure(ctx, email, ipAddress); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, errors.New("invalid credentials")
+	if err := throttle.RecordSuccess(ctx, email); err != nil {
+		s.s.Logger().Error("ログイン試行の状況のリセットに失敗: error=%v, userID=%d", err, user.ID)
 	}
 
-	if err := s.s.repo.User().UpdateLastLogin(ctx, user.ID); err != nil {
+	if err := s.s.repoFor(ctx).User().UpdateLastLogin(ctx, user.ID); err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
+// パスワードを検証
+func (s *UserService) ValidatePassword(ctx context.Context, userID int64, password string) error {
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return errors.New("invalid credentials")
+	}
+
+	return nil
+}
+
 // ユーザーの睡眠設定を取得
 func (s *UserService) GetSleepPreference(ctx context.Context, userID int64) (*models.UserSleepPreference, error) {
-	pref, err := s.s.repo.UserSleepPreference().GetByUserID(ctx, userID)
+	pref, err := s.s.repoFor(ctx).UserSleepPreference().GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 	if pref == nil {
 		// デフォルト設定を作成して返す
-		pref = s.s.repo.UserSleepPreference().GetDefaultPreference(userID)
-		if err := s.s.repo.UserSleepPreference().Create(ctx, pref); err != nil {
+		pref = s.s.repoFor(ctx).UserSleepPreference().GetDefaultPreference(userID)
+		if err := s.s.repoFor(ctx).UserSleepPreference().Create(ctx, pref); err != nil {
 			return nil, err
 		}
 	}
@@ -115,17 +177,199 @@ func (s *UserService) GetSleepPreference(ctx context.Context, userID int64) (*mo
 
 // ユーザーの睡眠設定を更新
 func (s *UserService) UpdateSleepPreference(ctx context.Context, pref *models.UserSleepPreference) error {
-	return s.s.repo.UserSleepPreference().Update(ctx, pref)
+	return s.s.repoFor(ctx).UserSleepPreference().Update(ctx, pref)
+}
+
+// 睡眠設定の部分更新の内容（nil の項目は変更しない）
+type SleepPreferenceUpdate struct {
+	PreferredBedtime    *time.Time
+	PreferredWakeupTime *time.Time
+	SleepGoalHours      *int
+	ReminderEnabled     *bool
+}
+
+// ユーザーの睡眠設定を部分更新
+// リマインダーの有効・無効は、通知設定の就寝時刻のリマインダーにも反映する
+func (s *UserService) PatchSleepPreference(ctx context.Context, userID int64, update SleepPreferenceUpdate) (*models.UserSleepPreference, error) {
+	if update.SleepGoalHours != nil && (*update.SleepGoalHours < 1 || *update.SleepGoalHours > 24) {
+		return nil, ErrInvalidSleepGoal
+	}
+
+	var pref *models.UserSleepPreference
+	err := s.s.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		pref, err = s.GetSleepPreference(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if update.PreferredBedtime != nil {
+			pref.PreferredBedtime = *update.PreferredBedtime
+		}
+		if update.PreferredWakeupTime != nil {
+			pref.PreferredWakeupTime = *update.PreferredWakeupTime
+		}
+		if update.SleepGoalHours != nil {
+			pref.SleepGoalHours = *update.SleepGoalHours
+		}
+		if err := s.s.repoFor(ctx).UserSleepPreference().Update(ctx, pref); err != nil {
+			return err
+		}
+
+		if update.ReminderEnabled == nil {
+			return nil
+		}
+		if _, err := s.UpdateNotificationSettings(ctx, userID, NotificationSettingsUpdate{BedtimeReminder: update.ReminderEnabled}); err != nil {
+			return err
+		}
+		pref.IsReminderEnabled = *update.ReminderEnabled
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pref, nil
+}
+
+// 通知設定の部分更新の内容（nil の項目は変更しない）
+type NotificationSettingsUpdate struct {
+	EmailEnabled    *bool
+	BedtimeReminder *bool
+	ReminderTime    *string
+	WeeklyReport    *bool
+}
+
+// ユーザーの通知設定を取得
+// 未登録の場合は既定値を返し、就寝時刻のリマインダーの有効・無効は睡眠設定に従う
+func (s *UserService) GetNotificationSettings(ctx context.Context, userID int64) (*models.NotificationSettings, error) {
+	settings, err := s.s.repoFor(ctx).NotificationSettings().GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		return settings, nil
+	}
+
+	settings = models.DefaultNotificationSettings(userID)
+	pref, err := s.s.repoFor(ctx).UserSleepPreference().GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pref != nil {
+		settings.BedtimeReminder = pref.IsReminderEnabled
+	}
+
+	return settings, nil
+}
+
+// ユーザーの通知設定を部分更新
+// 就寝時刻のリマインダーの有効・無効は、睡眠設定の他の項目を変えずに睡眠設定にも反映する
+func (s *UserService) UpdateNotificationSettings(ctx context.Context, userID int64, update NotificationSettingsUpdate) (*models.NotificationSettings, error) {
+	if update.ReminderTime != nil {
+		reminderTime := strings.TrimSpace(*update.ReminderTime)
+		if reminderTime != "" {
+			if _, err := time.Parse(models.ReminderTimeFormat, reminderTime); err != nil {
+				return nil, ErrInvalidReminderTime
+			}
+		}
+		update.ReminderTime = &reminderTime
+	}
+
+	var settings *models.NotificationSettings
+	err := s.s.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		settings, err = s.GetNotificationSettings(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if update.EmailEnabled != nil {
+			settings.EmailEnabled = *update.EmailEnabled
+		}
+		if update.BedtimeReminder != nil {
+			settings.BedtimeReminder = *update.BedtimeReminder
+		}
+		if update.ReminderTime != nil {
+			settings.ReminderTime = *update.ReminderTime
+		}
+		if update.WeeklyReport != nil {
+			settings.WeeklyReport = *update.WeeklyReport
+		}
+
+		if settings.ID == 0 {
+			err = s.s.repoFor(ctx).NotificationSettings().Create(ctx, settings)
+		} else {
+			err = s.s.repoFor(ctx).NotificationSettings().Update(ctx, settings)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 睡眠設定のリマインダーの有効・無効をあわせる
+		pref, err := s.s.repoFor(ctx).UserSleepPreference().GetByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if pref == nil || pref.IsReminderEnabled == settings.BedtimeReminder {
+			return nil
+		}
+		pref.IsReminderEnabled = settings.BedtimeReminder
+		return s.s.repoFor(ctx).UserSleepPreference().Update(ctx, pref)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
 }
 
 // ユーザープロフィールを更新
+// 表示名・メールアドレス・タイムゾーンのうち、空でない項目だけを既存のユーザー情報に反映する
 func (s *UserService) UpdateProfile(ctx context.Context, user *models.User) error {
-	return s.s.repo.User().Update(ctx, user)
+	if user.TimeZone != "" {
+		if _, err := time.LoadLocation(user.TimeZone); err != nil {
+			return ErrInvalidTimezone
+		}
+	}
+
+	return s.s.Transaction(ctx, func(ctx context.Context) error {
+		current, err := s.s.repoFor(ctx).User().GetByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return errors.New("user not found")
+		}
+
+		if user.DisplayName != "" {
+			current.DisplayName = user.DisplayName
+		}
+		if user.Email != "" && user.Email != current.Email {
+			existing, err := s.s.repoFor(ctx).User().GetByEmail(ctx, user.Email)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return ErrEmailAlreadyExists
+			}
+			current.Email = user.Email
+		}
+		if user.TimeZone != "" {
+			current.TimeZone = user.TimeZone
+		}
+
+		if err := s.s.repoFor(ctx).User().Update(ctx, current); err != nil {
+			return err
+		}
+		*user = *current
+		return nil
+	})
 }
 
 // パスワードを更新
 func (s *UserService) UpdatePassword(ctx context.Context, userID int64, currentPassword, newPassword string) error {
-	user, err := s.s.repo.User().GetByID(ctx, userID)
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -140,36 +384,185 @@ func (s *UserService) UpdatePassword(ctx context.Context, userID int64, currentP
 	}
 
 	user.PasswordHash = string(hash)
-	return s.s.repo.User().Update(ctx, user)
+
+	// パスワードの変更前に発行した再設定用のトークンは無効にする
+	return s.s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.s.repoFor(ctx).User().Update(ctx, user); err != nil {
+			return err
+		}
+		return s.s.repoFor(ctx).PasswordResetToken().DeleteByUserID(ctx, userID)
+	})
 }
 
 // アカウントを削除
+// 削除の完了はメールで通知する（送信に失敗しても削除は取り消さない）
 func (s *UserService) DeleteAccount(ctx context.Context, userID int64) error {
-	return s.s.Transaction(ctx, func(ctx context.Context) error {
-		if err := s.s.repo.UserSleepPreference().Delete(ctx, userID); err != nil {
+	user, err := s.s.repoFor(ctx).User().GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.s.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.s.repoFor(ctx).UserSleepPreference().Delete(ctx, userID); err != nil {
+			return err
+		}
+		if err := s.s.Session().DestroyUserSessions(ctx, userID); err != nil {
+			return err
+		}
+		if err := s.s.repoFor(ctx).PasswordResetToken().DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if err := s.s.repoFor(ctx).NotificationSettings().Delete(ctx, userID); err != nil {
+			return err
+		}
+		if err := s.s.repoFor(ctx).PersonalAccessToken().DeleteByUserID(ctx, userID); err != nil {
 			return err
 		}
-		return s.s.repo.User().Delete(ctx, userID)
+		return s.s.repoFor(ctx).User().Delete(ctx, userID)
 	})
+	if err != nil {
+		return err
+	}
+
+	if user != nil {
+		if err := s.s.Email().SendAccountDeletionEmail(ctx, user.Email, user.DisplayName); err != nil {
+			s.s.Logger().Error("アカウント削除の完了メールの送信に失敗: error=%v, userID=%d", err, userID)
+		}
+	}
+
+	return nil
 }
 
 // パスワードリセットの開始
+// トークンを発行して再設定用のリンクをメールで送信し、発行したトークンを返す
+// 登録されていないメールアドレスの場合は、登録の有無がわからないよう何もせずに空文字を返す
 func (s *UserService) InitiatePasswordReset(ctx context.Context, email string) (string, error) {
-    // TODO: 実装
-    // トークン生成とデータベースへの保存
-    return "reset_token", nil
+	user, err := s.s.repoFor(ctx).User().GetByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		s.s.Logger().Info("未登録のメールアドレスへのパスワードリセット: email=%s", email)
+		return "", nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return "", err
+	}
+
+	// 発行済みのトークンは無効にして、最新のトークンのみを有効にする
+	err = s.s.Transaction(ctx, func(ctx context.Context) error {
+		tokens := s.s.repoFor(ctx).PasswordResetToken()
+		if err := tokens.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		return tokens.Create(ctx, &models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hashResetToken(token),
+			Expires:   s.now().Add(s.resetTokenLifetime),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resetURL := s.s.BaseURL() + "/reset-password/" + token
+	if err := s.s.Email().SendPasswordResetEmail(ctx, user.Email, user.DisplayName, resetURL, s.resetTokenLifetime); err != nil {
+		return "", err
+	}
+
+	return token, nil
 }
 
 // リセットトークンの検証
 func (s *UserService) ValidateResetToken(ctx context.Context, token string) (bool, error) {
-    // TODO: 実装
-    // トークンの検証
-    return true, nil
+	_, err := s.findResetToken(ctx, token)
+	if errors.Is(err, ErrInvalidResetToken) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // パスワードリセットの実行
+// トークンは一度だけ利用でき、パスワードの更新と同時にユーザーのトークンとセッションをすべて無効にする
 func (s *UserService) CompletePasswordReset(ctx context.Context, token, newPassword string) error {
-    // この関数が未実装のため追加
-    // ...実装...
-    return nil
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	var user *models.User
+	err = s.s.Transaction(ctx, func(ctx context.Context) error {
+		resetToken, err := s.findResetToken(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		user, err = s.s.repoFor(ctx).User().GetByID(ctx, resetToken.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrInvalidResetToken
+		}
+
+		user.PasswordHash = string(hash)
+		if err := s.s.repoFor(ctx).User().Update(ctx, user); err != nil {
+			return err
+		}
+		if err := s.s.repoFor(ctx).PasswordResetToken().DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		return s.s.Session().DestroyUserSessions(ctx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// ログインの失敗によるロックを解除
+	if err := s.s.LoginThrottle().Unlock(ctx, user.Email); err != nil {
+		s.s.Logger().Error("アカウントのロック解除に失敗: error=%v, userID=%d", err, user.ID)
+	}
+
+	return nil
+}
+
+// 有効期限切れのパスワード再設定用トークンを削除
+func (s *UserService) PurgeExpiredResetTokens(ctx context.Context) (int64, error) {
+	return s.s.repoFor(ctx).PasswordResetToken().DeleteExpired(ctx, s.now())
+}
+
+// 有効なパスワード再設定用トークンを取得（無効か期限切れの場合は ErrInvalidResetToken）
+func (s *UserService) findResetToken(ctx context.Context, token string) (*models.PasswordResetToken, error) {
+	if token == "" {
+		return nil, ErrInvalidResetToken
+	}
+
+	resetToken, err := s.s.repoFor(ctx).PasswordResetToken().GetByTokenHash(ctx, hashResetToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if resetToken == nil || resetToken.IsExpired(s.now()) {
+		return nil, ErrInvalidResetToken
+	}
+	return resetToken, nil
+}
+
+// パスワード再設定用のトークンを生成（URLに含めるためBase64URL形式）
+func generateResetToken() (string, error) {
+	b := make([]byte, resetTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// 保存用のトークンのハッシュ値（SHA-256の16進数）
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
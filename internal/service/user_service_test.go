@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+// 保存されている通知設定を取得（ID・作成日時・更新日時は比較しない、未登録の場合はnil）
+func storedNotificationSettings(t *testing.T, s *Service, userID int64) *models.NotificationSettings {
+	t.Helper()
+	settings, err := s.repo.NotificationSettings().GetByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("failed to get notification settings: %v", err)
+	}
+	if settings == nil {
+		return nil
+	}
+	copied := *settings
+	copied.ID = 0
+	copied.Created = time.Time{}
+	copied.Modified = time.Time{}
+	return &copied
+}
+
+// 通知設定の未登録時の既定値を確認
+func TestGetNotificationSettingsDefault(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	user := createTestUser(t, s, "notification@example.com")
+
+	settings, err := s.User().GetNotificationSettings(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetNotificationSettings: %v", err)
+	}
+	if *settings != *models.DefaultNotificationSettings(user.ID) {
+		t.Errorf("settings = %+v, want defaults", settings)
+	}
+	// 取得しただけでは登録しない
+	if stored := storedNotificationSettings(t, s, user.ID); stored != nil {
+		t.Errorf("stored settings = %+v, want none", stored)
+	}
+
+	// 就寝時刻のリマインダーは睡眠設定に従う
+	pref := s.repo.UserSleepPreference().GetDefaultPreference(user.ID)
+	pref.IsReminderEnabled = false
+	if err := s.repo.UserSleepPreference().Create(ctx, pref); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = s.User().GetNotificationSettings(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetNotificationSettings: %v", err)
+	}
+	if settings.BedtimeReminder {
+		t.Error("bedtime reminder = true, want false from the sleep preference")
+	}
+}
+
+// 通知設定の部分更新と、睡眠設定のリマインダーとの同期を確認
+func TestUpdateNotificationSettings(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	user := createTestUser(t, s, "notification@example.com")
+	enabled, disabled := true, false
+
+	// 指定した項目のみ変更し、未登録の場合は既定値に反映して登録する
+	if _, err := s.User().UpdateNotificationSettings(ctx, user.ID, NotificationSettingsUpdate{WeeklyReport: &enabled}); err != nil {
+		t.Fatalf("UpdateNotificationSettings: %v", err)
+	}
+	want := models.DefaultNotificationSettings(user.ID)
+	want.WeeklyReport = true
+	if got := storedNotificationSettings(t, s, user.ID); got == nil || *got != *want {
+		t.Fatalf("stored = %+v, want %+v", got, want)
+	}
+
+	// リマインダーの時刻は前後の空白を除く
+	reminderTime := " 22:30 "
+	settings, err := s.User().UpdateNotificationSettings(ctx, user.ID, NotificationSettingsUpdate{ReminderTime: &reminderTime, EmailEnabled: &disabled})
+	if err != nil {
+		t.Fatalf("UpdateNotificationSettings: %v", err)
+	}
+	want.ReminderTime = "22:30"
+	want.EmailEnabled = false
+	if got := storedNotificationSettings(t, s, user.ID); got == nil || *got != *want {
+		t.Fatalf("stored = %+v, want %+v", got, want)
+	}
+	if settings.ReminderTime != "22:30" || settings.ID == 0 {
+		t.Errorf("returned settings = %+v", settings)
+	}
+
+	// 空の時刻は就寝時刻の一定時間前に戻す
+	empty := ""
+	if _, err := s.User().UpdateNotificationSettings(ctx, user.ID, NotificationSettingsUpdate{ReminderTime: &empty}); err != nil {
+		t.Fatalf("UpdateNotificationSettings: %v", err)
+	}
+	want.ReminderTime = ""
+	if got := storedNotificationSettings(t, s, user.ID); got == nil || *got != *want {
+		t.Fatalf("stored = %+v, want %+v", got, want)
+	}
+
+	// 就寝時刻のリマインダーは、睡眠設定の他の項目を変えずに睡眠設定にも反映する
+	goal := 9
+	if _, err := s.User().PatchSleepPreference(ctx, user.ID, SleepPreferenceUpdate{SleepGoalHours: &goal}); err != nil {
+		t.Fatalf("PatchSleepPreference: %v", err)
+	}
+	if _, err := s.User().UpdateNotificationSettings(ctx, user.ID, NotificationSettingsUpdate{BedtimeReminder: &disabled}); err != nil {
+		t.Fatalf("UpdateNotificationSettings: %v", err)
+	}
+	pref, err := s.repo.UserSleepPreference().GetByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pref.IsReminderEnabled || pref.SleepGoalHours != 9 {
+		t.Errorf("sleep preference = %+v, want reminder disabled and goal 9", pref)
+	}
+
+	// 睡眠設定で有効にすると、通知設定にも反映する
+	if _, err := s.User().PatchSleepPreference(ctx, user.ID, SleepPreferenceUpdate{ReminderEnabled: &enabled}); err != nil {
+		t.Fatalf("PatchSleepPreference: %v", err)
+	}
+	want.BedtimeReminder = true
+	if got := storedNotificationSettings(t, s, user.ID); got == nil || *got != *want {
+		t.Fatalf("stored = %+v, want %+v", got, want)
+	}
+}
+
+// 正しくないリマインダーの時刻は、何も変更せずにエラーを返すことを確認
+func TestUpdateNotificationSettingsInvalidTime(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	user := createTestUser(t, s, "notification@example.com")
+	enabled := true
+
+	for _, reminderTime := range []string{"25:00", "22:60", "22:3", "10pm", "22:30:00", "2230"} {
+		t.Run(reminderTime, func(t *testing.T) {
+			reminderTime := reminderTime
+			_, err := s.User().UpdateNotificationSettings(ctx, user.ID, NotificationSettingsUpdate{ReminderTime: &reminderTime, WeeklyReport: &enabled})
+			if !errors.Is(err, ErrInvalidReminderTime) {
+				t.Fatalf("error = %v, want %v", err, ErrInvalidReminderTime)
+			}
+			if stored := storedNotificationSettings(t, s, user.ID); stored != nil {
+				t.Fatalf("stored settings = %+v, want none", stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// internal/service/weekly_report_service.go
+// weekly_report_serviceは、週間レポート（前週の睡眠のまとめ）を作成してメールで送信するサービスを提供します。
+// 週は月曜日から日曜日までとし、ユーザーのタイムゾーンで月曜日の送信時刻を過ぎたら前週のレポートを送信します。
+// 送信済みの記録はデータベースに保存するため、再起動しても同じ週のレポートを二重に送信しません。
+
+// Package service provides application services.
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/223n-tech/SuiminNisshi-Go/internal/mail"
+	"github.com/223n-tech/SuiminNisshi-Go/internal/models"
+)
+
+const (
+	// 週間レポートを送信する、月曜日の時刻（ユーザーのタイムゾーン）
+	DefaultWeeklyReportHour = 8
+	// 既定の、週間レポートの送信チェックの間隔
+	DefaultWeeklyReportInterval = time.Hour
+)
+
+// 週間レポート
+type WeeklyReport struct {
+	StartDate           time.Time        // 対象週の月曜日
+	EndDate             time.Time        // 対象週の日曜日
+	NightsRecorded      int              // 記録した夜の数
+	AverageSleep        time.Duration    // 平均睡眠時間
+	AverageBedTime      string           // 平均就寝時刻（HH:MM）
+	AverageWakeTime     string           // 平均起床時刻（HH:MM）
+	BedtimeVariation    time.Duration    // 就寝時刻のばらつき（標準偏差）
+	WakeTimeVariation   time.Duration    // 起床時刻のばらつき（標準偏差）
+	SleepGoalHours      int              // 目標睡眠時間
+	GoalAchievementRate float64          // 目標の達成率（%）
+	Statistics          *SleepStatistics // 集計元の統計データ
+}
+
+// 週間レポートのサービス
+type WeeklyReportService struct {
+	s         *Service
+	attachPDF bool
+	now       func() time.Time
+}
+
+// 新しいWeeklyReportServiceを作成
+func NewWeeklyReportService(s *Service) *WeeklyReportService {
+	return &WeeklyReportService{
+		s:         s,
+		attachPDF: true,
+		now:       time.Now,
+	}
+}
+
+// 週間レポートのメールにPDFを添付するかを設定
+func (s *WeeklyReportService) SetAttachPDF(attach bool) {
+	s.attachPDF = attach
+}
+
+// 現在時刻の取得方法を差し替え（テスト用）
+func (s *WeeklyReportService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// ユーザーのタイムゾーンで、今週の月曜日を取得
+func (s *WeeklyReportService) CurrentWeek(user *models.User) time.Time {
+	return weekStart(s.now().In(user.Location()))
+}
+
+// 週間レポートを作成
+// date を含む週の月曜日から日曜日までを集計する
+func (s *WeeklyReportService) BuildReport(ctx context.Context, userID int64, date time.Time) (*WeeklyReport, error) {
+	start := weekStart(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC))
+	end := start.AddDate(0, 0, 6)
+
+	stats, err := s.s.Record().GetStatistics(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WeeklyReport{
+		StartDate:           start,
+		EndDate:             end,
+		NightsRecorded:      stats.RecordedNights,
+		AverageSleep:        hoursToDuration(stats.AverageTotalSleepHours),
+		AverageBedTime:      stats.AverageBedTime,
+		AverageWakeTime:     stats.AverageWakeTime,
+		BedtimeVariation:    minutesToDuration(stats.BedTimeDeviationMinutes),
+		WakeTimeVariation:   minutesToDuration(stats.WakeTimeDeviationMinutes),
+		SleepGoalHours:      stats.SleepGoalHours,
+		GoalAchievementRate: stats.TargetAchievementRate,
+		Statistics:          stats,
+	}, nil
+}
+
+// 週間レポートを閲覧するURLを取得
+func (s *WeeklyReportService) ReportURL(weekStart time.Time) string {
+	return s.s.BaseURL() + "/reports/weekly?" + url.Values{"start": {weekStart.Format("2006-01-02")}}.Encode()
+}
+
+// 週間レポートのPDFを生成
+func (s *WeeklyReportService) GeneratePDF(ctx context.Context, userID int64, report *WeeklyReport) ([]byte, error) {
+	return s.s.PDF().GenerateStatisticsPDF(ctx, userID, &models.PDFExportOptions{
+		StartDate: report.StartDate,
+		EndDate:   report.EndDate,
+	})
+}
+
+// 送信時刻になった前週の週間レポートを送信し、送信した件数を返す
+// ユーザーごとのエラーはログに記録して、他のユーザーの処理を続ける
+func (s *WeeklyReportService) SendDue(ctx context.Context) (int, error) {
+	users, err := s.s.repoFor(ctx).User().GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	sent := 0
+	for _, user := range users {
+		ok, err := s.sendDue(ctx, user, now)
+		if err != nil {
+			s.s.Logger().Error("週間レポートの送信に失敗: error=%v, user_id=%d", err, user.ID)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// 指定した間隔で週間レポートの送信をチェック（ctxが終了するまで続ける）
+func (s *WeeklyReportService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWeeklyReportInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SendDue(ctx); err != nil {
+			s.s.Logger().Error("週間レポートのチェックに失敗: error=%v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ユーザーの前週の週間レポートが送信時刻になっていれば送信
+func (s *WeeklyReportService) sendDue(ctx context.Context, user *models.User, now time.Time) (bool, error) {
+	settings, err := s.s.User().GetNotificationSettings(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	if !settings.EmailEnabled || !settings.WeeklyReport {
+		return false, nil
+	}
+
+	// 月曜日は送信時刻まで待つ
+	local := now.In(user.Location())
+	if local.Weekday() == time.Monday && local.Hour() < DefaultWeeklyReportHour {
+		return false, nil
+	}
+
+	previous := weekStart(local).AddDate(0, 0, -7)
+	deliveries := s.s.repoFor(ctx).NotificationDelivery()
+	periodKey := previous.Format("2006-01-02")
+	exists, err := deliveries.Exists(ctx, user.ID, models.NotificationKindWeeklyReport, periodKey)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	report, err := s.BuildReport(ctx, user.ID, previous)
+	if err != nil {
+		return false, err
+	}
+	// 記録のない週は送信しない（週の途中で前週分を記録した場合は、その後に送信する）
+	if report.NightsRecorded == 0 {
+		return false, nil
+	}
+
+	var attachments []mail.Attachment
+	if s.attachPDF {
+		// PDFを生成できない場合も、本文のみで送信する
+		pdf, err := s.GeneratePDF(ctx, user.ID, report)
+		if err != nil {
+			s.s.Logger().Error("週間レポートのPDFの生成に失敗: error=%v, user_id=%d", err, user.ID)
+		} else {
+			attachments = append(attachments, mail.Attachment{
+				Filename:    fmt.Sprintf("sleep-report_%s.pdf", periodKey),
+				ContentType: "application/pdf",
+				Data:        pdf,
+			})
+		}
+	}
+
+	err = s.s.Email().SendWeeklyReportEmail(ctx, user.Email, user.DisplayName, report, s.ReportURL(previous), attachments...)
+	if err != nil {
+		return false, err
+	}
+
+	delivery := &models.NotificationDelivery{
+		UserID:    user.ID,
+		Kind:      models.NotificationKindWeeklyReport,
+		PeriodKey: periodKey,
+	}
+	if err := deliveries.Create(ctx, delivery); err != nil {
+		return false, err
+	}
+
+	s.s.Logger().Info("週間レポートを送信: user_id=%d, week=%s", user.ID, periodKey)
+	return true, nil
+}
+
+// 週の始まり（月曜日の0時）を取得
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// 時間数を time.Duration に変換
+func hoursToDuration(hours float64) time.Duration {
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// 分数を time.Duration に変換
+func minutesToDuration(minutes float64) time.Duration {
+	return time.Duration(minutes * float64(time.Minute))
+}
@@ -0,0 +1,16 @@
+// CSRFトークンをAjaxリクエストのヘッダーに設定する
+(function () {
+  const meta = document.querySelector('meta[name="csrf-token"]');
+  if (!meta || !window.jQuery) {
+    return;
+  }
+  const token = meta.getAttribute("content");
+
+  window.jQuery.ajaxSetup({
+    beforeSend: function (xhr, settings) {
+      if (!/^(GET|HEAD|OPTIONS|TRACE)$/i.test(settings.type)) {
+        xhr.setRequestHeader("X-CSRF-Token", token);
+      }
+    },
+  });
+})();